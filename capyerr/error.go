@@ -101,3 +101,33 @@ func (e *ProcessorNotFoundType) Message() string {
 func (e *ProcessorNotFoundType) OriginalError() error {
 	return nil
 }
+
+type PresignedUploadsNotSupportedType struct {
+	code    string
+	message string
+	// Allows us building chained errors.
+	errs []error
+}
+
+func NewPresignedUploadsNotSupportedError(processorName string) *PresignedUploadsNotSupportedType {
+	return &PresignedUploadsNotSupportedType{
+		code:    "PRESIGNED_UPLOADS_NOT_SUPPORTED",
+		message: fmt.Sprintf("processor %s does not support presigned uploads", processorName),
+	}
+}
+
+func (e *PresignedUploadsNotSupportedType) Error() string {
+	return e.message
+}
+
+func (e *PresignedUploadsNotSupportedType) Code() string {
+	return e.code
+}
+
+func (e *PresignedUploadsNotSupportedType) Message() string {
+	return e.message
+}
+
+func (e *PresignedUploadsNotSupportedType) OriginalError() error {
+	return nil
+}
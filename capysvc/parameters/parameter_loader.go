@@ -86,3 +86,12 @@ func (parameterLoader *genericParameterLoader) LoadStringArrayValue() ([]string,
 		parameterLoader.etcdClient,
 	)
 }
+
+func (parameterLoader *genericParameterLoader) LoadStringMapValue() (map[string]string, error) {
+	return retrieveStringMapParameterValue(
+		parameterLoader.sourceType,
+		parameterLoader.source,
+		parameterLoader.req,
+		parameterLoader.etcdClient,
+	)
+}
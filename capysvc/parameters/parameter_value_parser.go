@@ -109,6 +109,32 @@ func retrieveStringArrayParameterValue(
 	return parameterValue, err
 }
 
+// retrieveStringMapParameterValue Same as for the string array, anything but the "value" source type should be JSON.
+func retrieveStringMapParameterValue(
+	valueType string,
+	value any,
+	request *http.Request,
+	etcdClient *clientv3.Client,
+) (map[string]string, error) {
+	if valueType == "value" {
+		return anyToStringMapValue(value)
+	}
+
+	rawValue, rawValueErr := retrieveStringParameterValue(valueType, value, request, etcdClient)
+	if rawValueErr != nil {
+		return map[string]string{}, rawValueErr
+	}
+
+	if len(rawValue) == 0 {
+		return map[string]string{}, errors.New("failed to retrieve string map parameter")
+	}
+
+	var parameterValue map[string]string
+	err := json.Unmarshal([]byte(rawValue), &parameterValue)
+
+	return parameterValue, err
+}
+
 func retrieveStringParameterValue(
 	valueType string,
 	value any,
@@ -424,3 +450,25 @@ func anyToStringArrayValue(value any) []string {
 
 	return nil
 }
+
+func anyToStringMapValue(value any) (map[string]string, error) {
+	if v, ok := value.(map[string]string); ok {
+		return v, nil
+	}
+
+	if v, ok := value.(map[string]interface{}); ok {
+		values := make(map[string]string, len(v))
+		for key := range v {
+			sv, ok := v[key].(string)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse string map value of the key \"%s\"", key)
+			}
+
+			values[key] = sv
+		}
+
+		return values, nil
+	}
+
+	return nil, errors.New("failed to parse string map value")
+}
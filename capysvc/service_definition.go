@@ -73,6 +73,48 @@ func (s *Service) RunProcessorConcurrentlyInLockMode(
 	)
 }
 
+// IssuePresignedUploads Issues the presigned requests to upload the files directly to the storage.
+// The first operation of the processor must be able to issue them. When the files are uploaded,
+// the processor is run as usual, and the first operation reads the uploaded files.
+func (s *Service) IssuePresignedUploads(
+	ctx Context,
+	processorName string,
+	filenames []string,
+) ([]operations.PresignedUpload, error) {
+	proc := s.FindProcessor(processorName)
+	if proc == nil {
+		return nil, capyerr.NewProcessorNotFoundError(processorName)
+	}
+
+	firstOp, _ := proc.firstAndLastOperations()
+	if firstOp == nil {
+		return nil, capyerr.NewPresignedUploadsNotSupportedError(processorName)
+	}
+
+	// The handler is created for this call only, since the processor may be running meanwhile.
+	handler, handlerErr := firstOp.newHandler(ctx)
+	if handlerErr != nil {
+		return nil, handlerErr
+	}
+
+	issuer, ok := handler.(operations.PresignedUploadIssuer)
+	if !ok {
+		return nil, capyerr.NewPresignedUploadsNotSupportedError(processorName)
+	}
+
+	var uploads []operations.PresignedUpload
+	for _, filename := range filenames {
+		upload, issueErr := issuer.IssuePresignedUpload(filename)
+		if issueErr != nil {
+			return nil, issueErr
+		}
+
+		uploads = append(uploads, *upload)
+	}
+
+	return uploads, nil
+}
+
 type Processor struct {
 	Name       string      `json:"name" yaml:"name"`
 	Operations []Operation `json:"operations" yaml:"operations"`
@@ -99,18 +141,27 @@ func (p *Processor) RunOperations(
 				errorCh <- operations.NewOperationError(op.Name, handlerErr)
 			}
 
+			p.finishOperations(nil, errorCh, notificationCh)
+
 			return nil, handlerErr
 		}
 
 		targetIn, skipIn := splitIntoTargetSkip(op.TargetFiles, op.operationState.io.in)
 
-		opHandleOut, opHandlerErr := handler.Handle(targetIn, errorCh, notificationCh)
-		if opHandlerErr != nil {
-			if errorCh != nil {
-				errorCh <- operations.NewOperationInputError(op.Name, op.operationState.io.in, opHandlerErr)
+		var opHandleOut []files.ProcessableFile
+		for _, packet := range splitIntoPackets(op.MaxPacketSize, targetIn) {
+			packetOut, opHandlerErr := handler.Handle(packet, errorCh, notificationCh)
+			if opHandlerErr != nil {
+				if errorCh != nil {
+					errorCh <- operations.NewOperationInputError(op.Name, op.operationState.io.in, opHandlerErr)
+				}
+
+				p.finishOperations(nil, errorCh, notificationCh)
+
+				return append(opHandleOut, packetOut...), opHandlerErr
 			}
 
-			return opHandleOut, opHandlerErr
+			opHandleOut = append(opHandleOut, packetOut...)
 		}
 
 		assignCleanupPolicy(op.CleanupPolicy, opHandleOut)
@@ -122,6 +173,8 @@ func (p *Processor) RunOperations(
 		}
 	}
 
+	p.finishOperations(lastOp.operationState.io.out, errorCh, notificationCh)
+
 	return lastOp.operationState.io.out, nil
 }
 
@@ -152,6 +205,8 @@ func (p *Processor) RunOperationsConcurrentlyInLockMode(
 				errorCh <- operations.NewOperationError(op.Name, handlerErr)
 			}
 
+			p.finishOperations(nil, errorCh, notificationCh)
+
 			return nil, handlerErr
 		}
 
@@ -197,13 +252,20 @@ func (p *Processor) RunOperationsConcurrentlyInLockMode(
 			handleFn := func(in []files.ProcessableFile) (out []files.ProcessableFile) {
 				targetIn, skipIn := splitIntoTargetSkip(op.TargetFiles, in)
 
-				opHandleOut, opHandleErr := handler.Handle(targetIn, errorCh, notificationCh)
-				if opHandleErr != nil {
-					// Perhaps maybe this is something that is related to the specific file,
-					// so we can just send an error to the channel and continue.
-					if errorCh != nil {
-						errorCh <- operations.NewOperationInputError(op.Name, targetIn, opHandleErr)
+				// The non-concurrent operations receive all the input at once, so here
+				// we also need to ensure that the max packet size is respected.
+				var opHandleOut []files.ProcessableFile
+				for _, packet := range splitIntoPackets(op.MaxPacketSize, targetIn) {
+					packetOut, opHandleErr := handler.Handle(packet, errorCh, notificationCh)
+					if opHandleErr != nil {
+						// Perhaps maybe this is something that is related to the specific file,
+						// so we can just send an error to the channel and continue.
+						if errorCh != nil {
+							errorCh <- operations.NewOperationInputError(op.Name, packet, opHandleErr)
+						}
 					}
+
+					opHandleOut = append(opHandleOut, packetOut...)
 				}
 
 				if len(skipIn) > 0 {
@@ -301,6 +363,8 @@ func (p *Processor) RunOperationsConcurrentlyInLockMode(
 	procWg.Wait()
 	completeWg.Wait()
 
+	p.finishOperations(lastOp.operationState.io.out, errorCh, notificationCh)
+
 	return lastOp.operationState.io.out, nil
 }
 
@@ -389,11 +453,7 @@ func (p *Processor) RunOperationsConcurrentlyInEventMode(
 
 					if op.MaxPacketSize > 0 {
 						// Here we are chunking the input into the pieces based on the max packet size.
-						var chunks [][]files.ProcessableFile
-						for op.MaxPacketSize < len(targetIn) {
-							targetIn, chunks = targetIn[op.MaxPacketSize:], append(chunks, targetIn[0:op.MaxPacketSize:op.MaxPacketSize])
-						}
-						chunks = append(chunks, targetIn)
+						chunks := splitIntoPackets(op.MaxPacketSize, targetIn)
 
 						// Right now we process the chunks in parallel.
 						wg := &sync.WaitGroup{}
@@ -520,9 +580,30 @@ func (p *Processor) RunOperationsConcurrentlyInEventMode(
 
 	completeWg.Wait()
 
+	p.finishOperations(lastOp.operationState.io.out, errorCh, notificationCh)
+
 	return lastOp.operationState.io.out, nil
 }
 
+// finishOperations Lets the operations know the pipeline has finished. The out is nil if the pipeline has failed.
+func (p *Processor) finishOperations(
+	out []files.ProcessableFile,
+	errorCh chan<- operations.OperationError,
+	notificationCh chan<- operations.OperationNotification,
+) {
+	for i := range p.Operations {
+		op := &p.Operations[i]
+
+		op.operationState.handlerLock.Lock()
+		handler := op.operationState.handler
+		op.operationState.handlerLock.Unlock()
+
+		if finishedHandler, ok := handler.(operations.PipelineFinishedHandler); ok {
+			finishedHandler.HandlePipelineFinished(out, errorCh, notificationCh)
+		}
+	}
+}
+
 // InitOperations initializes the operations before running the pipeline.
 //
 // Use this method when you run the pipeline first time or when you want to
@@ -709,6 +790,19 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 		return o.operationState.handler, nil
 	}
 
+	oh, ohErr := o.newHandler(ctx)
+	if ohErr != nil {
+		return nil, ohErr
+	}
+
+	o.operationState.handler = oh
+
+	return oh, nil
+}
+
+// newHandler Creates the operation handler. Unlike Handler, it does not touch the operation state,
+// so it can be used while the pipeline is running.
+func (o *Operation) newHandler(ctx Context) (operations.OperationHandler, error) {
 	var oh operations.OperationHandler
 	var ohErr error
 
@@ -755,6 +849,27 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 			parameterLoaderProvider,
 		)
 		break
+	case "image_dimensions_validate":
+		oh, ohErr = opfactories.NewImageDimensionsValidateOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "content_validate":
+		oh, ohErr = opfactories.NewContentValidateOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "virus_scan":
+		oh, ohErr = opfactories.NewVirusScanOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
 	case "exiftool_metadata_cleanup":
 		oh, ohErr = opfactories.NewExiftoolMetadataCleanupOperation(
 			o.Name,
@@ -769,6 +884,86 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 			parameterLoaderProvider,
 		)
 		break
+	case "pdf_process":
+		oh, ohErr = opfactories.NewPDFProcessOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "media_probe":
+		oh, ohErr = opfactories.NewMediaProbeOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "media_validate":
+		oh, ohErr = opfactories.NewMediaValidateOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "media_transcode":
+		oh, ohErr = opfactories.NewMediaTranscodeOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "text_transcode":
+		oh, ohErr = opfactories.NewTextTranscodeOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_encrypt":
+		oh, ohErr = opfactories.NewFileEncryptOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_decrypt":
+		oh, ohErr = opfactories.NewFileDecryptOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_sign":
+		oh, ohErr = opfactories.NewFileSignOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_verify_signature":
+		oh, ohErr = opfactories.NewFileVerifySignatureOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_split":
+		oh, ohErr = opfactories.NewFileSplitOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "file_join":
+		oh, ohErr = opfactories.NewFileJoinOperation(o.Name)
+		break
+	case "file_rename":
+		oh, ohErr = opfactories.NewFileRenameOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
 	case "s3_upload":
 		oh, ohErr = opfactories.NewS3UploadOperation(
 			o.Name,
@@ -776,6 +971,54 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 			parameterLoaderProvider,
 		)
 		break
+	case "gcs_upload":
+		oh, ohErr = opfactories.NewGCSUploadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "azure_blob_upload":
+		oh, ohErr = opfactories.NewAzureBlobUploadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "s3_input_read":
+		oh, ohErr = opfactories.NewS3InputReadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "s3_presigned_upload_input_read":
+		req := ctx.Request()
+		if req == nil {
+			return nil, errors.New("http request is not available in the given context")
+		}
+
+		oh, ohErr = opfactories.NewS3PresignedUploadInputReadOperation(
+			o.Name,
+			req,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "s3_object_delete":
+		oh, ohErr = opfactories.NewS3ObjectDeleteOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "s3_object_move":
+		oh, ohErr = opfactories.NewS3ObjectMoveOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
 	case "filesystem_input_read":
 		oh, ohErr = opfactories.NewFilesystemInputReadOperation(
 			o.Name,
@@ -790,6 +1033,20 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 			parameterLoaderProvider,
 		)
 		break
+	case "filesystem_input_move":
+		oh, ohErr = opfactories.NewFilesystemInputMoveOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "filesystem_input_copy":
+		oh, ohErr = opfactories.NewFilesystemInputCopyOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
 	case "filesystem_input_remove":
 		oh, ohErr = opfactories.NewFilesystemInputRemoveOperation(
 			o.Name,
@@ -800,6 +1057,79 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 	case "input_forget":
 		oh, ohErr = opfactories.NewInputForgetOperation(o.Name)
 		break
+	case "sftp_upload":
+		oh, ohErr = opfactories.NewSFTPUploadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "sftp_input_read":
+		oh, ohErr = opfactories.NewSFTPInputReadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "http_upload":
+		oh, ohErr = opfactories.NewHTTPUploadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "http_input_read":
+		// The request is only needed to read the URLs from its body, so it can be nil.
+		oh, ohErr = opfactories.NewHTTPInputReadOperation(
+			o.Name,
+			ctx.Request(),
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "sqs_input_read":
+		// The queue is polled, so it can only be read by the worker.
+		if _, isWorkerContext := ctx.(*WorkerContext); !isWorkerContext {
+			return nil, errors.New("queue input is only available in the worker context")
+		}
+
+		oh, ohErr = opfactories.NewSQSInputReadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "redis_stream_input_read":
+		// The queue is polled, so it can only be read by the worker.
+		if _, isWorkerContext := ctx.(*WorkerContext); !isWorkerContext {
+			return nil, errors.New("queue input is only available in the worker context")
+		}
+
+		oh, ohErr = opfactories.NewRedisStreamInputReadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "nats_input_read":
+		// The queue is polled, so it can only be read by the worker.
+		if _, isWorkerContext := ctx.(*WorkerContext); !isWorkerContext {
+			return nil, errors.New("queue input is only available in the worker context")
+		}
+
+		oh, ohErr = opfactories.NewNATSInputReadOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
+	case "event_publish":
+		oh, ohErr = opfactories.NewEventPublishOperation(
+			o.Name,
+			o.Params,
+			parameterLoaderProvider,
+		)
+		break
 	case "command_exec":
 		oh, ohErr = opfactories.NewCommandExecOperation(
 			o.Name,
@@ -815,8 +1145,6 @@ func (o *Operation) Handler(ctx Context) (operations.OperationHandler, error) {
 		return nil, ohErr
 	}
 
-	o.operationState.handler = oh
-
 	return oh, nil
 }
 
@@ -846,6 +1174,19 @@ func splitIntoTargetSkip(targetPolicy string, in []files.ProcessableFile) (targe
 	return target, skip
 }
 
+// splitIntoPackets splits the input into the packets of the given max size. It always
+// returns at least one packet, so the operation handler is called even if the input
+// is empty.
+func splitIntoPackets(maxPacketSize int, in []files.ProcessableFile) (packets [][]files.ProcessableFile) {
+	if maxPacketSize > 0 {
+		for maxPacketSize < len(in) {
+			in, packets = in[maxPacketSize:], append(packets, in[0:maxPacketSize:maxPacketSize])
+		}
+	}
+
+	return append(packets, in)
+}
+
 func assignCleanupPolicy(cleanupPolicy string, in []files.ProcessableFile) {
 	for i := range in {
 		pf := &in[i]
@@ -534,3 +534,57 @@ func TestService_RunProcessorsConcurrentlyInEventMode(t *testing.T) {
 			binProcessableFile.FileProcessingError.Code())
 	}
 }
+
+func TestOperation_HandlerWithQueueInputOutsideWorkerContext(t *testing.T) {
+	newOperation := func() *Operation {
+		o := &Operation{
+			Name: "sqs_input_read",
+			Params: map[string]parameters.Parameter{
+				"region": {
+					SourceType: "value",
+					Source:     "us-east-1",
+				},
+				"queueUrl": {
+					SourceType: "value",
+					Source:     "https://sqs.us-east-1.amazonaws.com/123456789012/files",
+				},
+			},
+		}
+		o.Reset()
+
+		return o
+	}
+
+	for _, ctx := range []Context{NewCliContext(), NewServerContext(nil, nil)} {
+		_, err := newOperation().Handler(ctx)
+		if err == nil {
+			t.Fatalf("expect an error for the queue input outside the worker context, got nil")
+		}
+	}
+
+	_, err := newOperation().Handler(NewWorkerContext(nil))
+	if err != nil {
+		t.Fatalf("expect no error for the queue input in the worker context, got %v", err)
+	}
+}
+
+func TestService_IssuePresignedUploadsKeepsProcessorState(t *testing.T) {
+	sd := testServiceDefinitionForCliContext()
+
+	proc := &sd.Processors[0]
+	initOpsErr := proc.InitOperations(NewCliContext())
+	if initOpsErr != nil {
+		t.Fatal(initOpsErr)
+	}
+	state := proc.Operations[0].operationState
+
+	_, issueErr := sd.IssuePresignedUploads(NewCliContext(), "photo", []string{"photo.jpg"})
+	if issueErr == nil {
+		t.Fatalf("IssuePresignedUploads() error = nil, want the presigned uploads to be not supported")
+	}
+
+	// The processor may be running meanwhile, so its state must not be reset.
+	if proc.Operations[0].operationState != state {
+		t.Fatalf("expected the processor operations to keep their state")
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"golang.org/x/exp/slog"
 	"net/http"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,9 +30,10 @@ type ResponseDTO struct {
 }
 
 type FileDTO struct {
-	Url              string  `json:"url"`
-	Filename         string  `json:"filename"`
-	OriginalFilename *string `json:"originalFilename"`
+	Url              string     `json:"url"`
+	UrlExpiresAt     *time.Time `json:"urlExpiresAt,omitempty"`
+	Filename         string     `json:"filename"`
+	OriginalFilename *string    `json:"originalFilename"`
 
 	Mime string `json:"mime"`
 	Size int64  `json:"size"`
@@ -88,10 +91,7 @@ func WriteOutput(out []files.ProcessableFile, w http.ResponseWriter) error {
 
 func (dto *ResponseDTO) writeProcessedFile(processableFile *files.ProcessableFile) {
 	if !processableFile.HasFileProcessingError() {
-		var fileURL string
-		if val, ok := processableFile.OperationMetadata[operations.MetadataKeyS3UploadFileUrl]; ok {
-			fileURL = val.(string)
-		}
+		fileURL, fileURLExpiresAt := operations.FileUrlFromMetadata(processableFile.OperationMetadata)
 
 		originalFilename := processableFile.OriginalFilename()
 
@@ -106,7 +106,8 @@ func (dto *ResponseDTO) writeProcessedFile(processableFile *files.ProcessableFil
 			Message: "file successfully uploaded",
 
 			Url:              fileURL,
-			Filename:         processableFile.GeneratedFilename(),
+			UrlExpiresAt:     fileURLExpiresAt,
+			Filename:         processableFile.LogicalFilename(),
 			OriginalFilename: &originalFilename,
 
 			Mime: mime.String(),
@@ -123,6 +124,28 @@ func (dto *ResponseDTO) writeProcessedFile(processableFile *files.ProcessableFil
 	var fileSizeIsTooSmall *operations.FileSizeIsTooSmallError
 	var fileSizeIsTooBig *operations.FileSizeIsTooBigError
 	var fileMimeTypeIsNotAllowed *operations.FileMimeTypeIsNotAllowedError
+	var fileExtensionIsNotAllowed *operations.FileExtensionIsNotAllowedError
+	var fileExtensionDoesNotMatchMimeType *operations.FileExtensionDoesNotMatchMimeTypeError
+	var imageWidthIsTooSmall *operations.ImageWidthIsTooSmallError
+	var imageWidthIsTooBig *operations.ImageWidthIsTooBigError
+	var imageHeightIsTooSmall *operations.ImageHeightIsTooSmallError
+	var imageHeightIsTooBig *operations.ImageHeightIsTooBigError
+	var imageResolutionIsTooLow *operations.ImageResolutionIsTooLowError
+	var imageResolutionIsTooHigh *operations.ImageResolutionIsTooHighError
+	var imageAspectRatioIsNotAllowed *operations.ImageAspectRatioIsNotAllowedError
+	var imageIsAnimated *operations.ImageIsAnimatedError
+	var imageIsNotAnimated *operations.ImageIsNotAnimatedError
+	var imageDimensionsCanNotBeRetrieved *operations.ImageDimensionsCanNotBeRetrievedError
+	var fileContentIsInvalid *operations.FileContentIsInvalidError
+	var fileIsNotText *operations.FileIsNotTextError
+	var textEncodingCanNotBeDetected *operations.TextEncodingCanNotBeDetectedError
+	var pdfCanNotBeRead *operations.PDFCanNotBeReadError
+	var pdfIsEncrypted *operations.PDFIsEncryptedError
+	var pdfHasTooFewPages *operations.PDFHasTooFewPagesError
+	var pdfHasTooManyPages *operations.PDFHasTooManyPagesError
+	var mediaCanNotBeProbed *operations.MediaCanNotBeProbedError
+	var mediaDurationIsTooLong *operations.MediaDurationIsTooLongError
+	var mediaCodecIsNotAllowed *operations.MediaCodecIsNotAllowedError
 	switch {
 	case errors.As(processableFile.FileProcessingError, &fileSizeIsTooSmall):
 		errorMessage = fmt.Sprintf(
@@ -142,6 +165,111 @@ func (dto *ResponseDTO) writeProcessedFile(processableFile *files.ProcessableFil
 			fileMimeTypeIsNotAllowed.Data.GivenMimeType,
 		)
 		break
+	case errors.As(processableFile.FileProcessingError, &fileExtensionIsNotAllowed):
+		errorMessage = fmt.Sprintf(
+			"file extension \"%s\" is not allowed",
+			fileExtensionIsNotAllowed.Data.GivenExtension,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &fileExtensionDoesNotMatchMimeType):
+		errorMessage = fmt.Sprintf(
+			"file extension \"%s\" does not match file MIME type \"%s\"",
+			fileExtensionDoesNotMatchMimeType.Data.GivenExtension,
+			fileExtensionDoesNotMatchMimeType.Data.GivenMimeType,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageWidthIsTooSmall):
+		errorMessage = fmt.Sprintf(
+			"image width can not be less than %dpx",
+			imageWidthIsTooSmall.Data.MinWidth,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageWidthIsTooBig):
+		errorMessage = fmt.Sprintf(
+			"image width can not be greater than %dpx",
+			imageWidthIsTooBig.Data.MaxWidth,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageHeightIsTooSmall):
+		errorMessage = fmt.Sprintf(
+			"image height can not be less than %dpx",
+			imageHeightIsTooSmall.Data.MinHeight,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageHeightIsTooBig):
+		errorMessage = fmt.Sprintf(
+			"image height can not be greater than %dpx",
+			imageHeightIsTooBig.Data.MaxHeight,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageResolutionIsTooLow):
+		errorMessage = fmt.Sprintf(
+			"image resolution can not be less than %s megapixels",
+			strconv.FormatFloat(imageResolutionIsTooLow.Data.MinMegapixels, 'f', -1, 64),
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageResolutionIsTooHigh):
+		errorMessage = fmt.Sprintf(
+			"image resolution can not be greater than %s megapixels",
+			strconv.FormatFloat(imageResolutionIsTooHigh.Data.MaxMegapixels, 'f', -1, 64),
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageAspectRatioIsNotAllowed):
+		errorMessage = imageAspectRatioMessage(imageAspectRatioIsNotAllowed.Data)
+		break
+	case errors.As(processableFile.FileProcessingError, &imageIsAnimated):
+		errorMessage = "animated images are not allowed"
+		break
+	case errors.As(processableFile.FileProcessingError, &imageIsNotAnimated):
+		errorMessage = "only animated images are allowed"
+		break
+	case errors.As(processableFile.FileProcessingError, &imageDimensionsCanNotBeRetrieved):
+		errorMessage = "file is not a supported image"
+		break
+	case errors.As(processableFile.FileProcessingError, &fileContentIsInvalid):
+		errorMessage = fileContentMessage(fileContentIsInvalid.Data)
+		break
+	case errors.As(processableFile.FileProcessingError, &fileIsNotText):
+		errorMessage = "file is not a text file"
+		break
+	case errors.As(processableFile.FileProcessingError, &textEncodingCanNotBeDetected):
+		errorMessage = "file text encoding can not be detected"
+		break
+	case errors.As(processableFile.FileProcessingError, &pdfCanNotBeRead):
+		errorMessage = "file is not a valid PDF"
+		break
+	case errors.As(processableFile.FileProcessingError, &pdfIsEncrypted):
+		errorMessage = "encrypted PDFs are not allowed"
+		break
+	case errors.As(processableFile.FileProcessingError, &pdfHasTooFewPages):
+		errorMessage = fmt.Sprintf(
+			"PDF can not have less than %d pages",
+			pdfHasTooFewPages.Data.MinPages,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &pdfHasTooManyPages):
+		errorMessage = fmt.Sprintf(
+			"PDF can not have more than %d pages",
+			pdfHasTooManyPages.Data.MaxPages,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &mediaCanNotBeProbed):
+		errorMessage = "file is not a valid audio or video file"
+		break
+	case errors.As(processableFile.FileProcessingError, &mediaDurationIsTooLong):
+		errorMessage = fmt.Sprintf(
+			"media duration can not be longer than %s",
+			mediaDurationIsTooLong.Data.MaxDuration,
+		)
+		break
+	case errors.As(processableFile.FileProcessingError, &mediaCodecIsNotAllowed):
+		errorMessage = fmt.Sprintf(
+			"%s codec %s is not allowed, allowed codecs are %s",
+			mediaCodecIsNotAllowed.Data.StreamType,
+			mediaCodecIsNotAllowed.Data.GivenCodec,
+			strings.Join(mediaCodecIsNotAllowed.Data.AllowedCodecs, ", "),
+		)
+		break
 	}
 
 	dto.Errors = append(dto.Errors, FileProcessingErrorDTO{
@@ -152,6 +280,49 @@ func (dto *ResponseDTO) writeProcessedFile(processableFile *files.ProcessableFil
 	})
 }
 
+func imageAspectRatioMessage(data *operations.ImageAspectRatioIsNotAllowedErrorData) string {
+	formatRatio := func(ratio float64) string {
+		return strconv.FormatFloat(ratio, 'f', 2, 64)
+	}
+
+	switch {
+	case data.MinAspectRatio > 0 && data.MaxAspectRatio > 0:
+		return fmt.Sprintf(
+			"image aspect ratio %s is not within the allowed range from %s to %s",
+			formatRatio(data.GivenAspectRatio),
+			formatRatio(data.MinAspectRatio),
+			formatRatio(data.MaxAspectRatio),
+		)
+	case data.MinAspectRatio > 0:
+		return fmt.Sprintf(
+			"image aspect ratio %s can not be less than %s",
+			formatRatio(data.GivenAspectRatio),
+			formatRatio(data.MinAspectRatio),
+		)
+	default:
+		return fmt.Sprintf(
+			"image aspect ratio %s can not be greater than %s",
+			formatRatio(data.GivenAspectRatio),
+			formatRatio(data.MaxAspectRatio),
+		)
+	}
+}
+
+func fileContentMessage(data *operations.FileContentIsInvalidErrorData) string {
+	message := fmt.Sprintf("invalid %s content", strings.ToUpper(data.Format))
+	if data.Line > 0 {
+		message += fmt.Sprintf(" at line %d", data.Line)
+		if data.Column > 0 {
+			message += fmt.Sprintf(", column %d", data.Column)
+		}
+	}
+	if data.Pointer != "" {
+		message += fmt.Sprintf(" (%s)", data.Pointer)
+	}
+
+	return message + ": " + data.Reason
+}
+
 func (dto *ResponseDTO) writeStatusAndMeta() {
 	successfulUploads := len(dto.Files)
 	failedUploads := len(dto.Errors)
@@ -282,3 +453,60 @@ func WriteHealthCheck(name, version, commit string, verbosity int, w http.Respon
 
 	return nil
 }
+
+type PresignedUploadsResponseDTO struct {
+	Status  string               `json:"status"`
+	Code    string               `json:"code"`
+	Message string               `json:"message"`
+	Uploads []PresignedUploadDTO `json:"uploads"`
+}
+
+type PresignedUploadDTO struct {
+	Filename string `json:"filename"`
+	Key      string `json:"key"`
+	Token    string `json:"token"`
+
+	Method    string            `json:"method"`
+	Url       string            `json:"url"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// WritePresignedUploads Writes the issued presigned uploads to the http response.
+func WritePresignedUploads(uploads []operations.PresignedUpload, w http.ResponseWriter) error {
+	response := PresignedUploadsResponseDTO{
+		Status:  "SUCCESS",
+		Code:    "PRESIGNED_UPLOADS_ISSUED",
+		Message: fmt.Sprintf("issued %d presigned upload(s)", len(uploads)),
+		Uploads: make([]PresignedUploadDTO, 0, len(uploads)),
+	}
+
+	for _, upload := range uploads {
+		headers := make(map[string]string, len(upload.Headers))
+		for name := range upload.Headers {
+			headers[name] = upload.Headers.Get(name)
+		}
+
+		response.Uploads = append(response.Uploads, PresignedUploadDTO{
+			Filename:  upload.Filename,
+			Key:       upload.Key,
+			Token:     upload.Token,
+			Method:    upload.Method,
+			Url:       upload.Url,
+			Headers:   headers,
+			ExpiresAt: upload.ExpiresAt,
+		})
+	}
+
+	responseWriterErr := json.NewEncoder(w).Encode(response)
+	if responseWriterErr != nil {
+		common.Logger.Error(
+			"failed to write presigned uploads for http",
+			slog.Any("error", responseWriterErr),
+		)
+
+		return responseWriterErr
+	}
+
+	return nil
+}
@@ -11,6 +11,8 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -130,3 +132,42 @@ func TestProcessFiles(t *testing.T) {
 		t.Errorf("expected errors length to be 1, got %v", len(errors))
 	}
 }
+
+func TestIssuePresignedUploadsForUnsupportedProcessor(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	testLoggerInitErr := common.InitTestLogger()
+	if testLoggerInitErr != nil {
+		t.Errorf("expected error to be nil, got %v", testLoggerInitErr)
+	}
+
+	capysvc.LoadTestServiceDefinition(&testServiceDefinition)
+
+	form := url.Values{}
+	form.Add("filename", "file_5kb.bin")
+
+	req := httptest.NewRequest(http.MethodPost, "/validator/bin_file/presigned-uploads", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+
+	s := Server{}
+	s.Handler(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status code to be %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	var data map[string]interface{}
+	jsonErr := json.NewDecoder(resp.Body).Decode(&data)
+	if jsonErr != nil {
+		t.Errorf("expected error to be nil, got %v", jsonErr)
+	}
+
+	if data["code"] != "PRESIGNED_UPLOADS_NOT_SUPPORTED" {
+		t.Errorf("expected code to be PRESIGNED_UPLOADS_NOT_SUPPORTED, got %v", data["code"])
+	}
+}
@@ -151,11 +151,13 @@ func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// What we have so far is /:service-name/:processor-name as file uploading
-	// endpoints (e.g. /messenger/avatar, /messenger/attachment, /images/upload).
+	// endpoints (e.g. /messenger/avatar, /messenger/attachment, /images/upload), and
+	// /:service-name/:processor-name/presigned-uploads to issue the presigned uploads.
 	path := strings.Split(
 		strings.TrimLeft(r.URL.Path, "/"),
 		"/")
-	if len(path) != 2 {
+	isPresignedUploadsPath := len(path) == 3 && path[2] == "presigned-uploads"
+	if len(path) != 2 && !isPresignedUploadsPath {
 		_ = httpio.WriteError(
 			httpio.NewHTTPAwareError(
 				404,
@@ -199,6 +201,11 @@ func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isPresignedUploadsPath {
+		s.PresignedUploadsHandler(w, r, svc, proc)
+		return
+	}
+
 	errorCh := make(chan operations.OperationError)
 	notificationCh := make(chan operations.OperationNotification)
 
@@ -347,6 +354,110 @@ func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// PresignedUploadsHandler Issues the presigned uploads, so the clients can upload the files directly
+// to the storage. When the files are uploaded, the clients call the processor endpoint with the keys
+// of the uploaded files.
+func (s *Server) PresignedUploadsHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+	svc *capysvc.Service,
+	proc *capysvc.Processor,
+) {
+	if r.Method != http.MethodPost {
+		_ = httpio.WriteError(
+			httpio.NewHTTPAwareError(
+				405,
+				"METHOD_NOT_ALLOWED",
+				"method not allowed",
+				nil,
+			),
+			w,
+		)
+		return
+	}
+
+	parseErr := r.ParseForm()
+	if parseErr != nil || len(r.Form["filename"]) == 0 {
+		_ = httpio.WriteError(
+			httpio.NewHTTPAwareError(
+				400,
+				"NO_FILENAMES_PROVIDED",
+				"no filenames provided",
+				parseErr,
+			),
+			w,
+		)
+		return
+	}
+
+	uploads, issueErr := svc.IssuePresignedUploads(
+		capysvc.NewServerContext(r, common.EtcdClient),
+		proc.Name,
+		r.Form["filename"],
+	)
+	if issueErr != nil {
+		common.Logger.Error(
+			"presigned uploads issue error",
+			slog.String("service", svc.Name),
+			slog.String("processor", proc.Name),
+			slog.Any("error", issueErr),
+		)
+
+		var notSupported *capyerr.PresignedUploadsNotSupportedType
+		if errors.As(issueErr, &notSupported) {
+			_ = httpio.WriteError(
+				httpio.NewHTTPAwareError(
+					400,
+					notSupported.Code(),
+					notSupported.Message(),
+					issueErr,
+				),
+				w,
+			)
+			return
+		}
+
+		var opCfg *capyerr.OperationConfigurationType
+		if errors.As(issueErr, &opCfg) {
+			_ = httpio.WriteError(
+				httpio.NewHTTPAwareError(
+					500,
+					opCfg.Code(),
+					opCfg.Message(),
+					issueErr,
+				),
+				w,
+			)
+			return
+		}
+
+		_ = httpio.WriteError(
+			httpio.NewHTTPAwareError(
+				500,
+				"INTERNAL",
+				"something went wrong",
+				issueErr,
+			),
+			w,
+		)
+		return
+	}
+
+	outputWriterErr := httpio.WritePresignedUploads(uploads, w)
+	if outputWriterErr != nil {
+		_ = httpio.WriteError(
+			httpio.NewHTTPAwareError(
+				500,
+				"REQUEST_OUTPUT_WRITING_FAILURE",
+				"request output writing failure",
+				outputWriterErr,
+			),
+			w,
+		)
+		return
+	}
+}
+
 func (s *Server) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
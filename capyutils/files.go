@@ -85,3 +85,36 @@ func WriteReaderToAppTmpDirectory(r io.Reader) (afero.File, error) {
 
 	return file, nil
 }
+
+// WriteToAppTmpDirectory Creates the file in the app tmp directory and passes it to the write function.
+// Unlike the other functions, it removes the file if the write function fails, so it suits the writes
+// that can fail halfway, like the stream transformations.
+func WriteToAppTmpDirectory(write func(w io.Writer) error) (afero.File, error) {
+	prepErr := prepareAppDirectory()
+	if prepErr != nil {
+		return nil, prepErr
+	}
+
+	homedir, uhdErr := os.UserHomeDir()
+	if uhdErr != nil {
+		return nil, uhdErr
+	}
+
+	file, fileErr := capyfs.Filesystem.Create(homedir + "/.capyfile/tmp/" + gonanoid.Must())
+	if fileErr != nil {
+		return nil, fileErr
+	}
+
+	writeErr := write(file)
+	closeErr := file.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		_ = capyfs.Filesystem.Remove(file.Name())
+
+		return nil, writeErr
+	}
+
+	return file, nil
+}
@@ -4,4 +4,10 @@ package files
 type ProcessableFileMetadata struct {
 	// OriginalFilename The original filename we receive from the client.
 	OriginalFilename string
+	// Filename The filename the file is stored with. It is set by the operations like file_rename.
+	// If empty, the generated filename is used.
+	Filename string
+	// FilenameGeneratedExt The extension of the generated filename at the time the filename has been set.
+	// It tells whether the file has been converted to another format since then.
+	FilenameGeneratedExt string
 }
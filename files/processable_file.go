@@ -150,6 +150,30 @@ func (f *ProcessableFile) GeneratedFilename() string {
 	return f.NanoID + f.mime.Extension()
 }
 
+// LogicalFilename The filename the file is stored with. This is either the filename set by Rename
+// or the generated one.
+func (f *ProcessableFile) LogicalFilename() string {
+	if f.Metadata.Filename == "" {
+		return f.GeneratedFilename()
+	}
+
+	// If the file has been converted to another format after it has been renamed,
+	// the extension must be relevant to the new format.
+	ext := filepath.Ext(f.Metadata.Filename)
+	generatedExt := filepath.Ext(f.GeneratedFilename())
+	if ext == "" || generatedExt == f.Metadata.FilenameGeneratedExt {
+		return f.Metadata.Filename
+	}
+
+	return f.Metadata.Filename[:len(f.Metadata.Filename)-len(ext)] + generatedExt
+}
+
+// Rename Sets the filename the file is stored with. The file itself is not renamed.
+func (f *ProcessableFile) Rename(filename string) {
+	f.Metadata.Filename = filename
+	f.Metadata.FilenameGeneratedExt = filepath.Ext(f.GeneratedFilename())
+}
+
 func (f *ProcessableFile) Name() string {
 	return f.name
 }
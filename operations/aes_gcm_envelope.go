@@ -0,0 +1,227 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The AES-256-GCM envelope is the streaming format, so the files of any size can be encrypted
+// without loading them into memory. The envelope consists of the header and the chunks.
+//
+// The header is:
+// - magic "CAPYAES1"
+// - key ID length (1 byte) and key ID, so the key can be looked up on decryption
+// - plaintext chunk size (4 bytes, big endian)
+// - nonce prefix (7 random bytes)
+//
+// Every chunk is sealed separately with the whole header as the additional data. The nonce of
+// the chunk is the nonce prefix, the chunk counter (4 bytes, big endian) and the last chunk
+// flag (1 byte), so the chunks can not be reordered, and the envelope can not be truncated.
+const aesGCMEnvelopeMagic = "CAPYAES1"
+
+const aesGCMEnvelopeChunkSize = 64 * 1024
+
+const aesGCMEnvelopeNoncePrefixSize = 7
+
+// aesGCMEnvelopeMaxChunkSize Protects the decryption from allocating the arbitrary buffer.
+const aesGCMEnvelopeMaxChunkSize = 16 * 1024 * 1024
+
+type aesGCMEnvelopeHeader struct {
+	keyID       string
+	chunkSize   uint32
+	noncePrefix []byte
+}
+
+func (h *aesGCMEnvelopeHeader) bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(aesGCMEnvelopeMagic)
+	buf.WriteByte(byte(len(h.keyID)))
+	buf.WriteString(h.keyID)
+	_ = binary.Write(&buf, binary.BigEndian, h.chunkSize)
+	buf.Write(h.noncePrefix)
+
+	return buf.Bytes()
+}
+
+func readAESGCMEnvelopeHeader(r io.Reader) (*aesGCMEnvelopeHeader, error) {
+	magic := make([]byte, len(aesGCMEnvelopeMagic)+1)
+	_, readErr := io.ReadFull(r, magic)
+	if readErr != nil || string(magic[:len(aesGCMEnvelopeMagic)]) != aesGCMEnvelopeMagic {
+		return nil, errors.New("file is not AES-256-GCM envelope")
+	}
+
+	keyID := make([]byte, magic[len(aesGCMEnvelopeMagic)])
+	_, readErr = io.ReadFull(r, keyID)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	header := &aesGCMEnvelopeHeader{
+		keyID:       string(keyID),
+		noncePrefix: make([]byte, aesGCMEnvelopeNoncePrefixSize),
+	}
+
+	readErr = binary.Read(r, binary.BigEndian, &header.chunkSize)
+	if readErr != nil {
+		return nil, readErr
+	}
+	if header.chunkSize == 0 || header.chunkSize > aesGCMEnvelopeMaxChunkSize {
+		return nil, fmt.Errorf("invalid chunk size %d", header.chunkSize)
+	}
+
+	_, readErr = io.ReadFull(r, header.noncePrefix)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return header, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("AES-256 key must be 32 bytes long")
+	}
+
+	block, blockErr := aes.NewCipher(key)
+	if blockErr != nil {
+		return nil, blockErr
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func (h *aesGCMEnvelopeHeader) chunkNonce(counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, aesGCMEnvelopeNoncePrefixSize+5)
+	nonce = append(nonce, h.noncePrefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+// readAESGCMEnvelopeChunk Reads up to len(buf) bytes and tells whether it is the last chunk.
+func readAESGCMEnvelopeChunk(r *bufio.Reader, buf []byte) (int, bool, error) {
+	n, readErr := io.ReadFull(r, buf)
+	if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+		return n, true, nil
+	}
+	if readErr != nil {
+		return n, false, readErr
+	}
+
+	_, peekErr := r.Peek(1)
+	if peekErr == io.EOF {
+		return n, true, nil
+	}
+
+	return n, false, peekErr
+}
+
+// aesGCMEncrypt Encrypts the src stream into the envelope written to dst.
+func aesGCMEncrypt(dst io.Writer, src io.Reader, key []byte, keyID string) error {
+	if len(keyID) > 255 {
+		return errors.New("key ID must not be longer than 255 bytes")
+	}
+
+	aead, aeadErr := newAESGCM(key)
+	if aeadErr != nil {
+		return aeadErr
+	}
+
+	header := &aesGCMEnvelopeHeader{
+		keyID:       keyID,
+		chunkSize:   aesGCMEnvelopeChunkSize,
+		noncePrefix: make([]byte, aesGCMEnvelopeNoncePrefixSize),
+	}
+	_, randErr := rand.Read(header.noncePrefix)
+	if randErr != nil {
+		return randErr
+	}
+
+	headerBytes := header.bytes()
+	_, writeErr := dst.Write(headerBytes)
+	if writeErr != nil {
+		return writeErr
+	}
+
+	r := bufio.NewReader(src)
+	plaintext := make([]byte, header.chunkSize)
+	ciphertext := make([]byte, 0, int(header.chunkSize)+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, last, readErr := readAESGCMEnvelopeChunk(r, plaintext)
+		if readErr != nil {
+			return readErr
+		}
+
+		ciphertext = aead.Seal(ciphertext[:0], header.chunkNonce(counter, last), plaintext[:n], headerBytes)
+		_, writeErr = dst.Write(ciphertext)
+		if writeErr != nil {
+			return writeErr
+		}
+
+		if last {
+			return nil
+		}
+		if counter == ^uint32(0) {
+			return errors.New("file is too large")
+		}
+	}
+}
+
+// aesGCMDecrypt Decrypts the envelope read from src into dst. The key function returns
+// the key for the key ID the envelope has been encrypted with.
+func aesGCMDecrypt(dst io.Writer, src io.Reader, key func(keyID string) ([]byte, error)) error {
+	r := bufio.NewReader(src)
+
+	header, headerErr := readAESGCMEnvelopeHeader(r)
+	if headerErr != nil {
+		return headerErr
+	}
+
+	keyBytes, keyErr := key(header.keyID)
+	if keyErr != nil {
+		return keyErr
+	}
+
+	aead, aeadErr := newAESGCM(keyBytes)
+	if aeadErr != nil {
+		return aeadErr
+	}
+
+	headerBytes := header.bytes()
+	ciphertext := make([]byte, int(header.chunkSize)+aead.Overhead())
+	plaintext := make([]byte, 0, header.chunkSize)
+	for counter := uint32(0); ; counter++ {
+		n, last, readErr := readAESGCMEnvelopeChunk(r, ciphertext)
+		if readErr != nil {
+			return readErr
+		}
+
+		var openErr error
+		plaintext, openErr = aead.Open(plaintext[:0], header.chunkNonce(counter, last), ciphertext[:n], headerBytes)
+		if openErr != nil {
+			return errors.New("envelope is corrupted or has been encrypted with another key")
+		}
+
+		_, writeErr := dst.Write(plaintext)
+		if writeErr != nil {
+			return writeErr
+		}
+
+		if last {
+			return nil
+		}
+		if counter == ^uint32(0) {
+			return errors.New("envelope is too large")
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/spf13/afero"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const ErrorCodeAzureBlobUploadOperationConfiguration = "AZURE_BLOB_UPLOAD_OPERATION_CONFIGURATION"
+
+const MetadataKeyAzureBlobUploadFileUrl = "azure_blob_upload.file_url"
+const MetadataKeyAzureBlobUploadKey = "azure_blob_upload.key"
+const MetadataKeyAzureBlobUploadPresignedUrl = "azure_blob_upload.presigned_url"
+const MetadataKeyAzureBlobUploadPresignedUrlExpiresAt = "azure_blob_upload.presigned_url_expires_at"
+
+// AzureBlobUploadAPI The interface to implement the Azure Blob Storage API calls that we need
+// to upload the files. The blobs are uploaded as block blobs.
+type AzureBlobUploadAPI interface {
+	UploadStream(
+		ctx context.Context,
+		containerName string,
+		blobName string,
+		body io.Reader,
+		o *azblob.UploadStreamOptions,
+	) (azblob.UploadStreamResponse, error)
+}
+
+type AzureBlobUploadOperation struct {
+	Name      string
+	Params    *AzureBlobUploadOperationParams
+	UploadAPI AzureBlobUploadAPI
+}
+
+func (o *AzureBlobUploadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *AzureBlobUploadOperation) AllowConcurrency() bool {
+	return true
+}
+
+type AzureBlobUploadOperationParams struct {
+	AccountName string
+	AccountKey  string
+	// Endpoint is the blob service URL. If empty, https://<AccountName>.blob.core.windows.net/ is used.
+	// For example, Azurite: http://127.0.0.1:10000/devstoreaccount1
+	Endpoint  string
+	Container string
+
+	// BlockSize is the size of the block in bytes the files are uploaded with.
+	// If zero, the default block size (1MiB) is used.
+	BlockSize int64
+	// Concurrency is the number of blocks of the same file to upload in parallel.
+	// If zero, the blocks are uploaded one by one.
+	Concurrency int
+
+	// Key is the blob name template. If empty, the generated filename is used as the name.
+	// See objectTemplateData for the available template vars.
+	Key string
+	// KeyTemplateVars are the additional vars available in the key, metadata and tags templates.
+	KeyTemplateVars map[string]string
+	// ContentDisposition is the disposition type ("attachment" or "inline") of the
+	// Content-Disposition header that is sent along with the original filename.
+	// If empty, the header is not set.
+	ContentDisposition string
+	CacheControl       string
+	// AccessTier is the access tier of the blob. For example: Cool
+	AccessTier string
+	// Metadata is the user-defined blob metadata. The values can be templated the same way as the key.
+	Metadata map[string]string
+	// Tags is the blob index tag set. The values can be templated the same way as the key.
+	Tags map[string]string
+
+	// PresignedUrlExpiration is how long the SAS URL of the uploaded file is valid for.
+	// If zero, the SAS URL is not generated. Useful for the private containers.
+	PresignedUrlExpiration time.Duration
+}
+
+func (p *AzureBlobUploadOperationParams) compileEndpoint() string {
+	if p.Endpoint != "" {
+		return p.Endpoint
+	}
+
+	return fmt.Sprintf("https://%s.blob.core.windows.net/", p.AccountName)
+}
+
+func (p *AzureBlobUploadOperationParams) newClient() (*azblob.Client, error) {
+	cred, credErr := azblob.NewSharedKeyCredential(p.AccountName, p.AccountKey)
+	if credErr != nil {
+		return nil, credErr
+	}
+
+	return azblob.NewClientWithSharedKeyCredential(p.compileEndpoint(), cred, nil)
+}
+
+// uploadStreamOptions Builds the blob name and upload options for the given processable file.
+func (p *AzureBlobUploadOperationParams) uploadStreamOptions(
+	pf *files.ProcessableFile,
+) (string, *azblob.UploadStreamOptions, error) {
+	data := objectTemplateData(pf, p.KeyTemplateVars)
+
+	key, keyErr := renderObjectKey(pf, p.Key, data)
+	if keyErr != nil {
+		return "", nil, keyErr
+	}
+
+	metadata, metadataErr := renderObjectTemplateMap(p.Metadata, data)
+	if metadataErr != nil {
+		return "", nil, metadataErr
+	}
+
+	tags, tagsErr := renderObjectTemplateMap(p.Tags, data)
+	if tagsErr != nil {
+		return "", nil, tagsErr
+	}
+
+	opts := &azblob.UploadStreamOptions{
+		BlockSize:   p.BlockSize,
+		Concurrency: p.Concurrency,
+		HTTPHeaders: &blob.HTTPHeaders{},
+		Tags:        tags,
+	}
+
+	if len(metadata) > 0 {
+		opts.Metadata = make(map[string]*string, len(metadata))
+		for k, v := range metadata {
+			val := v
+			opts.Metadata[k] = &val
+		}
+	}
+
+	mime, mimeErr := pf.Mime()
+	if mimeErr == nil && mime != nil {
+		contentType := mime.String()
+		opts.HTTPHeaders.BlobContentType = &contentType
+	}
+
+	if p.ContentDisposition != "" {
+		contentDisposition := objectContentDisposition(p.ContentDisposition, data)
+		opts.HTTPHeaders.BlobContentDisposition = &contentDisposition
+	}
+	if p.CacheControl != "" {
+		cacheControl := p.CacheControl
+		opts.HTTPHeaders.BlobCacheControl = &cacheControl
+	}
+	if p.AccessTier != "" {
+		accessTier := blob.AccessTier(p.AccessTier)
+		opts.AccessTier = &accessTier
+	}
+
+	return key, opts, nil
+}
+
+// compileFileUrl Compiles the URL of the blob with the given name.
+func (p *AzureBlobUploadOperationParams) compileFileUrl(key string) (string, error) {
+	u, urlParseErr := url.Parse(p.compileEndpoint())
+	if urlParseErr != nil {
+		return "", urlParseErr
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + p.Container + "/" + key
+
+	return u.String(), nil
+}
+
+// compilePresignedUrl Signs the read-only SAS for the blob with the account key and appends it to the file URL.
+func (p *AzureBlobUploadOperationParams) compilePresignedUrl(
+	fileUrl string,
+	key string,
+	expiresAt time.Time,
+) (string, error) {
+	cred, credErr := azblob.NewSharedKeyCredential(p.AccountName, p.AccountKey)
+	if credErr != nil {
+		return "", credErr
+	}
+
+	permissions := sas.BlobPermissions{Read: true}
+	queryParams, signErr := sas.BlobSignatureValues{
+		ExpiryTime:    expiresAt.UTC(),
+		Permissions:   permissions.String(),
+		ContainerName: p.Container,
+		BlobName:      key,
+	}.SignWithSharedKey(cred)
+	if signErr != nil {
+		return "", signErr
+	}
+
+	return fileUrl + "?" + queryParams.Encode(), nil
+}
+
+func (o *AzureBlobUploadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.UploadAPI == nil {
+		initErr := o.InitUploadAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("upload API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeAzureBlobUploadOperationConfiguration,
+				"upload API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		var pf = &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("Azure blob upload has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			defer func(file afero.File) {
+				closeErr := file.Close()
+				if closeErr != nil {
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, closeErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"file can not be closed", pf, closeErr)
+					}
+				}
+			}(file)
+
+			key, opts, optsErr := o.Params.uploadStreamOptions(pf)
+			if optsErr != nil {
+				pf.SetFileProcessingError(
+					NewAzureBlobTemplateCanNotBeRenderedError(optsErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, optsErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"Azure blob name, metadata or tags template can not be rendered", pf, optsErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			_, uploadErr := o.UploadAPI.UploadStream(context.TODO(), o.Params.Container, key, file, opts)
+			if uploadErr != nil {
+				pf.SetFileProcessingError(
+					NewAzureBlobFileUploadFailureError(uploadErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, uploadErr)
+				}
+
+				switch {
+				case bloberror.HasCode(uploadErr, bloberror.ContainerNotFound):
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not upload the file because Azure blob container does not exist", pf, uploadErr)
+					}
+				case bloberror.HasCode(uploadErr, bloberror.MD5Mismatch, bloberror.CRC64Mismatch):
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not upload the file because Azure blob checksum verification has failed", pf, uploadErr)
+					}
+				default:
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not upload the file because the request to Azure Blob Storage has failed", pf, uploadErr)
+					}
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			fileUrl, fileUrlErr := o.Params.compileFileUrl(key)
+			if fileUrlErr != nil {
+				pf.SetFileProcessingError(
+					NewAzureBlobFileUrlCanNotBeRetrievedError(fileUrlErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileUrlErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not retrieve Azure blob URL", pf, fileUrlErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyAzureBlobUploadFileUrl, fileUrl)
+			pf.AddOperationMetadata(MetadataKeyAzureBlobUploadKey, key)
+
+			if o.Params.PresignedUrlExpiration > 0 {
+				expiresAt := time.Now().Add(o.Params.PresignedUrlExpiration)
+				presignedUrl, presignErr := o.Params.compilePresignedUrl(fileUrl, key, expiresAt)
+				if presignErr != nil {
+					pf.SetFileProcessingError(
+						NewAzureBlobFileUrlCanNotBeRetrievedError(presignErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, presignErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not generate Azure blob SAS URL", pf, presignErr)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				pf.AddOperationMetadata(MetadataKeyAzureBlobUploadPresignedUrl, presignedUrl)
+				pf.AddOperationMetadata(MetadataKeyAzureBlobUploadPresignedUrlExpiresAt, expiresAt)
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("Azure blob upload has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+// InitUploadAPI Init AzureBlobUploadAPI that we need to upload the files to Azure Blob Storage.
+func (o *AzureBlobUploadOperation) InitUploadAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.UploadAPI = client
+
+	return nil
+}
+
+func (o *AzureBlobUploadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *AzureBlobUploadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,86 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeAzureBlobFileUploadFailure = "FILE_AZURE_BLOB_FILE_UPLOAD_FAILURE"
+
+func NewAzureBlobFileUploadFailureError(origErr error) *AzureBlobFileUploadFailureError {
+	return &AzureBlobFileUploadFailureError{
+		Data: &AzureBlobFileUploadFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type AzureBlobFileUploadFailureError struct {
+	files.FileProcessingError
+	Data *AzureBlobFileUploadFailureErrorData
+}
+
+type AzureBlobFileUploadFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *AzureBlobFileUploadFailureError) Code() string {
+	return ErrorCodeAzureBlobFileUploadFailure
+}
+
+func (e *AzureBlobFileUploadFailureError) Error() string {
+	return "failed to upload file to Azure Blob Storage"
+}
+
+const ErrorCodeAzureBlobFileUrlCanNotBeRetrieved = "FILE_AZURE_BLOB_FILE_URL_CAN_NOT_BE_RETRIEVED"
+
+func NewAzureBlobFileUrlCanNotBeRetrievedError(origErr error) *AzureBlobFileUrlCanNotBeRetrievedError {
+	return &AzureBlobFileUrlCanNotBeRetrievedError{
+		Data: &AzureBlobFileUrlCanNotBeRetrievedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type AzureBlobFileUrlCanNotBeRetrievedError struct {
+	files.FileProcessingError
+	Data *AzureBlobFileUrlCanNotBeRetrievedErrorData
+}
+
+type AzureBlobFileUrlCanNotBeRetrievedErrorData struct {
+	OrigErr error
+}
+
+func (e *AzureBlobFileUrlCanNotBeRetrievedError) Code() string {
+	return ErrorCodeAzureBlobFileUrlCanNotBeRetrieved
+}
+
+func (e *AzureBlobFileUrlCanNotBeRetrievedError) Error() string {
+	return "Azure blob URL can not be retrieved"
+}
+
+const ErrorCodeAzureBlobTemplateCanNotBeRendered = "FILE_AZURE_BLOB_TEMPLATE_CAN_NOT_BE_RENDERED"
+
+func NewAzureBlobTemplateCanNotBeRenderedError(origErr error) *AzureBlobTemplateCanNotBeRenderedError {
+	return &AzureBlobTemplateCanNotBeRenderedError{
+		Data: &AzureBlobTemplateCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type AzureBlobTemplateCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *AzureBlobTemplateCanNotBeRenderedErrorData
+}
+
+type AzureBlobTemplateCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *AzureBlobTemplateCanNotBeRenderedError) Code() string {
+	return ErrorCodeAzureBlobTemplateCanNotBeRendered
+}
+
+func (e *AzureBlobTemplateCanNotBeRenderedError) Error() string {
+	return "Azure blob name, metadata or tags template can not be rendered"
+}
@@ -0,0 +1,269 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"context"
+	"errors"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Azurite well-known development storage account.
+const azuriteAccountName = "devstoreaccount1"
+const azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+type fakeAzureBlobStorage struct {
+	mu      sync.Mutex
+	blobs   map[string][]byte
+	options map[string]*azblob.UploadStreamOptions
+
+	uploadErr error
+}
+
+func newFakeAzureBlobStorage() *fakeAzureBlobStorage {
+	return &fakeAzureBlobStorage{
+		blobs:   make(map[string][]byte),
+		options: make(map[string]*azblob.UploadStreamOptions),
+	}
+}
+
+func (s *fakeAzureBlobStorage) UploadStream(
+	ctx context.Context,
+	containerName string,
+	blobName string,
+	body io.Reader,
+	o *azblob.UploadStreamOptions,
+) (azblob.UploadStreamResponse, error) {
+	if s.uploadErr != nil {
+		return azblob.UploadStreamResponse{}, s.uploadErr
+	}
+
+	content, readErr := io.ReadAll(body)
+	if readErr != nil {
+		return azblob.UploadStreamResponse{}, readErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[containerName+"/"+blobName] = content
+	s.options[containerName+"/"+blobName] = o
+
+	return azblob.UploadStreamResponse{}, nil
+}
+
+func TestAzureBlobUploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileContent, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processableFile := files.NewProcessableFile(file.Name())
+	in := []files.ProcessableFile{
+		processableFile,
+	}
+
+	expectedKey := "avatars/42/" + processableFile.GeneratedFilename()
+
+	blobStorage := newFakeAzureBlobStorage()
+	operation := &AzureBlobUploadOperation{
+		Params: &AzureBlobUploadOperationParams{
+			AccountName: azuriteAccountName,
+			AccountKey:  azuriteAccountKey,
+			Endpoint:    "http://127.0.0.1:10000/devstoreaccount1",
+			Container:   "files",
+
+			Key: "avatars/{{.UserID}}/{{.GeneratedFilename}}",
+			KeyTemplateVars: map[string]string{
+				"UserID": "42",
+			},
+			ContentDisposition: "attachment",
+			AccessTier:         "Cool",
+			Metadata: map[string]string{
+				"originalfilename": "{{.OriginalFilename}}",
+			},
+			Tags: map[string]string{
+				"user": "{{.UserID}}",
+			},
+		},
+		UploadAPI: blobStorage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if bytes.Compare(blobStorage.blobs["files/"+expectedKey], fileContent) != 0 {
+		t.Fatalf("expected uploaded blob content to be equal to file content")
+	}
+
+	opts := blobStorage.options["files/"+expectedKey]
+	if *opts.HTTPHeaders.BlobContentType != "application/octet-stream" {
+		t.Fatalf("expected content type to be application/octet-stream, got %s", *opts.HTTPHeaders.BlobContentType)
+	}
+
+	if *opts.HTTPHeaders.BlobContentDisposition != "attachment; filename=file_5kb.bin" {
+		t.Fatalf(
+			"expected content disposition to be attachment; filename=file_5kb.bin, got %s",
+			*opts.HTTPHeaders.BlobContentDisposition,
+		)
+	}
+
+	if string(*opts.AccessTier) != "Cool" {
+		t.Fatalf("expected access tier to be Cool, got %s", *opts.AccessTier)
+	}
+
+	if *opts.Metadata["originalfilename"] != "file_5kb.bin" {
+		t.Fatalf("expected originalfilename metadata to be file_5kb.bin, got %s", *opts.Metadata["originalfilename"])
+	}
+
+	if opts.Tags["user"] != "42" {
+		t.Fatalf("expected user tag to be 42, got %s", opts.Tags["user"])
+	}
+
+	expectedUrl := "http://127.0.0.1:10000/devstoreaccount1/files/" + expectedKey
+	if out[0].OperationMetadata[MetadataKeyAzureBlobUploadFileUrl] != expectedUrl {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyAzureBlobUploadFileUrl,
+			out[0].OperationMetadata[MetadataKeyAzureBlobUploadFileUrl],
+			expectedUrl,
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyAzureBlobUploadKey] != expectedKey {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyAzureBlobUploadKey,
+			out[0].OperationMetadata[MetadataKeyAzureBlobUploadKey],
+			expectedKey,
+		)
+	}
+}
+
+func TestAzureBlobUploadOperation_HandleFailedFilesUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	blobStorage := newFakeAzureBlobStorage()
+	blobStorage.uploadErr = errors.New("connection reset by peer")
+
+	operation := &AzureBlobUploadOperation{
+		Params: &AzureBlobUploadOperationParams{
+			AccountName: azuriteAccountName,
+			AccountKey:  azuriteAccountKey,
+			Container:   "files",
+		},
+		UploadAPI: blobStorage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError == nil {
+		t.Fatalf("FileProcessingError = nil, want !nil")
+	}
+
+	if out[0].FileProcessingError.Code() != ErrorCodeAzureBlobFileUploadFailure {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want %s",
+			out[0].FileProcessingError.Code(),
+			ErrorCodeAzureBlobFileUploadFailure,
+		)
+	}
+}
+
+func TestAzureBlobUploadOperation_HandlePresignedUrlGeneration(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &AzureBlobUploadOperation{
+		Params: &AzureBlobUploadOperationParams{
+			AccountName:            azuriteAccountName,
+			AccountKey:             azuriteAccountKey,
+			Container:              "files",
+			PresignedUrlExpiration: 15 * time.Minute,
+		},
+		UploadAPI: newFakeAzureBlobStorage(),
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	presignedUrl, ok := out[0].OperationMetadata[MetadataKeyAzureBlobUploadPresignedUrl].(string)
+	if !ok {
+		t.Fatalf("metadata key %s = nil, want !nil", MetadataKeyAzureBlobUploadPresignedUrl)
+	}
+
+	expectedUrlPrefix := "https://devstoreaccount1.blob.core.windows.net/files/" + out[0].GeneratedFilename() + "?"
+	if !strings.HasPrefix(presignedUrl, expectedUrlPrefix) {
+		t.Fatalf("presigned URL = %s, want prefix %s", presignedUrl, expectedUrlPrefix)
+	}
+
+	for _, queryParam := range []string{"sp=r", "sr=b", "sig="} {
+		if !strings.Contains(presignedUrl, queryParam) {
+			t.Fatalf("presigned URL = %s, want to contain %s", presignedUrl, queryParam)
+		}
+	}
+
+	if _, ok := out[0].OperationMetadata[MetadataKeyAzureBlobUploadPresignedUrlExpiresAt].(time.Time); !ok {
+		t.Fatalf("metadata key %s = nil, want !nil", MetadataKeyAzureBlobUploadPresignedUrlExpiresAt)
+	}
+}
@@ -0,0 +1,131 @@
+package operations
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdResponseMaxSize The maximum size of the clamd response. The response is a single line
+// with the scan result, so anything bigger is not a clamd response.
+const clamdResponseMaxSize = 4096
+
+// clamdClient Scans the streams with the clamd daemon using INSTREAM command.
+type clamdClient struct {
+	// network is either "tcp" or "unix".
+	network string
+	address string
+	// timeout is the time limit for the whole scan, including the connection.
+	timeout   time.Duration
+	chunkSize int
+}
+
+// clamdUnavailableError The scan has not been completed because clamd can not be reached, or the connection
+// has failed. Unlike the scan errors clamd reports, like the exceeded size limit, these are subject to the fail
+// policy. Otherwise, the file could be crafted to fail the scan and pass without it.
+type clamdUnavailableError struct {
+	err error
+}
+
+func (e *clamdUnavailableError) Error() string {
+	return "clamd is unavailable: " + e.err.Error()
+}
+
+func (e *clamdUnavailableError) Unwrap() error {
+	return e.err
+}
+
+// scan Streams the content to clamd. Returns the signature name if the content is infected,
+// or an empty string if it is clean.
+func (c *clamdClient) scan(r io.Reader) (signature string, err error) {
+	conn, dialErr := net.DialTimeout(c.network, c.address, c.timeout)
+	if dialErr != nil {
+		return "", &clamdUnavailableError{err: dialErr}
+	}
+	defer conn.Close()
+
+	if c.timeout > 0 {
+		deadlineErr := conn.SetDeadline(time.Now().Add(c.timeout))
+		if deadlineErr != nil {
+			return "", &clamdUnavailableError{err: deadlineErr}
+		}
+	}
+
+	// The "z" prefix means the command and the response are null-terminated.
+	_, writeErr := conn.Write([]byte("zINSTREAM\x00"))
+	if writeErr != nil {
+		return "", &clamdUnavailableError{err: writeErr}
+	}
+
+	chunk := make([]byte, 4+c.chunkSize)
+	for {
+		n, readErr := io.ReadFull(r, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			_, writeErr = conn.Write(chunk[:4+n])
+			if writeErr != nil {
+				// clamd closes the connection once the stream exceeds StreamMaxLength,
+				// so the response might explain the failure better.
+				resp, respErr := readClamdResponse(conn)
+				if respErr == nil {
+					signature, parseErr := parseClamdResponse(resp)
+					if parseErr != nil || signature != "" {
+						return signature, parseErr
+					}
+				}
+
+				return "", &clamdUnavailableError{err: writeErr}
+			}
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+
+	// The zero-length chunk marks the end of the stream.
+	_, writeErr = conn.Write([]byte{0, 0, 0, 0})
+	if writeErr != nil {
+		return "", &clamdUnavailableError{err: writeErr}
+	}
+
+	resp, respErr := readClamdResponse(conn)
+	if respErr != nil {
+		return "", &clamdUnavailableError{err: respErr}
+	}
+
+	return parseClamdResponse(resp)
+}
+
+func readClamdResponse(conn net.Conn) (string, error) {
+	resp, readErr := bufio.NewReader(io.LimitReader(conn, clamdResponseMaxSize)).ReadString(0)
+	if readErr != nil && (readErr != io.EOF || resp == "") {
+		return "", readErr
+	}
+
+	return strings.TrimSpace(strings.TrimRight(resp, "\x00")), nil
+}
+
+// parseClamdResponse Parses INSTREAM response. The possible responses are:
+// "stream: OK", "stream: <signature> FOUND" and "<message> ERROR".
+func parseClamdResponse(resp string) (string, error) {
+	result := strings.TrimPrefix(resp, "stream: ")
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	case strings.HasSuffix(result, " ERROR"):
+		return "", errors.New("clamd has failed to scan the stream: " + strings.TrimSuffix(result, " ERROR"))
+	}
+
+	return "", fmt.Errorf("unexpected clamd response %q", resp)
+}
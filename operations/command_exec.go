@@ -2,15 +2,20 @@ package operations
 
 import (
 	"bytes"
+	"capyfile/capyerr"
 	"capyfile/capyfs"
 	"capyfile/files"
 	"errors"
 	"fmt"
 	"html/template"
 	"os/exec"
+	"regexp"
+	"strings"
 	"sync"
 )
 
+const ErrorCodeCommandExecOperationConfiguration = "COMMAND_EXEC_OPERATION_CONFIGURATION"
+
 type CommandExecOperation struct {
 	Name            string
 	Params          *CommandExecOperationParams
@@ -32,6 +37,16 @@ type CommandExecOperationParams struct {
 	CommandArgs            []string
 	OutputFileDestination  string
 	AllowParallelExecution bool
+	// BatchMode indicates whether the command should be executed once for the whole
+	// input packet instead of once per file. In this mode the command name and args
+	// templates receive batchTemplateData, and the arg that renders to multiple
+	// lines is passed to the command as multiple args (one per line).
+	BatchMode bool
+	// BatchFailedFilePattern is the regular expression that is used to find the
+	// files the command has failed to process in batch mode. It is matched against
+	// the command output, and the "file" named group (or the first group if there
+	// is no such) must contain the absolute path or the filename of the failed file.
+	BatchFailedFilePattern string
 }
 
 type templateData struct {
@@ -51,6 +66,46 @@ type templateData struct {
 	OriginalExtension    string
 }
 
+func newTemplateData(pf *files.ProcessableFile) (templateData, error) {
+	absolutePath, absolutePathErr := pf.FileAbsolutePath()
+	if absolutePathErr != nil {
+		return templateData{}, absolutePathErr
+	}
+
+	tmplData := templateData{
+		AbsolutePath:         absolutePath,
+		Filename:             pf.Filename(),
+		Basename:             pf.FileBasename(),
+		Extension:            pf.FileExtension(),
+		OriginalAbsolutePath: absolutePath,
+		OriginalFilename:     pf.Filename(),
+		OriginalBasename:     pf.FileBasename(),
+		OriginalExtension:    pf.FileExtension(),
+	}
+	if pf.OriginalProcessableFile != nil {
+		originalAbsolutePath, originalAbsolutePathErr := pf.OriginalProcessableFile.FileAbsolutePath()
+		if originalAbsolutePathErr != nil {
+			return templateData{}, originalAbsolutePathErr
+		}
+
+		tmplData.OriginalAbsolutePath = originalAbsolutePath
+		tmplData.OriginalFilename = pf.OriginalProcessableFile.Filename()
+		tmplData.OriginalBasename = pf.OriginalProcessableFile.FileBasename()
+		tmplData.OriginalExtension = pf.OriginalProcessableFile.FileExtension()
+	}
+
+	return tmplData, nil
+}
+
+// batchTemplateData is the data that is available for the command templates in batch mode.
+// For example, this is how all the files can be passed to the command as separate args:
+//
+//	{{range .Files}}{{println .AbsolutePath}}{{end}}
+type batchTemplateData struct {
+	// Files in the same order as they are in the input packet.
+	Files []templateData
+}
+
 func (o *CommandExecOperation) Handle(
 	in []files.ProcessableFile,
 	errorCh chan<- OperationError,
@@ -65,6 +120,12 @@ func (o *CommandExecOperation) Handle(
 		return nil, commandExecutorInitErr
 	}
 
+	// With empty input there is nothing to batch, so the command is executed
+	// the same way as it is executed in the regular mode.
+	if o.Params.BatchMode && len(in) > 0 {
+		return o.handleBatch(in, errorCh, notificationCh)
+	}
+
 	outHolder := newOutputHolder()
 
 	var wg sync.WaitGroup
@@ -82,54 +143,22 @@ func (o *CommandExecOperation) Handle(
 		var tmplData templateData
 
 		if pf != nil {
-			absolutePath, absolutePathErr := pf.FileAbsolutePath()
-			if absolutePathErr != nil {
+			var tmplDataErr error
+			tmplData, tmplDataErr = newTemplateData(pf)
+			if tmplDataErr != nil {
 				if errorCh != nil {
-					errorCh <- o.errorBuilder().Error(absolutePathErr)
+					errorCh <- o.errorBuilder().Error(tmplDataErr)
 				}
 
 				if notificationCh != nil {
 					notificationCh <- o.notificationBuilder().Failed(
-						"can not get the absolute path to the file", pf, absolutePathErr)
+						"can not get the absolute path to the file", pf, tmplDataErr)
 				}
 
 				outHolder.AppendToOut(pf)
 
 				return
 			}
-
-			tmplData = templateData{
-				AbsolutePath:         absolutePath,
-				Filename:             pf.Filename(),
-				Basename:             pf.FileBasename(),
-				Extension:            pf.FileExtension(),
-				OriginalAbsolutePath: absolutePath,
-				OriginalFilename:     pf.Filename(),
-				OriginalBasename:     pf.FileBasename(),
-				OriginalExtension:    pf.FileExtension(),
-			}
-			if pf.OriginalProcessableFile != nil {
-				originalAbsolutePath, originalAbsolutePathErr := pf.OriginalProcessableFile.FileAbsolutePath()
-				if originalAbsolutePathErr != nil {
-					if errorCh != nil {
-						errorCh <- o.errorBuilder().Error(originalAbsolutePathErr)
-					}
-
-					if notificationCh != nil {
-						notificationCh <- o.notificationBuilder().Failed(
-							"can not get the absolute path to the original file", pf, originalAbsolutePathErr)
-					}
-
-					outHolder.AppendToOut(pf)
-
-					return
-				}
-
-				tmplData.OriginalAbsolutePath = originalAbsolutePath
-				tmplData.OriginalFilename = pf.OriginalProcessableFile.Filename()
-				tmplData.OriginalBasename = pf.OriginalProcessableFile.FileBasename()
-				tmplData.OriginalExtension = pf.OriginalProcessableFile.FileExtension()
-			}
 		}
 
 		cmdName, cmdNameErr := o.renderTemplate(
@@ -281,10 +310,241 @@ func (o *CommandExecOperation) Handle(
 	return outHolder.Out, nil
 }
 
+// handleBatch executes the command once for the whole input packet.
+func (o *CommandExecOperation) handleBatch(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var failedFileRegexp *regexp.Regexp
+	if o.Params.BatchFailedFilePattern != "" {
+		var regexpErr error
+		failedFileRegexp, regexpErr = regexp.Compile(o.Params.BatchFailedFilePattern)
+		if regexpErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(regexpErr)
+			}
+
+			return in, capyerr.NewOperationConfigurationError(
+				ErrorCodeCommandExecOperationConfiguration,
+				"batch failed file pattern can not be compiled",
+				regexpErr,
+			)
+		}
+	}
+
+	// The files we were able to build the template data for. Only these files
+	// are passed to the command.
+	var batch []*files.ProcessableFile
+	var tmplData batchTemplateData
+
+	for i := range in {
+		pf := &in[i]
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Started("batch command execution has started", pf)
+		}
+
+		pfTmplData, pfTmplDataErr := newTemplateData(pf)
+		if pfTmplDataErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(pf, pfTmplDataErr)
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(
+					"can not get the absolute path to the file", pf, pfTmplDataErr)
+			}
+
+			out = append(out, *pf)
+
+			continue
+		}
+
+		batch = append(batch, pf)
+		tmplData.Files = append(tmplData.Files, pfTmplData)
+	}
+
+	if len(batch) == 0 {
+		return out, nil
+	}
+
+	// If the template can not be rendered, the whole batch fails.
+	failBatch := func(fileProcessingErr files.FileProcessingError, message string, err error) {
+		for _, pf := range batch {
+			pf.SetFileProcessingError(fileProcessingErr)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(message, pf, err)
+			}
+
+			out = append(out, *pf)
+		}
+	}
+
+	cmdName, cmdNameErr := o.renderTemplate(
+		"command name",
+		o.Params.CommandName,
+		tmplData,
+		nil,
+		errorCh,
+		notificationCh,
+	)
+	if cmdNameErr != nil {
+		failBatch(
+			NewCommandTemplateCanNotBeRenderedError(cmdNameErr),
+			"command name template can not be rendered",
+			cmdNameErr,
+		)
+
+		return out, nil
+	}
+
+	var cmdArgs []string
+	for _, arg := range o.Params.CommandArgs {
+		cmdArg, cmdArgErr := o.renderTemplate(
+			"command argument",
+			arg,
+			tmplData,
+			nil,
+			errorCh,
+			notificationCh,
+		)
+		if cmdArgErr != nil {
+			failBatch(
+				NewCommandTemplateCanNotBeRenderedError(cmdArgErr),
+				"command argument template can not be rendered",
+				cmdArgErr,
+			)
+
+			return out, nil
+		}
+
+		// The arg that renders to multiple lines is split into multiple args. This is
+		// how the files are passed to the command when the template ranges over them.
+		if !strings.Contains(cmdArg, "\n") {
+			cmdArgs = append(cmdArgs, cmdArg)
+
+			continue
+		}
+		for _, line := range strings.Split(cmdArg, "\n") {
+			if line != "" {
+				cmdArgs = append(cmdArgs, line)
+			}
+		}
+	}
+
+	output, execErr := o.CommandExecutor.Execute(cmdName, cmdArgs...)
+	if execErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().InputError(in, execErr)
+			errorCh <- o.errorBuilder().InputError(in, errors.New(string(output)))
+		}
+	}
+
+	// Now we need to figure out which files the command has failed to process.
+	failedFiles := make(map[int]string)
+	if failedFileRegexp != nil {
+		fileGroupIdx := failedFileRegexp.SubexpIndex("file")
+		if fileGroupIdx == -1 {
+			fileGroupIdx = 1
+		}
+
+		for _, match := range failedFileRegexp.FindAllStringSubmatch(string(output), -1) {
+			if fileGroupIdx >= len(match) {
+				continue
+			}
+
+			for i, pfTmplData := range tmplData.Files {
+				if match[fileGroupIdx] == pfTmplData.AbsolutePath || match[fileGroupIdx] == pfTmplData.Filename {
+					failedFiles[i] = match[0]
+				}
+			}
+		}
+	}
+	// If the command has failed, but we can not tell which files caused it,
+	// all the files of the batch are considered failed.
+	if execErr != nil && len(failedFiles) == 0 {
+		for i := range batch {
+			failedFiles[i] = execErr.Error()
+		}
+	}
+
+	for i, pf := range batch {
+		if failure, ok := failedFiles[i]; ok {
+			var fileExecErr = execErr
+			if fileExecErr == nil || failure != execErr.Error() {
+				fileExecErr = errors.New(failure)
+			}
+
+			pf.SetFileProcessingError(
+				NewCommandExecutionError(fileExecErr),
+			)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(
+					"batch command execution has failed for the file", pf, fileExecErr)
+			}
+
+			out = append(out, *pf)
+
+			continue
+		}
+
+		if o.Params.OutputFileDestination != "" {
+			// The output file destination is rendered for every file individually,
+			// since every file is expected to have its own output file.
+			outputFile, outputFileErr := o.renderTemplate(
+				"output file destination",
+				o.Params.OutputFileDestination,
+				tmplData.Files[i],
+				pf,
+				errorCh,
+				notificationCh,
+			)
+			if outputFileErr != nil {
+				out = append(out, *pf)
+
+				continue
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(outputFile)
+			if fileOpenErr != nil {
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+
+				pf.SetFileProcessingError(
+					NewFileIsUnreadableError(fileOpenErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not open the command output file", pf, fileOpenErr)
+				}
+
+				out = append(out, *pf)
+
+				continue
+			}
+
+			pf.ReplaceFile(file.Name())
+		}
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("batch command execution has finished", pf)
+		}
+
+		out = append(out, *pf)
+	}
+
+	return out, nil
+}
+
 func (o *CommandExecOperation) renderTemplate(
 	tmplName string,
 	tmpl string,
-	tmplData templateData,
+	tmplData any,
 	pf *files.ProcessableFile,
 	errorCh chan<- OperationError,
 	notificationCh chan<- OperationNotification,
@@ -3,6 +3,7 @@ package operations
 import (
 	"capyfile/capyfs"
 	"capyfile/files"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -286,3 +287,168 @@ func TestCommandExecOperation_HandleEmptyInput(t *testing.T) {
 		)
 	}
 }
+
+func TestCommandExecOperation_HandleBatchMode(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var in []files.ProcessableFile
+	for _, filename := range []string{"/tmp/image1.png", "/tmp/image2.png", "/tmp/image3.png"} {
+		writeFileErr := capyfs.FilesystemUtils.WriteFile(
+			filename, []byte("whatever bytes doesn't matter"), os.ModePerm)
+		if writeFileErr != nil {
+			t.Fatal(writeFileErr)
+		}
+
+		in = append(in, files.NewProcessableFile(filename))
+	}
+
+	mkdirErr := capyfs.FilesystemUtils.MkdirAll("/tmp/optimized", os.ModePerm)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	executions := 0
+
+	operation := &CommandExecOperation{
+		Name: "command_exec",
+		Params: &CommandExecOperationParams{
+			CommandName: "optipng",
+			CommandArgs: []string{
+				"-dir", "/tmp/optimized",
+				"{{range .Files}}{{println .AbsolutePath}}{{end}}",
+			},
+			OutputFileDestination:  "/tmp/optimized/{{.Filename}}",
+			BatchMode:              true,
+			BatchFailedFilePattern: `Error: (?P<file>\S+)`,
+		},
+		CommandExecutor: mockCommandExecutor(
+			func(name string, arg ...string) (output []byte, err error) {
+				executions++
+
+				if name != "optipng" {
+					t.Fatalf("expected name to be optipng, got %s", name)
+				}
+
+				if len(arg) != 5 {
+					t.Fatalf("len(arg) = %d, want 5", len(arg))
+				}
+
+				if arg[0] != "-dir" {
+					t.Fatalf("expected arg[0] to be -dir, got %s", arg[0])
+				}
+				if arg[1] != "/tmp/optimized" {
+					t.Fatalf("expected arg[1] to be /tmp/optimized, got %s", arg[1])
+				}
+				for i := range in {
+					absPath, _ := in[i].FileAbsolutePath()
+					if arg[i+2] != absPath {
+						t.Fatalf("expected arg[%d] to be %s, got %s", i+2, absPath, arg[i+2])
+					}
+				}
+
+				// The command produces the output files for all the files except the second one.
+				for _, filename := range []string{"image1.png", "image3.png"} {
+					writeFileErr := capyfs.FilesystemUtils.WriteFile(
+						"/tmp/optimized/"+filename,
+						[]byte("whatever bytes doesn't matter"),
+						os.ModePerm,
+					)
+					if writeFileErr != nil {
+						t.Fatal(writeFileErr)
+					}
+				}
+
+				return []byte("Error: /tmp/image2.png is not a PNG file"), errors.New("exit status 1")
+			},
+		),
+	}
+	out, opErr := operation.Handle(in, nil, nil)
+	if opErr != nil {
+		t.Fatal(opErr)
+	}
+
+	if executions != 1 {
+		t.Fatalf("executions = %d, want 1", executions)
+	}
+
+	if len(out) != 3 {
+		t.Fatalf("len(out) = %d, want 3", len(out))
+	}
+
+	for _, pf := range out {
+		if pf.OriginalFilename() == "/tmp/image2.png" {
+			var commandExecutionError *CommandExecutionError
+			if !errors.As(pf.FileProcessingError, &commandExecutionError) {
+				t.Fatalf("FileProcessingError = %v, want *CommandExecutionError", pf.FileProcessingError)
+			}
+
+			continue
+		}
+
+		if pf.FileProcessingError != nil {
+			t.Fatalf(
+				"FileProcessingError.Code() = %s, want nil",
+				pf.FileProcessingError.Code(),
+			)
+		}
+
+		absPath, _ := pf.FileAbsolutePath()
+		expectedAbsPath := "/tmp/optimized/" + pf.OriginalProcessableFile.Filename()
+		if absPath != expectedAbsPath {
+			t.Fatalf("expected file absolute path to be %s, got %s", expectedAbsPath, absPath)
+		}
+	}
+}
+
+func TestCommandExecOperation_HandleBatchModeWithUnknownFailure(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var in []files.ProcessableFile
+	for _, filename := range []string{"/tmp/image1.jpg", "/tmp/image2.jpg"} {
+		writeFileErr := capyfs.FilesystemUtils.WriteFile(
+			filename, []byte("whatever bytes doesn't matter"), os.ModePerm)
+		if writeFileErr != nil {
+			t.Fatal(writeFileErr)
+		}
+
+		in = append(in, files.NewProcessableFile(filename))
+	}
+
+	operation := &CommandExecOperation{
+		Name: "command_exec",
+		Params: &CommandExecOperationParams{
+			CommandName: "exiftool",
+			CommandArgs: []string{
+				"-all:all=",
+				"-overwrite_original",
+				"{{range .Files}}{{println .AbsolutePath}}{{end}}",
+			},
+			BatchMode: true,
+		},
+		CommandExecutor: mockCommandExecutor(
+			func(name string, arg ...string) (output []byte, err error) {
+				if len(arg) != 4 {
+					t.Fatalf("len(arg) = %d, want 4", len(arg))
+				}
+
+				return []byte("something went wrong"), errors.New("exit status 1")
+			},
+		),
+	}
+	out, opErr := operation.Handle(in, nil, nil)
+	if opErr != nil {
+		t.Fatal(opErr)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	// The failed file can not be determined, so all the files of the batch must fail.
+	for _, pf := range out {
+		var commandExecutionError *CommandExecutionError
+		if !errors.As(pf.FileProcessingError, &commandExecutionError) {
+			t.Fatalf("FileProcessingError = %v, want *CommandExecutionError", pf.FileProcessingError)
+		}
+	}
+}
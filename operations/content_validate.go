@@ -0,0 +1,159 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"sync"
+)
+
+const (
+	ContentFormatJSON = "json"
+	ContentFormatCSV  = "csv"
+	ContentFormatXML  = "xml"
+)
+
+const (
+	ContentEncodingUTF8  = "utf-8"
+	ContentEncodingASCII = "ascii"
+	ContentEncodingAny   = "any"
+)
+
+type ContentValidateOperation struct {
+	Name   string
+	Params *ContentValidateOperationParams
+}
+
+type ContentValidateOperationParams struct {
+	// Format is the expected file format. Possible values: "json", "csv", "xml".
+	Format string
+
+	// JSONSchema is the schema the JSON content must conform to. If nil, only the syntax is checked.
+	JSONSchema *jsonschema.Schema
+
+	CSVDelimiter rune
+	// CSVHeader is the expected header row. If empty, the header is not checked.
+	CSVHeader []string
+	// CSVColumnCount is the expected number of columns in every row. If zero, all the rows
+	// must have the same number of columns as the first one.
+	CSVColumnCount int64
+	// CSVEncoding is the expected CSV encoding. Possible values: "utf-8", "ascii", "any".
+	CSVEncoding string
+}
+
+func (o *ContentValidateOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *ContentValidateOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *ContentValidateOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file content validation started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			var validateErr error
+			switch o.Params.Format {
+			case ContentFormatJSON:
+				validateErr = validateJSONContent(file, o.Params.JSONSchema)
+			case ContentFormatCSV:
+				validateErr = validateCSVContent(file, o.Params)
+			case ContentFormatXML:
+				validateErr = validateXMLContent(file)
+			default:
+				validateErr = errors.New("unsupported content format " + o.Params.Format)
+			}
+			_ = file.Close()
+
+			var contentIsInvalidErr *FileContentIsInvalidError
+			if errors.As(validateErr, &contentIsInvalidErr) {
+				pf.SetFileProcessingError(contentIsInvalidErr)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("file content is invalid", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			if validateErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(validateErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, validateErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be read", pf, validateErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file content is valid", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *ContentValidateOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *ContentValidateOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
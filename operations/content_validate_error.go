@@ -0,0 +1,46 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileContentIsInvalid = "FILE_CONTENT_IS_INVALID"
+
+func NewFileContentIsInvalidError(format string, position contentPosition, reason string) *FileContentIsInvalidError {
+	return &FileContentIsInvalidError{
+		Data: &FileContentIsInvalidErrorData{
+			Format:  format,
+			Line:    position.Line,
+			Column:  position.Column,
+			Offset:  position.Offset,
+			Pointer: position.Pointer,
+			Reason:  reason,
+		},
+	}
+}
+
+type FileContentIsInvalidError struct {
+	files.FileProcessingError
+
+	Data *FileContentIsInvalidErrorData
+}
+
+type FileContentIsInvalidErrorData struct {
+	Format string
+	// Line and Column are 1-based. Zero means the position is unknown.
+	Line   int64
+	Column int64
+	// Offset is the byte offset from the beginning of the file.
+	Offset int64
+	// Pointer is the JSON pointer of the value that does not conform to the JSON schema.
+	Pointer string
+	Reason  string
+}
+
+func (e *FileContentIsInvalidError) Code() string {
+	return ErrorCodeFileContentIsInvalid
+}
+
+func (e *FileContentIsInvalidError) Error() string {
+	return "file content is invalid"
+}
@@ -0,0 +1,204 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestContentValidateOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	schema, schemaErr := jsonschema.CompileString("schema.json", `{
+		"type": "object",
+		"required": ["items"],
+		"properties": {
+			"items": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["id", "amount"],
+					"properties": {
+						"id": {"type": "integer"},
+						"amount": {"type": "number", "minimum": 0}
+					}
+				}
+			}
+		}
+	}`)
+	if schemaErr != nil {
+		t.Fatal(schemaErr)
+	}
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/content_validate", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	testCases := []struct {
+		name            string
+		content         string
+		params          *ContentValidateOperationParams
+		expectedValid   bool
+		expectedLine    int64
+		expectedColumn  int64
+		expectedPointer string
+	}{
+		{
+			name:    "valid json",
+			content: "{\"items\": [{\"id\": 1, \"amount\": 10.5}]}\n",
+			params: &ContentValidateOperationParams{
+				Format:     ContentFormatJSON,
+				JSONSchema: schema,
+			},
+			expectedValid: true,
+		},
+		{
+			name:    "malformed json",
+			content: "{\n  \"items\": [\n    {\"id\": 1,}\n  ]\n}",
+			params: &ContentValidateOperationParams{
+				Format: ContentFormatJSON,
+			},
+			expectedLine:   3,
+			expectedColumn: 14,
+		},
+		{
+			name:    "json does not conform to schema",
+			content: "{\n  \"items\": [\n    {\"id\": 1, \"amount\": 10},\n    {\"id\": 2, \"amount\": -5}\n  ]\n}",
+			params: &ContentValidateOperationParams{
+				Format:     ContentFormatJSON,
+				JSONSchema: schema,
+			},
+			expectedLine:    4,
+			expectedColumn:  25,
+			expectedPointer: "/items/1/amount",
+		},
+		{
+			name:    "json with trailing data",
+			content: "{\"items\": []}\n{}",
+			params: &ContentValidateOperationParams{
+				Format: ContentFormatJSON,
+			},
+			expectedLine:   2,
+			expectedColumn: 1,
+		},
+		{
+			name:    "valid csv",
+			content: "\xEF\xBB\xBFid;amount\n1;100\n2;200\n",
+			params: &ContentValidateOperationParams{
+				Format:         ContentFormatCSV,
+				CSVDelimiter:   ';',
+				CSVHeader:      []string{"id", "amount"},
+				CSVColumnCount: 2,
+				CSVEncoding:    ContentEncodingUTF8,
+			},
+			expectedValid: true,
+		},
+		{
+			name:    "csv with wrong number of columns",
+			content: "id,amount\n1,100\n2,200,300\n",
+			params: &ContentValidateOperationParams{
+				Format:      ContentFormatCSV,
+				CSVEncoding: ContentEncodingUTF8,
+			},
+			expectedLine:   3,
+			expectedColumn: 1,
+		},
+		{
+			name:    "csv with unexpected header",
+			content: "id,total\n1,100\n",
+			params: &ContentValidateOperationParams{
+				Format:      ContentFormatCSV,
+				CSVHeader:   []string{"id", "amount"},
+				CSVEncoding: ContentEncodingUTF8,
+			},
+			expectedLine:   1,
+			expectedColumn: 4,
+		},
+		{
+			name:    "csv is not ascii",
+			content: "id,name\n1,Zoë\n",
+			params: &ContentValidateOperationParams{
+				Format:      ContentFormatCSV,
+				CSVEncoding: ContentEncodingASCII,
+			},
+			expectedLine:   2,
+			expectedColumn: 3,
+		},
+		{
+			name:    "valid xml",
+			content: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<items>\n  <item id=\"1\"/>\n</items>\n",
+			params: &ContentValidateOperationParams{
+				Format: ContentFormatXML,
+			},
+			expectedValid: true,
+		},
+		{
+			name:    "malformed xml",
+			content: "<items>\n  <item id=\"1\">\n</items>\n",
+			params: &ContentValidateOperationParams{
+				Format: ContentFormatXML,
+			},
+			expectedLine: 3,
+		},
+		{
+			name:    "xml with multiple root elements",
+			content: "<items/>\n<items/>\n",
+			params: &ContentValidateOperationParams{
+				Format: ContentFormatXML,
+			},
+			expectedLine:   2,
+			expectedColumn: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filename := "/tmp/content_validate/" + testCase.params.Format
+			writeErr := afero.WriteFile(capyfs.Filesystem, filename, []byte(testCase.content), 0644)
+			if writeErr != nil {
+				t.Fatal(writeErr)
+			}
+
+			operation := &ContentValidateOperation{
+				Name:   "content_validate",
+				Params: testCase.params,
+			}
+
+			out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile(filename)}, nil, nil)
+			if err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			if testCase.expectedValid {
+				if out[0].HasFileProcessingError() {
+					t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+				}
+
+				return
+			}
+
+			contentIsInvalidErr, ok := out[0].FileProcessingError.(*FileContentIsInvalidError)
+			if !ok {
+				t.Fatalf("expected %s error, got %v", ErrorCodeFileContentIsInvalid, out[0].FileProcessingError)
+			}
+
+			data := contentIsInvalidErr.Data
+			if data.Line != testCase.expectedLine {
+				t.Fatalf("Line = %d, want %d (%s)", data.Line, testCase.expectedLine, data.Reason)
+			}
+			if testCase.expectedColumn != 0 && data.Column != testCase.expectedColumn {
+				t.Fatalf("Column = %d, want %d (%s)", data.Column, testCase.expectedColumn, data.Reason)
+			}
+			if data.Pointer != testCase.expectedPointer {
+				t.Fatalf("Pointer = %s, want %s", data.Pointer, testCase.expectedPointer)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// contentPosition The position of the content violation within the file.
+type contentPosition struct {
+	Line    int64
+	Column  int64
+	Offset  int64
+	Pointer string
+}
+
+// positionAtOffset Calculates the line and column of the byte offset.
+func positionAtOffset(data []byte, offset int64) contentPosition {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+
+	return contentPosition{
+		Line:   int64(bytes.Count(data[:offset], []byte{'\n'})) + 1,
+		Column: int64(utf8.RuneCount(data[lineStart:offset])) + 1,
+		Offset: offset,
+	}
+}
+
+// invalidUTF8Offset Returns the offset of the first byte that is not valid UTF-8, or -1 if the data is valid.
+func invalidUTF8Offset(data []byte) int64 {
+	for offset := 0; offset < len(data); {
+		r, size := utf8.DecodeRune(data[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return int64(offset)
+		}
+
+		offset += size
+	}
+
+	return -1
+}
+
+// validateJSONContent Checks that the content is a single well-formed JSON value conforming to the schema.
+// The whole file is read into memory, because the schema validation needs the decoded value anyway.
+func validateJSONContent(r io.Reader, schema *jsonschema.Schema) error {
+	data, readErr := io.ReadAll(r)
+	if readErr != nil {
+		return readErr
+	}
+
+	if invalidOffset := invalidUTF8Offset(data); invalidOffset >= 0 {
+		return NewFileContentIsInvalidError(
+			ContentFormatJSON, positionAtOffset(data, invalidOffset), "content is not valid UTF-8")
+	}
+
+	// JSON text can start with BOM, but it is not part of the value.
+	content := bytes.TrimPrefix(data, utf8BOM)
+	bomLen := int64(len(data) - len(content))
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value any
+	decodeErr := decoder.Decode(&value)
+	if decodeErr != nil {
+		var syntaxErr *json.SyntaxError
+		switch {
+		case errors.As(decodeErr, &syntaxErr):
+			// The error occurred after reading Offset bytes, so the offending byte is the last one read.
+			return NewFileContentIsInvalidError(
+				ContentFormatJSON, positionAtOffset(data, bomLen+syntaxErr.Offset-1), syntaxErr.Error())
+		case errors.Is(decodeErr, io.EOF):
+			return NewFileContentIsInvalidError(
+				ContentFormatJSON, positionAtOffset(data, 0), "content is empty")
+		case errors.Is(decodeErr, io.ErrUnexpectedEOF):
+			return NewFileContentIsInvalidError(
+				ContentFormatJSON, positionAtOffset(data, int64(len(data))), "unexpected end of JSON input")
+		default:
+			return decodeErr
+		}
+	}
+
+	valueEnd := decoder.InputOffset()
+	rest := content[valueEnd:]
+	if trimmed := bytes.TrimLeft(rest, " \t\r\n"); len(trimmed) > 0 {
+		return NewFileContentIsInvalidError(
+			ContentFormatJSON,
+			positionAtOffset(data, bomLen+valueEnd+int64(len(rest)-len(trimmed))),
+			"unexpected data after top-level value",
+		)
+	}
+
+	if schema == nil {
+		return nil
+	}
+
+	validationErr := schema.Validate(value)
+	if validationErr == nil {
+		return nil
+	}
+
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(validationErr, &schemaErr) {
+		return NewFileContentIsInvalidError(ContentFormatJSON, contentPosition{}, validationErr.Error())
+	}
+
+	// The deepest cause is the most specific one.
+	for len(schemaErr.Causes) > 0 {
+		schemaErr = schemaErr.Causes[0]
+	}
+
+	position := contentPosition{}
+	if offset, found := jsonPointerOffset(content, schemaErr.InstanceLocation); found {
+		position = positionAtOffset(data, bomLen+offset)
+	}
+	position.Pointer = schemaErr.InstanceLocation
+	if position.Pointer == "" {
+		position.Pointer = "/"
+	}
+
+	return NewFileContentIsInvalidError(ContentFormatJSON, position, schemaErr.Message)
+}
+
+// jsonPointerOffset Finds the offset of the value the JSON pointer points to.
+func jsonPointerOffset(data []byte, pointer string) (int64, bool) {
+	var path []string
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			path = append(path, strings.NewReplacer("~1", "/", "~0", "~").Replace(token))
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	return seekJSONValue(decoder, data, path)
+}
+
+func seekJSONValue(decoder *json.Decoder, data []byte, path []string) (int64, bool) {
+	valueStart := decoder.InputOffset()
+	for valueStart < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[valueStart]) >= 0 {
+		valueStart++
+	}
+
+	token, tokenErr := decoder.Token()
+	if tokenErr != nil {
+		return 0, false
+	}
+
+	if len(path) == 0 {
+		return valueStart, true
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			keyToken, keyErr := decoder.Token()
+			if keyErr != nil {
+				return 0, false
+			}
+
+			if key, _ := keyToken.(string); key == path[0] {
+				return seekJSONValue(decoder, data, path[1:])
+			}
+
+			if !skipJSONValue(decoder) {
+				return 0, false
+			}
+		}
+	case json.Delim('['):
+		index, indexErr := strconv.Atoi(path[0])
+		if indexErr != nil {
+			return 0, false
+		}
+
+		for i := 0; decoder.More(); i++ {
+			if i == index {
+				return seekJSONValue(decoder, data, path[1:])
+			}
+
+			if !skipJSONValue(decoder) {
+				return 0, false
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func skipJSONValue(decoder *json.Decoder) bool {
+	depth := 0
+	for {
+		token, tokenErr := decoder.Token()
+		if tokenErr != nil {
+			return false
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return true
+		}
+	}
+}
+
+// validateCSVContent Checks that the content is well-formed CSV with the expected header, number of columns
+// and encoding. The content is read row by row.
+func validateCSVContent(r io.Reader, params *ContentValidateOperationParams) error {
+	bufferedReader := bufio.NewReader(r)
+
+	// The offsets and columns are reported relative to the content after BOM.
+	bom, _ := bufferedReader.Peek(len(utf8BOM))
+	if bytes.Equal(bom, utf8BOM) {
+		_, _ = bufferedReader.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(bufferedReader)
+	if params.CSVDelimiter != 0 {
+		reader.Comma = params.CSVDelimiter
+	}
+	reader.FieldsPerRecord = int(params.CSVColumnCount)
+	reader.ReuseRecord = true
+
+	for rowIdx := 0; ; rowIdx++ {
+		rowOffset := reader.InputOffset()
+
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			if rowIdx == 0 {
+				return NewFileContentIsInvalidError(
+					ContentFormatCSV, contentPosition{Line: 1, Column: 1}, "content is empty")
+			}
+
+			return nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(readErr, &parseErr) {
+			return NewFileContentIsInvalidError(
+				ContentFormatCSV,
+				contentPosition{
+					Line:   int64(parseErr.Line),
+					Column: int64(parseErr.Column),
+					Offset: rowOffset,
+				},
+				parseErr.Err.Error(),
+			)
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		for fieldIdx, field := range record {
+			reason := csvFieldEncodingViolation(field, params.CSVEncoding)
+			if reason == "" {
+				continue
+			}
+
+			line, column := reader.FieldPos(fieldIdx)
+
+			return NewFileContentIsInvalidError(
+				ContentFormatCSV,
+				contentPosition{Line: int64(line), Column: int64(column), Offset: rowOffset},
+				reason,
+			)
+		}
+
+		if rowIdx == 0 && len(params.CSVHeader) > 0 {
+			reason, fieldIdx := csvHeaderViolation(record, params.CSVHeader)
+			if reason == "" {
+				continue
+			}
+
+			line, column := reader.FieldPos(fieldIdx)
+
+			return NewFileContentIsInvalidError(
+				ContentFormatCSV,
+				contentPosition{Line: int64(line), Column: int64(column), Offset: rowOffset},
+				reason,
+			)
+		}
+	}
+}
+
+func csvFieldEncodingViolation(field, encoding string) string {
+	switch encoding {
+	case ContentEncodingAny:
+		return ""
+	case ContentEncodingASCII:
+		for i := 0; i < len(field); i++ {
+			if field[i] >= utf8.RuneSelf {
+				return "content is not valid ASCII"
+			}
+		}
+	default:
+		if !utf8.ValidString(field) {
+			return "content is not valid UTF-8"
+		}
+	}
+
+	return ""
+}
+
+// csvHeaderViolation Returns the reason the header does not match the expected one, and the index
+// of the first mismatched column.
+func csvHeaderViolation(header, expectedHeader []string) (string, int) {
+	for i := range expectedHeader {
+		if i >= len(header) {
+			return fmt.Sprintf("header column %q is missing", expectedHeader[i]), len(header) - 1
+		}
+
+		if header[i] != expectedHeader[i] {
+			return fmt.Sprintf("header column %d is %q, expected %q", i+1, header[i], expectedHeader[i]), i
+		}
+	}
+
+	if len(header) > len(expectedHeader) {
+		return fmt.Sprintf("unexpected header column %q", header[len(expectedHeader)]), len(expectedHeader)
+	}
+
+	return "", 0
+}
+
+// validateXMLContent Checks that the content is well-formed XML document with a single root element.
+// Only UTF-8 encoded documents are supported.
+func validateXMLContent(r io.Reader) error {
+	decoder := xml.NewDecoder(bufio.NewReader(r))
+	decoder.Strict = true
+
+	currentPosition := func() contentPosition {
+		line, column := decoder.InputPos()
+
+		return contentPosition{Line: int64(line), Column: int64(column), Offset: decoder.InputOffset()}
+	}
+
+	depth := 0
+	rootElements := 0
+	for {
+		tokenStart := currentPosition()
+
+		token, tokenErr := decoder.Token()
+		if tokenErr == io.EOF {
+			break
+		}
+
+		var syntaxErr *xml.SyntaxError
+		if errors.As(tokenErr, &syntaxErr) {
+			position := currentPosition()
+			position.Line = int64(syntaxErr.Line)
+
+			return NewFileContentIsInvalidError(ContentFormatXML, position, syntaxErr.Msg)
+		}
+		if tokenErr != nil {
+			// Most likely, the document declares the encoding other than UTF-8.
+			return NewFileContentIsInvalidError(ContentFormatXML, currentPosition(), tokenErr.Error())
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				rootElements++
+				if rootElements > 1 {
+					return NewFileContentIsInvalidError(
+						ContentFormatXML, tokenStart, "document has more than one root element")
+				}
+			}
+
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return NewFileContentIsInvalidError(
+					ContentFormatXML, tokenStart, "text outside of the root element")
+			}
+		}
+	}
+
+	if rootElements == 0 {
+		return NewFileContentIsInvalidError(
+			ContentFormatXML, currentPosition(), "document has no root element")
+	}
+
+	return nil
+}
@@ -0,0 +1,304 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const ErrorCodeEventPublishOperationConfiguration = "EVENT_PUBLISH_OPERATION_CONFIGURATION"
+
+type EventPublishOperation struct {
+	Name   string
+	Params *EventPublishOperationParams
+	// Sink is the sink the events are published to. If not set, it is created from the params
+	// on the first Handle call, and reused by the next ones.
+	Sink EventSink
+
+	sinkMu sync.Mutex
+}
+
+func (o *EventPublishOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *EventPublishOperation) AllowConcurrency() bool {
+	return true
+}
+
+type EventPublishOperationParams struct {
+	EventSinkParams
+
+	// Template is the event payload template. If empty, the default event is published as is.
+	// Along with the vars described in objectTemplateData, the default event is available
+	// as {{.Event}}, and the "json" function can be used to encode any value.
+	// For example: {"id": {{json .Event.nanoId}}, "url": {{json .Event.url}}, "userId": {{json .UserID}}}
+	Template string
+	// TemplateVars are the additional vars available in the template.
+	TemplateVars map[string]string
+
+	// MaxRetries is how many times the publishing is retried after it has failed.
+	MaxRetries int64
+	// RetryDelay is the delay before the first retry. It is doubled after every retry.
+	RetryDelay time.Duration
+}
+
+// fileEvent The default event published for the file.
+type fileEvent struct {
+	NanoID           string         `json:"nanoId"`
+	OriginalFilename string         `json:"originalFilename"`
+	Filename         string         `json:"filename"`
+	Url              string         `json:"url,omitempty"`
+	UrlExpiresAt     *time.Time     `json:"urlExpiresAt,omitempty"`
+	MimeType         string         `json:"mimeType,omitempty"`
+	Size             int64          `json:"size"`
+	Checksum         string         `json:"checksum,omitempty"`
+	Status           string         `json:"status"`
+	ErrorCode        string         `json:"errorCode,omitempty"`
+	ErrorMessage     string         `json:"errorMessage,omitempty"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
+}
+
+// newFileEvent Builds the default event for the given processable file. The file might have
+// failed on the previous operations, so the file content related fields are filled on
+// the best effort basis.
+func newFileEvent(pf *files.ProcessableFile) *fileEvent {
+	event := &fileEvent{
+		NanoID:           pf.NanoID,
+		OriginalFilename: pf.OriginalFilename(),
+		Filename:         pf.LogicalFilename(),
+		Status:           "processed",
+		Metadata:         pf.OperationMetadata,
+	}
+
+	event.Url, event.UrlExpiresAt = FileUrlFromMetadata(pf.OperationMetadata)
+
+	mime, mimeErr := pf.Mime()
+	if mimeErr == nil && mime != nil {
+		event.MimeType = mime.String()
+	}
+
+	file, openErr := capyfs.Filesystem.Open(pf.Name())
+	if openErr == nil {
+		hash := sha256.New()
+		size, copyErr := io.Copy(hash, file)
+		if copyErr == nil {
+			event.Size = size
+			event.Checksum = hex.EncodeToString(hash.Sum(nil))
+		}
+		_ = file.Close()
+	}
+
+	if pf.HasFileProcessingError() {
+		event.Status = "failed"
+		event.ErrorCode = pf.FileProcessingError.Code()
+		event.ErrorMessage = pf.FileProcessingError.Error()
+	}
+
+	return event
+}
+
+// buildEvent Builds the event payload for the given processable file.
+func (p *EventPublishOperationParams) buildEvent(pf *files.ProcessableFile) ([]byte, error) {
+	event := newFileEvent(pf)
+	if p.Template == "" {
+		return json.Marshal(event)
+	}
+
+	// The event is passed to the template as a map, so its fields are addressed by the JSON names.
+	eventJson, marshalErr := json.Marshal(event)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	var eventData map[string]any
+	unmarshalErr := json.Unmarshal(eventJson, &eventData)
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	// The omitted fields are still known, so they are null instead of failing the template.
+	eventType := reflect.TypeOf(*event)
+	for i := 0; i < eventType.NumField(); i++ {
+		fieldName, _, _ := strings.Cut(eventType.Field(i).Tag.Get("json"), ",")
+		if _, ok := eventData[fieldName]; !ok {
+			eventData[fieldName] = nil
+		}
+	}
+
+	data := objectTemplateData(pf, p.TemplateVars)
+	data["Event"] = eventData
+
+	parsedTmpl, tmplParseErr := template.New("event").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				encoded, encodeErr := json.Marshal(v)
+
+				return string(encoded), encodeErr
+			},
+		}).
+		Parse(p.Template)
+	if tmplParseErr != nil {
+		return nil, tmplParseErr
+	}
+
+	var buf bytes.Buffer
+	tmplExecErr := parsedTmpl.Execute(&buf, data)
+	if tmplExecErr != nil {
+		return nil, tmplExecErr
+	}
+
+	// Compact the payload, so it is always a valid JSON, and it fits a single line of the JSONL file.
+	var compacted bytes.Buffer
+	compactErr := json.Compact(&compacted, buf.Bytes())
+	if compactErr != nil {
+		return nil, errors.New("event template has produced invalid JSON: " + compactErr.Error())
+	}
+
+	return compacted.Bytes(), nil
+}
+
+// sink Returns the sink the events are published to. The sink is created once, so the connection
+// to the broker is not established on every Handle call.
+func (o *EventPublishOperation) sink() (EventSink, error) {
+	o.sinkMu.Lock()
+	defer o.sinkMu.Unlock()
+
+	if o.Sink == nil {
+		sink, sinkErr := o.Params.newSink()
+		if sinkErr != nil {
+			return nil, sinkErr
+		}
+
+		o.Sink = sink
+	}
+
+	return o.Sink, nil
+}
+
+// publish Publishes the event retrying it with the exponential backoff.
+func (o *EventPublishOperation) publish(sink EventSink, event []byte) (err error) {
+	delay := o.Params.RetryDelay
+	for attempt := int64(0); ; attempt++ {
+		err = sink.Publish(event)
+		if err == nil || attempt >= o.Params.MaxRetries {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (o *EventPublishOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	sink, sinkErr := o.sink()
+	if sinkErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New("event sink can not be created"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeEventPublishOperationConfiguration,
+			"event sink can not be created",
+			sinkErr,
+		)
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		var pf = &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("event publishing has started", pf)
+			}
+
+			event, eventErr := o.Params.buildEvent(pf)
+			if eventErr != nil {
+				// The event can be published for the failed files too, and their original error
+				// is more important than the event related one.
+				if !pf.HasFileProcessingError() {
+					pf.SetFileProcessingError(
+						NewEventCanNotBeBuiltError(eventErr),
+					)
+				}
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, eventErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("event can not be built", pf, eventErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			publishErr := o.publish(sink, event)
+			if publishErr != nil {
+				if !pf.HasFileProcessingError() {
+					pf.SetFileProcessingError(
+						NewEventPublishFailureError(publishErr),
+					)
+				}
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, publishErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("failed to publish the event", pf, publishErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("event publishing has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *EventPublishOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *EventPublishOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeEventPublishFailure = "FILE_EVENT_PUBLISH_FAILURE"
+
+func NewEventPublishFailureError(origErr error) *EventPublishFailureError {
+	return &EventPublishFailureError{
+		Data: &EventPublishFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type EventPublishFailureError struct {
+	files.FileProcessingError
+	Data *EventPublishFailureErrorData
+}
+
+type EventPublishFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *EventPublishFailureError) Code() string {
+	return ErrorCodeEventPublishFailure
+}
+
+func (e *EventPublishFailureError) Error() string {
+	return "failed to publish file event"
+}
+
+const ErrorCodeEventCanNotBeBuilt = "FILE_EVENT_CAN_NOT_BE_BUILT"
+
+func NewEventCanNotBeBuiltError(origErr error) *EventCanNotBeBuiltError {
+	return &EventCanNotBeBuiltError{
+		Data: &EventCanNotBeBuiltErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type EventCanNotBeBuiltError struct {
+	files.FileProcessingError
+	Data *EventCanNotBeBuiltErrorData
+}
+
+type EventCanNotBeBuiltErrorData struct {
+	OrigErr error
+}
+
+func (e *EventCanNotBeBuiltError) Code() string {
+	return ErrorCodeEventCanNotBeBuilt
+}
+
+func (e *EventCanNotBeBuiltError) Error() string {
+	return "file event can not be built"
+}
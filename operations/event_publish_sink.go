@@ -0,0 +1,294 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	EventSinkWebhook     = "webhook"
+	EventSinkRedisStream = "redis_stream"
+	EventSinkNATS        = "nats"
+	EventSinkSQS         = "sqs"
+	EventSinkFile        = "file"
+)
+
+// EventSink The destination the events are published to.
+type EventSink interface {
+	// Publish Publishes the event. Returns once the sink has confirmed the event is accepted.
+	Publish(event []byte) error
+	Close() error
+}
+
+// EventSinkParams The parameters of the sinks. Only the parameters of the chosen sink are used.
+type EventSinkParams struct {
+	// Sink is the sink type. Possible values: "webhook", "redis_stream", "nats", "sqs", "file".
+	Sink string
+
+	WebhookUrl     string
+	WebhookHeaders map[string]string
+	WebhookTimeout time.Duration
+
+	RedisAddr     string
+	RedisUsername string
+	RedisPassword string
+	RedisDB       int64
+	RedisStream   string
+	// RedisField is the message field the event is written to.
+	RedisField string
+
+	NATSUrl      string
+	NATSUsername string
+	NATSPassword string
+	NATSToken    string
+	NATSSubject  string
+	// NATSJetStream Whether to publish to JetStream, so the server confirms the event is stored.
+	NATSJetStream bool
+
+	SQSAccessKeyId     string
+	SQSSecretAccessKey string
+	SQSSessionToken    string
+	SQSEndpoint        string
+	SQSRegion          string
+	SQSQueueUrl        string
+
+	// FilePath is the JSONL file the events are appended to.
+	FilePath string
+}
+
+// newSink Creates the sink based on the provided parameters.
+func (p *EventSinkParams) newSink() (EventSink, error) {
+	switch p.Sink {
+	case EventSinkWebhook:
+		return &webhookEventSink{
+			url:     p.WebhookUrl,
+			headers: p.WebhookHeaders,
+			client: &http.Client{
+				Timeout: p.WebhookTimeout,
+			},
+		}, nil
+	case EventSinkRedisStream:
+		return &redisStreamEventSink{
+			client: redis.NewClient(&redis.Options{
+				Addr:     p.RedisAddr,
+				Username: p.RedisUsername,
+				Password: p.RedisPassword,
+				DB:       int(p.RedisDB),
+			}),
+			stream: p.RedisStream,
+			field:  p.RedisField,
+		}, nil
+	case EventSinkNATS:
+		var options []nats.Option
+		if p.NATSUsername != "" {
+			options = append(options, nats.UserInfo(p.NATSUsername, p.NATSPassword))
+		}
+		if p.NATSToken != "" {
+			options = append(options, nats.Token(p.NATSToken))
+		}
+
+		conn, connErr := nats.Connect(p.NATSUrl, options...)
+		if connErr != nil {
+			return nil, connErr
+		}
+
+		sink := &natsEventSink{
+			conn:    conn,
+			subject: p.NATSSubject,
+		}
+		if p.NATSJetStream {
+			js, jsErr := conn.JetStream()
+			if jsErr != nil {
+				conn.Close()
+
+				return nil, jsErr
+			}
+
+			sink.js = js
+		}
+
+		return sink, nil
+	case EventSinkSQS:
+		options := sqs.Options{
+			Credentials: credentials.NewStaticCredentialsProvider(
+				p.SQSAccessKeyId,
+				p.SQSSecretAccessKey,
+				p.SQSSessionToken,
+			),
+			Region: p.SQSRegion,
+		}
+		if p.SQSEndpoint != "" {
+			endpoint := p.SQSEndpoint
+			if !strings.Contains(endpoint, "://") {
+				endpoint = "https://" + endpoint
+			}
+
+			options.EndpointResolver = sqs.EndpointResolverFromURL(endpoint)
+		}
+
+		return &sqsEventSink{
+			api:      sqs.New(options),
+			queueUrl: p.SQSQueueUrl,
+		}, nil
+	case EventSinkFile:
+		return &fileEventSink{
+			path: p.FilePath,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown event sink \"%s\"", p.Sink)
+}
+
+type webhookEventSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (s *webhookEventSink) Publish(event []byte) error {
+	req, reqErr := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(event))
+	if reqErr != nil {
+		return reqErr
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, respErr := s.client.Do(req)
+	if respErr != nil {
+		return respErr
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *webhookEventSink) Close() error {
+	return nil
+}
+
+type redisStreamEventSink struct {
+	client *redis.Client
+	stream string
+	field  string
+}
+
+func (s *redisStreamEventSink) Publish(event []byte) error {
+	return s.client.XAdd(context.TODO(), &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{s.field: string(event)},
+	}).Err()
+}
+
+func (s *redisStreamEventSink) Close() error {
+	return s.client.Close()
+}
+
+type natsEventSink struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+func (s *natsEventSink) Publish(event []byte) error {
+	if s.js != nil {
+		_, publishErr := s.js.Publish(s.subject, event)
+
+		return publishErr
+	}
+
+	publishErr := s.conn.Publish(s.subject, event)
+	if publishErr != nil {
+		return publishErr
+	}
+
+	// Core NATS does not confirm the delivery, so at least make sure the event has reached the server.
+	return s.conn.Flush()
+}
+
+func (s *natsEventSink) Close() error {
+	s.conn.Close()
+
+	return nil
+}
+
+// SQSEventPublishAPI The interface to implement the SQS API calls that we need to publish the events.
+type SQSEventPublishAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+type sqsEventSink struct {
+	api      SQSEventPublishAPI
+	queueUrl string
+}
+
+func (s *sqsEventSink) Publish(event []byte) error {
+	_, sendErr := s.api.SendMessage(context.TODO(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String(s.queueUrl),
+		MessageBody: aws.String(string(event)),
+	})
+
+	return sendErr
+}
+
+func (s *sqsEventSink) Close() error {
+	return nil
+}
+
+// fileEventSink Appends the events to the local JSONL file. Unlike the other sinks, it is not
+// backed by capyfs, because the file is not the processable file.
+type fileEventSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (s *fileEventSink) Publish(event []byte) error {
+	if bytes.ContainsAny(event, "\r\n") {
+		return errors.New("event must be a single line to be written to JSONL file")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mkdirErr := os.MkdirAll(filepath.Dir(s.path), 0755)
+	if mkdirErr != nil {
+		return mkdirErr
+	}
+
+	file, openErr := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return openErr
+	}
+
+	_, writeErr := file.Write(append(event, '\n'))
+	closeErr := file.Close()
+	if writeErr != nil {
+		return writeErr
+	}
+
+	return closeErr
+}
+
+func (s *fileEventSink) Close() error {
+	return nil
+}
@@ -0,0 +1,413 @@
+package operations
+
+import (
+	"bufio"
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEventPublishOperation_HandleWebhookWithRetries(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var mu sync.Mutex
+	var attempts int
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if r.Header.Get("X-Token") != "secret" {
+			t.Errorf("expected X-Token header to be secret, got %s", r.Header.Get("X-Token"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var event map[string]any
+		unmarshalErr := json.Unmarshal(body, &event)
+		if unmarshalErr != nil {
+			t.Errorf("expected event to be valid JSON, got %s", body)
+		}
+		events = append(events, event)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	pf := files.NewProcessableFile("testdata/file_5kb.bin")
+	pf.AddOperationMetadata(MetadataKeyHTTPUploadFileUrl, "https://cdn.internal/file_5kb.bin")
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink:           EventSinkWebhook,
+				WebhookUrl:     server.URL,
+				WebhookHeaders: map[string]string{"X-Token": "secret"},
+				WebhookTimeout: time.Second,
+			},
+			MaxRetries: 3,
+			RetryDelay: time.Millisecond,
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{pf}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+	if len(events) != 1 {
+		t.Fatalf("len(events) = %d, want 1", len(events))
+	}
+
+	event := events[0]
+	if event["nanoId"] != pf.NanoID || event["originalFilename"] != "testdata/file_5kb.bin" {
+		t.Fatalf("unexpected event %v", event)
+	}
+	if event["url"] != "https://cdn.internal/file_5kb.bin" {
+		t.Fatalf("event url = %v, want https://cdn.internal/file_5kb.bin", event["url"])
+	}
+	if event["status"] != "processed" || event["size"] != float64(5120) {
+		t.Fatalf("unexpected event %v", event)
+	}
+	if checksum, _ := event["checksum"].(string); len(checksum) != 64 {
+		t.Fatalf("expected checksum to be sha256 hex, got %v", event["checksum"])
+	}
+}
+
+func TestEventPublishOperation_HandleFailedPublishing(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	failedPf := files.NewProcessableFile("testdata/file_5kb.bin")
+	failedPf.SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink:       EventSinkWebhook,
+				WebhookUrl: server.URL,
+			},
+			MaxRetries: 2,
+			RetryDelay: time.Millisecond,
+		},
+	}
+
+	errorCh := make(chan OperationError, 2)
+	out, err := operation.Handle(
+		[]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin"), failedPf},
+		errorCh,
+		nil,
+	)
+	close(errorCh)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	if len(errorCh) != 2 {
+		t.Fatalf("len(errorCh) = %d, want 2", len(errorCh))
+	}
+	if attempts := atomic.LoadInt32(&attempts); attempts != 6 {
+		t.Fatalf("attempts = %d, want 6", attempts)
+	}
+
+	for _, pf := range out {
+		if !pf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be set")
+		}
+
+		// The original error of the failed file is kept.
+		expectedCode := ErrorCodeEventPublishFailure
+		if pf.NanoID == failedPf.NanoID {
+			expectedCode = ErrorCodeFileCanNotBeOpened
+		}
+		if pf.FileProcessingError.Code() != expectedCode {
+			t.Fatalf("error code = %s, want %s", pf.FileProcessingError.Code(), expectedCode)
+		}
+	}
+}
+
+func TestEventPublishOperation_HandleFileSinkWithTemplate(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	eventsFile := filepath.Join(t.TempDir(), "events", "files.jsonl")
+
+	failedPf := files.NewProcessableFile("testdata/file_5kb.bin")
+	failedPf.SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink:     EventSinkFile,
+				FilePath: eventsFile,
+			},
+			Template: `{
+				"id": {{json .Event.nanoId}},
+				"file": {{json .OriginalFilename}},
+				"user": {{json .UserID}},
+				"errorCode": {{json .Event.errorCode}}
+			}`,
+			TemplateVars: map[string]string{"UserID": "42"},
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{failedPf}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	out, err = operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	file, openErr := os.Open(eventsFile)
+	if openErr != nil {
+		t.Fatal(openErr)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %d, want 2", len(lines))
+	}
+
+	expectedLine := `{"id":"` + failedPf.NanoID + `","file":"file_5kb.bin","user":"42","errorCode":"FILE_CAN_NOT_BE_OPENED"}`
+	if lines[0] != expectedLine {
+		t.Fatalf("lines[0] = %s, want %s", lines[0], expectedLine)
+	}
+}
+
+func TestEventPublishOperation_HandleInvalidTemplate(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink:     EventSinkFile,
+				FilePath: filepath.Join(t.TempDir(), "files.jsonl"),
+			},
+			Template: `{"id": {{.NanoID}}}`,
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != ErrorCodeEventCanNotBeBuilt {
+		t.Fatalf("expected %s error, got %v", ErrorCodeEventCanNotBeBuilt, out[0].FileProcessingError)
+	}
+
+	// The misspelled var fails the event instead of producing null.
+	operation.Params.Template = `{"id": {{json .Event.nanoId}}, "user": {{json .UserId}}}`
+	operation.Params.TemplateVars = map[string]string{"UserID": "42"}
+
+	out, err = operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != ErrorCodeEventCanNotBeBuilt {
+		t.Fatalf("expected %s error, got %v", ErrorCodeEventCanNotBeBuilt, out[0].FileProcessingError)
+	}
+}
+
+func TestEventPublishOperation_HandleRedisStreamSink(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := miniredis.RunT(t)
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink:        EventSinkRedisStream,
+				RedisAddr:   server.Addr(),
+				RedisStream: "file-events",
+				RedisField:  "event",
+			},
+		},
+	}
+
+	pf := files.NewProcessableFile("testdata/file_5kb.bin")
+	pf.Rename("report.bin")
+	for _, in := range [][]files.ProcessableFile{{pf}, {files.NewProcessableFile("testdata/file_1kb.bin")}} {
+		out, err := operation.Handle(in, nil, nil)
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+		if len(out) != 1 || out[0].HasFileProcessingError() {
+			t.Fatalf("expected the file to be processed without errors")
+		}
+	}
+
+	// The sink is created once and reused by the next Handle calls.
+	if server.TotalConnectionCount() != 1 {
+		t.Fatalf("TotalConnectionCount() = %d, want 1", server.TotalConnectionCount())
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	messages, rangeErr := client.XRange(context.Background(), "file-events", "-", "+").Result()
+	if rangeErr != nil {
+		t.Fatal(rangeErr)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("len(messages) = %d, want 2", len(messages))
+	}
+
+	var event map[string]any
+	unmarshalErr := json.Unmarshal([]byte(messages[0].Values["event"].(string)), &event)
+	if unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+	if event["nanoId"] != pf.NanoID {
+		t.Fatalf("event nanoId = %v, want %s", event["nanoId"], pf.NanoID)
+	}
+	if event["filename"] != "report.bin" {
+		t.Fatalf("event filename = %v, want report.bin", event["filename"])
+	}
+}
+
+func TestEventPublishOperation_HandleSinkCanNotBeCreated(t *testing.T) {
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			EventSinkParams: EventSinkParams{
+				Sink: "kafka",
+			},
+		},
+	}
+
+	errorCh := make(chan OperationError, 1)
+	_, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, errorCh, nil)
+
+	var operationConfigurationError *capyerr.OperationConfigurationType
+	if !errors.As(err, &operationConfigurationError) {
+		t.Fatalf("err = %v, want *capyerr.OperationConfigurationType", err)
+	}
+	if len(errorCh) != 1 {
+		t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+	}
+}
+
+type fakeSQSEventPublisher struct {
+	mu       sync.Mutex
+	failures int
+	sent     []*sqs.SendMessageInput
+}
+
+func (p *fakeSQSEventPublisher) SendMessage(
+	ctx context.Context,
+	params *sqs.SendMessageInput,
+	optFns ...func(*sqs.Options),
+) (*sqs.SendMessageOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return nil, errors.New("throttled")
+	}
+
+	p.sent = append(p.sent, params)
+
+	return &sqs.SendMessageOutput{}, nil
+}
+
+func TestEventPublishOperation_HandleSQSSink(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	publisher := &fakeSQSEventPublisher{failures: 1}
+
+	operation := &EventPublishOperation{
+		Name: "event_publish",
+		Params: &EventPublishOperationParams{
+			MaxRetries: 1,
+			RetryDelay: time.Millisecond,
+		},
+		Sink: &sqsEventSink{
+			api:      publisher,
+			queueUrl: "https://sqs.us-east-1.amazonaws.com/000000000000/file-events",
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 || out[0].HasFileProcessingError() {
+		t.Fatalf("expected the file to be processed without errors")
+	}
+
+	if len(publisher.sent) != 1 {
+		t.Fatalf("len(publisher.sent) = %d, want 1", len(publisher.sent))
+	}
+	if aws.ToString(publisher.sent[0].QueueUrl) != "https://sqs.us-east-1.amazonaws.com/000000000000/file-events" {
+		t.Fatalf("unexpected queue url %s", aws.ToString(publisher.sent[0].QueueUrl))
+	}
+	if !json.Valid([]byte(aws.ToString(publisher.sent[0].MessageBody))) {
+		t.Fatalf("expected message body to be valid JSON, got %s", aws.ToString(publisher.sent[0].MessageBody))
+	}
+}
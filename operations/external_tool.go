@@ -0,0 +1,53 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// lookPathExternalTools Checks that the external tools are installed. Returns the first missing tool.
+func lookPathExternalTools(tools []string) (string, error) {
+	for _, tool := range tools {
+		_, lookPathErr := exec.LookPath(tool)
+		if lookPathErr != nil {
+			return tool, lookPathErr
+		}
+	}
+
+	return "", nil
+}
+
+// runExternalTool Runs the external tool and returns its output. The error contains the tool message.
+// The tool is killed if it does not finish in time. 0 timeout means no limit.
+func runExternalTool(timeout time.Duration, name string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, runErr := cmd.Output()
+	if runErr != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s has not finished in %s", name, timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.New(name + ": " + message)
+		}
+
+		return nil, runErr
+	}
+
+	return output, nil
+}
@@ -0,0 +1,27 @@
+package operations
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunExternalTool_Timeout(t *testing.T) {
+	if _, lookPathErr := exec.LookPath("sleep"); lookPathErr != nil {
+		t.Skip("sleep is not installed")
+	}
+
+	start := time.Now()
+	_, err := runExternalTool(100*time.Millisecond, "sleep", "10")
+	if err == nil {
+		t.Fatalf("runExternalTool() error = nil, want the timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("runExternalTool() took %s, want the tool to be killed on timeout", elapsed)
+	}
+
+	_, err = runExternalTool(0, "sleep", "0")
+	if err != nil {
+		t.Fatalf("runExternalTool() error = %v, want nil", err)
+	}
+}
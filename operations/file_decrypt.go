@@ -0,0 +1,190 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"filippo.io/age"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const MetadataKeyFileDecryptAlgorithm = "file_decrypt.algorithm"
+
+// MetadataKeyFileDecryptKeyId The ID of the key the file has been decrypted with.
+const MetadataKeyFileDecryptKeyId = "file_decrypt.key_id"
+
+type FileDecryptOperation struct {
+	Name   string
+	Params *FileDecryptOperationParams
+}
+
+func (o *FileDecryptOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FileDecryptOperation) AllowConcurrency() bool {
+	return true
+}
+
+type FileDecryptOperationParams struct {
+	// Algorithm is the encryption algorithm. Possible values: "age", "aes-256-gcm".
+	Algorithm string
+
+	// AgeIdentities are either X25519 identities, or the single scrypt (passphrase) identity.
+	AgeIdentities []age.Identity
+
+	// AESKey is 32 bytes long AES-256 key.
+	AESKey []byte
+	// AESKeyId is the ID of the key. If set, the files encrypted with another key are rejected
+	// without trying to decrypt them.
+	AESKeyId string
+}
+
+// keyIdRecordingAgeIdentity Records the ID of the identity that has unwrapped the file key.
+type keyIdRecordingAgeIdentity struct {
+	age.Identity
+	keyId *string
+}
+
+func (i *keyIdRecordingAgeIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	fileKey, unwrapErr := i.Identity.Unwrap(stanzas)
+	if unwrapErr == nil {
+		*i.keyId = ageKeyId(i.Identity)
+	}
+
+	return fileKey, unwrapErr
+}
+
+// decrypt Decrypts src into dst and returns the ID of the key the file has been decrypted with.
+func (p *FileDecryptOperationParams) decrypt(dst io.Writer, src io.Reader) (keyId string, err error) {
+	if p.Algorithm == EncryptionAlgorithmAES256GCM {
+		err = aesGCMDecrypt(dst, src, func(envelopeKeyId string) ([]byte, error) {
+			if p.AESKeyId != "" && envelopeKeyId != p.AESKeyId {
+				return nil, fmt.Errorf("file has been encrypted with \"%s\" key", envelopeKeyId)
+			}
+
+			keyId = envelopeKeyId
+
+			return p.AESKey, nil
+		})
+
+		return keyId, err
+	}
+
+	identities := make([]age.Identity, 0, len(p.AgeIdentities))
+	for _, identity := range p.AgeIdentities {
+		identities = append(identities, &keyIdRecordingAgeIdentity{
+			Identity: identity,
+			keyId:    &keyId,
+		})
+	}
+
+	r, decryptErr := age.Decrypt(src, identities...)
+	if decryptErr != nil {
+		return "", decryptErr
+	}
+
+	_, copyErr := io.Copy(dst, r)
+	if copyErr != nil {
+		return "", copyErr
+	}
+
+	return keyId, nil
+}
+
+func (o *FileDecryptOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file decryption has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			var keyId string
+			decryptedFile, decryptErr := capyutils.WriteToAppTmpDirectory(func(w io.Writer) (err error) {
+				keyId, err = o.Params.decrypt(w, file)
+
+				return err
+			})
+			_ = file.Close()
+			if decryptErr != nil {
+				pf.SetFileProcessingError(
+					NewFileDecryptionFailureError(decryptErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, decryptErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be decrypted", pf, decryptErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.ReplaceFile(decryptedFile.Name())
+
+			pf.AddOperationMetadata(MetadataKeyFileDecryptAlgorithm, o.Params.Algorithm)
+			pf.AddOperationMetadata(MetadataKeyFileDecryptKeyId, keyId)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file decryption has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FileDecryptOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileDecryptOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
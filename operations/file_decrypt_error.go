@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileDecryptionFailure = "FILE_DECRYPTION_FAILURE"
+
+func NewFileDecryptionFailureError(origErr error) *FileDecryptionFailureError {
+	return &FileDecryptionFailureError{
+		Data: &FileDecryptionFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileDecryptionFailureError struct {
+	files.FileProcessingError
+	Data *FileDecryptionFailureErrorData
+}
+
+type FileDecryptionFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileDecryptionFailureError) Code() string {
+	return ErrorCodeFileDecryptionFailure
+}
+
+func (e *FileDecryptionFailureError) Error() string {
+	return "file can not be decrypted"
+}
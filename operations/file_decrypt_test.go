@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"filippo.io/age"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestFileDecryptOperation_HandleInvalidFiles(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	key := bytes.Repeat([]byte{7}, 32)
+
+	var encrypted bytes.Buffer
+	encryptErr := aesGCMEncrypt(&encrypted, bytes.NewReader(bytes.Repeat([]byte("data"), 50000)), key, "current")
+	if encryptErr != nil {
+		t.Fatal(encryptErr)
+	}
+	envelope := encrypted.Bytes()
+
+	tampered := append([]byte(nil), envelope...)
+	tampered[len(tampered)-100] ^= 1
+
+	var otherKeyEncrypted bytes.Buffer
+	encryptErr = aesGCMEncrypt(&otherKeyEncrypted, bytes.NewReader([]byte("data")), key, "previous")
+	if encryptErr != nil {
+		t.Fatal(encryptErr)
+	}
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/file_decrypt", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	invalidFiles := map[string][]byte{
+		"/tmp/file_decrypt/tampered.bin":  tampered,
+		"/tmp/file_decrypt/truncated.bin": envelope[:aesGCMEnvelopeChunkSize+100],
+		"/tmp/file_decrypt/other_key.bin": otherKeyEncrypted.Bytes(),
+		"/tmp/file_decrypt/plain.bin":     []byte("not encrypted"),
+	}
+
+	var in []files.ProcessableFile
+	for name, content := range invalidFiles {
+		writeErr := afero.WriteFile(capyfs.Filesystem, name, content, 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+
+		in = append(in, files.NewProcessableFile(name))
+	}
+
+	operation := &FileDecryptOperation{
+		Name: "file_decrypt",
+		Params: &FileDecryptOperationParams{
+			Algorithm: EncryptionAlgorithmAES256GCM,
+			AESKey:    key,
+			AESKeyId:  "current",
+		},
+	}
+
+	errorCh := make(chan OperationError, len(in))
+	out, err := operation.Handle(in, errorCh, nil)
+	close(errorCh)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != len(in) {
+		t.Fatalf("len(out) = %d, want %d", len(out), len(in))
+	}
+	if len(errorCh) != len(in) {
+		t.Fatalf("len(errorCh) = %d, want %d", len(errorCh), len(in))
+	}
+
+	for _, pf := range out {
+		if !pf.HasFileProcessingError() || pf.FileProcessingError.Code() != ErrorCodeFileDecryptionFailure {
+			t.Fatalf("expected %s error for %s, got %v",
+				ErrorCodeFileDecryptionFailure, pf.OriginalFilename(), pf.FileProcessingError)
+		}
+		if pf.Name() != pf.OriginalFilename() {
+			t.Fatalf("expected %s not to be replaced", pf.OriginalFilename())
+		}
+	}
+}
+
+func TestFileDecryptOperation_HandleAgeWrongIdentity(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	identity, identityErr := age.GenerateX25519Identity()
+	if identityErr != nil {
+		t.Fatal(identityErr)
+	}
+	otherIdentity, otherIdentityErr := age.GenerateX25519Identity()
+	if otherIdentityErr != nil {
+		t.Fatal(otherIdentityErr)
+	}
+
+	encryptOperation := &FileEncryptOperation{
+		Name: "file_encrypt",
+		Params: &FileEncryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeRecipients: []age.Recipient{identity.Recipient()},
+		},
+	}
+
+	out, err := encryptOperation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	decryptOperation := &FileDecryptOperation{
+		Name: "file_decrypt",
+		Params: &FileDecryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeIdentities: []age.Identity{otherIdentity},
+		},
+	}
+
+	out, err = decryptOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != ErrorCodeFileDecryptionFailure {
+		t.Fatalf("expected %s error, got %v", ErrorCodeFileDecryptionFailure, out[0].FileProcessingError)
+	}
+}
@@ -0,0 +1,186 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"filippo.io/age"
+	"io"
+	"sync"
+)
+
+const MetadataKeyFileEncryptAlgorithm = "file_encrypt.algorithm"
+
+// MetadataKeyFileEncryptKeyIds The IDs of the keys the file can be decrypted with.
+const MetadataKeyFileEncryptKeyIds = "file_encrypt.key_ids"
+
+const (
+	EncryptionAlgorithmAge       = "age"
+	EncryptionAlgorithmAES256GCM = "aes-256-gcm"
+)
+
+// ageScryptKeyID The key ID of the passphrase, so it is not leaked to the metadata.
+const ageScryptKeyID = "scrypt"
+
+type FileEncryptOperation struct {
+	Name   string
+	Params *FileEncryptOperationParams
+}
+
+func (o *FileEncryptOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FileEncryptOperation) AllowConcurrency() bool {
+	return true
+}
+
+type FileEncryptOperationParams struct {
+	// Algorithm is the encryption algorithm. Possible values: "age", "aes-256-gcm".
+	Algorithm string
+
+	// AgeRecipients are either X25519 recipients, or the single scrypt (passphrase) recipient.
+	AgeRecipients []age.Recipient
+
+	// AESKey is 32 bytes long AES-256 key.
+	AESKey []byte
+	// AESKeyId is the ID the key is looked up by on decryption. It is stored in the envelope header.
+	AESKeyId string
+}
+
+// keyIds Returns the IDs of the keys the file is encrypted for.
+func (p *FileEncryptOperationParams) keyIds() []string {
+	if p.Algorithm == EncryptionAlgorithmAES256GCM {
+		return []string{p.AESKeyId}
+	}
+
+	keyIds := make([]string, 0, len(p.AgeRecipients))
+	for _, recipient := range p.AgeRecipients {
+		keyIds = append(keyIds, ageKeyId(recipient))
+	}
+
+	return keyIds
+}
+
+// ageKeyId Returns the ID of age recipient or identity. The X25519 keys are identified by
+// the public key, and the passphrase is never exposed.
+func ageKeyId(key any) string {
+	switch k := key.(type) {
+	case *age.X25519Recipient:
+		return k.String()
+	case *age.X25519Identity:
+		return k.Recipient().String()
+	}
+
+	return ageScryptKeyID
+}
+
+func (p *FileEncryptOperationParams) encrypt(dst io.Writer, src io.Reader) error {
+	if p.Algorithm == EncryptionAlgorithmAES256GCM {
+		return aesGCMEncrypt(dst, src, p.AESKey, p.AESKeyId)
+	}
+
+	w, encryptErr := age.Encrypt(dst, p.AgeRecipients...)
+	if encryptErr != nil {
+		return encryptErr
+	}
+
+	_, copyErr := io.Copy(w, src)
+	if copyErr != nil {
+		return copyErr
+	}
+
+	return w.Close()
+}
+
+func (o *FileEncryptOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file encryption has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			encryptedFile, encryptErr := capyutils.WriteToAppTmpDirectory(func(w io.Writer) error {
+				return o.Params.encrypt(w, file)
+			})
+			_ = file.Close()
+			if encryptErr != nil {
+				pf.SetFileProcessingError(
+					NewFileEncryptionFailureError(encryptErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, encryptErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be encrypted", pf, encryptErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.ReplaceFile(encryptedFile.Name())
+
+			pf.AddOperationMetadata(MetadataKeyFileEncryptAlgorithm, o.Params.Algorithm)
+			pf.AddOperationMetadata(MetadataKeyFileEncryptKeyIds, o.Params.keyIds())
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file encryption has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FileEncryptOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileEncryptOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
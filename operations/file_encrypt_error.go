@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileEncryptionFailure = "FILE_ENCRYPTION_FAILURE"
+
+func NewFileEncryptionFailureError(origErr error) *FileEncryptionFailureError {
+	return &FileEncryptionFailureError{
+		Data: &FileEncryptionFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileEncryptionFailureError struct {
+	files.FileProcessingError
+	Data *FileEncryptionFailureErrorData
+}
+
+type FileEncryptionFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileEncryptionFailureError) Code() string {
+	return ErrorCodeFileEncryptionFailure
+}
+
+func (e *FileEncryptionFailureError) Error() string {
+	return "file can not be encrypted"
+}
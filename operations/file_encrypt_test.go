@@ -0,0 +1,249 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/rand"
+	"filippo.io/age"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestFileEncryptOperation_HandleAgeX25519(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	fileContent, readErr := afero.ReadFile(capyfs.Filesystem, "testdata/file_5kb.bin")
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	identity, identityErr := age.GenerateX25519Identity()
+	if identityErr != nil {
+		t.Fatal(identityErr)
+	}
+	otherIdentity, otherIdentityErr := age.GenerateX25519Identity()
+	if otherIdentityErr != nil {
+		t.Fatal(otherIdentityErr)
+	}
+
+	encryptOperation := &FileEncryptOperation{
+		Name: "file_encrypt",
+		Params: &FileEncryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeRecipients: []age.Recipient{otherIdentity.Recipient(), identity.Recipient()},
+		},
+	}
+
+	out, err := encryptOperation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+	}
+
+	keyIds := out[0].OperationMetadata[MetadataKeyFileEncryptKeyIds].([]string)
+	if len(keyIds) != 2 || keyIds[1] != identity.Recipient().String() {
+		t.Fatalf("key ids = %v, want [%s %s]", keyIds, otherIdentity.Recipient(), identity.Recipient())
+	}
+
+	encryptedContent, readErr := afero.ReadFile(capyfs.Filesystem, out[0].Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if bytes.Contains(encryptedContent, fileContent[:64]) {
+		t.Fatalf("expected file content to be encrypted")
+	}
+
+	decryptOperation := &FileDecryptOperation{
+		Name: "file_decrypt",
+		Params: &FileDecryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeIdentities: []age.Identity{identity},
+		},
+	}
+
+	out, err = decryptOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+	}
+	if out[0].OperationMetadata[MetadataKeyFileDecryptKeyId] != identity.Recipient().String() {
+		t.Fatalf("key id = %v, want %s", out[0].OperationMetadata[MetadataKeyFileDecryptKeyId], identity.Recipient())
+	}
+
+	decryptedContent, readErr := afero.ReadFile(capyfs.Filesystem, out[0].Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !bytes.Equal(decryptedContent, fileContent) {
+		t.Fatalf("expected decrypted content to be equal to the original content")
+	}
+}
+
+func TestFileEncryptOperation_HandleAgePassphrase(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	recipient, recipientErr := age.NewScryptRecipient("correct horse battery staple")
+	if recipientErr != nil {
+		t.Fatal(recipientErr)
+	}
+	recipient.SetWorkFactor(10)
+
+	encryptOperation := &FileEncryptOperation{
+		Name: "file_encrypt",
+		Params: &FileEncryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeRecipients: []age.Recipient{recipient},
+		},
+	}
+
+	out, err := encryptOperation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+	}
+
+	// The passphrase must not leak to the metadata.
+	keyIds := out[0].OperationMetadata[MetadataKeyFileEncryptKeyIds].([]string)
+	if len(keyIds) != 1 || keyIds[0] != "scrypt" {
+		t.Fatalf("key ids = %v, want [scrypt]", keyIds)
+	}
+
+	identity, identityErr := age.NewScryptIdentity("correct horse battery staple")
+	if identityErr != nil {
+		t.Fatal(identityErr)
+	}
+
+	decryptOperation := &FileDecryptOperation{
+		Name: "file_decrypt",
+		Params: &FileDecryptOperationParams{
+			Algorithm:     EncryptionAlgorithmAge,
+			AgeIdentities: []age.Identity{identity},
+		},
+	}
+
+	out, err = decryptOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+	}
+	if out[0].OperationMetadata[MetadataKeyFileDecryptKeyId] != "scrypt" {
+		t.Fatalf("key id = %v, want scrypt", out[0].OperationMetadata[MetadataKeyFileDecryptKeyId])
+	}
+}
+
+func TestFileEncryptOperation_HandleAES256GCM(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	// The content spans several chunks, and the last chunk is full, to check the chunk boundaries.
+	fileContent := make([]byte, 3*aesGCMEnvelopeChunkSize)
+	_, randErr := rand.Read(fileContent)
+	if randErr != nil {
+		t.Fatal(randErr)
+	}
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/file_encrypt", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/file_encrypt/archive.tar", fileContent, 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	writeErr = afero.WriteFile(capyfs.Filesystem, "/tmp/file_encrypt/empty.txt", []byte{}, 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	key := make([]byte, 32)
+	_, randErr = rand.Read(key)
+	if randErr != nil {
+		t.Fatal(randErr)
+	}
+
+	encryptOperation := &FileEncryptOperation{
+		Name: "file_encrypt",
+		Params: &FileEncryptOperationParams{
+			Algorithm: EncryptionAlgorithmAES256GCM,
+			AESKey:    key,
+			AESKeyId:  "archive-2023-05",
+		},
+	}
+
+	out, err := encryptOperation.Handle(
+		[]files.ProcessableFile{
+			files.NewProcessableFile("/tmp/file_encrypt/archive.tar"),
+			files.NewProcessableFile("/tmp/file_encrypt/empty.txt"),
+		},
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	for _, pf := range out {
+		if pf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be nil, got %v", pf.FileProcessingError)
+		}
+
+		keyIds := pf.OperationMetadata[MetadataKeyFileEncryptKeyIds].([]string)
+		if len(keyIds) != 1 || keyIds[0] != "archive-2023-05" {
+			t.Fatalf("key ids = %v, want [archive-2023-05]", keyIds)
+		}
+	}
+
+	decryptOperation := &FileDecryptOperation{
+		Name: "file_decrypt",
+		Params: &FileDecryptOperationParams{
+			Algorithm: EncryptionAlgorithmAES256GCM,
+			AESKey:    key,
+			AESKeyId:  "archive-2023-05",
+		},
+	}
+
+	out, err = decryptOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	for _, pf := range out {
+		if pf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be nil, got %v", pf.FileProcessingError)
+		}
+		if pf.OperationMetadata[MetadataKeyFileDecryptKeyId] != "archive-2023-05" {
+			t.Fatalf("key id = %v, want archive-2023-05", pf.OperationMetadata[MetadataKeyFileDecryptKeyId])
+		}
+
+		expectedContent := fileContent
+		if pf.OriginalFilename() == "/tmp/file_encrypt/empty.txt" {
+			expectedContent = []byte{}
+		}
+
+		decryptedContent, readErr := afero.ReadFile(capyfs.Filesystem, pf.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		if !bytes.Equal(decryptedContent, expectedContent) {
+			t.Fatalf("expected decrypted content of %s to be equal to the original content", pf.OriginalFilename())
+		}
+	}
+}
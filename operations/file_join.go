@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"io"
+	"sort"
+	"strings"
+)
+
+const MetadataKeyFileJoinChunkCount = "file_join.chunk_count"
+
+type FileJoinOperation struct {
+	Name   string
+	Params *FileJoinOperationParams
+}
+
+type FileJoinOperationParams struct {
+}
+
+// chunkGroup The chunks of the same file.
+type chunkGroup struct {
+	groupID string
+	chunks  []*files.ProcessableFile
+}
+
+func (o *FileJoinOperation) OperationName() string {
+	return o.Name
+}
+
+// AllowConcurrency The chunks of the same file are joined together, so the operation needs all the files at once.
+func (o *FileJoinOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *FileJoinOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// The groups are kept in the order their first chunks are received.
+	var groups []*chunkGroup
+	groupsByID := make(map[string]*chunkGroup)
+
+	for i := range in {
+		pf := &in[i]
+
+		groupID, isChunk := pf.OperationMetadata[MetadataKeyFileSplitGroupID].(string)
+		if !isChunk {
+			out = append(out, *pf)
+
+			continue
+		}
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Started("file chunk join has started", pf)
+		}
+
+		group, ok := groupsByID[groupID]
+		if !ok {
+			group = &chunkGroup{groupID: groupID}
+			groupsByID[groupID] = group
+			groups = append(groups, group)
+		}
+
+		group.chunks = append(group.chunks, pf)
+	}
+
+	for _, group := range groups {
+		out = append(out, o.join(group, errorCh, notificationCh)...)
+	}
+
+	return out, nil
+}
+
+// join Joins the chunks of the group. If the chunks can not be joined, they are returned with the error attached.
+func (o *FileJoinOperation) join(
+	group *chunkGroup,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) []files.ProcessableFile {
+	sort.SliceStable(group.chunks, func(i, j int) bool {
+		return chunkIndex(group.chunks[i]) < chunkIndex(group.chunks[j])
+	})
+
+	expectedCount, _ := group.chunks[0].OperationMetadata[MetadataKeyFileSplitCount].(int64)
+	complete := int64(len(group.chunks)) == expectedCount
+	// The failed chunks are received if the operation targets the files with errors too.
+	// They can not be joined either, so only the chunks without errors are counted.
+	var givenCount int64 = 0
+	for i, chunk := range group.chunks {
+		if chunkIndex(chunk) != int64(i+1) || chunk.HasFileProcessingError() {
+			complete = false
+		}
+		if !chunk.HasFileProcessingError() {
+			givenCount++
+		}
+	}
+
+	if !complete {
+		chunksAreIncompleteErr := NewFileChunksAreIncompleteError(expectedCount, givenCount)
+
+		chunks := make([]files.ProcessableFile, 0, len(group.chunks))
+		for _, chunk := range group.chunks {
+			// The failed chunks keep their own errors.
+			if !chunk.HasFileProcessingError() {
+				chunk.SetFileProcessingError(chunksAreIncompleteErr)
+			}
+
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(chunk, chunksAreIncompleteErr)
+			}
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed("file chunks are incomplete", chunk, chunksAreIncompleteErr)
+			}
+
+			chunks = append(chunks, *chunk)
+		}
+
+		return chunks
+	}
+
+	joinedFile, joinErr := capyutils.WriteToAppTmpDirectory(func(w io.Writer) error {
+		for _, chunk := range group.chunks {
+			chunkFile, chunkOpenErr := capyfs.Filesystem.Open(chunk.Name())
+			if chunkOpenErr != nil {
+				return chunkOpenErr
+			}
+
+			_, copyErr := io.Copy(w, chunkFile)
+			_ = chunkFile.Close()
+			if copyErr != nil {
+				return copyErr
+			}
+		}
+
+		return nil
+	})
+	if joinErr != nil {
+		chunks := make([]files.ProcessableFile, 0, len(group.chunks))
+		for _, chunk := range group.chunks {
+			chunk.SetFileProcessingError(
+				NewFileJoinFailureError(joinErr),
+			)
+
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(chunk, joinErr)
+			}
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed("file chunks can not be joined", chunk, joinErr)
+			}
+
+			chunks = append(chunks, *chunk)
+		}
+
+		return chunks
+	}
+
+	// The joined file is the file the chunks have been split from.
+	firstChunk := group.chunks[0]
+	pf := files.NewProcessableFile(joinedFile.Name())
+	pf.NanoID = group.groupID
+	pf.Metadata.OriginalFilename = firstChunk.OriginalFilename()
+	pf.OriginalProcessableFile = firstChunk.OriginalProcessableFile
+	for k, v := range firstChunk.OperationMetadata {
+		if !strings.HasPrefix(k, "file_split.") {
+			pf.AddOperationMetadata(k, v)
+		}
+	}
+	pf.AddOperationMetadata(MetadataKeyFileJoinChunkCount, int64(len(group.chunks)))
+
+	for _, chunk := range group.chunks {
+		_ = chunk.FreeResources()
+	}
+
+	if notificationCh != nil {
+		notificationCh <- o.notificationBuilder().Finished("file chunks have been joined", &pf)
+	}
+
+	return []files.ProcessableFile{pf}
+}
+
+func chunkIndex(pf *files.ProcessableFile) int64 {
+	index, _ := pf.OperationMetadata[MetadataKeyFileSplitIndex].(int64)
+
+	return index
+}
+
+func (o *FileJoinOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileJoinOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
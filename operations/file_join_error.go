@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileJoinFailure = "FILE_JOIN_FAILURE"
+
+func NewFileJoinFailureError(origErr error) *FileJoinFailureError {
+	return &FileJoinFailureError{
+		Data: &FileJoinFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileJoinFailureError struct {
+	files.FileProcessingError
+	Data *FileJoinFailureErrorData
+}
+
+type FileJoinFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileJoinFailureError) Code() string {
+	return ErrorCodeFileJoinFailure
+}
+
+func (e *FileJoinFailureError) Error() string {
+	return "file chunks can not be joined"
+}
+
+const ErrorCodeFileChunksAreIncomplete = "FILE_CHUNKS_ARE_INCOMPLETE"
+
+func NewFileChunksAreIncompleteError(expected, given int64) *FileChunksAreIncompleteError {
+	return &FileChunksAreIncompleteError{
+		Data: &FileChunksAreIncompleteErrorData{
+			Expected: expected,
+			Given:    given,
+		},
+	}
+}
+
+type FileChunksAreIncompleteError struct {
+	files.FileProcessingError
+
+	Data *FileChunksAreIncompleteErrorData
+}
+
+type FileChunksAreIncompleteErrorData struct {
+	Expected int64
+	Given    int64
+}
+
+func (e *FileChunksAreIncompleteError) Code() string {
+	return ErrorCodeFileChunksAreIncomplete
+}
+
+func (e *FileChunksAreIncompleteError) Error() string {
+	return "file chunks are incomplete"
+}
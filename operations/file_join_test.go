@@ -0,0 +1,193 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestFileJoinOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_5kb.bin"),
+		files.NewProcessableFile("testdata/file_2kb.bin"),
+	}
+
+	splitOperation := &FileSplitOperation{
+		Name:   "file_split",
+		Params: &FileSplitOperationParams{ChunkSize: 1000},
+	}
+	chunks, err := splitOperation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) != 9 {
+		t.Fatalf("len(chunks) = %d, want 9", len(chunks))
+	}
+
+	// The chunks are joined regardless of the order they are received in.
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	notChunk := files.NewProcessableFile("testdata/file_1kb.bin")
+	chunks = append(chunks, notChunk)
+
+	joinOperation := &FileJoinOperation{
+		Name:   "file_join",
+		Params: &FileJoinOperationParams{},
+	}
+	out, err := joinOperation.Handle(chunks, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 3 {
+		t.Fatalf("len(out) = %d, want 3", len(out))
+	}
+
+	if out[0].NanoID != notChunk.NanoID {
+		t.Fatalf("out[0].NanoID = %s, want %s", out[0].NanoID, notChunk.NanoID)
+	}
+
+	for _, pf := range out[1:] {
+		if pf.FileProcessingError != nil {
+			t.Fatalf(
+				"FileProcessingError.Code() = %s, want nil",
+				pf.FileProcessingError.Code(),
+			)
+		}
+
+		var source *files.ProcessableFile
+		for i := range in {
+			if in[i].NanoID == pf.NanoID {
+				source = &in[i]
+			}
+		}
+		if source == nil {
+			t.Fatalf("joined file %s does not match any of the source files", pf.NanoID)
+		}
+
+		if pf.OriginalFilename() != source.OriginalFilename() {
+			t.Fatalf("OriginalFilename() = %s, want %s", pf.OriginalFilename(), source.OriginalFilename())
+		}
+
+		joined, readErr := afero.ReadFile(capyfs.Filesystem, pf.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		original, readErr := afero.ReadFile(capyfs.Filesystem, source.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		if !bytes.Equal(joined, original) {
+			t.Fatalf("joined content of %s does not match the original one", source.Name())
+		}
+	}
+}
+
+func TestFileJoinOperation_HandleIncompleteChunks(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	splitOperation := &FileSplitOperation{
+		Name:   "file_split",
+		Params: &FileSplitOperationParams{ChunkSize: 1000},
+	}
+	chunks, err := splitOperation.Handle([]files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_2kb.bin"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	joinOperation := &FileJoinOperation{
+		Name:   "file_join",
+		Params: &FileJoinOperationParams{},
+	}
+	errorCh := make(chan OperationError, 10)
+	out, err := joinOperation.Handle(chunks[1:], errorCh, nil)
+	close(errorCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	if len(errorCh) != 2 {
+		t.Fatalf("len(errorCh) = %d, want 2", len(errorCh))
+	}
+
+	for _, pf := range out {
+		if pf.FileProcessingError == nil {
+			t.Fatalf("FileProcessingError = nil, want %s", ErrorCodeFileChunksAreIncomplete)
+		}
+		if pf.FileProcessingError.Code() != ErrorCodeFileChunksAreIncomplete {
+			t.Fatalf(
+				"FileProcessingError.Code() = %s, want %s",
+				pf.FileProcessingError.Code(),
+				ErrorCodeFileChunksAreIncomplete,
+			)
+		}
+	}
+}
+
+func TestFileJoinOperation_HandleFailedChunk(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	splitOperation := &FileSplitOperation{
+		Name:   "file_split",
+		Params: &FileSplitOperationParams{ChunkSize: 1000},
+	}
+	chunks, err := splitOperation.Handle([]files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_2kb.bin"),
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The failed chunk is received if the operation targets the files with errors too.
+	chunks[0].SetFileProcessingError(NewFileJoinFailureError(nil))
+
+	joinOperation := &FileJoinOperation{
+		Name:   "file_join",
+		Params: &FileJoinOperationParams{},
+	}
+	errorCh := make(chan OperationError, 10)
+	out, err := joinOperation.Handle(chunks, errorCh, nil)
+	close(errorCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 3 {
+		t.Fatalf("len(out) = %d, want 3", len(out))
+	}
+
+	if len(errorCh) != 3 {
+		t.Fatalf("len(errorCh) = %d, want 3", len(errorCh))
+	}
+
+	expectedCodes := []string{
+		ErrorCodeFileJoinFailure,
+		ErrorCodeFileChunksAreIncomplete,
+		ErrorCodeFileChunksAreIncomplete,
+	}
+	for i, pf := range out {
+		if pf.FileProcessingError == nil {
+			t.Fatalf("out[%d].FileProcessingError = nil, want %s", i, expectedCodes[i])
+		}
+		if pf.FileProcessingError.Code() != expectedCodes[i] {
+			t.Fatalf(
+				"out[%d].FileProcessingError.Code() = %s, want %s",
+				i,
+				pf.FileProcessingError.Code(),
+				expectedCodes[i],
+			)
+		}
+	}
+}
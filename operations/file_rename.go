@@ -0,0 +1,169 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const MetadataKeyFileRenameFilename = "file_rename.filename"
+
+type FileRenameOperation struct {
+	Name   string
+	Params *FileRenameOperationParams
+
+	// counter The number of the files renamed by the operation so far.
+	counter int64
+}
+
+type FileRenameOperationParams struct {
+	// Template is the new filename template. Along with the vars described in objectTemplateData,
+	// the following vars are available:
+	// - {{.Date}} - current UTC date. For example: 2023-06-01
+	// - {{.Now}} - current UTC time, so it can be formatted. For example: {{.Now.Format "20060102150405"}}
+	// - {{.Checksum}} - SHA-256 checksum of the file content
+	// - {{.Counter}} - number of the file renamed by the operation, starting from 1
+	// For example: {{.OriginalBasename}}-{{.Date}}{{.Ext}}
+	Template string
+	// TemplateVars are the additional vars available in the template.
+	TemplateVars map[string]string
+}
+
+// renderFilename Renders the new filename of the file.
+func (p *FileRenameOperationParams) renderFilename(
+	pf *files.ProcessableFile,
+	counter int64,
+	now time.Time,
+) (string, error) {
+	data := objectTemplateData(pf, p.TemplateVars)
+	data["Date"] = now.Format("2006-01-02")
+	data["Now"] = now
+	data["Counter"] = counter
+
+	// Calculating the checksum requires reading the whole file, so it is done only if it is needed.
+	if strings.Contains(p.Template, ".Checksum") {
+		checksum, checksumErr := fileChecksum(pf)
+		if checksumErr != nil {
+			return "", checksumErr
+		}
+
+		data["Checksum"] = checksum
+	}
+
+	filename, renderErr := renderObjectTemplate(p.Template, data)
+	if renderErr != nil {
+		return "", renderErr
+	}
+
+	filename = strings.TrimSpace(filename)
+	if filename == "" || filename == "." || filename == ".." {
+		return "", errors.New("filename \"" + filename + "\" is not valid")
+	}
+	// The filename is used as is by the operations writing the files, so it can not escape the destination.
+	if strings.ContainsAny(filename, "/\\\x00") {
+		return "", errors.New("filename \"" + filename + "\" must not contain path separators")
+	}
+
+	return filename, nil
+}
+
+func fileChecksum(pf *files.ProcessableFile) (string, error) {
+	file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+	if fileOpenErr != nil {
+		return "", fileOpenErr
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, copyErr := io.Copy(hash, file); copyErr != nil {
+		return "", copyErr
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (o *FileRenameOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FileRenameOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *FileRenameOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	now := time.Now().UTC()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+		// The counter is taken here, so the files are numbered in the order they are received.
+		counter := atomic.AddInt64(&o.counter, 1)
+
+		go func(pf *files.ProcessableFile, counter int64) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file rename has started", pf)
+			}
+
+			filename, renderErr := o.Params.renderFilename(pf, counter, now)
+			if renderErr != nil {
+				pf.SetFileProcessingError(
+					NewFileRenameFailureError(renderErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, renderErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file can not be renamed", pf, renderErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.Rename(filename)
+			pf.AddOperationMetadata(MetadataKeyFileRenameFilename, filename)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file rename has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf, counter)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FileRenameOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileRenameOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileRenameFailure = "FILE_RENAME_FAILURE"
+
+func NewFileRenameFailureError(origErr error) *FileRenameFailureError {
+	return &FileRenameFailureError{
+		Data: &FileRenameFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileRenameFailureError struct {
+	files.FileProcessingError
+	Data *FileRenameFailureErrorData
+}
+
+type FileRenameFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileRenameFailureError) Code() string {
+	return ErrorCodeFileRenameFailure
+}
+
+func (e *FileRenameFailureError) Error() string {
+	return "file can not be renamed"
+}
@@ -0,0 +1,132 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"regexp"
+	"testing"
+)
+
+func TestFileRenameOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	testCases := []struct {
+		name              string
+		params            *FileRenameOperationParams
+		expectedFilenames []string
+		expectedErrorCode string
+	}{
+		{
+			name: "original name with the counter",
+			params: &FileRenameOperationParams{
+				Template: `{{.OriginalBasename}}-{{printf "%03d" .Counter}}{{.Ext}}`,
+			},
+			expectedFilenames: []string{
+				`^image_512x512-001\.png$`,
+				`^image_512x512-002\.jpg$`,
+			},
+		},
+		{
+			name: "date and template vars",
+			params: &FileRenameOperationParams{
+				Template:     "{{.Prefix}}_{{.Date}}_{{.OriginalFilename}}",
+				TemplateVars: map[string]string{"Prefix": "avatar"},
+			},
+			expectedFilenames: []string{
+				`^avatar_\d{4}-\d{2}-\d{2}_image_512x512\.png$`,
+				`^avatar_\d{4}-\d{2}-\d{2}_image_512x512\.jpg$`,
+			},
+		},
+		{
+			name: "checksum",
+			params: &FileRenameOperationParams{
+				Template: "{{.Checksum}}{{.Ext}}",
+			},
+			expectedFilenames: []string{
+				`^[0-9a-f]{64}\.png$`,
+				`^[0-9a-f]{64}\.jpg$`,
+			},
+		},
+		{
+			name: "path separator",
+			params: &FileRenameOperationParams{
+				Template: "../{{.OriginalFilename}}",
+			},
+			expectedErrorCode: ErrorCodeFileRenameFailure,
+		},
+		{
+			name: "unknown var",
+			params: &FileRenameOperationParams{
+				Template: "{{.Unknown}}",
+			},
+			expectedErrorCode: ErrorCodeFileRenameFailure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := []files.ProcessableFile{
+				files.NewProcessableFile("testdata/image_512x512.png"),
+				files.NewProcessableFile("testdata/image_512x512.jpg"),
+			}
+
+			operation := &FileRenameOperation{
+				Name:   "file_rename",
+				Params: tc.params,
+			}
+			// The files are handled one by one, so the counter is checked against the input order.
+			var out []files.ProcessableFile
+			for i := range in {
+				fileOut, err := operation.Handle(in[i:i+1], nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				out = append(out, fileOut...)
+			}
+
+			if len(out) != 2 {
+				t.Fatalf("len(out) = %d, want 2", len(out))
+			}
+
+			for i := range out {
+				if tc.expectedErrorCode != "" {
+					if out[i].FileProcessingError == nil {
+						t.Fatalf("FileProcessingError = nil, want %s", tc.expectedErrorCode)
+					}
+					if out[i].FileProcessingError.Code() != tc.expectedErrorCode {
+						t.Fatalf(
+							"FileProcessingError.Code() = %s, want %s",
+							out[i].FileProcessingError.Code(),
+							tc.expectedErrorCode,
+						)
+					}
+					if out[i].Metadata.Filename != "" {
+						t.Fatalf("Metadata.Filename = %s, want empty", out[i].Metadata.Filename)
+					}
+
+					continue
+				}
+
+				if out[i].FileProcessingError != nil {
+					t.Fatalf(
+						"FileProcessingError.Code() = %s, want nil",
+						out[i].FileProcessingError.Code(),
+					)
+				}
+
+				if !regexp.MustCompile(tc.expectedFilenames[i]).MatchString(out[i].LogicalFilename()) {
+					t.Fatalf("LogicalFilename() = %s, want %s", out[i].LogicalFilename(), tc.expectedFilenames[i])
+				}
+				if out[i].OperationMetadata[MetadataKeyFileRenameFilename] != out[i].LogicalFilename() {
+					t.Fatalf(
+						"OperationMetadata[%s] = %v, want %s",
+						MetadataKeyFileRenameFilename,
+						out[i].OperationMetadata[MetadataKeyFileRenameFilename],
+						out[i].LogicalFilename(),
+					)
+				}
+			}
+		})
+	}
+}
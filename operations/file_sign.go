@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"aead.dev/minisign"
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MetadataKeyFileSignKeyId = "file_sign.key_id"
+
+// MetadataKeyFileSignSignatureFile The NanoID of the signature file. It is set on the signed file.
+const MetadataKeyFileSignSignatureFile = "file_sign.signature_file"
+
+// MetadataKeyFileSignSignedFile The NanoID of the signed file. It is set on the signature file.
+const MetadataKeyFileSignSignedFile = "file_sign.signed_file"
+
+const (
+	SignatureFormatMinisign = "minisign"
+	SignatureFormatOpenPGP  = "openpgp"
+)
+
+// DefaultSignatureExtension Returns the extension the signature files of the given format have.
+func DefaultSignatureExtension(format string) string {
+	if format == SignatureFormatOpenPGP {
+		return ".asc"
+	}
+
+	return ".minisig"
+}
+
+// minisignKeyId Formats the key ID the same way minisign does.
+func minisignKeyId(id uint64) string {
+	return fmt.Sprintf("%016X", id)
+}
+
+// openPGPKeyId Formats the key ID as the primary key fingerprint.
+func openPGPKeyId(entity *openpgp.Entity) string {
+	return strings.ToUpper(fmt.Sprintf("%x", entity.PrimaryKey.Fingerprint))
+}
+
+type FileSignOperation struct {
+	Name   string
+	Params *FileSignOperationParams
+}
+
+func (o *FileSignOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FileSignOperation) AllowConcurrency() bool {
+	return true
+}
+
+type FileSignOperationParams struct {
+	// Format is the signature format. Possible values: "minisign", "openpgp".
+	Format string
+
+	MinisignPrivateKey minisign.PrivateKey
+	// OpenPGPSigner is the entity with the decrypted private key.
+	OpenPGPSigner *openpgp.Entity
+}
+
+// sign Creates the detached signature of the file.
+func (p *FileSignOperationParams) sign(pf *files.ProcessableFile) (signature []byte, keyId string, err error) {
+	file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+	if fileOpenErr != nil {
+		return nil, "", fileOpenErr
+	}
+	defer file.Close()
+
+	if p.Format == SignatureFormatOpenPGP {
+		var buf bytes.Buffer
+		signErr := openpgp.ArmoredDetachSign(&buf, p.OpenPGPSigner, file, nil)
+		if signErr != nil {
+			return nil, "", signErr
+		}
+
+		return buf.Bytes(), openPGPKeyId(p.OpenPGPSigner), nil
+	}
+
+	reader := minisign.NewReader(file)
+	_, copyErr := io.Copy(io.Discard, reader)
+	if copyErr != nil {
+		return nil, "", copyErr
+	}
+
+	keyId = minisignKeyId(p.MinisignPrivateKey.ID())
+
+	// The comments are the same as the ones minisign adds.
+	return reader.SignWithComments(
+		p.MinisignPrivateKey,
+		fmt.Sprintf("timestamp:%d\tfile:%s", time.Now().Unix(), filepath.Base(pf.OriginalFilename())),
+		"signature from minisign secret key "+keyId,
+	), keyId, nil
+}
+
+func (o *FileSignOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file signing has started", pf)
+			}
+
+			signature, keyId, signErr := o.Params.sign(pf)
+			if signErr != nil {
+				pf.SetFileProcessingError(
+					NewFileSigningFailureError(signErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, signErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file can not be signed", pf, signErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			signatureFile, writeErr := capyutils.WriteBytesToAppTmpDirectory(signature)
+			if writeErr != nil {
+				pf.SetFileProcessingError(
+					NewFileIsUnwritableError(writeErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, writeErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("can not write the signature file", pf, writeErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			// The signature becomes the file on its own, so it goes through the rest of
+			// the operations, and it is uploaded along with the signed file.
+			signaturePf := files.NewProcessableFile(signatureFile.Name())
+			signaturePf.Metadata.OriginalFilename =
+				filepath.Base(pf.OriginalFilename()) + DefaultSignatureExtension(o.Params.Format)
+			// The signature is stored next to the signed file by default, for example: V1StGXR8_Z5jdHi6B-myT.pdf.minisig
+			signaturePf.Rename(pf.LogicalFilename() + DefaultSignatureExtension(o.Params.Format))
+			signaturePf.AddOperationMetadata(MetadataKeyFileSignKeyId, keyId)
+			signaturePf.AddOperationMetadata(MetadataKeyFileSignSignedFile, pf.NanoID)
+
+			pf.AddOperationMetadata(MetadataKeyFileSignKeyId, keyId)
+			pf.AddOperationMetadata(MetadataKeyFileSignSignatureFile, signaturePf.NanoID)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file signing has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+			outHolder.AppendToOut(&signaturePf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FileSignOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileSignOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
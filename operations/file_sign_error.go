@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileSigningFailure = "FILE_SIGNING_FAILURE"
+
+func NewFileSigningFailureError(origErr error) *FileSigningFailureError {
+	return &FileSigningFailureError{
+		Data: &FileSigningFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileSigningFailureError struct {
+	files.FileProcessingError
+	Data *FileSigningFailureErrorData
+}
+
+type FileSigningFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileSigningFailureError) Code() string {
+	return ErrorCodeFileSigningFailure
+}
+
+func (e *FileSigningFailureError) Error() string {
+	return "file can not be signed"
+}
@@ -0,0 +1,164 @@
+package operations
+
+import (
+	"aead.dev/minisign"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/rand"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/afero"
+	"strings"
+	"testing"
+)
+
+func TestFileSignOperation_HandleMinisign(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	publicKey, privateKey, keyErr := minisign.GenerateKey(rand.Reader)
+	if keyErr != nil {
+		t.Fatal(keyErr)
+	}
+
+	pf := files.NewProcessableFile("testdata/file_5kb.bin")
+
+	signOperation := &FileSignOperation{
+		Name: "file_sign",
+		Params: &FileSignOperationParams{
+			Format:             SignatureFormatMinisign,
+			MinisignPrivateKey: privateKey,
+		},
+	}
+
+	out, err := signOperation.Handle([]files.ProcessableFile{pf}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	signedPf, signaturePf := out[0], out[1]
+	if signedPf.NanoID != pf.NanoID {
+		signedPf, signaturePf = signaturePf, signedPf
+	}
+
+	if signaturePf.OriginalFilename() != "file_5kb.bin.minisig" {
+		t.Fatalf("signature OriginalFilename() = %s, want file_5kb.bin.minisig", signaturePf.OriginalFilename())
+	}
+
+	signedKey, signedKeyErr := renderObjectKey(&signedPf, "", objectTemplateData(&signedPf, nil))
+	if signedKeyErr != nil {
+		t.Fatal(signedKeyErr)
+	}
+	signatureKey, signatureKeyErr := renderObjectKey(&signaturePf, "", objectTemplateData(&signaturePf, nil))
+	if signatureKeyErr != nil {
+		t.Fatal(signatureKeyErr)
+	}
+	if signatureKey != signedKey+".minisig" {
+		t.Fatalf("signature upload key = %s, want %s.minisig", signatureKey, signedKey)
+	}
+	if signaturePf.OperationMetadata[MetadataKeyFileSignSignedFile] != pf.NanoID {
+		t.Fatalf("expected signature file to be linked to the signed file")
+	}
+	if signedPf.OperationMetadata[MetadataKeyFileSignSignatureFile] != signaturePf.NanoID {
+		t.Fatalf("expected signed file to be linked to the signature file")
+	}
+	if signedPf.OperationMetadata[MetadataKeyFileSignKeyId] != minisignKeyId(publicKey.ID()) {
+		t.Fatalf("key id = %v, want %s", signedPf.OperationMetadata[MetadataKeyFileSignKeyId], minisignKeyId(publicKey.ID()))
+	}
+
+	signature, readErr := afero.ReadFile(capyfs.Filesystem, signaturePf.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !strings.Contains(string(signature), "file:file_5kb.bin") {
+		t.Fatalf("expected trusted comment to contain the filename, got %s", signature)
+	}
+
+	verifyOperation := &FileVerifySignatureOperation{
+		Name: "file_verify_signature",
+		Params: &FileVerifySignatureOperationParams{
+			Format:             SignatureFormatMinisign,
+			MinisignPublicKeys: []minisign.PublicKey{publicKey},
+			SignatureExtension: ".minisig",
+		},
+	}
+
+	out, err = verifyOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	for _, outPf := range out {
+		if outPf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be nil, got %v", outPf.FileProcessingError)
+		}
+		if outPf.NanoID == pf.NanoID &&
+			outPf.OperationMetadata[MetadataKeyFileVerifySignatureKeyId] != minisignKeyId(publicKey.ID()) {
+			t.Fatalf("expected the file to be verified with %s key", minisignKeyId(publicKey.ID()))
+		}
+	}
+}
+
+func TestFileSignOperation_HandleOpenPGP(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	entity, entityErr := openpgp.NewEntity("Compliance", "", "compliance@example.com", nil)
+	if entityErr != nil {
+		t.Fatal(entityErr)
+	}
+
+	signOperation := &FileSignOperation{
+		Name: "file_sign",
+		Params: &FileSignOperationParams{
+			Format:        SignatureFormatOpenPGP,
+			OpenPGPSigner: entity,
+		},
+	}
+
+	out, err := signOperation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	for _, pf := range out {
+		if pf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be nil, got %v", pf.FileProcessingError)
+		}
+		if _, ok := pf.OperationMetadata[MetadataKeyFileSignSignedFile]; ok && pf.OriginalFilename() != "file_5kb.bin.asc" {
+			t.Fatalf("signature OriginalFilename() = %s, want file_5kb.bin.asc", pf.OriginalFilename())
+		}
+	}
+
+	verifyOperation := &FileVerifySignatureOperation{
+		Name: "file_verify_signature",
+		Params: &FileVerifySignatureOperationParams{
+			Format:             SignatureFormatOpenPGP,
+			OpenPGPKeyRing:     openpgp.EntityList{entity},
+			SignatureExtension: ".asc",
+		},
+	}
+
+	out, err = verifyOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	for _, pf := range out {
+		if pf.HasFileProcessingError() {
+			t.Fatalf("expected file processing error to be nil, got %v", pf.FileProcessingError)
+		}
+		if _, ok := pf.OperationMetadata[MetadataKeyFileSignSignatureFile]; ok &&
+			pf.OperationMetadata[MetadataKeyFileVerifySignatureKeyId] != openPGPKeyId(entity) {
+			t.Fatalf("expected the file to be verified with %s key", openPGPKeyId(entity))
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package operations
+
+import (
+	"bufio"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"io"
+	"sync"
+)
+
+const (
+	MetadataKeyFileSplitGroupID = "file_split.group_id"
+	MetadataKeyFileSplitIndex   = "file_split.index"
+	MetadataKeyFileSplitCount   = "file_split.count"
+)
+
+type FileSplitOperation struct {
+	Name   string
+	Params *FileSplitOperationParams
+}
+
+type FileSplitOperationParams struct {
+	// ChunkSize is the max size of the chunk in bytes.
+	ChunkSize int64
+	// PreserveLines Whether to end the chunks split by size at the line boundaries. The lines
+	// that are longer than the chunk size are split anyway.
+	PreserveLines bool
+	// LineCount is the number of lines in the chunk. Can not be used along with ChunkSize.
+	LineCount int64
+}
+
+// fileSplitter Reads the file chunk by chunk.
+type fileSplitter struct {
+	params *FileSplitOperationParams
+	reader *bufio.Reader
+
+	// line is the line, or the beginning of it, that has been read but not written yet.
+	line []byte
+	eof  bool
+}
+
+func newFileSplitter(r io.Reader, params *FileSplitOperationParams) *fileSplitter {
+	return &fileSplitter{
+		params: params,
+		reader: bufio.NewReader(r),
+	}
+}
+
+// writeChunk Writes the next chunk. Returns io.EOF if there is nothing left to write.
+func (s *fileSplitter) writeChunk(w io.Writer) error {
+	if s.params.LineCount > 0 {
+		return s.writeLinesChunk(w)
+	}
+	if s.params.PreserveLines {
+		return s.writeSizeChunkPreservingLines(w)
+	}
+
+	n, copyErr := io.CopyN(w, s.reader, s.params.ChunkSize)
+	if copyErr == io.EOF && n > 0 {
+		return nil
+	}
+
+	return copyErr
+}
+
+func (s *fileSplitter) writeLinesChunk(w io.Writer) error {
+	var lines, written int64
+	for lines < s.params.LineCount {
+		fragment, readErr := s.reader.ReadSlice('\n')
+		if len(fragment) > 0 {
+			if _, writeErr := w.Write(fragment); writeErr != nil {
+				return writeErr
+			}
+
+			written += int64(len(fragment))
+			if fragment[len(fragment)-1] == '\n' {
+				lines++
+			}
+		}
+
+		if readErr == io.EOF {
+			if written == 0 {
+				return io.EOF
+			}
+
+			return nil
+		}
+		if readErr != nil && readErr != bufio.ErrBufferFull {
+			return readErr
+		}
+	}
+
+	return nil
+}
+
+func (s *fileSplitter) writeSizeChunkPreservingLines(w io.Writer) error {
+	var written int64
+	for {
+		remaining := s.params.ChunkSize - written
+
+		complete, readErr := s.readLine(remaining)
+		if readErr != nil {
+			return readErr
+		}
+
+		if len(s.line) == 0 {
+			if written == 0 {
+				return io.EOF
+			}
+
+			return nil
+		}
+
+		if complete && int64(len(s.line)) <= remaining {
+			if _, writeErr := w.Write(s.line); writeErr != nil {
+				return writeErr
+			}
+
+			written += int64(len(s.line))
+			s.line = s.line[:0]
+
+			continue
+		}
+
+		// The line goes to the next chunk.
+		if written > 0 {
+			return nil
+		}
+
+		// The line is longer than the chunk, so it is split.
+		if _, writeErr := w.Write(s.line[:s.params.ChunkSize]); writeErr != nil {
+			return writeErr
+		}
+		s.line = append(s.line[:0], s.line[s.params.ChunkSize:]...)
+
+		return nil
+	}
+}
+
+// readLine Reads the current line until its end, or until it is longer than the limit.
+func (s *fileSplitter) readLine(limit int64) (complete bool, err error) {
+	for int64(len(s.line)) <= limit {
+		if s.eof || (len(s.line) > 0 && s.line[len(s.line)-1] == '\n') {
+			return true, nil
+		}
+
+		fragment, readErr := s.reader.ReadSlice('\n')
+		s.line = append(s.line, fragment...)
+
+		if readErr == io.EOF {
+			s.eof = true
+
+			continue
+		}
+		if readErr != nil && readErr != bufio.ErrBufferFull {
+			return false, readErr
+		}
+	}
+
+	return false, nil
+}
+
+// split Splits the file into the chunk files.
+func (p *FileSplitOperationParams) split(pf *files.ProcessableFile) ([]afero.File, error) {
+	file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+	if fileOpenErr != nil {
+		return nil, fileOpenErr
+	}
+	defer file.Close()
+
+	splitter := newFileSplitter(file, p)
+
+	var chunkFiles []afero.File
+	for {
+		chunkFile, chunkErr := capyutils.WriteToAppTmpDirectory(splitter.writeChunk)
+		if chunkErr == io.EOF {
+			break
+		}
+		if chunkErr != nil {
+			for _, f := range chunkFiles {
+				_ = capyfs.Filesystem.Remove(f.Name())
+			}
+
+			return nil, chunkErr
+		}
+
+		chunkFiles = append(chunkFiles, chunkFile)
+	}
+
+	// The empty file is the single empty chunk, so it is not lost.
+	if len(chunkFiles) == 0 {
+		chunkFile, chunkErr := capyutils.WriteBytesToAppTmpDirectory(nil)
+		if chunkErr != nil {
+			return nil, chunkErr
+		}
+
+		chunkFiles = append(chunkFiles, chunkFile)
+	}
+
+	return chunkFiles, nil
+}
+
+func (o *FileSplitOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FileSplitOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *FileSplitOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file split has started", pf)
+			}
+
+			chunkFiles, splitErr := o.Params.split(pf)
+			if splitErr != nil {
+				pf.SetFileProcessingError(
+					NewFileSplitFailureError(splitErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, splitErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file can not be split", pf, splitErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			for idx, chunkFile := range chunkFiles {
+				chunkPf := files.NewProcessableFile(chunkFile.Name())
+				// The chunks are the parts of the same file, so they inherit everything the file has.
+				chunkPf.Metadata.OriginalFilename = pf.OriginalFilename()
+				chunkPf.OriginalProcessableFile = pf.OriginalProcessableFile
+				for k, v := range pf.OperationMetadata {
+					chunkPf.AddOperationMetadata(k, v)
+				}
+				chunkPf.AddOperationMetadata(MetadataKeyFileSplitGroupID, pf.NanoID)
+				chunkPf.AddOperationMetadata(MetadataKeyFileSplitIndex, int64(idx+1))
+				chunkPf.AddOperationMetadata(MetadataKeyFileSplitCount, int64(len(chunkFiles)))
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("file chunk has been created", &chunkPf)
+				}
+
+				outHolder.AppendToOut(&chunkPf)
+			}
+
+			// The file is replaced with the chunks, so capyfile stops tracking it.
+			_ = pf.FreeResources()
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FileSplitOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileSplitOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
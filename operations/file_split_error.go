@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileSplitFailure = "FILE_SPLIT_FAILURE"
+
+func NewFileSplitFailureError(origErr error) *FileSplitFailureError {
+	return &FileSplitFailureError{
+		Data: &FileSplitFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileSplitFailureError struct {
+	files.FileProcessingError
+	Data *FileSplitFailureErrorData
+}
+
+type FileSplitFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *FileSplitFailureError) Code() string {
+	return ErrorCodeFileSplitFailure
+}
+
+func (e *FileSplitFailureError) Error() string {
+	return "file can not be split"
+}
@@ -0,0 +1,128 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFileSplitOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/file_split", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	testCases := []struct {
+		name           string
+		content        string
+		params         *FileSplitOperationParams
+		expectedChunks []string
+	}{
+		{
+			name:           "by size",
+			content:        "0123456789abcdef",
+			params:         &FileSplitOperationParams{ChunkSize: 6},
+			expectedChunks: []string{"012345", "6789ab", "cdef"},
+		},
+		{
+			name:           "by size with exact chunks",
+			content:        "0123456789ab",
+			params:         &FileSplitOperationParams{ChunkSize: 6},
+			expectedChunks: []string{"012345", "6789ab"},
+		},
+		{
+			name:           "by size preserving lines",
+			content:        "one\ntwo\nthree\nfour\n",
+			params:         &FileSplitOperationParams{ChunkSize: 9, PreserveLines: true},
+			expectedChunks: []string{"one\ntwo\n", "three\n", "four\n"},
+		},
+		{
+			name:           "by size preserving lines with long line",
+			content:        "one\nthirteen chars\ntwo",
+			params:         &FileSplitOperationParams{ChunkSize: 6, PreserveLines: true},
+			expectedChunks: []string{"one\n", "thirte", "en cha", "rs\ntwo"},
+		},
+		{
+			name:           "by line count",
+			content:        "one\ntwo\nthree\nfour\nfive",
+			params:         &FileSplitOperationParams{LineCount: 2},
+			expectedChunks: []string{"one\ntwo\n", "three\nfour\n", "five"},
+		},
+		{
+			name:           "empty file",
+			content:        "",
+			params:         &FileSplitOperationParams{LineCount: 2},
+			expectedChunks: []string{""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/file_split/input.txt", []byte(tc.content), 0644)
+			if writeErr != nil {
+				t.Fatal(writeErr)
+			}
+
+			in := []files.ProcessableFile{
+				files.NewProcessableFile("/tmp/file_split/input.txt"),
+			}
+			in[0].AddOperationMetadata("file_type_validate.mime", "text/plain")
+
+			operation := &FileSplitOperation{
+				Name:   "file_split",
+				Params: tc.params,
+			}
+			out, err := operation.Handle(in, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != len(tc.expectedChunks) {
+				t.Fatalf("len(out) = %d, want %d", len(out), len(tc.expectedChunks))
+			}
+
+			sort.Slice(out, func(i, j int) bool {
+				return chunkIndex(&out[i]) < chunkIndex(&out[j])
+			})
+
+			var chunks []string
+			for i := range out {
+				if out[i].FileProcessingError != nil {
+					t.Fatalf(
+						"FileProcessingError.Code() = %s, want nil",
+						out[i].FileProcessingError.Code(),
+					)
+				}
+
+				expectedMetadata := map[string]interface{}{
+					"file_type_validate.mime":   "text/plain",
+					MetadataKeyFileSplitGroupID: in[0].NanoID,
+					MetadataKeyFileSplitIndex:   int64(i + 1),
+					MetadataKeyFileSplitCount:   int64(len(tc.expectedChunks)),
+				}
+				if !reflect.DeepEqual(out[i].OperationMetadata, expectedMetadata) {
+					t.Fatalf("OperationMetadata = %v, want %v", out[i].OperationMetadata, expectedMetadata)
+				}
+				if out[i].OriginalFilename() != "/tmp/file_split/input.txt" {
+					t.Fatalf("OriginalFilename() = %s, want /tmp/file_split/input.txt", out[i].OriginalFilename())
+				}
+
+				chunk, readErr := afero.ReadFile(capyfs.Filesystem, out[i].Name())
+				if readErr != nil {
+					t.Fatal(readErr)
+				}
+
+				chunks = append(chunks, string(chunk))
+			}
+
+			if !reflect.DeepEqual(chunks, tc.expectedChunks) {
+				t.Fatalf("chunks = %q, want %q", chunks, tc.expectedChunks)
+			}
+		})
+	}
+}
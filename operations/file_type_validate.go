@@ -2,6 +2,10 @@ package operations
 
 import (
 	"capyfile/files"
+	"github.com/gabriel-vasile/mimetype"
+	"mime"
+	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -11,7 +15,103 @@ type FileTypeValidateOperation struct {
 }
 
 type FileTypeValidateOperationParams struct {
+	// AllowedMimeTypes and DeniedMimeTypes can contain wildcards like "image/*".
 	AllowedMimeTypes []string
+	// DeniedMimeTypes are matched against the MIME type and all its parents, so denying
+	// "application/zip" denies all the zip-based formats as well.
+	DeniedMimeTypes []string
+
+	// AllowedExtensions and DeniedExtensions are the lowercase extensions with the leading dot.
+	AllowedExtensions []string
+	DeniedExtensions  []string
+
+	// ValidateExtension Whether the extension of the original filename must match the detected MIME type.
+	ValidateExtension bool
+}
+
+func (p *FileTypeValidateOperationParams) needsMime() bool {
+	return len(p.AllowedMimeTypes) > 0 || len(p.DeniedMimeTypes) > 0 || p.ValidateExtension
+}
+
+// validateExtension Returns the error if the extension is not allowed, or nil if it is.
+func (p *FileTypeValidateOperationParams) validateExtension(ext string) files.FileProcessingError {
+	if len(p.AllowedExtensions) > 0 && !containsString(p.AllowedExtensions, ext) {
+		return NewFileExtensionIsNotAllowedError(p.AllowedExtensions, ext)
+	}
+	if containsString(p.DeniedExtensions, ext) {
+		return NewFileExtensionIsNotAllowedError(p.AllowedExtensions, ext)
+	}
+
+	return nil
+}
+
+// validateMime Returns the error if the MIME type is not allowed, or nil if it is.
+func (p *FileTypeValidateOperationParams) validateMime(m *mimetype.MIME, ext string) files.FileProcessingError {
+	if len(p.AllowedMimeTypes) > 0 {
+		var allowed = false
+		for _, allowedMime := range p.AllowedMimeTypes {
+			if mimeMatches(m, allowedMime) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return NewFileMimeTypeIsNotAllowedError(p.AllowedMimeTypes, m.String())
+		}
+	}
+
+	for parent := m; parent != nil; parent = parent.Parent() {
+		for _, deniedMime := range p.DeniedMimeTypes {
+			if mimeMatches(parent, deniedMime) {
+				return NewFileMimeTypeIsNotAllowedError(p.AllowedMimeTypes, m.String())
+			}
+		}
+	}
+
+	// The files without the extension do not claim to be of any type.
+	if p.ValidateExtension && ext != "" && !extensionMatchesMime(ext, m) {
+		return NewFileExtensionDoesNotMatchMimeTypeError(ext, m.String())
+	}
+
+	return nil
+}
+
+// mimeMatches Whether the MIME type matches the pattern. The pattern is either the MIME type, or
+// the wildcard like "image/*".
+func mimeMatches(m *mimetype.MIME, pattern string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		mediaType, _, _ := strings.Cut(m.String(), ";")
+
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return m.Is(pattern)
+}
+
+// extensionMatchesMime Whether the extension is one of the extensions of the MIME type. The MIME type is
+// allowed to be more specific than the extension claims, so "archive.zip" matches the docx file.
+func extensionMatchesMime(ext string, m *mimetype.MIME) bool {
+	extMediaType, _, _ := strings.Cut(mime.TypeByExtension(ext), ";")
+
+	for parent := m; parent != nil; parent = parent.Parent() {
+		if parent.Extension() != "" && parent.Extension() == ext {
+			return true
+		}
+		if extMediaType != "" && parent.Is(extMediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (o *FileTypeValidateOperation) OperationName() string {
@@ -43,7 +143,23 @@ func (o *FileTypeValidateOperation) Handle(
 				notificationCh <- o.notificationBuilder().Started("file type validation started", pf)
 			}
 
-			if len(o.Params.AllowedMimeTypes) > 0 {
+			ext := strings.ToLower(filepath.Ext(pf.OriginalFilename()))
+
+			extensionErr := o.Params.validateExtension(ext)
+			if extensionErr != nil {
+				pf.SetFileProcessingError(extensionErr)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(
+						"file extension is not allowed", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if o.Params.needsMime() {
 				mime, mimeErr := pf.Mime()
 				if mimeErr != nil {
 					pf.SetFileProcessingError(
@@ -63,20 +179,13 @@ func (o *FileTypeValidateOperation) Handle(
 					return
 				}
 
-				var allowed = false
-				for _, allowedMime := range o.Params.AllowedMimeTypes {
-					if mime.Is(allowedMime) {
-						allowed = true
-					}
-				}
-				if !allowed {
-					pf.SetFileProcessingError(
-						NewFileMimeTypeIsNotAllowedError(o.Params.AllowedMimeTypes, mime.String()),
-					)
+				mimeValidationErr := o.Params.validateMime(mime, ext)
+				if mimeValidationErr != nil {
+					pf.SetFileProcessingError(mimeValidationErr)
 
 					if notificationCh != nil {
 						notificationCh <- o.notificationBuilder().Finished(
-							"file MIME type is not allowed", pf)
+							mimeValidationErr.Error(), pf)
 					}
 
 					outHolder.AppendToOut(pf)
@@ -61,3 +61,63 @@ func (e *FileMimeTypeCanNotBeDeterminedError) Code() string {
 func (e *FileMimeTypeCanNotBeDeterminedError) Error() string {
 	return "file MIME type can not be determined"
 }
+
+const ErrorCodeFileExtensionIsNotAllowed = "FILE_EXTENSION_IS_NOT_ALLOWED"
+
+func NewFileExtensionIsNotAllowedError(allowedExtensions []string, givenExtension string) *FileExtensionIsNotAllowedError {
+	return &FileExtensionIsNotAllowedError{
+		Data: &FileExtensionIsNotAllowedErrorData{
+			AllowedExtensions: allowedExtensions,
+			GivenExtension:    givenExtension,
+		},
+	}
+}
+
+type FileExtensionIsNotAllowedError struct {
+	files.FileProcessingError
+
+	Data *FileExtensionIsNotAllowedErrorData
+}
+
+type FileExtensionIsNotAllowedErrorData struct {
+	AllowedExtensions []string
+	GivenExtension    string
+}
+
+func (e *FileExtensionIsNotAllowedError) Code() string {
+	return ErrorCodeFileExtensionIsNotAllowed
+}
+
+func (e *FileExtensionIsNotAllowedError) Error() string {
+	return "file extension is not allowed"
+}
+
+const ErrorCodeFileExtensionDoesNotMatchMimeType = "FILE_EXTENSION_DOES_NOT_MATCH_MIME_TYPE"
+
+func NewFileExtensionDoesNotMatchMimeTypeError(givenExtension, givenMimeType string) *FileExtensionDoesNotMatchMimeTypeError {
+	return &FileExtensionDoesNotMatchMimeTypeError{
+		Data: &FileExtensionDoesNotMatchMimeTypeErrorData{
+			GivenExtension: givenExtension,
+			GivenMimeType:  givenMimeType,
+		},
+	}
+}
+
+type FileExtensionDoesNotMatchMimeTypeError struct {
+	files.FileProcessingError
+
+	Data *FileExtensionDoesNotMatchMimeTypeErrorData
+}
+
+type FileExtensionDoesNotMatchMimeTypeErrorData struct {
+	GivenExtension string
+	GivenMimeType  string
+}
+
+func (e *FileExtensionDoesNotMatchMimeTypeError) Code() string {
+	return ErrorCodeFileExtensionDoesNotMatchMimeType
+}
+
+func (e *FileExtensionDoesNotMatchMimeTypeError) Error() string {
+	return "file extension does not match file MIME type"
+}
@@ -3,6 +3,7 @@ package operations
 import (
 	"capyfile/capyfs"
 	"capyfile/files"
+	"github.com/spf13/afero"
 	"golang.org/x/exp/slices"
 	"os"
 	"testing"
@@ -224,3 +225,131 @@ func TestFileTypeValidateOperation_HandleFilesOfAllowedAndNotAllowedTypes(t *tes
 		)
 	}
 }
+
+func TestFileTypeValidateOperation_HandleExtensions(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	jpeg, readErr := afero.ReadFile(capyfs.Filesystem, "testdata/image_512x512.jpg")
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	executable := make([]byte, 64)
+	copy(executable, "\x7fELF\x02\x01\x01")
+	executable[16] = 0x02
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/file_type_validate", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	testFiles := map[string][]byte{
+		"/tmp/file_type_validate/photo.JPEG":  jpeg,
+		"/tmp/file_type_validate/photo.png":   jpeg,
+		"/tmp/file_type_validate/invoice.pdf": executable,
+		"/tmp/file_type_validate/report.csv":  []byte("id,amount\n1,100\n2,200\n"),
+		"/tmp/file_type_validate/setup.exe":   []byte("MZ"),
+	}
+	for name, content := range testFiles {
+		writeErr := afero.WriteFile(capyfs.Filesystem, name, content, 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		filename          string
+		params            *FileTypeValidateOperationParams
+		expectedErrorCode string
+	}{
+		{
+			name:     "extension matches MIME type",
+			filename: "/tmp/file_type_validate/photo.JPEG",
+			params: &FileTypeValidateOperationParams{
+				AllowedMimeTypes:  []string{"image/*"},
+				ValidateExtension: true,
+			},
+		},
+		{
+			name:     "csv extension matches more specific MIME type",
+			filename: "/tmp/file_type_validate/report.csv",
+			params: &FileTypeValidateOperationParams{
+				ValidateExtension: true,
+			},
+		},
+		{
+			name:     "jpeg disguised as png",
+			filename: "/tmp/file_type_validate/photo.png",
+			params: &FileTypeValidateOperationParams{
+				AllowedMimeTypes:  []string{"image/*"},
+				ValidateExtension: true,
+			},
+			expectedErrorCode: ErrorCodeFileExtensionDoesNotMatchMimeType,
+		},
+		{
+			name:     "executable disguised as pdf",
+			filename: "/tmp/file_type_validate/invoice.pdf",
+			params: &FileTypeValidateOperationParams{
+				ValidateExtension: true,
+			},
+			expectedErrorCode: ErrorCodeFileExtensionDoesNotMatchMimeType,
+		},
+		{
+			name:     "executable family is denied",
+			filename: "/tmp/file_type_validate/invoice.pdf",
+			params: &FileTypeValidateOperationParams{
+				DeniedMimeTypes: []string{"application/x-elf"},
+			},
+			expectedErrorCode: ErrorCodeFileMimeTypeIsNotAllowed,
+		},
+		{
+			name:     "wildcard does not allow other types",
+			filename: "/tmp/file_type_validate/report.csv",
+			params: &FileTypeValidateOperationParams{
+				AllowedMimeTypes: []string{"image/*"},
+			},
+			expectedErrorCode: ErrorCodeFileMimeTypeIsNotAllowed,
+		},
+		{
+			name:     "extension is denied",
+			filename: "/tmp/file_type_validate/setup.exe",
+			params: &FileTypeValidateOperationParams{
+				DeniedExtensions: []string{".exe", ".bat"},
+			},
+			expectedErrorCode: ErrorCodeFileExtensionIsNotAllowed,
+		},
+		{
+			name:     "extension is not allowed",
+			filename: "/tmp/file_type_validate/report.csv",
+			params: &FileTypeValidateOperationParams{
+				AllowedExtensions: []string{".jpg", ".jpeg"},
+			},
+			expectedErrorCode: ErrorCodeFileExtensionIsNotAllowed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			operation := &FileTypeValidateOperation{
+				Params: testCase.params,
+			}
+			out, err := operation.Handle(
+				[]files.ProcessableFile{files.NewProcessableFile(testCase.filename)}, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			var errorCode string
+			if out[0].FileProcessingError != nil {
+				errorCode = out[0].FileProcessingError.Code()
+			}
+			if errorCode != testCase.expectedErrorCode {
+				t.Fatalf("FileProcessingError.Code() = %s, want %s", errorCode, testCase.expectedErrorCode)
+			}
+		})
+	}
+}
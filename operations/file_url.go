@@ -0,0 +1,50 @@
+package operations
+
+import "time"
+
+// uploadUrlMetadataKeys The metadata keys the upload operations store the file URLs under.
+var uploadUrlMetadataKeys = []struct {
+	fileUrl               string
+	presignedUrl          string
+	presignedUrlExpiresAt string
+}{
+	{
+		fileUrl:               MetadataKeyS3UploadFileUrl,
+		presignedUrl:          MetadataKeyS3UploadPresignedUrl,
+		presignedUrlExpiresAt: MetadataKeyS3UploadPresignedUrlExpiresAt,
+	},
+	{
+		fileUrl:               MetadataKeyGCSUploadFileUrl,
+		presignedUrl:          MetadataKeyGCSUploadPresignedUrl,
+		presignedUrlExpiresAt: MetadataKeyGCSUploadPresignedUrlExpiresAt,
+	},
+	{
+		fileUrl:               MetadataKeyAzureBlobUploadFileUrl,
+		presignedUrl:          MetadataKeyAzureBlobUploadPresignedUrl,
+		presignedUrlExpiresAt: MetadataKeyAzureBlobUploadPresignedUrlExpiresAt,
+	},
+	{
+		fileUrl: MetadataKeyHTTPUploadFileUrl,
+	},
+}
+
+// FileUrlFromMetadata Retrieves the file URL left by the upload operation.
+func FileUrlFromMetadata(metadata map[string]any) (string, *time.Time) {
+	for _, keys := range uploadUrlMetadataKeys {
+		// The presigned URL is preferred because the plain one is useless for the private buckets.
+		if val, ok := metadata[keys.presignedUrl]; ok {
+			if expiresAt, ok := metadata[keys.presignedUrlExpiresAt]; ok {
+				expiresAtTime := expiresAt.(time.Time)
+				return val.(string), &expiresAtTime
+			}
+
+			return val.(string), nil
+		}
+
+		if val, ok := metadata[keys.fileUrl]; ok {
+			return val.(string), nil
+		}
+	}
+
+	return "", nil
+}
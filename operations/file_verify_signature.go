@@ -0,0 +1,342 @@
+package operations
+
+import (
+	"aead.dev/minisign"
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/spf13/afero"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const MetadataKeyFileVerifySignatureKeyId = "file_verify_signature.key_id"
+
+// MetadataKeyFileVerifySignatureSignatureFile The NanoID of the signature file the file has been verified with.
+const MetadataKeyFileVerifySignatureSignatureFile = "file_verify_signature.signature_file"
+
+// maxSignatureFileSize The signatures are small, so anything bigger is not a signature.
+const maxSignatureFileSize = 1 << 20
+
+type FileVerifySignatureOperation struct {
+	Name   string
+	Params *FileVerifySignatureOperationParams
+}
+
+func (o *FileVerifySignatureOperation) OperationName() string {
+	return o.Name
+}
+
+// AllowConcurrency The files are paired with their signatures, so the operation needs all the files at once.
+func (o *FileVerifySignatureOperation) AllowConcurrency() bool {
+	return false
+}
+
+type FileVerifySignatureOperationParams struct {
+	// Format is the signature format. Possible values: "minisign", "openpgp".
+	Format string
+
+	MinisignPublicKeys []minisign.PublicKey
+	OpenPGPKeyRing     openpgp.EntityList
+
+	// SignatureExtension is the extension of the signature files. The signature of the file
+	// is the file with the same original filename and this extension. For example: report.pdf.minisig
+	SignatureExtension string
+}
+
+// verify Verifies the file signature and returns the ID of the key the file has been signed with.
+func (p *FileVerifySignatureOperationParams) verify(pf, signaturePf *files.ProcessableFile) (string, error) {
+	signature, signatureReadErr := readSignatureFile(signaturePf)
+	if signatureReadErr != nil {
+		return "", signatureReadErr
+	}
+
+	file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+	if fileOpenErr != nil {
+		return "", fileOpenErr
+	}
+	defer file.Close()
+
+	if p.Format == SignatureFormatOpenPGP {
+		return p.verifyOpenPGP(file, signature)
+	}
+
+	return p.verifyMinisign(file, signature)
+}
+
+func readSignatureFile(pf *files.ProcessableFile) ([]byte, error) {
+	file, openErr := capyfs.Filesystem.Open(pf.Name())
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+
+	// Read one byte more to tell whether the file exceeds the limit.
+	signature, readErr := io.ReadAll(io.LimitReader(file, maxSignatureFileSize+1))
+	if readErr != nil {
+		return nil, readErr
+	}
+	if len(signature) > maxSignatureFileSize {
+		return nil, fmt.Errorf("signature file exceeds the limit of %d bytes", maxSignatureFileSize)
+	}
+
+	return signature, nil
+}
+
+// isSignature Tells whether the file parses as the signature of the configured format.
+func (p *FileVerifySignatureOperationParams) isSignature(pf *files.ProcessableFile) bool {
+	signature, readErr := readSignatureFile(pf)
+	if readErr != nil {
+		return false
+	}
+
+	if p.Format == SignatureFormatOpenPGP {
+		return isOpenPGPSignature(signature)
+	}
+
+	var parsedSignature minisign.Signature
+
+	return parsedSignature.UnmarshalText(signature) == nil
+}
+
+func isOpenPGPSignature(signature []byte) bool {
+	var reader io.Reader = bytes.NewReader(signature)
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		block, decodeErr := armor.Decode(reader)
+		if decodeErr != nil || block.Type != openpgp.SignatureType {
+			return false
+		}
+
+		reader = block.Body
+	}
+
+	pkt, readErr := packet.Read(reader)
+	if readErr != nil {
+		return false
+	}
+	_, ok := pkt.(*packet.Signature)
+
+	return ok
+}
+
+func (p *FileVerifySignatureOperationParams) verifyMinisign(file afero.File, signature []byte) (string, error) {
+	var parsedSignature minisign.Signature
+	parseErr := parsedSignature.UnmarshalText(signature)
+	if parseErr != nil {
+		return "", NewFileSignatureIsInvalidError(parseErr)
+	}
+
+	keyId := minisignKeyId(parsedSignature.KeyID)
+
+	var publicKey *minisign.PublicKey
+	for i := range p.MinisignPublicKeys {
+		if p.MinisignPublicKeys[i].ID() == parsedSignature.KeyID {
+			publicKey = &p.MinisignPublicKeys[i]
+			break
+		}
+	}
+	if publicKey == nil {
+		return keyId, NewFileSignatureKeyIsUnknownError(keyId)
+	}
+
+	// The legacy signatures are made over the whole file, not over its hash, so the file would have to be read
+	// into memory to verify them.
+	if parsedSignature.Algorithm != minisign.HashEdDSA {
+		return keyId, NewFileSignatureIsInvalidError(errors.New("legacy minisign signatures are not supported"))
+	}
+
+	reader := minisign.NewReader(file)
+	_, copyErr := io.Copy(io.Discard, reader)
+	if copyErr != nil {
+		return keyId, copyErr
+	}
+
+	isValid := reader.Verify(*publicKey, signature)
+	if !isValid {
+		return keyId, NewFileSignatureIsInvalidError(errors.New("signature verification failed"))
+	}
+
+	return keyId, nil
+}
+
+func (p *FileVerifySignatureOperationParams) verifyOpenPGP(file afero.File, signature []byte) (string, error) {
+	var signer *openpgp.Entity
+	var checkErr error
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		signer, checkErr = openpgp.CheckArmoredDetachedSignature(p.OpenPGPKeyRing, file, bytes.NewReader(signature), nil)
+	} else {
+		signer, checkErr = openpgp.CheckDetachedSignature(p.OpenPGPKeyRing, file, bytes.NewReader(signature), nil)
+	}
+	if checkErr == pgperrors.ErrUnknownIssuer {
+		return "", NewFileSignatureKeyIsUnknownError("")
+	}
+	if checkErr != nil {
+		return "", NewFileSignatureIsInvalidError(checkErr)
+	}
+
+	return openPGPKeyId(signer), nil
+}
+
+// pairSignatures Finds the signature file of every file. The signature is looked up by the link left
+// by file_sign operation, and then by the original filename with the signature extension. Only the files
+// that parse as the signatures are considered as such, the rest of the files need the signatures themselves.
+// The signatures that do not belong to any file are returned as orphaned.
+func (p *FileVerifySignatureOperationParams) pairSignatures(
+	in []files.ProcessableFile,
+) (signed map[int]int, unsigned []int, orphaned []int) {
+	byNanoID := make(map[string]int, len(in))
+	byFilename := make(map[string]int, len(in))
+	isSignature := make(map[int]bool)
+	for i := range in {
+		filename := filepath.Base(in[i].OriginalFilename())
+		if _, ok := in[i].OperationMetadata[MetadataKeyFileSignSignedFile]; ok ||
+			strings.HasSuffix(filename, p.SignatureExtension) {
+			if !p.isSignature(&in[i]) {
+				continue
+			}
+
+			byNanoID[in[i].NanoID] = i
+			byFilename[filename] = i
+			isSignature[i] = true
+		}
+	}
+
+	signed = make(map[int]int)
+	paired := make(map[int]bool)
+	for i := range in {
+		if isSignature[i] {
+			continue
+		}
+
+		if signatureNanoID, ok := in[i].OperationMetadata[MetadataKeyFileSignSignatureFile].(string); ok {
+			if signatureIdx, ok := byNanoID[signatureNanoID]; ok {
+				signed[i] = signatureIdx
+				paired[signatureIdx] = true
+				continue
+			}
+		}
+
+		if signatureIdx, ok := byFilename[filepath.Base(in[i].OriginalFilename())+p.SignatureExtension]; ok {
+			signed[i] = signatureIdx
+			paired[signatureIdx] = true
+			continue
+		}
+
+		unsigned = append(unsigned, i)
+	}
+
+	for i := range in {
+		if isSignature[i] && !paired[i] {
+			orphaned = append(orphaned, i)
+		}
+	}
+
+	return signed, unsigned, orphaned
+}
+
+func (o *FileVerifySignatureOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	signed, unsigned, orphaned := o.Params.pairSignatures(in)
+
+	for _, i := range unsigned {
+		pf := &in[i]
+
+		pf.SetFileProcessingError(
+			NewFileSignatureIsMissingError(),
+		)
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("file signature is missing", pf)
+		}
+	}
+
+	for _, i := range orphaned {
+		pf := &in[i]
+
+		pf.SetFileProcessingError(
+			NewSignedFileIsMissingError(),
+		)
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("signed file is missing", pf)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for i, signatureIdx := range signed {
+		wg.Add(1)
+
+		go func(pf, signaturePf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file signature verification has started", pf)
+			}
+
+			keyId, verifyErr := o.Params.verify(pf, signaturePf)
+			if keyId != "" {
+				pf.AddOperationMetadata(MetadataKeyFileVerifySignatureKeyId, keyId)
+			}
+			pf.AddOperationMetadata(MetadataKeyFileVerifySignatureSignatureFile, signaturePf.NanoID)
+
+			if verifyErr != nil {
+				var fileProcessingError files.FileProcessingError
+				if errors.As(verifyErr, &fileProcessingError) {
+					pf.SetFileProcessingError(fileProcessingError)
+
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Finished(fileProcessingError.Error(), pf)
+					}
+
+					return
+				}
+
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(verifyErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, verifyErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file can not be read", pf, verifyErr)
+				}
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file signature is valid", pf)
+			}
+		}(&in[i], &in[signatureIdx])
+	}
+
+	wg.Wait()
+
+	// The signature files are kept along with the signed files, so they can be delivered together.
+	return in, nil
+}
+
+func (o *FileVerifySignatureOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FileVerifySignatureOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,97 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileSignatureIsInvalid = "FILE_SIGNATURE_IS_INVALID"
+
+func NewFileSignatureIsInvalidError(origErr error) *FileSignatureIsInvalidError {
+	return &FileSignatureIsInvalidError{
+		Data: &FileSignatureIsInvalidErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FileSignatureIsInvalidError struct {
+	files.FileProcessingError
+	Data *FileSignatureIsInvalidErrorData
+}
+
+type FileSignatureIsInvalidErrorData struct {
+	OrigErr error
+}
+
+func (e *FileSignatureIsInvalidError) Code() string {
+	return ErrorCodeFileSignatureIsInvalid
+}
+
+func (e *FileSignatureIsInvalidError) Error() string {
+	return "file signature is invalid"
+}
+
+const ErrorCodeFileSignatureIsMissing = "FILE_SIGNATURE_IS_MISSING"
+
+func NewFileSignatureIsMissingError() *FileSignatureIsMissingError {
+	return &FileSignatureIsMissingError{}
+}
+
+type FileSignatureIsMissingError struct {
+	files.FileProcessingError
+}
+
+func (e *FileSignatureIsMissingError) Code() string {
+	return ErrorCodeFileSignatureIsMissing
+}
+
+func (e *FileSignatureIsMissingError) Error() string {
+	return "file signature is missing"
+}
+
+const ErrorCodeSignedFileIsMissing = "SIGNED_FILE_IS_MISSING"
+
+func NewSignedFileIsMissingError() *SignedFileIsMissingError {
+	return &SignedFileIsMissingError{}
+}
+
+type SignedFileIsMissingError struct {
+	files.FileProcessingError
+}
+
+func (e *SignedFileIsMissingError) Code() string {
+	return ErrorCodeSignedFileIsMissing
+}
+
+func (e *SignedFileIsMissingError) Error() string {
+	return "signed file is missing"
+}
+
+const ErrorCodeFileSignatureKeyIsUnknown = "FILE_SIGNATURE_KEY_IS_UNKNOWN"
+
+func NewFileSignatureKeyIsUnknownError(keyId string) *FileSignatureKeyIsUnknownError {
+	return &FileSignatureKeyIsUnknownError{
+		Data: &FileSignatureKeyIsUnknownErrorData{
+			KeyId: keyId,
+		},
+	}
+}
+
+type FileSignatureKeyIsUnknownError struct {
+	files.FileProcessingError
+
+	Data *FileSignatureKeyIsUnknownErrorData
+}
+
+type FileSignatureKeyIsUnknownErrorData struct {
+	// KeyId is the ID of the key the file has been signed with, if the signature tells it.
+	KeyId string
+}
+
+func (e *FileSignatureKeyIsUnknownError) Code() string {
+	return ErrorCodeFileSignatureKeyIsUnknown
+}
+
+func (e *FileSignatureKeyIsUnknownError) Error() string {
+	return "file has been signed with unknown key"
+}
@@ -0,0 +1,162 @@
+package operations
+
+import (
+	"aead.dev/minisign"
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/rand"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/spf13/afero"
+	"io"
+	"testing"
+)
+
+// signMinisignPrehashed Signs the message the same way as minisign does by default, over the message hash.
+func signMinisignPrehashed(t *testing.T, privateKey minisign.PrivateKey, message []byte) []byte {
+	t.Helper()
+
+	reader := minisign.NewReader(bytes.NewReader(message))
+	if _, copyErr := io.Copy(io.Discard, reader); copyErr != nil {
+		t.Fatal(copyErr)
+	}
+
+	return reader.Sign(privateKey)
+}
+
+func TestFileVerifySignatureOperation_HandleMinisign(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	publicKey, privateKey, keyErr := minisign.GenerateKey(rand.Reader)
+	if keyErr != nil {
+		t.Fatal(keyErr)
+	}
+	_, unknownPrivateKey, unknownKeyErr := minisign.GenerateKey(rand.Reader)
+	if unknownKeyErr != nil {
+		t.Fatal(unknownKeyErr)
+	}
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/partner", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	partnerFiles := map[string][]byte{
+		"/tmp/partner/valid.csv":                       []byte("id,amount\n1,100\n"),
+		"/tmp/partner/valid.csv.minisig":               signMinisignPrehashed(t, privateKey, []byte("id,amount\n1,100\n")),
+		"/tmp/partner/tampered.csv":                    []byte("id,amount\n1,1000\n"),
+		"/tmp/partner/tampered.csv.minisig":            signMinisignPrehashed(t, privateKey, []byte("id,amount\n1,100\n")),
+		"/tmp/partner/unknown_key.csv":                 []byte("id,amount\n2,200\n"),
+		"/tmp/partner/unknown_key.csv.minisig":         signMinisignPrehashed(t, unknownPrivateKey, []byte("id,amount\n2,200\n")),
+		"/tmp/partner/unsigned.csv":                    []byte("id,amount\n3,300\n"),
+		"/tmp/partner/corrupted_signature.csv":         []byte("id,amount\n4,400\n"),
+		"/tmp/partner/corrupted_signature.csv.minisig": []byte("not a signature"),
+		"/tmp/partner/legacy.csv":                      []byte("id,amount\n6,600\n"),
+		"/tmp/partner/legacy.csv.minisig":              minisign.Sign(privateKey, []byte("id,amount\n6,600\n")),
+		"/tmp/partner/orphaned.csv.minisig":            signMinisignPrehashed(t, privateKey, []byte("id,amount\n5,500\n")),
+	}
+
+	var in []files.ProcessableFile
+	for name, content := range partnerFiles {
+		writeErr := afero.WriteFile(capyfs.Filesystem, name, content, 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+
+		in = append(in, files.NewProcessableFile(name))
+	}
+
+	operation := &FileVerifySignatureOperation{
+		Name: "file_verify_signature",
+		Params: &FileVerifySignatureOperationParams{
+			Format:             SignatureFormatMinisign,
+			MinisignPublicKeys: []minisign.PublicKey{publicKey},
+			SignatureExtension: ".minisig",
+		},
+	}
+
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != len(in) {
+		t.Fatalf("len(out) = %d, want %d", len(out), len(in))
+	}
+
+	// The file that does not parse as the signature is not considered as such, so it needs the signature itself.
+	expectedErrorCodes := map[string]string{
+		"/tmp/partner/valid.csv":                       "",
+		"/tmp/partner/valid.csv.minisig":               "",
+		"/tmp/partner/tampered.csv":                    ErrorCodeFileSignatureIsInvalid,
+		"/tmp/partner/tampered.csv.minisig":            "",
+		"/tmp/partner/unknown_key.csv":                 ErrorCodeFileSignatureKeyIsUnknown,
+		"/tmp/partner/unknown_key.csv.minisig":         "",
+		"/tmp/partner/unsigned.csv":                    ErrorCodeFileSignatureIsMissing,
+		"/tmp/partner/corrupted_signature.csv":         ErrorCodeFileSignatureIsMissing,
+		"/tmp/partner/corrupted_signature.csv.minisig": ErrorCodeFileSignatureIsMissing,
+		"/tmp/partner/legacy.csv":                      ErrorCodeFileSignatureIsInvalid,
+		"/tmp/partner/legacy.csv.minisig":              "",
+		"/tmp/partner/orphaned.csv.minisig":            ErrorCodeSignedFileIsMissing,
+	}
+	for _, pf := range out {
+		var errorCode string
+		if pf.HasFileProcessingError() {
+			errorCode = pf.FileProcessingError.Code()
+		}
+
+		if errorCode != expectedErrorCodes[pf.OriginalFilename()] {
+			t.Fatalf("%s error code = %s, want %s",
+				pf.OriginalFilename(), errorCode, expectedErrorCodes[pf.OriginalFilename()])
+		}
+	}
+}
+
+func TestFileVerifySignatureOperation_HandleOpenPGPUnknownKey(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	entity, entityErr := openpgp.NewEntity("Partner", "", "partner@example.com", nil)
+	if entityErr != nil {
+		t.Fatal(entityErr)
+	}
+	trustedEntity, trustedEntityErr := openpgp.NewEntity("Trusted", "", "trusted@example.com", nil)
+	if trustedEntityErr != nil {
+		t.Fatal(trustedEntityErr)
+	}
+
+	signOperation := &FileSignOperation{
+		Name: "file_sign",
+		Params: &FileSignOperationParams{
+			Format:        SignatureFormatOpenPGP,
+			OpenPGPSigner: entity,
+		},
+	}
+
+	out, err := signOperation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	verifyOperation := &FileVerifySignatureOperation{
+		Name: "file_verify_signature",
+		Params: &FileVerifySignatureOperationParams{
+			Format:             SignatureFormatOpenPGP,
+			OpenPGPKeyRing:     openpgp.EntityList{trustedEntity},
+			SignatureExtension: ".asc",
+		},
+	}
+
+	out, err = verifyOperation.Handle(out, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	for _, pf := range out {
+		if _, ok := pf.OperationMetadata[MetadataKeyFileSignSignatureFile]; !ok {
+			continue
+		}
+
+		if !pf.HasFileProcessingError() || pf.FileProcessingError.Code() != ErrorCodeFileSignatureKeyIsUnknown {
+			t.Fatalf("expected %s error, got %v", ErrorCodeFileSignatureKeyIsUnknown, pf.FileProcessingError)
+		}
+	}
+}
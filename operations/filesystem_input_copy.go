@@ -0,0 +1,183 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MetadataKeyFilesystemInputCopyDestination = "filesystem_input_copy.destination"
+
+// FilesystemInputCopyOperation copies input to another directory on the filesystem.
+type FilesystemInputCopyOperation struct {
+	Name   string
+	Params *FilesystemInputCopyOperationParams
+}
+
+type FilesystemInputCopyOperationParams struct {
+	FilesystemDestinationParams
+}
+
+// FilesystemDestinationParams The parameters of the operations that put the files to the directory.
+type FilesystemDestinationParams struct {
+	// Destination is the target directory template. Along with the vars described in objectTemplateData,
+	// the following vars are available:
+	// - {{.ErrorCode}} - code of the error attached to the file, or empty string
+	// - {{.Date}} - current UTC date. For example: 2023-06-01
+	// For example: /var/quarantine/{{.ErrorCode}}
+	Destination string
+	// DestinationTemplateVars are the additional vars available in the destination template.
+	DestinationTemplateVars map[string]string
+	// UseOriginalFilename indicates whether to use the original filename or the generated one (nanoid).
+	// It has no effect if the file has been renamed with file_rename.
+	UseOriginalFilename bool
+}
+
+// destinationPath Renders the path the file is put to.
+func (p *FilesystemDestinationParams) destinationPath(pf *files.ProcessableFile) (string, error) {
+	data := objectTemplateData(pf, p.DestinationTemplateVars)
+	data["ErrorCode"] = ""
+	if pf.HasFileProcessingError() {
+		data["ErrorCode"] = pf.FileProcessingError.Code()
+	}
+	data["Date"] = time.Now().UTC().Format("2006-01-02")
+
+	destinationDir, renderErr := renderObjectTemplate(p.Destination, data)
+	if renderErr != nil {
+		return "", renderErr
+	}
+
+	// The template vars can come from the file, so the rendered directory must stay
+	// within the directory the template starts with.
+	destinationDir = filepath.Clean(destinationDir)
+	baseDir := destinationBaseDir(p.Destination)
+	rel, relErr := filepath.Rel(baseDir, destinationDir)
+	if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("destination \"%s\" is outside of \"%s\"", destinationDir, baseDir)
+	}
+
+	return filepath.Join(destinationDir, destinationFilename(pf, p.UseOriginalFilename)), nil
+}
+
+// destinationBaseDir Returns the directory the destination template starts with, the one before the first var.
+// For example: /var/quarantine for /var/quarantine/{{.ErrorCode}}
+func destinationBaseDir(destination string) string {
+	staticPrefix := destination
+	if i := strings.Index(destination, "{{"); i >= 0 {
+		staticPrefix = destination[:i]
+	}
+
+	if staticPrefix == destination || strings.HasSuffix(staticPrefix, string(filepath.Separator)) {
+		return filepath.Clean(staticPrefix)
+	}
+
+	return filepath.Dir(staticPrefix)
+}
+
+// copyFile Copies the file content to the destination. The missing directories are created.
+func copyFile(src, dest string) error {
+	file, fileOpenErr := capyfs.Filesystem.Open(src)
+	if fileOpenErr != nil {
+		return fileOpenErr
+	}
+	defer file.Close()
+
+	return capyfs.FilesystemUtils.WriteReader(dest, file)
+}
+
+func (o *FilesystemInputCopyOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FilesystemInputCopyOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *FilesystemInputCopyOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file copy started", pf)
+			}
+
+			destFilename, destErr := o.Params.destinationPath(pf)
+			if destErr != nil {
+				pf.SetFileProcessingError(
+					NewFilesystemDestinationTemplateCanNotBeRenderedError(destErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, destErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"filesystem destination template can not be rendered", pf, destErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			copyErr := copyFile(pf.Name(), destFilename)
+			if copyErr != nil {
+				pf.SetFileProcessingError(
+					NewFileInputIsUnwritableError(copyErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, copyErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file copy failed with error", pf, copyErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyFilesystemInputCopyDestination, destFilename)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file copy finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FilesystemInputCopyOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FilesystemInputCopyOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
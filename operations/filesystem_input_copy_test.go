@@ -0,0 +1,147 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"testing"
+)
+
+func TestFilesystemInputCopyOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/image_512x512.png"),
+	}
+	in[0].Rename("avatar.png")
+
+	operation := &FilesystemInputCopyOperation{
+		Name: "filesystem_input_copy",
+		Params: &FilesystemInputCopyOperationParams{
+			FilesystemDestinationParams: FilesystemDestinationParams{
+				Destination:             "/tmp/filesystem_input_copy/{{.UserID}}",
+				DestinationTemplateVars: map[string]string{"UserID": "42"},
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if out[0].Name() != "testdata/image_512x512.png" {
+		t.Fatalf("Name() = %s, want testdata/image_512x512.png", out[0].Name())
+	}
+
+	exists, existsErr := capyfs.FilesystemUtils.Exists("/tmp/filesystem_input_copy/42/avatar.png")
+	if existsErr != nil {
+		t.Fatal(existsErr)
+	}
+	if !exists {
+		t.Fatalf("file has not been copied to the destination")
+	}
+	if out[0].OperationMetadata[MetadataKeyFilesystemInputCopyDestination] != "/tmp/filesystem_input_copy/42/avatar.png" {
+		t.Fatalf(
+			"OperationMetadata[%s] = %v, want /tmp/filesystem_input_copy/42/avatar.png",
+			MetadataKeyFilesystemInputCopyDestination,
+			out[0].OperationMetadata[MetadataKeyFilesystemInputCopyDestination],
+		)
+	}
+}
+
+func TestFilesystemInputCopyOperation_HandleInvalidTemplate(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/image_512x512.png"),
+	}
+
+	operation := &FilesystemInputCopyOperation{
+		Name: "filesystem_input_copy",
+		Params: &FilesystemInputCopyOperationParams{
+			FilesystemDestinationParams: FilesystemDestinationParams{
+				Destination: "/tmp/filesystem_input_copy/{{.Unknown}}",
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError == nil {
+		t.Fatalf("FileProcessingError = nil, want %s", ErrorCodeFilesystemDestinationTemplateCanNotBeRendered)
+	}
+	if out[0].FileProcessingError.Code() != ErrorCodeFilesystemDestinationTemplateCanNotBeRendered {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want %s",
+			out[0].FileProcessingError.Code(),
+			ErrorCodeFilesystemDestinationTemplateCanNotBeRendered,
+		)
+	}
+}
+
+func TestFilesystemInputCopyOperation_HandleDestinationOutsideOfTemplateDirectory(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	testCases := []struct {
+		destination string
+		userID      string
+	}{
+		{destination: "/tmp/filesystem_input_copy/{{.UserID}}", userID: "../../etc"},
+		{destination: "/tmp/filesystem_input_copy/user-{{.UserID}}", userID: "/../../../etc"},
+		{destination: "filesystem_input_copy/{{.UserID}}/avatars", userID: "../../.."},
+		{destination: "{{.UserID}}", userID: "/etc"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.destination, func(t *testing.T) {
+			in := []files.ProcessableFile{
+				files.NewProcessableFile("testdata/image_512x512.png"),
+			}
+
+			operation := &FilesystemInputCopyOperation{
+				Name: "filesystem_input_copy",
+				Params: &FilesystemInputCopyOperationParams{
+					FilesystemDestinationParams: FilesystemDestinationParams{
+						Destination:             testCase.destination,
+						DestinationTemplateVars: map[string]string{"UserID": testCase.userID},
+					},
+				},
+			}
+			out, err := operation.Handle(in, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			if out[0].FileProcessingError == nil {
+				t.Fatalf("FileProcessingError = nil, want %s", ErrorCodeFilesystemDestinationTemplateCanNotBeRendered)
+			}
+			if out[0].FileProcessingError.Code() != ErrorCodeFilesystemDestinationTemplateCanNotBeRendered {
+				t.Fatalf(
+					"FileProcessingError.Code() = %s, want %s",
+					out[0].FileProcessingError.Code(),
+					ErrorCodeFilesystemDestinationTemplateCanNotBeRendered,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+const MetadataKeyFilesystemInputMoveDestination = "filesystem_input_move.destination"
+
+// FilesystemInputMoveOperation moves input to another directory on the filesystem.
+type FilesystemInputMoveOperation struct {
+	Name   string
+	Params *FilesystemInputMoveOperationParams
+}
+
+type FilesystemInputMoveOperationParams struct {
+	FilesystemDestinationParams
+}
+
+// moveFile Renames the file. If the destination is on another device, the file is copied and removed.
+func moveFile(src, dest string) error {
+	mkdirErr := capyfs.Filesystem.MkdirAll(filepath.Dir(dest), 0755)
+	if mkdirErr != nil {
+		return mkdirErr
+	}
+
+	renameErr := capyfs.Filesystem.Rename(src, dest)
+	if !errors.Is(renameErr, syscall.EXDEV) {
+		return renameErr
+	}
+
+	copyErr := copyFile(src, dest)
+	if copyErr != nil {
+		return copyErr
+	}
+
+	return capyfs.Filesystem.Remove(src)
+}
+
+func (o *FilesystemInputMoveOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *FilesystemInputMoveOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *FilesystemInputMoveOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("file move started", pf)
+			}
+
+			destFilename, destErr := o.Params.destinationPath(pf)
+			if destErr != nil {
+				pf.SetFileProcessingError(
+					NewFilesystemDestinationTemplateCanNotBeRenderedError(destErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, destErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"filesystem destination template can not be rendered", pf, destErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			moveErr := moveFile(pf.Name(), destFilename)
+			if moveErr != nil {
+				pf.SetFileProcessingError(
+					NewFileInputIsUnwritableError(moveErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, moveErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("file move failed with error", pf, moveErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			// The file is in its final place now, so it must never be removed on cleanup.
+			pf.ReplaceFile(destFilename)
+			pf.KeepOnFreeResources()
+			pf.AddOperationMetadata(MetadataKeyFilesystemInputMoveDestination, destFilename)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file move finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *FilesystemInputMoveOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *FilesystemInputMoveOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
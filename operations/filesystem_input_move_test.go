@@ -0,0 +1,83 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"testing"
+)
+
+func TestFilesystemInputMoveOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/filesystem_input_move/incoming", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	for _, name := range []string{"report.csv", "broken.csv"} {
+		writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/filesystem_input_move/incoming/"+name, []byte("id,name\n1,Alice\n2,Bob\n"), 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("/tmp/filesystem_input_move/incoming/report.csv"),
+		files.NewProcessableFile("/tmp/filesystem_input_move/incoming/broken.csv"),
+	}
+	in[1].SetFileProcessingError(NewFileContentIsInvalidError(ContentFormatCSV, contentPosition{}, "broken"))
+
+	operation := &FilesystemInputMoveOperation{
+		Name: "filesystem_input_move",
+		Params: &FilesystemInputMoveOperationParams{
+			FilesystemDestinationParams: FilesystemDestinationParams{
+				Destination:         "/tmp/filesystem_input_move/{{if .ErrorCode}}failed/{{.ErrorCode}}{{else}}done{{end}}",
+				UseOriginalFilename: true,
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	expectedDestinations := map[string]string{
+		"/tmp/filesystem_input_move/incoming/report.csv": "/tmp/filesystem_input_move/done/report.csv",
+		"/tmp/filesystem_input_move/incoming/broken.csv": "/tmp/filesystem_input_move/failed/FILE_CONTENT_IS_INVALID/broken.csv",
+	}
+	for _, pf := range out {
+		expectedDestination := expectedDestinations[pf.OriginalFilename()]
+
+		if pf.Name() != expectedDestination {
+			t.Fatalf("Name() = %s, want %s", pf.Name(), expectedDestination)
+		}
+		if pf.OperationMetadata[MetadataKeyFilesystemInputMoveDestination] != expectedDestination {
+			t.Fatalf(
+				"OperationMetadata[%s] = %v, want %s",
+				MetadataKeyFilesystemInputMoveDestination,
+				pf.OperationMetadata[MetadataKeyFilesystemInputMoveDestination],
+				expectedDestination,
+			)
+		}
+
+		exists, existsErr := capyfs.FilesystemUtils.Exists(expectedDestination)
+		if existsErr != nil {
+			t.Fatal(existsErr)
+		}
+		if !exists {
+			t.Fatalf("file %s has not been moved to the destination", pf.OriginalFilename())
+		}
+
+		exists, existsErr = capyfs.FilesystemUtils.Exists(pf.OriginalFilename())
+		if existsErr != nil {
+			t.Fatal(existsErr)
+		}
+		if exists {
+			t.Fatalf("file %s still exists", pf.OriginalFilename())
+		}
+	}
+}
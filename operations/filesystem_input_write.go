@@ -18,9 +18,32 @@ type FilesystemInputWriteOperationParams struct {
 	// Destination is the target directory to write to.
 	Destination string
 	// UseOriginalFilename indicates whether to use the original filename or the generated one (nanoid).
+	// It has no effect if the file has been renamed with file_rename.
 	UseOriginalFilename bool
 }
 
+// destinationFilename Returns the filename the file is written to the filesystem with. The filename set
+// by file_rename always takes precedence.
+func destinationFilename(pf *files.ProcessableFile, useOriginalFilename bool) string {
+	if pf.Metadata.Filename != "" {
+		return pf.LogicalFilename()
+	}
+
+	if !useOriginalFilename {
+		return pf.GeneratedFilename()
+	}
+
+	base := filepath.Base(pf.OriginalFilename())
+	// In addition to it, we need to ensure that the extension is relevant.
+	// This is for the cases we transform the file to another format, etc.
+	ext := filepath.Ext(pf.OriginalFilename())
+	if ext != "" {
+		base = base[:len(base)-len(ext)] + filepath.Ext(pf.GeneratedFilename())
+	}
+
+	return base
+}
+
 func (o *FilesystemInputWriteOperation) OperationName() string {
 	return o.Name
 }
@@ -48,19 +71,7 @@ func (o *FilesystemInputWriteOperation) Handle(
 				notificationCh <- o.notificationBuilder().Started("file write started", pf)
 			}
 
-			var base string
-			if o.Params.UseOriginalFilename {
-				base = filepath.Base(pf.OriginalFilename())
-				// In addition to it, we need to ensure that the extension is relevant.
-				// This is for the cases we transform the file to another format, etc.
-				ext := filepath.Ext(pf.OriginalFilename())
-				if ext != "" {
-					base = base[:len(base)-len(ext)] + filepath.Ext(pf.GeneratedFilename())
-				}
-			} else {
-				base = pf.GeneratedFilename()
-			}
-			destFilename := filepath.Join(o.Params.Destination, base)
+			destFilename := filepath.Join(o.Params.Destination, destinationFilename(pf, o.Params.UseOriginalFilename))
 
 			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
 			if fileOpenErr != nil {
@@ -31,3 +31,30 @@ func (e *FileInputIsUnwritableError) Code() string {
 func (e *FileInputIsUnwritableError) Error() string {
 	return "file input is unwritable"
 }
+
+const ErrorCodeFilesystemDestinationTemplateCanNotBeRendered = "FILESYSTEM_DESTINATION_TEMPLATE_CAN_NOT_BE_RENDERED"
+
+func NewFilesystemDestinationTemplateCanNotBeRenderedError(origErr error) *FilesystemDestinationTemplateCanNotBeRenderedError {
+	return &FilesystemDestinationTemplateCanNotBeRenderedError{
+		Data: &FilesystemDestinationTemplateCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type FilesystemDestinationTemplateCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *FilesystemDestinationTemplateCanNotBeRenderedErrorData
+}
+
+type FilesystemDestinationTemplateCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *FilesystemDestinationTemplateCanNotBeRenderedError) Code() string {
+	return ErrorCodeFilesystemDestinationTemplateCanNotBeRendered
+}
+
+func (e *FilesystemDestinationTemplateCanNotBeRenderedError) Error() string {
+	return "filesystem destination template can not be rendered"
+}
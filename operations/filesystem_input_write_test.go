@@ -143,3 +143,64 @@ func TestFilesystemInputWriteOperation_HandleMultipleFileWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestFilesystemInputWriteOperation_HandleRenamedFile(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	pf := files.NewProcessableFile("testdata/image_512x512.png")
+	pf.Rename("avatar.png")
+
+	operation := &FilesystemInputWriteOperation{
+		Params: &FilesystemInputWriteOperationParams{
+			Destination:         "/tmp/testdata",
+			UseOriginalFilename: true,
+		},
+	}
+	out, err := operation.Handle([]files.ProcessableFile{pf}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	exists, err := capyfs.FilesystemUtils.Exists("/tmp/testdata/avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatalf("renamed file has not been written to the destination")
+	}
+}
+
+func TestFilesystemInputWriteOperation_HandleRenamedAndConvertedFile(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	pf := files.NewProcessableFile("testdata/image_512x512.png")
+	pf.Rename("avatar.png")
+	// The file is converted to another format after it has been renamed.
+	pf.ReplaceFile("testdata/image_512x512.jpg")
+
+	operation := &FilesystemInputWriteOperation{
+		Params: &FilesystemInputWriteOperationParams{
+			Destination: "/tmp/testdata",
+		},
+	}
+	out, err := operation.Handle([]files.ProcessableFile{pf}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	exists, err := capyfs.FilesystemUtils.Exists("/tmp/testdata/avatar.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatalf("renamed file has not been written with the extension of the new format")
+	}
+}
@@ -0,0 +1,403 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"cloud.google.com/go/storage"
+	"context"
+	"errors"
+	"github.com/spf13/afero"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const ErrorCodeGCSUploadOperationConfiguration = "GCS_UPLOAD_OPERATION_CONFIGURATION"
+
+const MetadataKeyGCSUploadFileUrl = "gcs_upload.file_url"
+const MetadataKeyGCSUploadKey = "gcs_upload.key"
+const MetadataKeyGCSUploadPresignedUrl = "gcs_upload.presigned_url"
+const MetadataKeyGCSUploadPresignedUrlExpiresAt = "gcs_upload.presigned_url_expires_at"
+
+// MaxGCSPresignedUrlExpiration The maximum expiration time of the signed URL signed with V4 signing scheme.
+const MaxGCSPresignedUrlExpiration = 7 * 24 * time.Hour
+
+// GCSUploadAPI The interface to implement the GCS API calls that we need to upload the files to GCS.
+type GCSUploadAPI interface {
+	// UploadObject Uploads the object described by attrs. If chunkSize is zero, the default chunk size is used.
+	UploadObject(ctx context.Context, attrs *storage.ObjectAttrs, chunkSize int, body io.Reader) error
+}
+
+// GCSSignedURLAPI The interface to implement the signed URL generation that we need to generate
+// the signed URLs of the uploaded files.
+type GCSSignedURLAPI interface {
+	SignedURL(bucket, object string, opts *storage.SignedURLOptions) (string, error)
+}
+
+// gcsClient Implements GCSUploadAPI and GCSSignedURLAPI on top of the storage client.
+type gcsClient struct {
+	client *storage.Client
+}
+
+func (c *gcsClient) UploadObject(
+	ctx context.Context,
+	attrs *storage.ObjectAttrs,
+	chunkSize int,
+	body io.Reader,
+) error {
+	// The upload is aborted if the context is canceled before the writer is closed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := c.client.Bucket(attrs.Bucket).Object(attrs.Name).NewWriter(ctx)
+	w.ObjectAttrs = *attrs
+	if chunkSize > 0 {
+		w.ChunkSize = chunkSize
+	}
+
+	_, copyErr := io.Copy(w, body)
+	if copyErr != nil {
+		cancel()
+		_ = w.Close()
+
+		return copyErr
+	}
+
+	return w.Close()
+}
+
+func (c *gcsClient) SignedURL(bucket, object string, opts *storage.SignedURLOptions) (string, error) {
+	return c.client.Bucket(bucket).SignedURL(object, opts)
+}
+
+type GCSUploadOperation struct {
+	Name         string
+	Params       *GCSUploadOperationParams
+	UploadAPI    GCSUploadAPI
+	SignedURLAPI GCSSignedURLAPI
+}
+
+func (o *GCSUploadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *GCSUploadOperation) AllowConcurrency() bool {
+	return true
+}
+
+type GCSUploadOperationParams struct {
+	// CredentialsJson is the service account key file content. If empty, the application
+	// default credentials are used, or no credentials at all if the Endpoint is set.
+	CredentialsJson string
+	// Endpoint is the storage endpoint. Set it to use the emulator like fake-gcs-server.
+	// For example: http://localhost:4443
+	Endpoint string
+	Bucket   string
+	// ChunkSize is the size of the chunk in bytes the files are uploaded with.
+	// If zero, the default chunk size (16MiB) is used.
+	ChunkSize int64
+
+	// Key is the object name template. If empty, the generated filename is used as the name.
+	// See objectTemplateData for the available template vars.
+	Key string
+	// KeyTemplateVars are the additional vars available in the key and metadata templates.
+	KeyTemplateVars map[string]string
+	// ContentDisposition is the disposition type ("attachment" or "inline") of the
+	// Content-Disposition header that is sent along with the original filename.
+	// If empty, the header is not set.
+	ContentDisposition string
+	CacheControl       string
+	// PredefinedACL is the predefined ACL to apply to the object. For example: publicRead
+	PredefinedACL string
+	StorageClass  string
+	// Metadata is the user-defined object metadata. The values can be templated the same way as the key.
+	Metadata map[string]string
+
+	// PresignedUrlExpiration is how long the signed GET URL of the uploaded file is valid for.
+	// If zero, the signed URL is not generated. Useful for the private buckets.
+	PresignedUrlExpiration time.Duration
+}
+
+func (p *GCSUploadOperationParams) newClient() (*gcsClient, error) {
+	var opts []option.ClientOption
+	if p.CredentialsJson != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(p.CredentialsJson)))
+	} else if p.Endpoint != "" {
+		opts = append(opts, option.WithoutAuthentication())
+	}
+	if p.Endpoint != "" {
+		opts = append(opts, option.WithEndpoint(strings.TrimRight(p.Endpoint, "/")+"/storage/v1/"))
+	}
+
+	client, clientErr := storage.NewClient(context.Background(), opts...)
+	if clientErr != nil {
+		return nil, clientErr
+	}
+
+	return &gcsClient{client: client}, nil
+}
+
+// objectAttrs Builds the object attributes for the given processable file.
+func (p *GCSUploadOperationParams) objectAttrs(pf *files.ProcessableFile) (*storage.ObjectAttrs, error) {
+	data := objectTemplateData(pf, p.KeyTemplateVars)
+
+	key, keyErr := renderObjectKey(pf, p.Key, data)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	metadata, metadataErr := renderObjectTemplateMap(p.Metadata, data)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+
+	attrs := &storage.ObjectAttrs{
+		Bucket:        p.Bucket,
+		Name:          key,
+		CacheControl:  p.CacheControl,
+		PredefinedACL: p.PredefinedACL,
+		StorageClass:  p.StorageClass,
+		Metadata:      metadata,
+	}
+
+	mime, mimeErr := pf.Mime()
+	if mimeErr == nil && mime != nil {
+		attrs.ContentType = mime.String()
+	}
+
+	if p.ContentDisposition != "" {
+		attrs.ContentDisposition = objectContentDisposition(p.ContentDisposition, data)
+	}
+
+	return attrs, nil
+}
+
+// compileFileUrl Compiles the public URL of the object with the given name.
+func (p *GCSUploadOperationParams) compileFileUrl(key string) (string, error) {
+	endpoint := "https://storage.googleapis.com"
+	if p.Endpoint != "" {
+		endpoint = p.Endpoint
+	}
+
+	u, urlParseErr := url.Parse(endpoint)
+	if urlParseErr != nil {
+		return "", urlParseErr
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + p.Bucket + "/" + key
+
+	return u.String(), nil
+}
+
+func (o *GCSUploadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.UploadAPI == nil || (o.SignedURLAPI == nil && o.Params.PresignedUrlExpiration > 0) {
+		initErr := o.InitAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("GCS client can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeGCSUploadOperationConfiguration,
+				"GCS client can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		var pf = &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("GCS file upload has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			defer func(file afero.File) {
+				closeErr := file.Close()
+				if closeErr != nil {
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, closeErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"file can not be closed", pf, closeErr)
+					}
+				}
+			}(file)
+
+			attrs, attrsErr := o.Params.objectAttrs(pf)
+			if attrsErr != nil {
+				pf.SetFileProcessingError(
+					NewGCSObjectTemplateCanNotBeRenderedError(attrsErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, attrsErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"GCS object name or metadata template can not be rendered", pf, attrsErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			uploadErr := o.UploadAPI.UploadObject(context.TODO(), attrs, int(o.Params.ChunkSize), file)
+			if uploadErr != nil {
+				pf.SetFileProcessingError(
+					NewGCSFileUploadFailureError(uploadErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, uploadErr)
+				}
+
+				var apiErr *googleapi.Error
+				if errors.As(uploadErr, &apiErr) && apiErr.Code == http.StatusNotFound {
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not upload the file because GCS bucket does not exist", pf, uploadErr)
+					}
+				} else if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not upload the file because the request to GCS has failed", pf, uploadErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			fileUrl, fileUrlErr := o.Params.compileFileUrl(attrs.Name)
+			if fileUrlErr != nil {
+				pf.SetFileProcessingError(
+					NewGCSFileUrlCanNotBeRetrievedError(fileUrlErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileUrlErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not retrieve GCS file URL", pf, fileUrlErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyGCSUploadFileUrl, fileUrl)
+			pf.AddOperationMetadata(MetadataKeyGCSUploadKey, attrs.Name)
+
+			if o.Params.PresignedUrlExpiration > 0 {
+				expiresAt := time.Now().Add(o.Params.PresignedUrlExpiration)
+				signedUrl, signErr := o.SignedURLAPI.SignedURL(attrs.Bucket, attrs.Name, &storage.SignedURLOptions{
+					Scheme:  storage.SigningSchemeV4,
+					Method:  http.MethodGet,
+					Expires: expiresAt,
+				})
+				if signErr != nil {
+					pf.SetFileProcessingError(
+						NewGCSFileUrlCanNotBeRetrievedError(signErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, signErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not generate GCS file signed URL", pf, signErr)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				pf.AddOperationMetadata(MetadataKeyGCSUploadPresignedUrl, signedUrl)
+				pf.AddOperationMetadata(MetadataKeyGCSUploadPresignedUrlExpiresAt, expiresAt)
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("GCS file upload has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+// InitAPI Init GCSUploadAPI and GCSSignedURLAPI that we need to upload the files to GCS.
+func (o *GCSUploadOperation) InitAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	if o.UploadAPI == nil {
+		o.UploadAPI = client
+	}
+	if o.SignedURLAPI == nil {
+		o.SignedURLAPI = client
+	}
+
+	return nil
+}
+
+func (o *GCSUploadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *GCSUploadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,86 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeGCSFileUploadFailure = "FILE_GCS_FILE_UPLOAD_FAILURE"
+
+func NewGCSFileUploadFailureError(origErr error) *GCSFileUploadFailureError {
+	return &GCSFileUploadFailureError{
+		Data: &GCSFileUploadFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type GCSFileUploadFailureError struct {
+	files.FileProcessingError
+	Data *GCSFileUploadFailureErrorData
+}
+
+type GCSFileUploadFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *GCSFileUploadFailureError) Code() string {
+	return ErrorCodeGCSFileUploadFailure
+}
+
+func (e *GCSFileUploadFailureError) Error() string {
+	return "failed to upload file to GCS"
+}
+
+const ErrorCodeGCSFileUrlCanNotBeRetrieved = "FILE_GCS_FILE_URL_CAN_NOT_BE_RETRIEVED"
+
+func NewGCSFileUrlCanNotBeRetrievedError(origErr error) *GCSFileUrlCanNotBeRetrievedError {
+	return &GCSFileUrlCanNotBeRetrievedError{
+		Data: &GCSFileUrlCanNotBeRetrievedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type GCSFileUrlCanNotBeRetrievedError struct {
+	files.FileProcessingError
+	Data *GCSFileUrlCanNotBeRetrievedErrorData
+}
+
+type GCSFileUrlCanNotBeRetrievedErrorData struct {
+	OrigErr error
+}
+
+func (e *GCSFileUrlCanNotBeRetrievedError) Code() string {
+	return ErrorCodeGCSFileUrlCanNotBeRetrieved
+}
+
+func (e *GCSFileUrlCanNotBeRetrievedError) Error() string {
+	return "GCS file URL can not be retrieved"
+}
+
+const ErrorCodeGCSObjectTemplateCanNotBeRendered = "FILE_GCS_OBJECT_TEMPLATE_CAN_NOT_BE_RENDERED"
+
+func NewGCSObjectTemplateCanNotBeRenderedError(origErr error) *GCSObjectTemplateCanNotBeRenderedError {
+	return &GCSObjectTemplateCanNotBeRenderedError{
+		Data: &GCSObjectTemplateCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type GCSObjectTemplateCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *GCSObjectTemplateCanNotBeRenderedErrorData
+}
+
+type GCSObjectTemplateCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *GCSObjectTemplateCanNotBeRenderedError) Code() string {
+	return ErrorCodeGCSObjectTemplateCanNotBeRendered
+}
+
+func (e *GCSObjectTemplateCanNotBeRenderedError) Error() string {
+	return "GCS object name or metadata template can not be rendered"
+}
@@ -0,0 +1,257 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"cloud.google.com/go/storage"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeGCSStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	attrs   map[string]*storage.ObjectAttrs
+
+	uploadErr error
+}
+
+func newFakeGCSStorage() *fakeGCSStorage {
+	return &fakeGCSStorage{
+		objects: make(map[string][]byte),
+		attrs:   make(map[string]*storage.ObjectAttrs),
+	}
+}
+
+func (s *fakeGCSStorage) UploadObject(
+	ctx context.Context,
+	attrs *storage.ObjectAttrs,
+	chunkSize int,
+	body io.Reader,
+) error {
+	if s.uploadErr != nil {
+		return s.uploadErr
+	}
+
+	content, readErr := io.ReadAll(body)
+	if readErr != nil {
+		return readErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[attrs.Bucket+"/"+attrs.Name] = content
+	s.attrs[attrs.Bucket+"/"+attrs.Name] = attrs
+
+	return nil
+}
+
+func (s *fakeGCSStorage) SignedURL(bucket, object string, opts *storage.SignedURLOptions) (string, error) {
+	if opts.Method != http.MethodGet {
+		return "", errors.New("unexpected method")
+	}
+
+	return "https://storage.googleapis.com/" + bucket + "/" + object + "?X-Goog-Signature=abc", nil
+}
+
+func TestGCSUploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileContent, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processableFile := files.NewProcessableFile(file.Name())
+	processableFile.AddOperationMetadata("checksum", "abc")
+	in := []files.ProcessableFile{
+		processableFile,
+	}
+
+	expectedKey := "avatars/42/" + processableFile.GeneratedFilename()
+
+	gcsStorage := newFakeGCSStorage()
+	operation := &GCSUploadOperation{
+		Params: &GCSUploadOperationParams{
+			Endpoint: "http://localhost:4443",
+			Bucket:   "files",
+
+			Key: "/avatars/{{.UserID}}/{{.NanoID}}{{.Ext}}",
+			KeyTemplateVars: map[string]string{
+				"UserID": "42",
+			},
+			ContentDisposition: "inline",
+			CacheControl:       "max-age=3600",
+			StorageClass:       "NEARLINE",
+			Metadata: map[string]string{
+				"checksum": "{{.Metadata.checksum}}",
+			},
+		},
+		UploadAPI:    gcsStorage,
+		SignedURLAPI: gcsStorage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if bytes.Compare(gcsStorage.objects["files/"+expectedKey], fileContent) != 0 {
+		t.Fatalf("expected uploaded object content to be equal to file content")
+	}
+
+	attrs := gcsStorage.attrs["files/"+expectedKey]
+	if attrs.ContentType != "application/octet-stream" {
+		t.Fatalf("expected content type to be application/octet-stream, got %s", attrs.ContentType)
+	}
+
+	if attrs.ContentDisposition != "inline; filename=file_5kb.bin" {
+		t.Fatalf("expected content disposition to be inline; filename=file_5kb.bin, got %s", attrs.ContentDisposition)
+	}
+
+	if attrs.CacheControl != "max-age=3600" || attrs.StorageClass != "NEARLINE" {
+		t.Fatalf("unexpected cache control %s or storage class %s", attrs.CacheControl, attrs.StorageClass)
+	}
+
+	if attrs.Metadata["checksum"] != "abc" {
+		t.Fatalf("expected checksum metadata to be abc, got %s", attrs.Metadata["checksum"])
+	}
+
+	expectedUrl := "http://localhost:4443/files/" + expectedKey
+	if out[0].OperationMetadata[MetadataKeyGCSUploadFileUrl] != expectedUrl {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyGCSUploadFileUrl,
+			out[0].OperationMetadata[MetadataKeyGCSUploadFileUrl],
+			expectedUrl,
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyGCSUploadKey] != expectedKey {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyGCSUploadKey,
+			out[0].OperationMetadata[MetadataKeyGCSUploadKey],
+			expectedKey,
+		)
+	}
+
+	if _, ok := out[0].OperationMetadata[MetadataKeyGCSUploadPresignedUrl]; ok {
+		t.Fatalf("metadata key %s is set, want not set", MetadataKeyGCSUploadPresignedUrl)
+	}
+}
+
+func TestGCSUploadOperation_HandleFailedFilesUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	gcsStorage := newFakeGCSStorage()
+	gcsStorage.uploadErr = errors.New("connection reset by peer")
+
+	operation := &GCSUploadOperation{
+		Params: &GCSUploadOperationParams{
+			Bucket: "files",
+		},
+		UploadAPI: gcsStorage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError == nil {
+		t.Fatalf("FileProcessingError = nil, want !nil")
+	}
+
+	if out[0].FileProcessingError.Code() != ErrorCodeGCSFileUploadFailure {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want %s",
+			out[0].FileProcessingError.Code(),
+			ErrorCodeGCSFileUploadFailure,
+		)
+	}
+}
+
+func TestGCSUploadOperation_HandleSignedUrlGeneration(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	gcsStorage := newFakeGCSStorage()
+	operation := &GCSUploadOperation{
+		Params: &GCSUploadOperationParams{
+			Bucket:                 "files",
+			PresignedUrlExpiration: time.Hour,
+		},
+		UploadAPI:    gcsStorage,
+		SignedURLAPI: gcsStorage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	expectedUrl := "https://storage.googleapis.com/files/" + out[0].GeneratedFilename() + "?X-Goog-Signature=abc"
+	if out[0].OperationMetadata[MetadataKeyGCSUploadPresignedUrl] != expectedUrl {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyGCSUploadPresignedUrl,
+			out[0].OperationMetadata[MetadataKeyGCSUploadPresignedUrl],
+			expectedUrl,
+		)
+	}
+
+	expiresAt, ok := out[0].OperationMetadata[MetadataKeyGCSUploadPresignedUrlExpiresAt].(time.Time)
+	if !ok {
+		t.Fatalf("metadata key %s = nil, want !nil", MetadataKeyGCSUploadPresignedUrlExpiresAt)
+	}
+
+	if expiresAt.Before(time.Now().Add(59*time.Minute)) || expiresAt.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("metadata key %s = %s, want ~1h from now", MetadataKeyGCSUploadPresignedUrlExpiresAt, expiresAt)
+	}
+}
@@ -0,0 +1,385 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const MetadataKeyHTTPInputReadUrl = "http_input_read.url"
+const MetadataKeyHTTPInputReadContentType = "http_input_read.content_type"
+
+// HTTPInputReadOperation downloads the files from the URLs for further processing.
+type HTTPInputReadOperation struct {
+	Name   string
+	Params *HTTPInputReadOperationParams
+	// Req is the request to read the URLs from if RequestJsonField is set. Can be nil otherwise.
+	Req        *http.Request
+	HTTPClient *http.Client
+}
+
+type HTTPInputReadOperationParams struct {
+	// Urls are the URLs to download the files from.
+	Urls []string
+	// RequestJsonField is the dot-separated path of the request JSON body field that contains
+	// the URLs to download. The field can be either a string or an array of strings.
+	// For example: data.urls
+	RequestJsonField string
+	// Headers are the headers sent with each request to the allowed hosts. For example, to authenticate.
+	// They require AllowedHosts, since the URLs can come from the request: without it, the headers are
+	// never sent, so the credentials do not leak to an arbitrary host.
+	Headers map[string]string
+	// Timeout is the time limit of each download, including reading the response body.
+	Timeout time.Duration
+	// MaxFileSize is the maximum size of the downloaded file in bytes. 0 means no limit.
+	MaxFileSize int64
+	// MaxRedirects is the maximum number of redirects to follow. 0 means the redirects are not followed.
+	MaxRedirects int64
+	// AllowedSchemes are the URL schemes that can be downloaded from.
+	AllowedSchemes []string
+	// AllowedHosts are the hosts that can be downloaded from. The "*." prefix matches the subdomains,
+	// for example: *.example.com. Any host can be downloaded from if empty.
+	AllowedHosts []string
+	// AllowPrivateAddresses tells whether the files can be downloaded from the private, loopback
+	// and link-local addresses. Only for the trusted URLs, since it lets the URLs reach the internal network.
+	AllowPrivateAddresses bool
+}
+
+func (o *HTTPInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *HTTPInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *HTTPInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	o.initHTTPClient()
+
+	urls, urlsErr := o.urls()
+	if urlsErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(urlsErr)
+		}
+
+		return out, urlsErr
+	}
+
+	for _, fileUrl := range urls {
+		pf, downloadErr := o.download(fileUrl)
+		if downloadErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("file \"%s\" can not be downloaded: %w", fileUrl, downloadErr),
+				)
+			}
+
+			// Perhaps it makes sense to try to download other files.
+			continue
+		}
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("HTTP file read finished", pf)
+		}
+
+		out = append(out, *pf)
+	}
+
+	return out, nil
+}
+
+// urls Returns the configured URLs along with the ones from the request JSON body.
+func (o *HTTPInputReadOperation) urls() ([]string, error) {
+	urls := append([]string{}, o.Params.Urls...)
+
+	if o.Params.RequestJsonField == "" {
+		return urls, nil
+	}
+
+	if o.Req == nil || o.Req.Body == nil || o.Req.Body == http.NoBody {
+		return urls, nil
+	}
+
+	body, readErr := io.ReadAll(o.Req.Body)
+	if readErr != nil {
+		return urls, readErr
+	}
+	// Give the body back, so it can be read by the other operations.
+	o.Req.Body = io.NopCloser(bytes.NewReader(body))
+
+	var data any
+	unmarshalErr := json.Unmarshal(body, &data)
+	if unmarshalErr != nil {
+		return urls, fmt.Errorf("request body can not be parsed as JSON: %w", unmarshalErr)
+	}
+
+	fieldVal, ok := jsonFieldValue(data, o.Params.RequestJsonField)
+	if !ok {
+		return urls, nil
+	}
+
+	switch val := fieldVal.(type) {
+	case string:
+		urls = append(urls, val)
+	case []any:
+		for _, item := range val {
+			itemUrl, isString := item.(string)
+			if !isString {
+				return urls, fmt.Errorf("request field \"%s\" must contain only strings", o.Params.RequestJsonField)
+			}
+
+			urls = append(urls, itemUrl)
+		}
+	default:
+		return urls, fmt.Errorf(
+			"request field \"%s\" must be either a string or an array of strings", o.Params.RequestJsonField)
+	}
+
+	return urls, nil
+}
+
+// initHTTPClient Creates the client unless it is set already, and makes it check the redirects.
+func (o *HTTPInputReadOperation) initHTTPClient() {
+	if o.HTTPClient == nil {
+		o.HTTPClient = o.newHTTPClient()
+	}
+	o.HTTPClient.CheckRedirect = o.checkRedirect
+}
+
+// newHTTPClient Creates the client that checks the address it connects to after the host name is resolved,
+// so the host names resolving to the private addresses are refused too. The proxy is not used, since then
+// the proxy address would be checked instead.
+func (o *HTTPInputReadOperation) newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !o.Params.AllowPrivateAddresses {
+		dialer.Control = checkPublicAddress
+	}
+
+	return &http.Client{
+		Timeout: o.Params.Timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+// checkPublicAddress Refuses to connect to the private, loopback, link-local and other non-public addresses.
+func checkPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, splitErr := net.SplitHostPort(address)
+	if splitErr != nil {
+		return splitErr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("address \"%s\" is not an IP address", host)
+	}
+
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return fmt.Errorf("address \"%s\" is not allowed", host)
+	}
+
+	return nil
+}
+
+func (o *HTTPInputReadOperation) checkURL(u *url.URL) error {
+	schemeAllowed := false
+	for _, scheme := range o.Params.AllowedSchemes {
+		if u.Scheme == scheme {
+			schemeAllowed = true
+			break
+		}
+	}
+	if !schemeAllowed {
+		return fmt.Errorf("URL scheme \"%s\" is not allowed", u.Scheme)
+	}
+
+	if len(o.Params.AllowedHosts) > 0 && !o.isAllowedHost(u) {
+		return fmt.Errorf("URL host \"%s\" is not allowed", u.Hostname())
+	}
+
+	return nil
+}
+
+func (o *HTTPInputReadOperation) isAllowedHost(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	for _, allowedHost := range o.Params.AllowedHosts {
+		allowedHost = strings.ToLower(allowedHost)
+		if strings.HasPrefix(allowedHost, "*.") {
+			if strings.HasSuffix(host, allowedHost[1:]) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == allowedHost {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *HTTPInputReadOperation) checkRedirect(req *http.Request, via []*http.Request) error {
+	if int64(len(via)) > o.Params.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", o.Params.MaxRedirects)
+	}
+
+	// The client copies the headers of the first request to the redirected one, so they have to be
+	// dropped here not to leak the credentials to the other hosts.
+	if req.URL.Host != via[0].URL.Host || !o.isAllowedHost(req.URL) {
+		for name := range o.Params.Headers {
+			req.Header.Del(name)
+		}
+	}
+
+	return o.checkURL(req.URL)
+}
+
+func (o *HTTPInputReadOperation) download(fileUrl string) (*files.ProcessableFile, error) {
+	filename, resp, fetchErr := o.fetch(fileUrl)
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	pf := files.NewProcessableFile(filename)
+	if originalFilename := httpResponseFilename(resp); originalFilename != "" {
+		pf.Metadata.OriginalFilename = originalFilename
+	}
+
+	// The final URL, in case the request has been redirected.
+	pf.AddOperationMetadata(MetadataKeyHTTPInputReadUrl, resp.Request.URL.String())
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		pf.AddOperationMetadata(MetadataKeyHTTPInputReadContentType, contentType)
+	}
+
+	return &pf, nil
+}
+
+// fetch Downloads the file to the app tmp directory. Returns the name of the downloaded file along with
+// the response, the body of which is already closed.
+func (o *HTTPInputReadOperation) fetch(fileUrl string) (string, *http.Response, error) {
+	parsedUrl, parseErr := url.Parse(fileUrl)
+	if parseErr != nil {
+		return "", nil, parseErr
+	}
+
+	urlErr := o.checkURL(parsedUrl)
+	if urlErr != nil {
+		return "", nil, urlErr
+	}
+
+	req, reqErr := http.NewRequest(http.MethodGet, parsedUrl.String(), nil)
+	if reqErr != nil {
+		return "", nil, reqErr
+	}
+	// The headers are sent to the allowed hosts only, since the URLs can come from the request.
+	if o.isAllowedHost(parsedUrl) {
+		for name, value := range o.Params.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, respErr := o.HTTPClient.Do(req)
+	if respErr != nil {
+		return "", nil, respErr
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", nil, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	if o.Params.MaxFileSize > 0 && resp.ContentLength > o.Params.MaxFileSize {
+		return "", nil, fmt.Errorf("file size %d exceeds the limit of %d bytes", resp.ContentLength, o.Params.MaxFileSize)
+	}
+
+	var body io.Reader = resp.Body
+	if o.Params.MaxFileSize > 0 {
+		// Read one byte more to tell whether the file exceeds the limit.
+		body = io.LimitReader(resp.Body, o.Params.MaxFileSize+1)
+	}
+
+	file, fileWriteErr := capyutils.WriteReaderToAppTmpDirectory(body)
+	if fileWriteErr != nil {
+		return "", nil, fileWriteErr
+	}
+
+	if o.Params.MaxFileSize > 0 {
+		fileInfo, statErr := capyfs.Filesystem.Stat(file.Name())
+		if statErr != nil {
+			_ = capyfs.Filesystem.Remove(file.Name())
+
+			return "", nil, statErr
+		}
+
+		if fileInfo.Size() > o.Params.MaxFileSize {
+			_ = capyfs.Filesystem.Remove(file.Name())
+
+			return "", nil, fmt.Errorf("file size exceeds the limit of %d bytes", o.Params.MaxFileSize)
+		}
+	}
+
+	return file.Name(), resp, nil
+}
+
+// httpResponseFilename Derives the filename from the Content-Disposition header, or from the URL path
+// if the header is not set.
+func httpResponseFilename(resp *http.Response) string {
+	_, dispositionParams, parseErr := mime.ParseMediaType(resp.Header.Get("Content-Disposition"))
+	if parseErr == nil {
+		if filename := path.Base(dispositionParams["filename"]); filename != "." && filename != "/" {
+			return filename
+		}
+	}
+
+	filename := path.Base(resp.Request.URL.Path)
+	if filename == "." || filename == "/" {
+		return ""
+	}
+
+	return filename
+}
+
+func (o *HTTPInputReadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *HTTPInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
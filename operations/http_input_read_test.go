@@ -0,0 +1,273 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHTTPInputReadServer(t *testing.T, content []byte) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(content)
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="../invoice.bin"`)
+		_, _ = w.Write(content)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/download", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestHTTPInputReadOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	content, readErr := os.ReadFile("testdata/file_5kb.bin")
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	server := newTestHTTPInputReadServer(t, content)
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/upload",
+		strings.NewReader(`{"data": {"urls": ["`+server.URL+`/redirect", "`+server.URL+`/missing"]}}`),
+	)
+
+	operation := &HTTPInputReadOperation{
+		Name: "http_input_read",
+		Params: &HTTPInputReadOperationParams{
+			Urls:             []string{server.URL + "/files/report.pdf"},
+			RequestJsonField: "data.urls",
+			Headers: map[string]string{
+				"Authorization": "Bearer token",
+			},
+			Timeout:               10 * time.Second,
+			MaxRedirects:          1,
+			AllowedSchemes:        []string{"http", "https"},
+			AllowedHosts:          []string{"127.0.0.1"},
+			AllowPrivateAddresses: true,
+		},
+		Req: req,
+	}
+
+	errorCh := make(chan OperationError, 10)
+	out, err := operation.Handle([]files.ProcessableFile{}, errorCh, nil)
+	close(errorCh)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	expectedFiles := []struct {
+		originalFilename string
+		url              string
+	}{
+		{originalFilename: "report.pdf", url: server.URL + "/files/report.pdf"},
+		{originalFilename: "invoice.bin", url: server.URL + "/download"},
+	}
+	for i, expectedFile := range expectedFiles {
+		pf := out[i]
+
+		if pf.Metadata.OriginalFilename != expectedFile.originalFilename {
+			t.Fatalf(
+				"out[%d].Metadata.OriginalFilename = %s, want %s",
+				i, pf.Metadata.OriginalFilename, expectedFile.originalFilename)
+		}
+
+		if url := pf.OperationMetadata[MetadataKeyHTTPInputReadUrl]; url != expectedFile.url {
+			t.Fatalf("out[%d] url = %s, want %s", i, url, expectedFile.url)
+		}
+
+		if contentType := pf.OperationMetadata[MetadataKeyHTTPInputReadContentType]; contentType != "application/octet-stream" {
+			t.Fatalf("out[%d] content type = %s, want application/octet-stream", i, contentType)
+		}
+
+		downloaded, downloadedErr := afero.ReadFile(capyfs.Filesystem, pf.Name())
+		if downloadedErr != nil {
+			t.Fatal(downloadedErr)
+		}
+		if !bytes.Equal(downloaded, content) {
+			t.Fatalf("out[%d] content does not match", i)
+		}
+	}
+
+	errorCnt := 0
+	for range errorCh {
+		errorCnt++
+	}
+	if errorCnt != 1 {
+		t.Fatalf("errorCnt = %d, want 1", errorCnt)
+	}
+}
+
+func TestHTTPInputReadOperation_HandleLimits(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	content, readErr := os.ReadFile("testdata/file_5kb.bin")
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	server := newTestHTTPInputReadServer(t, content)
+
+	testCases := []struct {
+		name   string
+		url    string
+		params HTTPInputReadOperationParams
+	}{
+		{
+			name: "file is too large",
+			url:  server.URL + "/download",
+			params: HTTPInputReadOperationParams{
+				MaxFileSize:           1024,
+				AllowedSchemes:        []string{"http"},
+				AllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "redirects are not allowed",
+			url:  server.URL + "/redirect",
+			params: HTTPInputReadOperationParams{
+				AllowedSchemes:        []string{"http"},
+				AllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "scheme is not allowed",
+			url:  server.URL + "/download",
+			params: HTTPInputReadOperationParams{
+				AllowedSchemes:        []string{"https"},
+				AllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "host is not allowed",
+			url:  server.URL + "/download",
+			params: HTTPInputReadOperationParams{
+				AllowedSchemes:        []string{"http"},
+				AllowedHosts:          []string{"*.example.com"},
+				AllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "loopback address is not allowed",
+			url:  server.URL + "/download",
+			params: HTTPInputReadOperationParams{
+				AllowedSchemes: []string{"http"},
+			},
+		},
+		{
+			name: "link-local address is not allowed",
+			url:  "http://169.254.169.254/latest/meta-data/",
+			params: HTTPInputReadOperationParams{
+				Timeout:        10 * time.Second,
+				AllowedSchemes: []string{"http"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			params := testCase.params
+			params.Urls = []string{testCase.url}
+
+			operation := &HTTPInputReadOperation{
+				Name:   "http_input_read",
+				Params: &params,
+			}
+
+			errorCh := make(chan OperationError, 1)
+			out, err := operation.Handle([]files.ProcessableFile{}, errorCh, nil)
+			close(errorCh)
+			if err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+
+			if len(out) != 0 {
+				t.Fatalf("len(out) = %d, want 0", len(out))
+			}
+
+			if len(errorCh) != 1 {
+				t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+			}
+		})
+	}
+}
+
+func TestHTTPInputReadOperation_HandleCrossHostRedirect(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var leakedApiKey string
+	otherServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leakedApiKey = r.Header.Get("X-Api-Key")
+		_, _ = w.Write([]byte("content"))
+	}))
+	t.Cleanup(otherServer.Close)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		http.Redirect(w, r, otherServer.URL+"/report.pdf", http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+
+	operation := &HTTPInputReadOperation{
+		Name: "http_input_read",
+		Params: &HTTPInputReadOperationParams{
+			Urls: []string{server.URL + "/report.pdf"},
+			Headers: map[string]string{
+				"X-Api-Key": "secret",
+			},
+			MaxRedirects:          1,
+			AllowedSchemes:        []string{"http"},
+			AllowedHosts:          []string{"127.0.0.1"},
+			AllowPrivateAddresses: true,
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if leakedApiKey != "" {
+		t.Fatalf("X-Api-Key = %s, want it to be dropped on the redirect to the other host", leakedApiKey)
+	}
+}
@@ -0,0 +1,550 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	mimepkg "mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MetadataKeyHTTPUploadStatusCode = "http_upload.status_code"
+const MetadataKeyHTTPUploadFileUrl = "http_upload.file_url"
+
+// MetadataKeyPrefixHTTPUploadResponse The prefix of the metadata keys the response fields are stored under.
+const MetadataKeyPrefixHTTPUploadResponse = "http_upload.response."
+
+const (
+	HTTPUploadBodyTypeMultipart = "multipart"
+	HTTPUploadBodyTypeRaw       = "raw"
+)
+
+const (
+	HTTPUploadAuthTypeNone   = ""
+	HTTPUploadAuthTypeBearer = "bearer"
+	HTTPUploadAuthTypeBasic  = "basic"
+	HTTPUploadAuthTypeHMAC   = "hmac"
+)
+
+const (
+	HTTPUploadHMACAlgorithmSHA256 = "sha256"
+	HTTPUploadHMACAlgorithmSHA512 = "sha512"
+)
+
+// maxHTTPUploadResponseSize The maximum size of the response body that is read to extract the fields.
+const maxHTTPUploadResponseSize = 1 << 20
+
+type HTTPUploadOperation struct {
+	Name       string
+	Params     *HTTPUploadOperationParams
+	HTTPClient *http.Client
+}
+
+func (o *HTTPUploadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *HTTPUploadOperation) AllowConcurrency() bool {
+	return true
+}
+
+type HTTPUploadOperationParams struct {
+	// Url is the endpoint URL template. See objectTemplateData for the available template vars.
+	// For example: https://media.internal/users/{{.UserID}}/files
+	Url    string
+	Method string
+	// TemplateVars are the additional vars available in the URL, headers and fields templates.
+	TemplateVars map[string]string
+	// Headers are the request headers. The values can be templated the same way as the URL.
+	Headers map[string]string
+	Timeout time.Duration
+
+	// BodyType is how the file is sent. Possible values: "multipart", "raw".
+	BodyType string
+	// MultipartFieldName is the name of the form field the file is sent in.
+	MultipartFieldName string
+	// MultipartFields are the additional form fields. The values can be templated the same way as the URL.
+	MultipartFields map[string]string
+
+	AuthType      string
+	BearerToken   string
+	BasicUsername string
+	BasicPassword string
+	// HMACSecret is the secret the request body is signed with. The signature is sent
+	// in HMACHeader as "<algorithm>=<hex encoded signature>".
+	HMACSecret    string
+	HMACHeader    string
+	HMACAlgorithm string
+
+	// StatusErrorCodes maps the response status codes to the file processing error codes.
+	// The keys are either exact status codes ("413") or status classes ("4xx").
+	// The non-2xx statuses that are not mapped are reported with FILE_HTTP_UPLOAD_REJECTED code.
+	StatusErrorCodes map[string]string
+
+	// FileUrlField is the JSON response field path of the stored file URL. For example: data.url
+	FileUrlField string
+	// ResponseFields maps the metadata names to the JSON response field paths.
+	// The values are stored in the metadata with MetadataKeyPrefixHTTPUploadResponse prefix.
+	ResponseFields map[string]string
+}
+
+// statusErrorCode Returns the error code the response status code is mapped to.
+func (p *HTTPUploadOperationParams) statusErrorCode(statusCode int) string {
+	if code, ok := p.StatusErrorCodes[strconv.Itoa(statusCode)]; ok {
+		return code
+	}
+
+	if code, ok := p.StatusErrorCodes[fmt.Sprintf("%dxx", statusCode/100)]; ok {
+		return code
+	}
+
+	return ErrorCodeHTTPUploadRejected
+}
+
+// httpUploadBody Builds the request body. The body can be built more than once,
+// so it can be signed before it is sent.
+type httpUploadBody struct {
+	filename      string
+	prefix        []byte
+	suffix        []byte
+	contentType   string
+	contentLength int64
+}
+
+func (b *httpUploadBody) open() (io.ReadCloser, error) {
+	file, fileOpenErr := capyfs.Filesystem.Open(b.filename)
+	if fileOpenErr != nil {
+		return nil, fileOpenErr
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(b.prefix), file, bytes.NewReader(b.suffix)),
+		Closer: file,
+	}, nil
+}
+
+func (p *HTTPUploadOperationParams) newBody(pf *files.ProcessableFile, data map[string]any) (*httpUploadBody, error) {
+	fileInfo, statErr := capyfs.Filesystem.Stat(pf.Name())
+	if statErr != nil {
+		return nil, statErr
+	}
+
+	mimeType := "application/octet-stream"
+	mime, mimeErr := pf.Mime()
+	if mimeErr == nil && mime != nil {
+		mimeType = mime.String()
+	}
+
+	body := &httpUploadBody{
+		filename:      pf.Name(),
+		contentType:   mimeType,
+		contentLength: fileInfo.Size(),
+	}
+
+	if p.BodyType == HTTPUploadBodyTypeRaw {
+		return body, nil
+	}
+
+	fields, fieldsErr := renderObjectTemplateMap(p.MultipartFields, data)
+	if fieldsErr != nil {
+		return nil, fieldsErr
+	}
+
+	// The multipart envelope is written apart from the file content,
+	// so the content can be streamed, and the body length is known.
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	fieldNames := make([]string, 0, len(fields))
+	for name := range fields {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+	for _, name := range fieldNames {
+		writeErr := mw.WriteField(name, fields[name])
+		if writeErr != nil {
+			return nil, writeErr
+		}
+	}
+
+	partHeader := make(textproto.MIMEHeader)
+	partHeader.Set("Content-Disposition", mimepkg.FormatMediaType(
+		"form-data",
+		map[string]string{"name": p.MultipartFieldName, "filename": data["OriginalFilename"].(string)},
+	))
+	partHeader.Set("Content-Type", mimeType)
+	_, partErr := mw.CreatePart(partHeader)
+	if partErr != nil {
+		return nil, partErr
+	}
+
+	body.prefix = append([]byte(nil), buf.Bytes()...)
+	buf.Reset()
+
+	closeErr := mw.Close()
+	if closeErr != nil {
+		return nil, closeErr
+	}
+
+	body.suffix = append([]byte(nil), buf.Bytes()...)
+	body.contentType = mw.FormDataContentType()
+	body.contentLength += int64(len(body.prefix) + len(body.suffix))
+
+	return body, nil
+}
+
+func (p *HTTPUploadOperationParams) newHash() func() hash.Hash {
+	if p.HMACAlgorithm == HTTPUploadHMACAlgorithmSHA512 {
+		return sha512.New
+	}
+
+	return sha256.New
+}
+
+// sign Calculates the HMAC signature of the request body.
+func (p *HTTPUploadOperationParams) sign(body *httpUploadBody) (string, error) {
+	bodyReader, openErr := body.open()
+	if openErr != nil {
+		return "", openErr
+	}
+	defer bodyReader.Close()
+
+	mac := hmac.New(p.newHash(), []byte(p.HMACSecret))
+	_, copyErr := io.Copy(mac, bodyReader)
+	if copyErr != nil {
+		return "", copyErr
+	}
+
+	algorithm := p.HMACAlgorithm
+	if algorithm == "" {
+		algorithm = HTTPUploadHMACAlgorithmSHA256
+	}
+
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// newRequest Builds the upload request for the given processable file.
+func (p *HTTPUploadOperationParams) newRequest(pf *files.ProcessableFile) (*http.Request, error) {
+	data := objectTemplateData(pf, p.TemplateVars)
+
+	url, urlErr := renderObjectTemplate(p.Url, data)
+	if urlErr != nil {
+		return nil, urlErr
+	}
+
+	headers, headersErr := renderObjectTemplateMap(p.Headers, data)
+	if headersErr != nil {
+		return nil, headersErr
+	}
+
+	body, bodyErr := p.newBody(pf, data)
+	if bodyErr != nil {
+		return nil, bodyErr
+	}
+
+	req, reqErr := http.NewRequest(p.Method, url, nil)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	// The same as http.NewRequest does for the in-memory bodies, so the body can be sent again
+	// if the request is redirected with 307/308 or retried.
+	req.ContentLength = body.contentLength
+	req.GetBody = func() (io.ReadCloser, error) {
+		if body.contentLength == 0 {
+			return http.NoBody, nil
+		}
+
+		return body.open()
+	}
+	req.Header.Set("Content-Type", body.contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	switch p.AuthType {
+	case HTTPUploadAuthTypeBearer:
+		req.Header.Set("Authorization", "Bearer "+p.BearerToken)
+	case HTTPUploadAuthTypeBasic:
+		req.SetBasicAuth(p.BasicUsername, p.BasicPassword)
+	case HTTPUploadAuthTypeHMAC:
+		signature, signErr := p.sign(body)
+		if signErr != nil {
+			return nil, signErr
+		}
+
+		req.Header.Set(p.HMACHeader, signature)
+	}
+
+	return req, nil
+}
+
+// jsonFieldValue Retrieves the value of the field by its dot-separated path. The array
+// elements are addressed by their indexes. For example: data.files.0.url
+func jsonFieldValue(data any, path string) (any, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch val := data.(type) {
+		case map[string]any:
+			fieldVal, ok := val[segment]
+			if !ok {
+				return nil, false
+			}
+
+			data = fieldVal
+		case []any:
+			index, indexErr := strconv.Atoi(segment)
+			if indexErr != nil || index < 0 || index >= len(val) {
+				return nil, false
+			}
+
+			data = val[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return data, true
+}
+
+// extractResponseFields Stores the configured response fields in the file metadata.
+func (p *HTTPUploadOperationParams) extractResponseFields(pf *files.ProcessableFile, responseBody []byte) error {
+	if p.FileUrlField == "" && len(p.ResponseFields) == 0 {
+		return nil
+	}
+
+	var data any
+	unmarshalErr := json.Unmarshal(responseBody, &data)
+	if unmarshalErr != nil {
+		return unmarshalErr
+	}
+
+	if p.FileUrlField != "" {
+		fileUrl, ok := jsonFieldValue(data, p.FileUrlField)
+		if !ok {
+			return fmt.Errorf("response field \"%s\" is missing", p.FileUrlField)
+		}
+
+		fileUrlStr, ok := fileUrl.(string)
+		if !ok {
+			return fmt.Errorf("response field \"%s\" is not a string", p.FileUrlField)
+		}
+
+		pf.AddOperationMetadata(MetadataKeyHTTPUploadFileUrl, fileUrlStr)
+	}
+
+	for name, path := range p.ResponseFields {
+		val, ok := jsonFieldValue(data, path)
+		if !ok {
+			return fmt.Errorf("response field \"%s\" is missing", path)
+		}
+
+		pf.AddOperationMetadata(MetadataKeyPrefixHTTPUploadResponse+name, val)
+	}
+
+	return nil
+}
+
+func (o *HTTPUploadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{
+			Timeout: o.Params.Timeout,
+		}
+	}
+	o.HTTPClient.CheckRedirect = o.checkRedirect
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		var pf = &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("HTTP file upload has started", pf)
+			}
+
+			req, reqErr := o.Params.newRequest(pf)
+			if reqErr != nil {
+				pf.SetFileProcessingError(
+					NewHTTPUploadRequestCanNotBeBuiltError(reqErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, reqErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"HTTP upload request can not be built", pf, reqErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			bodyReader, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(bodyErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, bodyErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, bodyErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			// The client closes the request body.
+			req.Body = bodyReader
+
+			resp, respErr := o.HTTPClient.Do(req)
+			if respErr != nil {
+				pf.SetFileProcessingError(
+					NewHTTPUploadFailureError(respErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, respErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not upload the file because the HTTP request has failed", pf, respErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			responseBody, readErr := io.ReadAll(io.LimitReader(resp.Body, maxHTTPUploadResponseSize))
+			_ = resp.Body.Close()
+
+			pf.AddOperationMetadata(MetadataKeyHTTPUploadStatusCode, resp.StatusCode)
+
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				rejectedErr := errors.New(resp.Status)
+
+				pf.SetFileProcessingError(
+					NewHTTPUploadRejectedError(
+						rejectedErr,
+						resp.StatusCode,
+						o.Params.statusErrorCode(resp.StatusCode),
+					),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, rejectedErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"the file has been rejected by the HTTP endpoint", pf, rejectedErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if readErr == nil {
+				readErr = o.Params.extractResponseFields(pf, responseBody)
+			}
+			if readErr != nil {
+				pf.SetFileProcessingError(
+					NewHTTPUploadResponseCanNotBeParsedError(readErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, readErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"HTTP upload response can not be parsed", pf, readErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("HTTP file upload has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+// checkRedirect Drops the configured headers and the HMAC signature when the request is redirected to another host,
+// not to leak the credentials. The client drops the Authorization header itself.
+func (o *HTTPUploadOperation) checkRedirect(req *http.Request, via []*http.Request) error {
+	// The same limit as the default one of the client.
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	if req.URL.Host != via[0].URL.Host {
+		for name := range o.Params.Headers {
+			if http.CanonicalHeaderKey(name) == "Content-Type" {
+				continue
+			}
+
+			req.Header.Del(name)
+		}
+		if o.Params.HMACHeader != "" {
+			req.Header.Del(o.Params.HMACHeader)
+		}
+	}
+
+	return nil
+}
+
+func (o *HTTPUploadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *HTTPUploadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
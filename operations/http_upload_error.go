@@ -0,0 +1,123 @@
+package operations
+
+import (
+	"capyfile/files"
+	"fmt"
+)
+
+const ErrorCodeHTTPUploadFailure = "FILE_HTTP_UPLOAD_FAILURE"
+
+func NewHTTPUploadFailureError(origErr error) *HTTPUploadFailureError {
+	return &HTTPUploadFailureError{
+		Data: &HTTPUploadFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type HTTPUploadFailureError struct {
+	files.FileProcessingError
+	Data *HTTPUploadFailureErrorData
+}
+
+type HTTPUploadFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *HTTPUploadFailureError) Code() string {
+	return ErrorCodeHTTPUploadFailure
+}
+
+func (e *HTTPUploadFailureError) Error() string {
+	return "failed to upload file to HTTP endpoint"
+}
+
+const ErrorCodeHTTPUploadRequestCanNotBeBuilt = "FILE_HTTP_UPLOAD_REQUEST_CAN_NOT_BE_BUILT"
+
+func NewHTTPUploadRequestCanNotBeBuiltError(origErr error) *HTTPUploadRequestCanNotBeBuiltError {
+	return &HTTPUploadRequestCanNotBeBuiltError{
+		Data: &HTTPUploadRequestCanNotBeBuiltErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type HTTPUploadRequestCanNotBeBuiltError struct {
+	files.FileProcessingError
+	Data *HTTPUploadRequestCanNotBeBuiltErrorData
+}
+
+type HTTPUploadRequestCanNotBeBuiltErrorData struct {
+	OrigErr error
+}
+
+func (e *HTTPUploadRequestCanNotBeBuiltError) Code() string {
+	return ErrorCodeHTTPUploadRequestCanNotBeBuilt
+}
+
+func (e *HTTPUploadRequestCanNotBeBuiltError) Error() string {
+	return "HTTP upload request can not be built"
+}
+
+const ErrorCodeHTTPUploadResponseCanNotBeParsed = "FILE_HTTP_UPLOAD_RESPONSE_CAN_NOT_BE_PARSED"
+
+func NewHTTPUploadResponseCanNotBeParsedError(origErr error) *HTTPUploadResponseCanNotBeParsedError {
+	return &HTTPUploadResponseCanNotBeParsedError{
+		Data: &HTTPUploadResponseCanNotBeParsedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type HTTPUploadResponseCanNotBeParsedError struct {
+	files.FileProcessingError
+	Data *HTTPUploadResponseCanNotBeParsedErrorData
+}
+
+type HTTPUploadResponseCanNotBeParsedErrorData struct {
+	OrigErr error
+}
+
+func (e *HTTPUploadResponseCanNotBeParsedError) Code() string {
+	return ErrorCodeHTTPUploadResponseCanNotBeParsed
+}
+
+func (e *HTTPUploadResponseCanNotBeParsedError) Error() string {
+	return "HTTP upload response can not be parsed"
+}
+
+const ErrorCodeHTTPUploadRejected = "FILE_HTTP_UPLOAD_REJECTED"
+
+func NewHTTPUploadRejectedError(origErr error, statusCode int, code string) *HTTPUploadRejectedError {
+	return &HTTPUploadRejectedError{
+		Data: &HTTPUploadRejectedErrorData{
+			OrigErr:    origErr,
+			StatusCode: statusCode,
+			Code:       code,
+		},
+	}
+}
+
+type HTTPUploadRejectedError struct {
+	files.FileProcessingError
+	Data *HTTPUploadRejectedErrorData
+}
+
+type HTTPUploadRejectedErrorData struct {
+	OrigErr    error
+	StatusCode int
+	// Code is the error code the status code is mapped to.
+	Code string
+}
+
+func (e *HTTPUploadRejectedError) Code() string {
+	if e.Data.Code != "" {
+		return e.Data.Code
+	}
+
+	return ErrorCodeHTTPUploadRejected
+}
+
+func (e *HTTPUploadRejectedError) Error() string {
+	return fmt.Sprintf("file has been rejected by HTTP endpoint with status %d", e.Data.StatusCode)
+}
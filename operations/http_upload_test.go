@@ -0,0 +1,386 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPUploadOperation_HandleMultipartUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileContent, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/users/42/files" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("expected Authorization header to be Bearer token, got %s", r.Header.Get("Authorization"))
+		}
+
+		if r.Header.Get("X-Original-Filename") != "file_5kb.bin" {
+			t.Errorf("expected X-Original-Filename header to be file_5kb.bin, got %s", r.Header.Get("X-Original-Filename"))
+		}
+
+		if r.ContentLength <= int64(len(fileContent)) {
+			t.Errorf("expected content length to be greater than %d, got %d", len(fileContent), r.ContentLength)
+		}
+
+		if r.FormValue("folder") != "avatars" {
+			t.Errorf("expected folder field to be avatars, got %s", r.FormValue("folder"))
+		}
+
+		formFile, formFileHeader, formFileErr := r.FormFile("upload")
+		if formFileErr != nil {
+			t.Errorf("expected error to be nil, got %v", formFileErr)
+			return
+		}
+
+		if formFileHeader.Filename != "file_5kb.bin" {
+			t.Errorf("expected filename to be file_5kb.bin, got %s", formFileHeader.Filename)
+		}
+
+		uploadedContent, _ := io.ReadAll(formFile)
+		if bytes.Compare(uploadedContent, fileContent) != 0 {
+			t.Errorf("expected uploaded content to be equal to file content")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{"id":17,"files":[{"url":"https://cdn.internal/17.bin"}]}}`))
+	}))
+	defer server.Close()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &HTTPUploadOperation{
+		Params: &HTTPUploadOperationParams{
+			Url:    server.URL + "/users/{{.UserID}}/files",
+			Method: http.MethodPost,
+			TemplateVars: map[string]string{
+				"UserID": "42",
+			},
+			Headers: map[string]string{
+				"X-Original-Filename": "{{.OriginalFilename}}",
+			},
+			BodyType:           HTTPUploadBodyTypeMultipart,
+			MultipartFieldName: "upload",
+			MultipartFields: map[string]string{
+				"folder": "avatars",
+			},
+			AuthType:     HTTPUploadAuthTypeBearer,
+			BearerToken:  "token",
+			FileUrlField: "data.files.0.url",
+			ResponseFields: map[string]string{
+				"id": "data.id",
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyHTTPUploadFileUrl] != "https://cdn.internal/17.bin" {
+		t.Fatalf(
+			"metadata key %s = %v, want https://cdn.internal/17.bin",
+			MetadataKeyHTTPUploadFileUrl,
+			out[0].OperationMetadata[MetadataKeyHTTPUploadFileUrl],
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyPrefixHTTPUploadResponse+"id"] != float64(17) {
+		t.Fatalf(
+			"metadata key %s = %v, want 17",
+			MetadataKeyPrefixHTTPUploadResponse+"id",
+			out[0].OperationMetadata[MetadataKeyPrefixHTTPUploadResponse+"id"],
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyHTTPUploadStatusCode] != http.StatusCreated {
+		t.Fatalf(
+			"metadata key %s = %v, want %d",
+			MetadataKeyHTTPUploadStatusCode,
+			out[0].OperationMetadata[MetadataKeyHTTPUploadStatusCode],
+			http.StatusCreated,
+		)
+	}
+}
+
+func TestHTTPUploadOperation_HandleRawUploadWithHMACSignature(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("expected method to be PUT, got %s", r.Method)
+		}
+
+		if r.Header.Get("Content-Type") != "application/octet-stream" {
+			t.Errorf("expected content type to be application/octet-stream, got %s", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if len(body) != 5120 || r.ContentLength != 5120 {
+			t.Errorf("expected body length to be 5120, got %d (Content-Length %d)", len(body), r.ContentLength)
+		}
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		expectedSignature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if r.Header.Get("X-Signature") != expectedSignature {
+			t.Errorf("expected signature to be %s, got %s", expectedSignature, r.Header.Get("X-Signature"))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &HTTPUploadOperation{
+		Params: &HTTPUploadOperationParams{
+			Url:        server.URL + "/{{.GeneratedFilename}}",
+			Method:     http.MethodPut,
+			BodyType:   HTTPUploadBodyTypeRaw,
+			AuthType:   HTTPUploadAuthTypeHMAC,
+			HMACSecret: "secret",
+			HMACHeader: "X-Signature",
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+}
+
+func TestHTTPUploadOperation_HandleRedirectedUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		// The client must send the body again to the new location.
+		http.Redirect(w, r, "/storage/upload", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/storage/upload", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) != 5120 || r.ContentLength != 5120 {
+			t.Errorf("expected body length to be 5120, got %d (Content-Length %d)", len(body), r.ContentLength)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_5kb.bin"),
+	}
+
+	operation := &HTTPUploadOperation{
+		Params: &HTTPUploadOperationParams{
+			Url:      server.URL + "/upload",
+			Method:   http.MethodPut,
+			BodyType: HTTPUploadBodyTypeRaw,
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+}
+
+func TestHTTPUploadOperation_HandleCrossHostRedirectedUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var leakedHeaders []string
+	storageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"X-Api-Key", "X-Signature"} {
+			if r.Header.Get(name) != "" {
+				leakedHeaders = append(leakedHeaders, name)
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer storageServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, storageServer.URL+"/upload", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_5kb.bin"),
+	}
+
+	operation := &HTTPUploadOperation{
+		Params: &HTTPUploadOperationParams{
+			Url:      server.URL + "/upload",
+			Method:   http.MethodPut,
+			BodyType: HTTPUploadBodyTypeRaw,
+			Headers: map[string]string{
+				"X-Api-Key": "key",
+			},
+			AuthType:      HTTPUploadAuthTypeHMAC,
+			HMACSecret:    "secret",
+			HMACHeader:    "X-Signature",
+			HMACAlgorithm: HTTPUploadHMACAlgorithmSHA256,
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if len(leakedHeaders) != 0 {
+		t.Fatalf("headers %v are sent to the other host, want them to be dropped on the redirect", leakedHeaders)
+	}
+}
+
+func TestHTTPUploadOperation_HandleRejectedUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	testCases := []struct {
+		name         string
+		statusCode   int
+		expectedCode string
+	}{
+		{
+			name:         "exact status code",
+			statusCode:   http.StatusRequestEntityTooLarge,
+			expectedCode: "FILE_IS_TOO_LARGE_FOR_STORAGE",
+		},
+		{
+			name:         "status class",
+			statusCode:   http.StatusBadGateway,
+			expectedCode: "FILE_STORAGE_IS_UNAVAILABLE",
+		},
+		{
+			name:         "not mapped status code",
+			statusCode:   http.StatusForbidden,
+			expectedCode: ErrorCodeHTTPUploadRejected,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				if !ok || username != "capy" || password != "secret" {
+					t.Errorf("expected basic auth credentials capy:secret, got %s:%s", username, password)
+				}
+
+				w.WriteHeader(testCase.statusCode)
+			}))
+			defer server.Close()
+
+			in := []files.ProcessableFile{
+				files.NewProcessableFile(file.Name()),
+			}
+
+			operation := &HTTPUploadOperation{
+				Params: &HTTPUploadOperationParams{
+					Url:                server.URL,
+					Method:             http.MethodPost,
+					BodyType:           HTTPUploadBodyTypeMultipart,
+					MultipartFieldName: "file",
+					AuthType:           HTTPUploadAuthTypeBasic,
+					BasicUsername:      "capy",
+					BasicPassword:      "secret",
+					StatusErrorCodes: map[string]string{
+						"413": "FILE_IS_TOO_LARGE_FOR_STORAGE",
+						"5xx": "FILE_STORAGE_IS_UNAVAILABLE",
+					},
+				},
+			}
+			out, err := operation.Handle(in, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			if out[0].FileProcessingError == nil {
+				t.Fatalf("FileProcessingError = nil, want !nil")
+			}
+
+			if out[0].FileProcessingError.Code() != testCase.expectedCode {
+				t.Fatalf(
+					"FileProcessingError.Code() = %s, want %s",
+					out[0].FileProcessingError.Code(),
+					testCase.expectedCode,
+				)
+			}
+		})
+	}
+}
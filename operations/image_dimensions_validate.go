@@ -0,0 +1,181 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"sync"
+)
+
+const MetadataKeyImageDimensionsValidateWidth = "image_dimensions_validate.width"
+const MetadataKeyImageDimensionsValidateHeight = "image_dimensions_validate.height"
+
+type ImageDimensionsValidateOperation struct {
+	Name   string
+	Params *ImageDimensionsValidateOperationParams
+}
+
+type ImageDimensionsValidateOperationParams struct {
+	MinWidth  int64
+	MaxWidth  int64
+	MinHeight int64
+	MaxHeight int64
+
+	MinMegapixels float64
+	MaxMegapixels float64
+
+	// MinAspectRatio and MaxAspectRatio are the width to height ratio bounds.
+	MinAspectRatio float64
+	MaxAspectRatio float64
+
+	// Animated Whether the image must be animated. If nil, both animated and static images are allowed.
+	Animated *bool
+}
+
+// validate Returns the first violated constraint, or nil if the image is valid.
+func (p *ImageDimensionsValidateOperationParams) validate(info imageInfo) files.FileProcessingError {
+	width, height := int64(info.Width), int64(info.Height)
+
+	if p.MinWidth > 0 && width < p.MinWidth {
+		return NewImageWidthIsTooSmallError(p.MinWidth, width)
+	}
+	if p.MaxWidth > 0 && width > p.MaxWidth {
+		return NewImageWidthIsTooBigError(p.MaxWidth, width)
+	}
+	if p.MinHeight > 0 && height < p.MinHeight {
+		return NewImageHeightIsTooSmallError(p.MinHeight, height)
+	}
+	if p.MaxHeight > 0 && height > p.MaxHeight {
+		return NewImageHeightIsTooBigError(p.MaxHeight, height)
+	}
+
+	megapixels := info.megapixels()
+	if p.MinMegapixels > 0 && megapixels < p.MinMegapixels {
+		return NewImageResolutionIsTooLowError(p.MinMegapixels, megapixels)
+	}
+	if p.MaxMegapixels > 0 && megapixels > p.MaxMegapixels {
+		return NewImageResolutionIsTooHighError(p.MaxMegapixels, megapixels)
+	}
+
+	aspectRatio := info.aspectRatio()
+	if (p.MinAspectRatio > 0 && aspectRatio < p.MinAspectRatio) ||
+		(p.MaxAspectRatio > 0 && aspectRatio > p.MaxAspectRatio) {
+		return NewImageAspectRatioIsNotAllowedError(p.MinAspectRatio, p.MaxAspectRatio, aspectRatio)
+	}
+
+	if p.Animated != nil && *p.Animated != info.Animated {
+		if info.Animated {
+			return NewImageIsAnimatedError()
+		}
+
+		return NewImageIsNotAnimatedError()
+	}
+
+	return nil
+}
+
+func (o *ImageDimensionsValidateOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *ImageDimensionsValidateOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *ImageDimensionsValidateOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("image dimensions validation started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			info, infoErr := readImageInfo(file)
+			_ = file.Close()
+			if infoErr != nil {
+				// Most likely the file is not an image, or the image format is not supported.
+				pf.SetFileProcessingError(
+					NewImageDimensionsCanNotBeRetrievedError(infoErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(
+						"image dimensions can not be retrieved", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyImageDimensionsValidateWidth, info.Width)
+			pf.AddOperationMetadata(MetadataKeyImageDimensionsValidateHeight, info.Height)
+
+			validationErr := o.Params.validate(info)
+			if validationErr != nil {
+				pf.SetFileProcessingError(validationErr)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(validationErr.Error(), pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("image dimensions are valid", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *ImageDimensionsValidateOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *ImageDimensionsValidateOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
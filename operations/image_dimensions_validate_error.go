@@ -0,0 +1,272 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeImageDimensionsCanNotBeRetrieved = "IMAGE_DIMENSIONS_CAN_NOT_BE_RETRIEVED"
+const ErrorCodeImageWidthIsTooSmall = "IMAGE_WIDTH_IS_TOO_SMALL"
+const ErrorCodeImageWidthIsTooBig = "IMAGE_WIDTH_IS_TOO_BIG"
+const ErrorCodeImageHeightIsTooSmall = "IMAGE_HEIGHT_IS_TOO_SMALL"
+const ErrorCodeImageHeightIsTooBig = "IMAGE_HEIGHT_IS_TOO_BIG"
+const ErrorCodeImageResolutionIsTooLow = "IMAGE_RESOLUTION_IS_TOO_LOW"
+const ErrorCodeImageResolutionIsTooHigh = "IMAGE_RESOLUTION_IS_TOO_HIGH"
+const ErrorCodeImageAspectRatioIsNotAllowed = "IMAGE_ASPECT_RATIO_IS_NOT_ALLOWED"
+const ErrorCodeImageIsAnimated = "IMAGE_IS_ANIMATED"
+const ErrorCodeImageIsNotAnimated = "IMAGE_IS_NOT_ANIMATED"
+
+func NewImageDimensionsCanNotBeRetrievedError(origErr error) *ImageDimensionsCanNotBeRetrievedError {
+	return &ImageDimensionsCanNotBeRetrievedError{
+		Data: &ImageDimensionsCanNotBeRetrievedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type ImageDimensionsCanNotBeRetrievedError struct {
+	files.FileProcessingError
+
+	Data *ImageDimensionsCanNotBeRetrievedErrorData
+}
+
+type ImageDimensionsCanNotBeRetrievedErrorData struct {
+	OrigErr error
+}
+
+func (e *ImageDimensionsCanNotBeRetrievedError) Code() string {
+	return ErrorCodeImageDimensionsCanNotBeRetrieved
+}
+
+func (e *ImageDimensionsCanNotBeRetrievedError) Error() string {
+	return "image dimensions can not be retrieved"
+}
+
+func NewImageWidthIsTooSmallError(minWidth, givenWidth int64) *ImageWidthIsTooSmallError {
+	return &ImageWidthIsTooSmallError{
+		Data: &ImageWidthIsTooSmallErrorData{
+			MinWidth:   minWidth,
+			GivenWidth: givenWidth,
+		},
+	}
+}
+
+type ImageWidthIsTooSmallError struct {
+	files.FileProcessingError
+
+	Data *ImageWidthIsTooSmallErrorData
+}
+
+type ImageWidthIsTooSmallErrorData struct {
+	MinWidth   int64
+	GivenWidth int64
+}
+
+func (e *ImageWidthIsTooSmallError) Code() string {
+	return ErrorCodeImageWidthIsTooSmall
+}
+
+func (e *ImageWidthIsTooSmallError) Error() string {
+	return "image width is too small"
+}
+
+func NewImageWidthIsTooBigError(maxWidth, givenWidth int64) *ImageWidthIsTooBigError {
+	return &ImageWidthIsTooBigError{
+		Data: &ImageWidthIsTooBigErrorData{
+			MaxWidth:   maxWidth,
+			GivenWidth: givenWidth,
+		},
+	}
+}
+
+type ImageWidthIsTooBigError struct {
+	files.FileProcessingError
+
+	Data *ImageWidthIsTooBigErrorData
+}
+
+type ImageWidthIsTooBigErrorData struct {
+	MaxWidth   int64
+	GivenWidth int64
+}
+
+func (e *ImageWidthIsTooBigError) Code() string {
+	return ErrorCodeImageWidthIsTooBig
+}
+
+func (e *ImageWidthIsTooBigError) Error() string {
+	return "image width is too big"
+}
+
+func NewImageHeightIsTooSmallError(minHeight, givenHeight int64) *ImageHeightIsTooSmallError {
+	return &ImageHeightIsTooSmallError{
+		Data: &ImageHeightIsTooSmallErrorData{
+			MinHeight:   minHeight,
+			GivenHeight: givenHeight,
+		},
+	}
+}
+
+type ImageHeightIsTooSmallError struct {
+	files.FileProcessingError
+
+	Data *ImageHeightIsTooSmallErrorData
+}
+
+type ImageHeightIsTooSmallErrorData struct {
+	MinHeight   int64
+	GivenHeight int64
+}
+
+func (e *ImageHeightIsTooSmallError) Code() string {
+	return ErrorCodeImageHeightIsTooSmall
+}
+
+func (e *ImageHeightIsTooSmallError) Error() string {
+	return "image height is too small"
+}
+
+func NewImageHeightIsTooBigError(maxHeight, givenHeight int64) *ImageHeightIsTooBigError {
+	return &ImageHeightIsTooBigError{
+		Data: &ImageHeightIsTooBigErrorData{
+			MaxHeight:   maxHeight,
+			GivenHeight: givenHeight,
+		},
+	}
+}
+
+type ImageHeightIsTooBigError struct {
+	files.FileProcessingError
+
+	Data *ImageHeightIsTooBigErrorData
+}
+
+type ImageHeightIsTooBigErrorData struct {
+	MaxHeight   int64
+	GivenHeight int64
+}
+
+func (e *ImageHeightIsTooBigError) Code() string {
+	return ErrorCodeImageHeightIsTooBig
+}
+
+func (e *ImageHeightIsTooBigError) Error() string {
+	return "image height is too big"
+}
+
+func NewImageResolutionIsTooLowError(minMegapixels, givenMegapixels float64) *ImageResolutionIsTooLowError {
+	return &ImageResolutionIsTooLowError{
+		Data: &ImageResolutionIsTooLowErrorData{
+			MinMegapixels:   minMegapixels,
+			GivenMegapixels: givenMegapixels,
+		},
+	}
+}
+
+type ImageResolutionIsTooLowError struct {
+	files.FileProcessingError
+
+	Data *ImageResolutionIsTooLowErrorData
+}
+
+type ImageResolutionIsTooLowErrorData struct {
+	MinMegapixels   float64
+	GivenMegapixels float64
+}
+
+func (e *ImageResolutionIsTooLowError) Code() string {
+	return ErrorCodeImageResolutionIsTooLow
+}
+
+func (e *ImageResolutionIsTooLowError) Error() string {
+	return "image resolution is too low"
+}
+
+func NewImageResolutionIsTooHighError(maxMegapixels, givenMegapixels float64) *ImageResolutionIsTooHighError {
+	return &ImageResolutionIsTooHighError{
+		Data: &ImageResolutionIsTooHighErrorData{
+			MaxMegapixels:   maxMegapixels,
+			GivenMegapixels: givenMegapixels,
+		},
+	}
+}
+
+type ImageResolutionIsTooHighError struct {
+	files.FileProcessingError
+
+	Data *ImageResolutionIsTooHighErrorData
+}
+
+type ImageResolutionIsTooHighErrorData struct {
+	MaxMegapixels   float64
+	GivenMegapixels float64
+}
+
+func (e *ImageResolutionIsTooHighError) Code() string {
+	return ErrorCodeImageResolutionIsTooHigh
+}
+
+func (e *ImageResolutionIsTooHighError) Error() string {
+	return "image resolution is too high"
+}
+
+func NewImageAspectRatioIsNotAllowedError(minAspectRatio, maxAspectRatio, givenAspectRatio float64) *ImageAspectRatioIsNotAllowedError {
+	return &ImageAspectRatioIsNotAllowedError{
+		Data: &ImageAspectRatioIsNotAllowedErrorData{
+			MinAspectRatio:   minAspectRatio,
+			MaxAspectRatio:   maxAspectRatio,
+			GivenAspectRatio: givenAspectRatio,
+		},
+	}
+}
+
+type ImageAspectRatioIsNotAllowedError struct {
+	files.FileProcessingError
+
+	Data *ImageAspectRatioIsNotAllowedErrorData
+}
+
+type ImageAspectRatioIsNotAllowedErrorData struct {
+	MinAspectRatio   float64
+	MaxAspectRatio   float64
+	GivenAspectRatio float64
+}
+
+func (e *ImageAspectRatioIsNotAllowedError) Code() string {
+	return ErrorCodeImageAspectRatioIsNotAllowed
+}
+
+func (e *ImageAspectRatioIsNotAllowedError) Error() string {
+	return "image aspect ratio is not allowed"
+}
+
+func NewImageIsAnimatedError() *ImageIsAnimatedError {
+	return &ImageIsAnimatedError{}
+}
+
+type ImageIsAnimatedError struct {
+	files.FileProcessingError
+}
+
+func (e *ImageIsAnimatedError) Code() string {
+	return ErrorCodeImageIsAnimated
+}
+
+func (e *ImageIsAnimatedError) Error() string {
+	return "animated image is not allowed"
+}
+
+func NewImageIsNotAnimatedError() *ImageIsNotAnimatedError {
+	return &ImageIsNotAnimatedError{}
+}
+
+type ImageIsNotAnimatedError struct {
+	files.FileProcessingError
+}
+
+func (e *ImageIsNotAnimatedError) Code() string {
+	return ErrorCodeImageIsNotAnimated
+}
+
+func (e *ImageIsNotAnimatedError) Error() string {
+	return "image must be animated"
+}
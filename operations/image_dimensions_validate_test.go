@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+func TestImageDimensionsValidateOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/image_dimensions_validate", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	var wideImage bytes.Buffer
+	encodeErr := png.Encode(&wideImage, image.NewRGBA(image.Rect(0, 0, 600, 300)))
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+	writeErr := afero.WriteFile(
+		capyfs.Filesystem, "/tmp/image_dimensions_validate/wide.png", wideImage.Bytes(), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	var animatedImage bytes.Buffer
+	encodeErr = gif.EncodeAll(&animatedImage, &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 400, 400), palette.Plan9),
+			image.NewPaletted(image.Rect(0, 0, 400, 400), palette.Plan9),
+		},
+		Delay: []int{10, 10},
+	})
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+	writeErr = afero.WriteFile(
+		capyfs.Filesystem, "/tmp/image_dimensions_validate/animated.gif", animatedImage.Bytes(), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	notAnimated := false
+
+	testCases := []struct {
+		name              string
+		filename          string
+		params            *ImageDimensionsValidateOperationParams
+		expectedErrorCode string
+	}{
+		{
+			name:     "square jpeg avatar",
+			filename: "testdata/image_512x512.jpg",
+			params: &ImageDimensionsValidateOperationParams{
+				MinWidth:       256,
+				MinHeight:      256,
+				MinAspectRatio: 0.9,
+				MaxAspectRatio: 1.1,
+				Animated:       &notAnimated,
+			},
+		},
+		{
+			name:     "webp is too small",
+			filename: "testdata/image_512x512.webp",
+			params: &ImageDimensionsValidateOperationParams{
+				MinWidth: 1024,
+			},
+			expectedErrorCode: ErrorCodeImageWidthIsTooSmall,
+		},
+		{
+			name:     "png is too tall",
+			filename: "testdata/image_512x512.png",
+			params: &ImageDimensionsValidateOperationParams{
+				MaxHeight: 500,
+			},
+			expectedErrorCode: ErrorCodeImageHeightIsTooBig,
+		},
+		{
+			name:     "resolution is too high",
+			filename: "testdata/image_512x512.png",
+			params: &ImageDimensionsValidateOperationParams{
+				MaxMegapixels: 0.2,
+			},
+			expectedErrorCode: ErrorCodeImageResolutionIsTooHigh,
+		},
+		{
+			name:     "image is not square",
+			filename: "/tmp/image_dimensions_validate/wide.png",
+			params: &ImageDimensionsValidateOperationParams{
+				MinAspectRatio: 0.9,
+				MaxAspectRatio: 1.1,
+			},
+			expectedErrorCode: ErrorCodeImageAspectRatioIsNotAllowed,
+		},
+		{
+			name:     "animated image is not allowed",
+			filename: "/tmp/image_dimensions_validate/animated.gif",
+			params: &ImageDimensionsValidateOperationParams{
+				MinWidth: 256,
+				Animated: &notAnimated,
+			},
+			expectedErrorCode: ErrorCodeImageIsAnimated,
+		},
+		{
+			name:     "file is not an image",
+			filename: "testdata/file_1kb.bin",
+			params: &ImageDimensionsValidateOperationParams{
+				MinWidth: 256,
+			},
+			expectedErrorCode: ErrorCodeImageDimensionsCanNotBeRetrieved,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			operation := &ImageDimensionsValidateOperation{
+				Name:   "image_dimensions_validate",
+				Params: testCase.params,
+			}
+
+			out, err := operation.Handle(
+				[]files.ProcessableFile{files.NewProcessableFile(testCase.filename)}, nil, nil)
+			if err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			var errorCode string
+			if out[0].HasFileProcessingError() {
+				errorCode = out[0].FileProcessingError.Code()
+			}
+			if errorCode != testCase.expectedErrorCode {
+				t.Fatalf("FileProcessingError.Code() = %s, want %s", errorCode, testCase.expectedErrorCode)
+			}
+		})
+	}
+}
+
+func TestReadImageInfo_StaticGIF(t *testing.T) {
+	var staticImage bytes.Buffer
+	encodeErr := gif.Encode(&staticImage, image.NewPaletted(image.Rect(0, 0, 20, 10), palette.Plan9), nil)
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+
+	info, infoErr := readImageInfo(bytes.NewReader(staticImage.Bytes()))
+	if infoErr != nil {
+		t.Fatal(infoErr)
+	}
+
+	if info.Width != 20 || info.Height != 10 || info.Animated {
+		t.Fatalf("info = %+v, want 20x10 static image", info)
+	}
+}
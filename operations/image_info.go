@@ -0,0 +1,197 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+type imageInfo struct {
+	Format   string
+	Width    int
+	Height   int
+	Animated bool
+}
+
+func (i imageInfo) megapixels() float64 {
+	return float64(i.Width) * float64(i.Height) / 1_000_000
+}
+
+func (i imageInfo) aspectRatio() float64 {
+	return float64(i.Width) / float64(i.Height)
+}
+
+// readImageInfo Reads the image dimensions and whether the image is animated. Only the image
+// header is read, so the image is never decoded. Supported formats: JPEG, PNG, GIF, WebP.
+func readImageInfo(r io.ReadSeeker) (imageInfo, error) {
+	config, format, decodeErr := image.DecodeConfig(bufio.NewReader(r))
+	if decodeErr != nil {
+		return imageInfo{}, decodeErr
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return imageInfo{}, errors.New("image has no dimensions")
+	}
+
+	info := imageInfo{
+		Format: format,
+		Width:  config.Width,
+		Height: config.Height,
+	}
+
+	_, seekErr := r.Seek(0, io.SeekStart)
+	if seekErr != nil {
+		return imageInfo{}, seekErr
+	}
+
+	var animatedErr error
+	switch format {
+	case "gif":
+		info.Animated, animatedErr = isAnimatedGIF(bufio.NewReader(r))
+	case "png":
+		info.Animated, animatedErr = isAnimatedPNG(bufio.NewReader(r))
+	case "webp":
+		info.Animated, animatedErr = isAnimatedWebP(r)
+	}
+	if animatedErr != nil {
+		return imageInfo{}, animatedErr
+	}
+
+	return info, nil
+}
+
+// isAnimatedGIF Walks through the GIF blocks and reports whether there is more than one frame.
+func isAnimatedGIF(r *bufio.Reader) (bool, error) {
+	var header [13]byte
+	_, readErr := io.ReadFull(r, header[:])
+	if readErr != nil {
+		return false, readErr
+	}
+
+	// The global color table follows the logical screen descriptor.
+	if header[10]&0x80 != 0 {
+		_, discardErr := r.Discard(3 * (1 << (int(header[10]&0x07) + 1)))
+		if discardErr != nil {
+			return false, discardErr
+		}
+	}
+
+	frames := 0
+	for {
+		blockType, blockErr := r.ReadByte()
+		if blockErr == io.EOF && frames == 1 {
+			// Some encoders omit the trailer.
+			return false, nil
+		}
+		if blockErr != nil {
+			return false, blockErr
+		}
+
+		switch blockType {
+		case 0x21: // extension
+			_, labelErr := r.ReadByte()
+			if labelErr != nil {
+				return false, labelErr
+			}
+		case 0x2C: // image descriptor
+			frames++
+			if frames > 1 {
+				return true, nil
+			}
+
+			var descriptor [9]byte
+			_, descriptorErr := io.ReadFull(r, descriptor[:])
+			if descriptorErr != nil {
+				return false, descriptorErr
+			}
+			if descriptor[8]&0x80 != 0 {
+				_, discardErr := r.Discard(3 * (1 << (int(descriptor[8]&0x07) + 1)))
+				if discardErr != nil {
+					return false, discardErr
+				}
+			}
+
+			// LZW minimum code size.
+			_, discardErr := r.Discard(1)
+			if discardErr != nil {
+				return false, discardErr
+			}
+		case 0x3B: // trailer
+			return false, nil
+		default:
+			return false, errors.New("gif: unknown block type")
+		}
+
+		skipErr := skipGIFSubBlocks(r)
+		if skipErr != nil {
+			return false, skipErr
+		}
+	}
+}
+
+func skipGIFSubBlocks(r *bufio.Reader) error {
+	for {
+		size, sizeErr := r.ReadByte()
+		if sizeErr != nil {
+			return sizeErr
+		}
+		if size == 0 {
+			return nil
+		}
+
+		_, discardErr := r.Discard(int(size))
+		if discardErr != nil {
+			return discardErr
+		}
+	}
+}
+
+// isAnimatedPNG Reports whether the PNG is APNG. The animation control chunk must come before the image data.
+func isAnimatedPNG(r *bufio.Reader) (bool, error) {
+	_, discardErr := r.Discard(8)
+	if discardErr != nil {
+		return false, discardErr
+	}
+
+	var chunkHeader [8]byte
+	for {
+		_, readErr := io.ReadFull(r, chunkHeader[:])
+		if readErr != nil {
+			return false, readErr
+		}
+
+		switch string(chunkHeader[4:]) {
+		case "acTL":
+			return true, nil
+		case "IDAT", "IEND":
+			return false, nil
+		}
+
+		// The chunk data is followed by CRC.
+		_, discardErr = r.Discard(int(binary.BigEndian.Uint32(chunkHeader[:4])) + 4)
+		if discardErr != nil {
+			return false, discardErr
+		}
+	}
+}
+
+// isAnimatedWebP Reports whether the WebP has the animation flag set in the extended format header.
+func isAnimatedWebP(r io.Reader) (bool, error) {
+	var header [21]byte
+	_, readErr := io.ReadFull(r, header[:])
+	if readErr != nil {
+		return false, readErr
+	}
+
+	if !bytes.Equal(header[12:16], []byte("VP8X")) {
+		return false, nil
+	}
+
+	return header[20]&0x02 != 0, nil
+}
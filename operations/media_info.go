@@ -0,0 +1,137 @@
+package operations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mediaInfo The media file properties reported by ffprobe.
+type mediaInfo struct {
+	Format   string
+	Duration time.Duration
+	// Bitrate is the overall bitrate in bits per second.
+	Bitrate int64
+
+	// VideoCodec, Width and Height are of the first video stream.
+	VideoCodec string
+	Width      int64
+	Height     int64
+	// VideoCodecs are the codecs of all the video streams.
+	VideoCodecs []string
+
+	// AudioCodec is the codec of the first audio stream.
+	AudioCodec string
+	// AudioCodecs are the codecs of all the audio streams.
+	AudioCodecs []string
+}
+
+// mediaPlaylistFormats The ffprobe formats of the files that refer to the other files or URLs, like the HLS
+// playlists. Such files are refused, since ffmpeg would read the files they refer to.
+var mediaPlaylistFormats = []string{"hls", "concat", "dash", "webm_dash_manifest", "imf", "sdp", "rtsp", "rtp"}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType   string `json:"codec_type"`
+		CodecName   string `json:"codec_name"`
+		Width       int64  `json:"width"`
+		Height      int64  `json:"height"`
+		Duration    string `json:"duration"`
+		Disposition struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// readMediaInfo Reads the media file properties with ffprobe.
+func readMediaInfo(name string, timeout time.Duration) (mediaInfo, error) {
+	output, runErr := runExternalTool(
+		timeout,
+		"ffprobe",
+		"-v", "error",
+		// Only the given file can be read, not the URLs it may refer to.
+		"-protocol_whitelist", "file",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		name,
+	)
+	if runErr != nil {
+		return mediaInfo{}, runErr
+	}
+
+	return parseFFprobeOutput(output)
+}
+
+func parseFFprobeOutput(output []byte) (mediaInfo, error) {
+	var probe ffprobeOutput
+	if unmarshalErr := json.Unmarshal(output, &probe); unmarshalErr != nil {
+		return mediaInfo{}, unmarshalErr
+	}
+
+	// The format name can be the list of the format aliases, like "mov,mp4,m4a".
+	for _, format := range strings.Split(probe.Format.FormatName, ",") {
+		if containsString(mediaPlaylistFormats, format) {
+			return mediaInfo{}, fmt.Errorf("%s format is not allowed, since it refers to other files", format)
+		}
+	}
+
+	info := mediaInfo{
+		Format:   probe.Format.FormatName,
+		Duration: parseFFprobeDuration(probe.Format.Duration),
+	}
+	info.Bitrate, _ = strconv.ParseInt(probe.Format.BitRate, 10, 64)
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			// The cover art of the audio files is the video stream too, but it is not the video.
+			if stream.Disposition.AttachedPic == 1 {
+				continue
+			}
+
+			if info.VideoCodec == "" {
+				info.VideoCodec = stream.CodecName
+				info.Width = stream.Width
+				info.Height = stream.Height
+			}
+			info.VideoCodecs = append(info.VideoCodecs, stream.CodecName)
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = stream.CodecName
+			}
+			info.AudioCodecs = append(info.AudioCodecs, stream.CodecName)
+		default:
+			continue
+		}
+
+		// Some containers report the duration of the streams only.
+		if streamDuration := parseFFprobeDuration(stream.Duration); streamDuration > info.Duration {
+			info.Duration = streamDuration
+		}
+	}
+
+	if info.VideoCodec == "" && info.AudioCodec == "" {
+		return mediaInfo{}, errors.New("file has no video or audio streams")
+	}
+
+	return info, nil
+}
+
+// parseFFprobeDuration Parses the duration in seconds, like "12.345000". Returns zero if the duration is unknown.
+func parseFFprobeDuration(s string) time.Duration {
+	seconds, parseErr := strconv.ParseFloat(s, 64)
+	if parseErr != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/files"
+	"errors"
+	"sync"
+	"time"
+)
+
+const ErrorCodeMediaProbeOperationConfiguration = "MEDIA_PROBE_OPERATION_CONFIGURATION"
+
+const (
+	MetadataKeyMediaProbeFormat     = "media_probe.format"
+	MetadataKeyMediaProbeDuration   = "media_probe.duration"
+	MetadataKeyMediaProbeBitrate    = "media_probe.bitrate"
+	MetadataKeyMediaProbeVideoCodec = "media_probe.video_codec"
+	MetadataKeyMediaProbeWidth      = "media_probe.width"
+	MetadataKeyMediaProbeHeight     = "media_probe.height"
+	MetadataKeyMediaProbeAudioCodec = "media_probe.audio_codec"
+)
+
+type MediaProbeOperation struct {
+	Name   string
+	Params *MediaProbeOperationParams
+}
+
+type MediaProbeOperationParams struct {
+	// Timeout is the time limit of ffprobe run. 0 means no limit.
+	Timeout time.Duration
+}
+
+// addMediaInfoMetadata Writes the media file properties to the metadata. The properties of the streams
+// the file does not have are not written.
+func addMediaInfoMetadata(pf *files.ProcessableFile, info mediaInfo) {
+	pf.AddOperationMetadata(MetadataKeyMediaProbeFormat, info.Format)
+	pf.AddOperationMetadata(MetadataKeyMediaProbeDuration, info.Duration.Seconds())
+	pf.AddOperationMetadata(MetadataKeyMediaProbeBitrate, info.Bitrate)
+
+	if info.VideoCodec != "" {
+		pf.AddOperationMetadata(MetadataKeyMediaProbeVideoCodec, info.VideoCodec)
+		pf.AddOperationMetadata(MetadataKeyMediaProbeWidth, info.Width)
+		pf.AddOperationMetadata(MetadataKeyMediaProbeHeight, info.Height)
+	}
+	if info.AudioCodec != "" {
+		pf.AddOperationMetadata(MetadataKeyMediaProbeAudioCodec, info.AudioCodec)
+	}
+}
+
+func (o *MediaProbeOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *MediaProbeOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *MediaProbeOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// First it makes sense to check if the ffprobe is installed.
+	missingTool, lookPathErr := lookPathExternalTools([]string{"ffprobe"})
+	if lookPathErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New(missingTool + " is not installed"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeMediaProbeOperationConfiguration,
+			missingTool+" is not installed",
+			lookPathErr,
+		)
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("media probe has started", pf)
+			}
+
+			info, infoErr := readMediaInfo(pf.Name(), o.Params.Timeout)
+			if infoErr != nil {
+				// Most likely the file is not a media file, or it is corrupted.
+				pf.SetFileProcessingError(
+					NewMediaCanNotBeProbedError(infoErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("media can not be probed", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			addMediaInfoMetadata(pf, info)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("media probe has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *MediaProbeOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *MediaProbeOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeMediaCanNotBeProbed = "MEDIA_CAN_NOT_BE_PROBED"
+
+func NewMediaCanNotBeProbedError(origErr error) *MediaCanNotBeProbedError {
+	return &MediaCanNotBeProbedError{
+		Data: &MediaCanNotBeProbedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type MediaCanNotBeProbedError struct {
+	files.FileProcessingError
+	Data *MediaCanNotBeProbedErrorData
+}
+
+type MediaCanNotBeProbedErrorData struct {
+	OrigErr error
+}
+
+func (e *MediaCanNotBeProbedError) Code() string {
+	return ErrorCodeMediaCanNotBeProbed
+}
+
+func (e *MediaCanNotBeProbedError) Error() string {
+	return "media can not be probed"
+}
@@ -0,0 +1,130 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFFprobeOutput(t *testing.T) {
+	output := []byte(`{
+		"streams": [
+			{"codec_type": "video", "codec_name": "mjpeg", "width": 300, "height": 300, "disposition": {"attached_pic": 1}},
+			{"codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720, "duration": "12.500000", "disposition": {"attached_pic": 0}},
+			{"codec_type": "audio", "codec_name": "aac", "duration": "12.480000"},
+			{"codec_type": "audio", "codec_name": "ac3", "duration": "12.480000"},
+			{"codec_type": "subtitle", "codec_name": "mov_text", "duration": "20.000000"}
+		],
+		"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "bit_rate": "1048576"}
+	}`)
+
+	info, err := parseFFprobeOutput(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := mediaInfo{
+		Format:      "mov,mp4,m4a,3gp,3g2,mj2",
+		Duration:    12500 * time.Millisecond,
+		Bitrate:     1048576,
+		VideoCodec:  "h264",
+		Width:       1280,
+		Height:      720,
+		VideoCodecs: []string{"h264"},
+		AudioCodec:  "aac",
+		AudioCodecs: []string{"aac", "ac3"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Fatalf("parseFFprobeOutput() = %+v, want %+v", info, expected)
+	}
+
+	_, err = parseFFprobeOutput([]byte(`{"streams": [], "format": {"format_name": "tty"}}`))
+	if err == nil {
+		t.Fatalf("parseFFprobeOutput() error = nil, want error")
+	}
+
+	_, err = parseFFprobeOutput([]byte(`{
+		"streams": [{"codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720}],
+		"format": {"format_name": "hls", "duration": "30.000000"}
+	}`))
+	if err == nil {
+		t.Fatalf("parseFFprobeOutput() error = nil, want the playlist to be refused")
+	}
+}
+
+func TestMediaProbeOperation_Handle(t *testing.T) {
+	if _, lookPathErr := exec.LookPath("ffprobe"); lookPathErr != nil {
+		t.Skip("ffprobe is not installed")
+	}
+
+	capyfs.InitOsFilesystem()
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/audio_1s.wav"),
+		files.NewProcessableFile("testdata/file_1kb.bin"),
+	}
+
+	operation := &MediaProbeOperation{
+		Name:   "media_probe",
+		Params: &MediaProbeOperationParams{},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	for _, pf := range out {
+		if pf.Name() == "testdata/file_1kb.bin" {
+			if pf.FileProcessingError == nil || pf.FileProcessingError.Code() != ErrorCodeMediaCanNotBeProbed {
+				t.Fatalf("FileProcessingError = %v, want %s", pf.FileProcessingError, ErrorCodeMediaCanNotBeProbed)
+			}
+
+			continue
+		}
+
+		if pf.FileProcessingError != nil {
+			t.Fatalf("FileProcessingError.Code() = %s, want nil", pf.FileProcessingError.Code())
+		}
+		if pf.OperationMetadata[MetadataKeyMediaProbeAudioCodec] != "pcm_s16le" {
+			t.Fatalf(
+				"OperationMetadata[%s] = %v, want pcm_s16le",
+				MetadataKeyMediaProbeAudioCodec,
+				pf.OperationMetadata[MetadataKeyMediaProbeAudioCodec],
+			)
+		}
+		if pf.OperationMetadata[MetadataKeyMediaProbeDuration] != float64(1) {
+			t.Fatalf(
+				"OperationMetadata[%s] = %v, want 1",
+				MetadataKeyMediaProbeDuration,
+				pf.OperationMetadata[MetadataKeyMediaProbeDuration],
+			)
+		}
+		if _, ok := pf.OperationMetadata[MetadataKeyMediaProbeVideoCodec]; ok {
+			t.Fatalf("OperationMetadata[%s] is set for the audio file", MetadataKeyMediaProbeVideoCodec)
+		}
+	}
+}
+
+func TestMediaProbeOperation_HandleWithoutFFprobe(t *testing.T) {
+	if _, lookPathErr := exec.LookPath("ffprobe"); lookPathErr == nil {
+		t.Skip("ffprobe is installed")
+	}
+
+	operation := &MediaProbeOperation{
+		Name:   "media_probe",
+		Params: &MediaProbeOperationParams{},
+	}
+	_, err := operation.Handle([]files.ProcessableFile{
+		files.NewProcessableFile("testdata/audio_1s.wav"),
+	}, nil, nil)
+	if err == nil {
+		t.Fatalf("Handle() error = nil, want the configuration error")
+	}
+}
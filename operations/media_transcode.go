@@ -0,0 +1,229 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"errors"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const ErrorCodeMediaTranscodeOperationConfiguration = "MEDIA_TRANSCODE_OPERATION_CONFIGURATION"
+
+const MetadataKeyMediaTranscodePreset = "media_transcode.preset"
+
+const (
+	// MediaTranscodePresetWebMP4 H.264 video and AAC audio in MP4 that can be played by the browsers.
+	MediaTranscodePresetWebMP4 = "web_mp4"
+	// MediaTranscodePresetAudioMP3 MP3 audio without video.
+	MediaTranscodePresetAudioMP3 = "audio_mp3"
+	// MediaTranscodePresetPosterFrame JPEG image of the single video frame.
+	MediaTranscodePresetPosterFrame = "poster_frame"
+)
+
+type MediaTranscodeOperation struct {
+	Name   string
+	Params *MediaTranscodeOperationParams
+}
+
+type MediaTranscodeOperationParams struct {
+	// Preset is the transcoding preset. Possible values: "web_mp4", "audio_mp3", "poster_frame".
+	Preset string
+	// PosterFrameTime is the time of the poster frame. If the video is shorter, its middle frame is taken.
+	PosterFrameTime time.Duration
+	// Timeout is the time limit of each ffprobe and ffmpeg run. 0 means no limit.
+	Timeout time.Duration
+}
+
+// ffmpegArgs Returns the ffmpeg arguments for the preset.
+func (p *MediaTranscodeOperationParams) ffmpegArgs(name, outputName string, info mediaInfo) ([]string, error) {
+	args := []string{
+		"-nostdin", "-hide_banner", "-v", "error", "-y",
+		// Only the given file can be read, not the URLs it may refer to.
+		"-protocol_whitelist", "file",
+	}
+
+	switch p.Preset {
+	case MediaTranscodePresetWebMP4:
+		args = append(args,
+			"-i", name,
+			"-map", "0:v:0?",
+			"-map", "0:a:0?",
+			"-c:v", "libx264",
+			"-preset", "medium",
+			"-crf", "23",
+			// The yuv420p pixel format needs the even dimensions.
+			"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2",
+			"-pix_fmt", "yuv420p",
+			"-c:a", "aac",
+			"-b:a", "128k",
+			"-movflags", "+faststart",
+			"-f", "mp4",
+		)
+	case MediaTranscodePresetAudioMP3:
+		args = append(args,
+			"-i", name,
+			"-map", "0:a:0",
+			"-vn",
+			"-c:a", "libmp3lame",
+			"-q:a", "2",
+			"-f", "mp3",
+		)
+	case MediaTranscodePresetPosterFrame:
+		frameTime := p.PosterFrameTime
+		if info.Duration > 0 && frameTime >= info.Duration {
+			frameTime = info.Duration / 2
+		}
+
+		args = append(args,
+			// Seeking before the input is fast, because it is done by the keyframes.
+			"-ss", strconv.FormatFloat(frameTime.Seconds(), 'f', 3, 64),
+			"-i", name,
+			"-map", "0:v:0",
+			"-frames:v", "1",
+			"-q:v", "2",
+			"-f", "image2",
+		)
+	default:
+		return nil, errors.New("unsupported media transcode preset " + p.Preset)
+	}
+
+	return append(args, outputName), nil
+}
+
+func (p *MediaTranscodeOperationParams) outputExtension() string {
+	switch p.Preset {
+	case MediaTranscodePresetAudioMP3:
+		return ".mp3"
+	case MediaTranscodePresetPosterFrame:
+		return ".jpg"
+	}
+
+	return ".mp4"
+}
+
+// transcode Transcodes the file and returns the name of the transcoded file.
+func (p *MediaTranscodeOperationParams) transcode(name string) (string, error) {
+	tmpDir, tmpDirErr := capyutils.GetAppTmpDirectory()
+	if tmpDirErr != nil {
+		return "", tmpDirErr
+	}
+
+	// The file is probed first to refuse the playlists and other formats that refer to the other files.
+	info, infoErr := readMediaInfo(name, p.Timeout)
+	if infoErr != nil {
+		return "", infoErr
+	}
+
+	outputName := filepath.Join(tmpDir, gonanoid.Must()+p.outputExtension())
+
+	args, argsErr := p.ffmpegArgs(name, outputName, info)
+	if argsErr != nil {
+		return "", argsErr
+	}
+
+	_, runErr := runExternalTool(p.Timeout, "ffmpeg", args...)
+	if runErr != nil {
+		_ = capyfs.Filesystem.Remove(outputName)
+
+		return "", runErr
+	}
+
+	return outputName, nil
+}
+
+func (o *MediaTranscodeOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *MediaTranscodeOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *MediaTranscodeOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// First it makes sense to check if the ffmpeg is installed. The ffprobe is needed to probe the file first.
+	missingTool, lookPathErr := lookPathExternalTools([]string{"ffmpeg", "ffprobe"})
+	if lookPathErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New(missingTool + " is not installed"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeMediaTranscodeOperationConfiguration,
+			missingTool+" is not installed",
+			lookPathErr,
+		)
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("media transcoding has started", pf)
+			}
+
+			transcodedName, transcodeErr := o.Params.transcode(pf.Name())
+			if transcodeErr != nil {
+				pf.SetFileProcessingError(
+					NewMediaTranscodeFailureError(transcodeErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, transcodeErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("media can not be transcoded", pf, transcodeErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.ReplaceFile(transcodedName)
+			pf.AddOperationMetadata(MetadataKeyMediaTranscodePreset, o.Params.Preset)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("media transcoding has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *MediaTranscodeOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *MediaTranscodeOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
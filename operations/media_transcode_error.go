@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeMediaTranscodeFailure = "MEDIA_TRANSCODE_FAILURE"
+
+func NewMediaTranscodeFailureError(origErr error) *MediaTranscodeFailureError {
+	return &MediaTranscodeFailureError{
+		Data: &MediaTranscodeFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type MediaTranscodeFailureError struct {
+	files.FileProcessingError
+	Data *MediaTranscodeFailureErrorData
+}
+
+type MediaTranscodeFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *MediaTranscodeFailureError) Code() string {
+	return ErrorCodeMediaTranscodeFailure
+}
+
+func (e *MediaTranscodeFailureError) Error() string {
+	return "media can not be transcoded"
+}
@@ -0,0 +1,131 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"os/exec"
+	"testing"
+)
+
+func TestMediaTranscodeOperation_Handle(t *testing.T) {
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, lookPathErr := exec.LookPath(tool); lookPathErr != nil {
+			t.Skip(tool + " is not installed")
+		}
+	}
+
+	capyfs.InitOsFilesystem()
+
+	testCases := []struct {
+		name              string
+		filename          string
+		preset            string
+		expectedMime      string
+		expectedErrorCode string
+	}{
+		{
+			name:         "audio mp3",
+			filename:     "testdata/audio_1s.wav",
+			preset:       MediaTranscodePresetAudioMP3,
+			expectedMime: "audio/mpeg",
+		},
+		{
+			name:         "web mp4",
+			filename:     "testdata/audio_1s.wav",
+			preset:       MediaTranscodePresetWebMP4,
+			expectedMime: "video/mp4",
+		},
+		{
+			name:              "poster frame of the file without video",
+			filename:          "testdata/audio_1s.wav",
+			preset:            MediaTranscodePresetPosterFrame,
+			expectedErrorCode: ErrorCodeMediaTranscodeFailure,
+		},
+		{
+			name:              "not media",
+			filename:          "testdata/file_1kb.bin",
+			preset:            MediaTranscodePresetAudioMP3,
+			expectedErrorCode: ErrorCodeMediaTranscodeFailure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := []files.ProcessableFile{
+				files.NewProcessableFile(tc.filename),
+			}
+
+			operation := &MediaTranscodeOperation{
+				Name: "media_transcode",
+				Params: &MediaTranscodeOperationParams{
+					Preset: tc.preset,
+				},
+			}
+			out, err := operation.Handle(in, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			if tc.expectedErrorCode != "" {
+				if out[0].FileProcessingError == nil {
+					t.Fatalf("FileProcessingError = nil, want %s", tc.expectedErrorCode)
+				}
+				if out[0].FileProcessingError.Code() != tc.expectedErrorCode {
+					t.Fatalf(
+						"FileProcessingError.Code() = %s, want %s",
+						out[0].FileProcessingError.Code(),
+						tc.expectedErrorCode,
+					)
+				}
+
+				return
+			}
+
+			if out[0].FileProcessingError != nil {
+				t.Fatalf("FileProcessingError.Code() = %s, want nil", out[0].FileProcessingError.Code())
+			}
+
+			mime, mimeErr := out[0].Mime()
+			if mimeErr != nil {
+				t.Fatal(mimeErr)
+			}
+			if mime.String() != tc.expectedMime {
+				t.Fatalf("Mime() = %s, want %s", mime.String(), tc.expectedMime)
+			}
+
+			if out[0].OperationMetadata[MetadataKeyMediaTranscodePreset] != tc.preset {
+				t.Fatalf(
+					"OperationMetadata[%s] = %v, want %s",
+					MetadataKeyMediaTranscodePreset,
+					out[0].OperationMetadata[MetadataKeyMediaTranscodePreset],
+					tc.preset,
+				)
+			}
+
+			_ = out[0].Remove()
+		})
+	}
+}
+
+func TestMediaTranscodeOperation_HandleWithoutFFmpeg(t *testing.T) {
+	if _, lookPathErr := exec.LookPath("ffmpeg"); lookPathErr == nil {
+		t.Skip("ffmpeg is installed")
+	}
+
+	operation := &MediaTranscodeOperation{
+		Name: "media_transcode",
+		Params: &MediaTranscodeOperationParams{
+			Preset: MediaTranscodePresetAudioMP3,
+		},
+	}
+	_, err := operation.Handle([]files.ProcessableFile{
+		files.NewProcessableFile("testdata/audio_1s.wav"),
+	}, nil, nil)
+	if err == nil {
+		t.Fatalf("Handle() error = nil, want the configuration error")
+	}
+}
@@ -0,0 +1,163 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/files"
+	"errors"
+	"sync"
+	"time"
+)
+
+const ErrorCodeMediaValidateOperationConfiguration = "MEDIA_VALIDATE_OPERATION_CONFIGURATION"
+
+const (
+	MediaStreamTypeVideo = "video"
+	MediaStreamTypeAudio = "audio"
+)
+
+type MediaValidateOperation struct {
+	Name   string
+	Params *MediaValidateOperationParams
+}
+
+type MediaValidateOperationParams struct {
+	// MaxDuration is the max duration of the media.
+	MaxDuration time.Duration
+	// AllowUnknownDuration tells whether the files with unknown duration pass the MaxDuration check.
+	// Such files are refused otherwise.
+	AllowUnknownDuration bool
+	// AllowedVideoCodecs are the allowed ffprobe video codec names. For example: "h264", "vp9".
+	// The codecs of all the video streams are checked. The files without video stream are not checked.
+	AllowedVideoCodecs []string
+	// AllowedAudioCodecs are the allowed ffprobe audio codec names. For example: "aac", "mp3".
+	// The codecs of all the audio streams are checked. The files without audio stream are not checked.
+	AllowedAudioCodecs []string
+	// Timeout is the time limit of ffprobe run. 0 means no limit.
+	Timeout time.Duration
+}
+
+func (p *MediaValidateOperationParams) validate(info mediaInfo) files.FileProcessingError {
+	if p.MaxDuration > 0 {
+		if info.Duration == 0 && !p.AllowUnknownDuration {
+			return NewMediaDurationIsUnknownError(p.MaxDuration)
+		}
+		if info.Duration > p.MaxDuration {
+			return NewMediaDurationIsTooLongError(p.MaxDuration, info.Duration)
+		}
+	}
+	if len(p.AllowedVideoCodecs) > 0 {
+		for _, codec := range info.VideoCodecs {
+			if !containsString(p.AllowedVideoCodecs, codec) {
+				return NewMediaCodecIsNotAllowedError(MediaStreamTypeVideo, p.AllowedVideoCodecs, codec)
+			}
+		}
+	}
+	if len(p.AllowedAudioCodecs) > 0 {
+		for _, codec := range info.AudioCodecs {
+			if !containsString(p.AllowedAudioCodecs, codec) {
+				return NewMediaCodecIsNotAllowedError(MediaStreamTypeAudio, p.AllowedAudioCodecs, codec)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *MediaValidateOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *MediaValidateOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *MediaValidateOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// First it makes sense to check if the ffprobe is installed.
+	missingTool, lookPathErr := lookPathExternalTools([]string{"ffprobe"})
+	if lookPathErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New(missingTool + " is not installed"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeMediaValidateOperationConfiguration,
+			missingTool+" is not installed",
+			lookPathErr,
+		)
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("media validation started", pf)
+			}
+
+			info, infoErr := readMediaInfo(pf.Name(), o.Params.Timeout)
+			if infoErr != nil {
+				// Most likely the file is not a media file, or it is corrupted.
+				pf.SetFileProcessingError(
+					NewMediaCanNotBeProbedError(infoErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("media can not be probed", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			validationErr := o.Params.validate(info)
+			if validationErr != nil {
+				pf.SetFileProcessingError(validationErr)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(validationErr.Error(), pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("media is valid", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *MediaValidateOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *MediaValidateOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,99 @@
+package operations
+
+import (
+	"capyfile/files"
+	"time"
+)
+
+const ErrorCodeMediaDurationIsTooLong = "MEDIA_DURATION_IS_TOO_LONG"
+const ErrorCodeMediaDurationIsUnknown = "MEDIA_DURATION_IS_UNKNOWN"
+const ErrorCodeMediaCodecIsNotAllowed = "MEDIA_CODEC_IS_NOT_ALLOWED"
+
+func NewMediaDurationIsTooLongError(maxDuration, givenDuration time.Duration) *MediaDurationIsTooLongError {
+	return &MediaDurationIsTooLongError{
+		Data: &MediaDurationIsTooLongErrorData{
+			MaxDuration:   maxDuration,
+			GivenDuration: givenDuration,
+		},
+	}
+}
+
+type MediaDurationIsTooLongError struct {
+	files.FileProcessingError
+
+	Data *MediaDurationIsTooLongErrorData
+}
+
+type MediaDurationIsTooLongErrorData struct {
+	MaxDuration   time.Duration
+	GivenDuration time.Duration
+}
+
+func (e *MediaDurationIsTooLongError) Code() string {
+	return ErrorCodeMediaDurationIsTooLong
+}
+
+func (e *MediaDurationIsTooLongError) Error() string {
+	return "media duration is too long"
+}
+
+func NewMediaDurationIsUnknownError(maxDuration time.Duration) *MediaDurationIsUnknownError {
+	return &MediaDurationIsUnknownError{
+		Data: &MediaDurationIsUnknownErrorData{
+			MaxDuration: maxDuration,
+		},
+	}
+}
+
+type MediaDurationIsUnknownError struct {
+	files.FileProcessingError
+
+	Data *MediaDurationIsUnknownErrorData
+}
+
+type MediaDurationIsUnknownErrorData struct {
+	MaxDuration time.Duration
+}
+
+func (e *MediaDurationIsUnknownError) Code() string {
+	return ErrorCodeMediaDurationIsUnknown
+}
+
+func (e *MediaDurationIsUnknownError) Error() string {
+	return "media duration is unknown"
+}
+
+func NewMediaCodecIsNotAllowedError(
+	streamType string,
+	allowedCodecs []string,
+	givenCodec string,
+) *MediaCodecIsNotAllowedError {
+	return &MediaCodecIsNotAllowedError{
+		Data: &MediaCodecIsNotAllowedErrorData{
+			StreamType:    streamType,
+			AllowedCodecs: allowedCodecs,
+			GivenCodec:    givenCodec,
+		},
+	}
+}
+
+type MediaCodecIsNotAllowedError struct {
+	files.FileProcessingError
+
+	Data *MediaCodecIsNotAllowedErrorData
+}
+
+type MediaCodecIsNotAllowedErrorData struct {
+	// StreamType is the type of the stream with the codec. Possible values: "video", "audio".
+	StreamType    string
+	AllowedCodecs []string
+	GivenCodec    string
+}
+
+func (e *MediaCodecIsNotAllowedError) Code() string {
+	return ErrorCodeMediaCodecIsNotAllowed
+}
+
+func (e *MediaCodecIsNotAllowedError) Error() string {
+	return "media codec is not allowed"
+}
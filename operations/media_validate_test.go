@@ -0,0 +1,125 @@
+package operations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMediaValidateOperationParams_Validate(t *testing.T) {
+	info := mediaInfo{
+		Format:      "matroska,webm",
+		Duration:    90 * time.Second,
+		VideoCodec:  "vp9",
+		Width:       1920,
+		Height:      1080,
+		VideoCodecs: []string{"vp9"},
+		AudioCodec:  "opus",
+		AudioCodecs: []string{"opus", "aac"},
+	}
+
+	testCases := []struct {
+		name              string
+		params            *MediaValidateOperationParams
+		info              mediaInfo
+		expectedErrorCode string
+	}{
+		{
+			name: "valid media",
+			params: &MediaValidateOperationParams{
+				MaxDuration:        2 * time.Minute,
+				AllowedVideoCodecs: []string{"h264", "vp9"},
+				AllowedAudioCodecs: []string{"aac", "opus"},
+			},
+			info: info,
+		},
+		{
+			name: "duration is unknown",
+			params: &MediaValidateOperationParams{
+				MaxDuration: time.Minute,
+			},
+			info: mediaInfo{
+				Format:      "mpegts",
+				VideoCodec:  "h264",
+				VideoCodecs: []string{"h264"},
+			},
+			expectedErrorCode: ErrorCodeMediaDurationIsUnknown,
+		},
+		{
+			name: "unknown duration is allowed",
+			params: &MediaValidateOperationParams{
+				MaxDuration:          time.Minute,
+				AllowUnknownDuration: true,
+			},
+			info: mediaInfo{
+				Format:      "mpegts",
+				VideoCodec:  "h264",
+				VideoCodecs: []string{"h264"},
+			},
+		},
+		{
+			name: "duration is too long",
+			params: &MediaValidateOperationParams{
+				MaxDuration: time.Minute,
+			},
+			info:              info,
+			expectedErrorCode: ErrorCodeMediaDurationIsTooLong,
+		},
+		{
+			name: "video codec is not allowed",
+			params: &MediaValidateOperationParams{
+				AllowedVideoCodecs: []string{"h264"},
+			},
+			info:              info,
+			expectedErrorCode: ErrorCodeMediaCodecIsNotAllowed,
+		},
+		{
+			name: "audio codec is not allowed",
+			params: &MediaValidateOperationParams{
+				AllowedAudioCodecs: []string{"aac", "mp3"},
+			},
+			info:              info,
+			expectedErrorCode: ErrorCodeMediaCodecIsNotAllowed,
+		},
+		{
+			name: "codec of the second audio stream is not allowed",
+			params: &MediaValidateOperationParams{
+				AllowedAudioCodecs: []string{"opus"},
+			},
+			info:              info,
+			expectedErrorCode: ErrorCodeMediaCodecIsNotAllowed,
+		},
+		{
+			name: "audio file is not checked against video codecs",
+			params: &MediaValidateOperationParams{
+				AllowedVideoCodecs: []string{"h264"},
+				AllowedAudioCodecs: []string{"mp3"},
+			},
+			info: mediaInfo{
+				Format:      "mp3",
+				Duration:    3 * time.Minute,
+				AudioCodec:  "mp3",
+				AudioCodecs: []string{"mp3"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fileProcessingErr := tc.params.validate(tc.info)
+			if tc.expectedErrorCode == "" {
+				if fileProcessingErr != nil {
+					t.Fatalf("validate() = %s, want nil", fileProcessingErr.Code())
+				}
+
+				return
+			}
+
+			if fileProcessingErr == nil {
+				t.Fatalf("validate() = nil, want %s", tc.expectedErrorCode)
+			}
+			if fileProcessingErr.Code() != tc.expectedErrorCode {
+				t.Fatalf("validate() = %s, want %s", fileProcessingErr.Code(), tc.expectedErrorCode)
+			}
+		})
+	}
+}
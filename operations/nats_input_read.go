@@ -0,0 +1,205 @@
+package operations
+
+import (
+	"capyfile/files"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"strconv"
+	"time"
+)
+
+const MetadataKeyNATSInputReadMessageId = "nats_input_read.message_id"
+
+// natsMinFetchWait is how long to wait for the messages if the wait time is not set.
+// NATS does not allow to fetch the messages without waiting.
+const natsMinFetchWait = 100 * time.Millisecond
+
+// NATSInputReadOperation reads the files described by the messages from NATS JetStream for further processing.
+// The messages are read with the durable pull consumer.
+type NATSInputReadOperation struct {
+	Name   string
+	Params *NATSInputReadOperationParams
+	// queueInputReader reads the files the messages describe.
+	queueInputReader *queueInputReader
+}
+
+type NATSInputReadOperationParams struct {
+	QueueInputParams
+
+	// Url is the server URL. For example: nats://nats:4222
+	Url      string
+	Username string
+	Password string
+	Token    string
+
+	// Stream is the stream to consume. If empty, the stream is looked up by the subject.
+	Stream  string
+	Subject string
+	// Durable is the durable consumer name. The consumer is created if it does not exist.
+	Durable string
+	// AckWait is how long the server waits for the acknowledgement before delivering the message again.
+	// Also, the visibility extension resets this timer. 0 means the server default is used.
+	AckWait time.Duration
+}
+
+func (o *NATSInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *NATSInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *NATSInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// The connection must stay open until the messages are settled, so it is closed by the queue input reader.
+	conn, connErr := o.connect()
+	if connErr != nil {
+		// Most likely it is a network issue, so the worker keeps polling.
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				fmt.Errorf("NATS connection can not be established: %w", connErr),
+			)
+		}
+
+		return out, nil
+	}
+
+	natsMessages, receiveErr := o.receive(conn)
+	if receiveErr != nil {
+		conn.Close()
+
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				fmt.Errorf("NATS messages can not be received: %w", receiveErr),
+			)
+		}
+
+		return out, nil
+	}
+
+	var messages []QueueMessage
+	for _, msg := range natsMessages {
+		messages = append(messages, &natsQueueMessage{
+			msg: msg,
+		})
+	}
+
+	return o.reader().read(messages, natsConnCloser{conn: conn}, errorCh, notificationCh), nil
+}
+
+func (o *NATSInputReadOperation) connect() (*nats.Conn, error) {
+	var options []nats.Option
+	if o.Params.Username != "" {
+		options = append(options, nats.UserInfo(o.Params.Username, o.Params.Password))
+	}
+	if o.Params.Token != "" {
+		options = append(options, nats.Token(o.Params.Token))
+	}
+
+	return nats.Connect(o.Params.Url, options...)
+}
+
+func (o *NATSInputReadOperation) receive(conn *nats.Conn) ([]*nats.Msg, error) {
+	js, jsErr := conn.JetStream()
+	if jsErr != nil {
+		return nil, jsErr
+	}
+
+	subOptions := []nats.SubOpt{nats.ManualAck()}
+	if o.Params.Stream != "" {
+		subOptions = append(subOptions, nats.BindStream(o.Params.Stream))
+	}
+	if o.Params.AckWait > 0 {
+		subOptions = append(subOptions, nats.AckWait(o.Params.AckWait))
+	}
+
+	sub, subErr := js.PullSubscribe(o.Params.Subject, o.Params.Durable, subOptions...)
+	if subErr != nil {
+		return nil, subErr
+	}
+
+	wait := o.Params.WaitTime
+	if wait < natsMinFetchWait {
+		wait = natsMinFetchWait
+	}
+
+	messages, fetchErr := sub.Fetch(int(o.Params.MaxMessages), nats.MaxWait(wait))
+	if fetchErr != nil && !errors.Is(fetchErr, nats.ErrTimeout) {
+		return nil, fetchErr
+	}
+
+	return messages, nil
+}
+
+// HandlePipelineFinished Acknowledges the messages of the processed files, and gives the other messages
+// back to the queue.
+func (o *NATSInputReadOperation) HandlePipelineFinished(
+	out []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) {
+	o.reader().handlePipelineFinished(out, errorCh)
+}
+
+func (o *NATSInputReadOperation) reader() *queueInputReader {
+	if o.queueInputReader == nil {
+		o.queueInputReader = &queueInputReader{
+			OperationName:        o.Name,
+			Params:               &o.Params.QueueInputParams,
+			MetadataKeyMessageId: MetadataKeyNATSInputReadMessageId,
+		}
+	}
+
+	return o.queueInputReader
+}
+
+func (o *NATSInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
+
+// natsConnCloser Closes the connection without removing the durable consumer.
+type natsConnCloser struct {
+	conn *nats.Conn
+}
+
+func (c natsConnCloser) Close() error {
+	c.conn.Close()
+
+	return nil
+}
+
+type natsQueueMessage struct {
+	msg *nats.Msg
+}
+
+func (m *natsQueueMessage) ID() string {
+	metadata, metadataErr := m.msg.Metadata()
+	if metadataErr != nil {
+		return ""
+	}
+
+	return metadata.Stream + ":" + strconv.FormatUint(metadata.Sequence.Stream, 10)
+}
+
+func (m *natsQueueMessage) Body() []byte {
+	return m.msg.Data
+}
+
+func (m *natsQueueMessage) Ack() error {
+	return m.msg.AckSync()
+}
+
+func (m *natsQueueMessage) Nack() error {
+	return m.msg.Nak()
+}
+
+func (m *natsQueueMessage) ExtendVisibility() error {
+	return m.msg.InProgress()
+}
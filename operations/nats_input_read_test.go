@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/afero"
+	"testing"
+	"time"
+)
+
+func TestNATSInputReadOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/nats", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	for _, name := range []string{"document.pdf", "photo.jpg"} {
+		writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/nats/"+name, []byte(name), 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	server := natsserver.RunServer(&opts)
+	defer server.Shutdown()
+
+	conn, connErr := nats.Connect(server.ClientURL())
+	if connErr != nil {
+		t.Fatal(connErr)
+	}
+	defer conn.Close()
+
+	js, jsErr := conn.JetStream()
+	if jsErr != nil {
+		t.Fatal(jsErr)
+	}
+
+	_, streamErr := js.AddStream(&nats.StreamConfig{
+		Name:     "FILES",
+		Subjects: []string{"files.>"},
+	})
+	if streamErr != nil {
+		t.Fatal(streamErr)
+	}
+
+	for _, name := range []string{"document.pdf", "photo.jpg"} {
+		_, publishErr := js.Publish("files.uploaded", []byte(`{"path": "/tmp/nats/`+name+`"}`))
+		if publishErr != nil {
+			t.Fatal(publishErr)
+		}
+	}
+
+	newOperation := func() *NATSInputReadOperation {
+		return &NATSInputReadOperation{
+			Name: "nats_input_read",
+			Params: &NATSInputReadOperationParams{
+				QueueInputParams: QueueInputParams{
+					MaxMessages: 10,
+					WaitTime:    time.Second,
+				},
+				Url:     server.ClientURL(),
+				Stream:  "FILES",
+				Subject: "files.uploaded",
+				Durable: "capyworker",
+				AckWait: time.Minute,
+			},
+		}
+	}
+
+	operation := newOperation()
+	out, err := operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	if out[0].OriginalFilename() != "document.pdf" || out[1].OriginalFilename() != "photo.jpg" {
+		t.Fatalf("unexpected files %s, %s", out[0].OriginalFilename(), out[1].OriginalFilename())
+	}
+	if out[0].OperationMetadata[MetadataKeyNATSInputReadMessageId] != "FILES:1" {
+		t.Fatalf("message id = %v, want FILES:1", out[0].OperationMetadata[MetadataKeyNATSInputReadMessageId])
+	}
+
+	out[1].SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+	settleErrorCh := make(chan OperationError, 10)
+	operation.HandlePipelineFinished(out, settleErrorCh, nil)
+	close(settleErrorCh)
+	if len(settleErrorCh) != 0 {
+		t.Fatalf("len(settleErrorCh) = %d, want 0", len(settleErrorCh))
+	}
+
+	// The nacked message is delivered again right away.
+	operation = newOperation()
+	out, err = operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].OriginalFilename() != "photo.jpg" {
+		t.Fatalf("out[0].OriginalFilename() = %s, want photo.jpg", out[0].OriginalFilename())
+	}
+
+	settleErrorCh = make(chan OperationError, 10)
+	operation.HandlePipelineFinished(out, settleErrorCh, nil)
+	close(settleErrorCh)
+	if len(settleErrorCh) != 0 {
+		t.Fatalf("len(settleErrorCh) = %d, want 0", len(settleErrorCh))
+	}
+
+	consumerInfo, consumerInfoErr := js.ConsumerInfo("FILES", "capyworker")
+	if consumerInfoErr != nil {
+		t.Fatal(consumerInfoErr)
+	}
+	if consumerInfo.NumAckPending != 0 || consumerInfo.NumPending != 0 {
+		t.Fatalf("all the messages are expected to be acknowledged, got %+v", consumerInfo)
+	}
+}
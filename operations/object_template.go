@@ -0,0 +1,100 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/files"
+	mimepkg "mime"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// objectTemplateData Builds the data for the object key and metadata templates of the upload operations.
+//
+// The following vars are available:
+// - {{.NanoID}} - NanoID of the file
+// - {{.Ext}} - extension of the file based on its MIME type. For example: .jpg
+// - {{.GeneratedFilename}} - NanoID with the extension. For example: V1StGXR8_Z5jdHi6B-myT.jpg
+// - {{.Filename}} - filename set by file_rename, or the generated filename. For example: avatar-2023-06-01.jpg
+// - {{.OriginalFilename}} - original filename. For example: avatar.jpeg
+// - {{.OriginalBasename}} - original filename without extension. For example: avatar
+// - {{.OriginalExtension}} - original filename extension. For example: .jpeg
+// - {{.Metadata}} - metadata provided by the previous operations
+// - any var from the templateVars
+func objectTemplateData(pf *files.ProcessableFile, templateVars map[string]string) map[string]any {
+	data := make(map[string]any, len(templateVars)+8)
+	for k, v := range templateVars {
+		data[k] = v
+	}
+
+	generatedFilename := pf.GeneratedFilename()
+	originalFilename := filepath.Base(pf.OriginalFilename())
+	originalExtension := filepath.Ext(originalFilename)
+
+	data["NanoID"] = pf.NanoID
+	data["Ext"] = generatedFilename[len(pf.NanoID):]
+	data["GeneratedFilename"] = generatedFilename
+	data["Filename"] = pf.LogicalFilename()
+	data["OriginalFilename"] = originalFilename
+	data["OriginalBasename"] = originalFilename[:len(originalFilename)-len(originalExtension)]
+	data["OriginalExtension"] = originalExtension
+	data["Metadata"] = pf.OperationMetadata
+
+	return data
+}
+
+func renderObjectTemplate(tmpl string, data map[string]any) (string, error) {
+	parsedTmpl, tmplParseErr := template.New("object").Option("missingkey=error").Parse(tmpl)
+	if tmplParseErr != nil {
+		return "", tmplParseErr
+	}
+
+	var buf bytes.Buffer
+	tmplExecErr := parsedTmpl.Execute(&buf, data)
+	if tmplExecErr != nil {
+		return "", tmplExecErr
+	}
+
+	return buf.String(), nil
+}
+
+// renderObjectKey Renders the object key template. If the template is empty, the logical filename is used.
+func renderObjectKey(pf *files.ProcessableFile, keyTmpl string, data map[string]any) (string, error) {
+	if keyTmpl == "" {
+		return pf.LogicalFilename(), nil
+	}
+
+	renderedKey, keyErr := renderObjectTemplate(keyTmpl, data)
+	if keyErr != nil {
+		return "", keyErr
+	}
+
+	return strings.TrimLeft(renderedKey, "/"), nil
+}
+
+// renderObjectTemplateMap Renders every value of the map as the object template.
+func renderObjectTemplateMap(tmpls map[string]string, data map[string]any) (map[string]string, error) {
+	if len(tmpls) == 0 {
+		return nil, nil
+	}
+
+	rendered := make(map[string]string, len(tmpls))
+	for k, v := range tmpls {
+		renderedVal, valErr := renderObjectTemplate(v, data)
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		rendered[k] = renderedVal
+	}
+
+	return rendered, nil
+}
+
+// objectContentDisposition Formats the Content-Disposition header value with the original filename.
+func objectContentDisposition(disposition string, data map[string]any) string {
+	return mimepkg.FormatMediaType(
+		disposition,
+		map[string]string{"filename": data["OriginalFilename"].(string)},
+	)
+}
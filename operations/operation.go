@@ -48,6 +48,17 @@ type OperationHandler interface {
 	) (out []files.ProcessableFile, err error)
 }
 
+// PipelineFinishedHandler The operation that has to do something once the whole pipeline has finished.
+// For example, to clean up the source the files have been read from only if the files have been processed.
+type PipelineFinishedHandler interface {
+	// HandlePipelineFinished handles the pipeline output. The out is nil if the pipeline has failed.
+	HandlePipelineFinished(
+		out []files.ProcessableFile,
+		errorCh chan<- OperationError,
+		notificationCh chan<- OperationNotification,
+	)
+}
+
 func newOutputHolder() *outputHolder {
 	return &outputHolder{
 		outLock: sync.Mutex{},
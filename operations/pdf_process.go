@@ -0,0 +1,383 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"errors"
+	"fmt"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const ErrorCodePDFProcessOperationConfiguration = "PDF_PROCESS_OPERATION_CONFIGURATION"
+
+const (
+	MetadataKeyPDFProcessPageCount = "pdf_process.page_count"
+	MetadataKeyPDFProcessEncrypted = "pdf_process.encrypted"
+	MetadataKeyPDFProcessText      = "pdf_process.text"
+	MetadataKeyPDFProcessSourceID  = "pdf_process.source_id"
+	MetadataKeyPDFProcessPage      = "pdf_process.page"
+)
+
+const (
+	PDFRenderFormatPNG  = "png"
+	PDFRenderFormatJPEG = "jpeg"
+)
+
+const (
+	PDFTextOutputMetadata = "metadata"
+	PDFTextOutputSidecar  = "sidecar"
+)
+
+// maxPDFTextMetadataLength The max length of the text written to the metadata. The metadata is
+// passed along with the file everywhere, so the whole text of the big documents does not fit there.
+const maxPDFTextMetadataLength = 64 * 1024
+
+type PDFProcessOperation struct {
+	Name   string
+	Params *PDFProcessOperationParams
+}
+
+type PDFProcessOperationParams struct {
+	MinPages int64
+	MaxPages int64
+	// AllowEncrypted Whether to process the encrypted documents that can be opened without password.
+	// The documents encrypted with the user password can not be processed anyway.
+	AllowEncrypted bool
+
+	// RenderPages are the pages to render to the images. If empty, the pages are not rendered.
+	RenderPages []PDFPageRange
+	// RenderFormat is the format of the rendered pages. Possible values: "png", "jpeg".
+	RenderFormat string
+	// RenderResolution is the resolution of the rendered pages in DPI.
+	RenderResolution int64
+
+	// TextOutput is where to put the extracted text. Possible values: "metadata", "sidecar".
+	// If empty, the text is not extracted.
+	TextOutput string
+
+	// Timeout is the time limit of each poppler utility run. 0 means no limit.
+	Timeout time.Duration
+}
+
+// requiredTools Returns the poppler utilities the operation needs.
+func (p *PDFProcessOperationParams) requiredTools() []string {
+	tools := []string{"pdfinfo"}
+	if len(p.RenderPages) > 0 {
+		tools = append(tools, "pdftoppm")
+	}
+	if p.TextOutput != "" {
+		tools = append(tools, "pdftotext")
+	}
+
+	return tools
+}
+
+func (p *PDFProcessOperationParams) validate(info pdfInfo) files.FileProcessingError {
+	if info.Encrypted && !p.AllowEncrypted {
+		return NewPDFIsEncryptedError()
+	}
+	if p.MinPages > 0 && info.PageCount < p.MinPages {
+		return NewPDFHasTooFewPagesError(p.MinPages, info.PageCount)
+	}
+	if p.MaxPages > 0 && info.PageCount > p.MaxPages {
+		return NewPDFHasTooManyPagesError(p.MaxPages, info.PageCount)
+	}
+
+	return nil
+}
+
+// renderPages Renders the selected pages to the image files.
+func (p *PDFProcessOperationParams) renderPages(
+	pf *files.ProcessableFile,
+	pageCount int64,
+) ([]files.ProcessableFile, error) {
+	tmpDir, tmpDirErr := capyutils.GetAppTmpDirectory()
+	if tmpDirErr != nil {
+		return nil, tmpDirErr
+	}
+
+	formatArg, ext := "-png", ".png"
+	if p.RenderFormat == PDFRenderFormatJPEG {
+		formatArg, ext = "-jpeg", ".jpg"
+	}
+
+	originalFilename := filepath.Base(pf.OriginalFilename())
+	originalBasename := originalFilename[:len(originalFilename)-len(filepath.Ext(originalFilename))]
+
+	var pagePfs []files.ProcessableFile
+	for _, page := range pdfPages(p.RenderPages, pageCount) {
+		pageArg := strconv.FormatInt(page, 10)
+		outputPrefix := filepath.Join(tmpDir, gonanoid.Must())
+
+		// With -singlefile, pdftoppm does not add the page number to the output filename.
+		_, renderErr := runExternalTool(
+			p.Timeout,
+			"pdftoppm",
+			formatArg,
+			"-r", strconv.FormatInt(p.RenderResolution, 10),
+			"-f", pageArg,
+			"-l", pageArg,
+			"-singlefile",
+			pf.Name(),
+			outputPrefix,
+		)
+		if renderErr != nil {
+			for i := range pagePfs {
+				_ = pagePfs[i].Remove()
+			}
+
+			return nil, fmt.Errorf("page %d: %w", page, renderErr)
+		}
+
+		pagePf := newPDFDerivedFile(pf, outputPrefix+ext)
+		pagePf.Metadata.OriginalFilename = fmt.Sprintf("%s-page-%d%s", originalBasename, page, ext)
+		pagePf.AddOperationMetadata(MetadataKeyPDFProcessPage, page)
+
+		pagePfs = append(pagePfs, pagePf)
+	}
+
+	return pagePfs, nil
+}
+
+// extractText Extracts the text of the document. The text is either written to the metadata,
+// or returned as the sidecar text file.
+func (p *PDFProcessOperationParams) extractText(pf *files.ProcessableFile) (*files.ProcessableFile, error) {
+	if p.TextOutput == PDFTextOutputMetadata {
+		text, extractErr := runExternalTool(p.Timeout, "pdftotext", "-enc", "UTF-8", pf.Name(), "-")
+		if extractErr != nil {
+			return nil, extractErr
+		}
+
+		pf.AddOperationMetadata(MetadataKeyPDFProcessText, truncateUTF8(text, maxPDFTextMetadataLength))
+
+		return nil, nil
+	}
+
+	tmpDir, tmpDirErr := capyutils.GetAppTmpDirectory()
+	if tmpDirErr != nil {
+		return nil, tmpDirErr
+	}
+
+	textFilename := filepath.Join(tmpDir, gonanoid.Must()+".txt")
+	_, extractErr := runExternalTool(p.Timeout, "pdftotext", "-enc", "UTF-8", pf.Name(), textFilename)
+	if extractErr != nil {
+		_ = capyfs.Filesystem.Remove(textFilename)
+
+		return nil, extractErr
+	}
+
+	originalFilename := filepath.Base(pf.OriginalFilename())
+
+	textPf := newPDFDerivedFile(pf, textFilename)
+	textPf.Metadata.OriginalFilename = originalFilename[:len(originalFilename)-len(filepath.Ext(originalFilename))] + ".txt"
+
+	return &textPf, nil
+}
+
+// newPDFDerivedFile Creates the file derived from the PDF. It inherits the metadata of the PDF, like the queue
+// message ID, except the metadata written by this operation.
+func newPDFDerivedFile(pf *files.ProcessableFile, name string) files.ProcessableFile {
+	derivedPf := files.NewProcessableFile(name)
+	for k, v := range pf.OperationMetadata {
+		if !strings.HasPrefix(k, "pdf_process.") {
+			derivedPf.AddOperationMetadata(k, v)
+		}
+	}
+	derivedPf.AddOperationMetadata(MetadataKeyPDFProcessSourceID, pf.NanoID)
+
+	return derivedPf
+}
+
+// truncateUTF8 Truncates the text to the max length without breaking the multibyte characters.
+func truncateUTF8(text []byte, maxLength int) string {
+	if len(text) <= maxLength {
+		return string(text)
+	}
+
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return string(text[:end])
+}
+
+func (o *PDFProcessOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *PDFProcessOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *PDFProcessOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// First it makes sense to check if the poppler utilities are installed.
+	missingTool, lookPathErr := lookPathExternalTools(o.Params.requiredTools())
+	if lookPathErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New(missingTool + " is not installed"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodePDFProcessOperationConfiguration,
+			missingTool+" is not installed",
+			lookPathErr,
+		)
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("PDF processing has started", pf)
+			}
+
+			info, infoErr := readPDFInfo(pf.Name(), o.Params.Timeout)
+			if errors.Is(infoErr, errPDFIsEncrypted) {
+				pf.SetFileProcessingError(NewPDFIsEncryptedError())
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("PDF is encrypted", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			if infoErr != nil {
+				// Most likely the file is not a PDF, or it is corrupted.
+				pf.SetFileProcessingError(
+					NewPDFCanNotBeReadError(infoErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("PDF can not be read", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyPDFProcessPageCount, info.PageCount)
+			pf.AddOperationMetadata(MetadataKeyPDFProcessEncrypted, info.Encrypted)
+
+			validationErr := o.Params.validate(info)
+			if validationErr != nil {
+				pf.SetFileProcessingError(validationErr)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(validationErr.Error(), pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			var derivedPfs []files.ProcessableFile
+
+			if len(o.Params.RenderPages) > 0 {
+				pagePfs, renderErr := o.Params.renderPages(pf, info.PageCount)
+				if renderErr != nil {
+					pf.SetFileProcessingError(
+						NewPDFPageCanNotBeRenderedError(renderErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, renderErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed("PDF page can not be rendered", pf, renderErr)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				derivedPfs = append(derivedPfs, pagePfs...)
+			}
+
+			if o.Params.TextOutput != "" {
+				textPf, extractErr := o.Params.extractText(pf)
+				if extractErr != nil {
+					for i := range derivedPfs {
+						_ = derivedPfs[i].Remove()
+					}
+
+					pf.SetFileProcessingError(
+						NewPDFTextCanNotBeExtractedError(extractErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, extractErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed("PDF text can not be extracted", pf, extractErr)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				if textPf != nil {
+					derivedPfs = append(derivedPfs, *textPf)
+				}
+			}
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("PDF processing has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+			for i := range derivedPfs {
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("file has been created from PDF", &derivedPfs[i])
+				}
+
+				outHolder.AppendToOut(&derivedPfs[i])
+			}
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *PDFProcessOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *PDFProcessOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
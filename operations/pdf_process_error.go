@@ -0,0 +1,164 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodePDFCanNotBeRead = "PDF_CAN_NOT_BE_READ"
+
+func NewPDFCanNotBeReadError(origErr error) *PDFCanNotBeReadError {
+	return &PDFCanNotBeReadError{
+		Data: &PDFCanNotBeReadErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type PDFCanNotBeReadError struct {
+	files.FileProcessingError
+	Data *PDFCanNotBeReadErrorData
+}
+
+type PDFCanNotBeReadErrorData struct {
+	OrigErr error
+}
+
+func (e *PDFCanNotBeReadError) Code() string {
+	return ErrorCodePDFCanNotBeRead
+}
+
+func (e *PDFCanNotBeReadError) Error() string {
+	return "PDF can not be read"
+}
+
+const ErrorCodePDFIsEncrypted = "PDF_IS_ENCRYPTED"
+
+func NewPDFIsEncryptedError() *PDFIsEncryptedError {
+	return &PDFIsEncryptedError{}
+}
+
+type PDFIsEncryptedError struct {
+	files.FileProcessingError
+}
+
+func (e *PDFIsEncryptedError) Code() string {
+	return ErrorCodePDFIsEncrypted
+}
+
+func (e *PDFIsEncryptedError) Error() string {
+	return "PDF is encrypted"
+}
+
+const ErrorCodePDFHasTooFewPages = "PDF_HAS_TOO_FEW_PAGES"
+
+func NewPDFHasTooFewPagesError(minPages, givenPages int64) *PDFHasTooFewPagesError {
+	return &PDFHasTooFewPagesError{
+		Data: &PDFHasTooFewPagesErrorData{
+			MinPages:   minPages,
+			GivenPages: givenPages,
+		},
+	}
+}
+
+type PDFHasTooFewPagesError struct {
+	files.FileProcessingError
+
+	Data *PDFHasTooFewPagesErrorData
+}
+
+type PDFHasTooFewPagesErrorData struct {
+	MinPages   int64
+	GivenPages int64
+}
+
+func (e *PDFHasTooFewPagesError) Code() string {
+	return ErrorCodePDFHasTooFewPages
+}
+
+func (e *PDFHasTooFewPagesError) Error() string {
+	return "PDF has too few pages"
+}
+
+const ErrorCodePDFHasTooManyPages = "PDF_HAS_TOO_MANY_PAGES"
+
+func NewPDFHasTooManyPagesError(maxPages, givenPages int64) *PDFHasTooManyPagesError {
+	return &PDFHasTooManyPagesError{
+		Data: &PDFHasTooManyPagesErrorData{
+			MaxPages:   maxPages,
+			GivenPages: givenPages,
+		},
+	}
+}
+
+type PDFHasTooManyPagesError struct {
+	files.FileProcessingError
+
+	Data *PDFHasTooManyPagesErrorData
+}
+
+type PDFHasTooManyPagesErrorData struct {
+	MaxPages   int64
+	GivenPages int64
+}
+
+func (e *PDFHasTooManyPagesError) Code() string {
+	return ErrorCodePDFHasTooManyPages
+}
+
+func (e *PDFHasTooManyPagesError) Error() string {
+	return "PDF has too many pages"
+}
+
+const ErrorCodePDFPageCanNotBeRendered = "PDF_PAGE_CAN_NOT_BE_RENDERED"
+
+func NewPDFPageCanNotBeRenderedError(origErr error) *PDFPageCanNotBeRenderedError {
+	return &PDFPageCanNotBeRenderedError{
+		Data: &PDFPageCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type PDFPageCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *PDFPageCanNotBeRenderedErrorData
+}
+
+type PDFPageCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *PDFPageCanNotBeRenderedError) Code() string {
+	return ErrorCodePDFPageCanNotBeRendered
+}
+
+func (e *PDFPageCanNotBeRenderedError) Error() string {
+	return "PDF page can not be rendered"
+}
+
+const ErrorCodePDFTextCanNotBeExtracted = "PDF_TEXT_CAN_NOT_BE_EXTRACTED"
+
+func NewPDFTextCanNotBeExtractedError(origErr error) *PDFTextCanNotBeExtractedError {
+	return &PDFTextCanNotBeExtractedError{
+		Data: &PDFTextCanNotBeExtractedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type PDFTextCanNotBeExtractedError struct {
+	files.FileProcessingError
+	Data *PDFTextCanNotBeExtractedErrorData
+}
+
+type PDFTextCanNotBeExtractedErrorData struct {
+	OrigErr error
+}
+
+func (e *PDFTextCanNotBeExtractedError) Code() string {
+	return ErrorCodePDFTextCanNotBeExtracted
+}
+
+func (e *PDFTextCanNotBeExtractedError) Error() string {
+	return "PDF text can not be extracted"
+}
@@ -0,0 +1,281 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePDFPageRanges(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ranges        string
+		pageCount     int64
+		expectedPages []int64
+		expectedErr   bool
+	}{
+		{
+			name:          "single page",
+			ranges:        "2",
+			pageCount:     5,
+			expectedPages: []int64{2},
+		},
+		{
+			name:          "ranges and last page",
+			ranges:        "1-2, 4-, last",
+			pageCount:     5,
+			expectedPages: []int64{1, 2, 4, 5},
+		},
+		{
+			name:          "pages that do not exist",
+			ranges:        "3-10,12",
+			pageCount:     5,
+			expectedPages: []int64{3, 4, 5},
+		},
+		{
+			name:          "order is kept",
+			ranges:        "last,1",
+			pageCount:     3,
+			expectedPages: []int64{3, 1},
+		},
+		{
+			name:        "zero page",
+			ranges:      "0",
+			expectedErr: true,
+		},
+		{
+			name:        "reversed range",
+			ranges:      "3-1",
+			expectedErr: true,
+		},
+		{
+			name:        "range from the last page",
+			ranges:      "last-3",
+			expectedErr: true,
+		},
+		{
+			name:        "empty",
+			ranges:      " , ",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := ParsePDFPageRanges(tc.ranges)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("ParsePDFPageRanges(%q) error = nil, want error", tc.ranges)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pages := pdfPages(ranges, tc.pageCount)
+			if !reflect.DeepEqual(pages, tc.expectedPages) {
+				t.Fatalf("pdfPages() = %v, want %v", pages, tc.expectedPages)
+			}
+		})
+	}
+}
+
+func TestParsePDFInfo(t *testing.T) {
+	output := "Producer:        capyfile\n" +
+		"Encrypted:       yes (print:yes copy:no change:no addNotes:no algorithm:AES-256)\n" +
+		"Pages:           12\n" +
+		"Page size:       595 x 842 pts (A4)\n"
+
+	info, err := parsePDFInfo([]byte(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.PageCount != 12 {
+		t.Fatalf("PageCount = %d, want 12", info.PageCount)
+	}
+	if !info.Encrypted {
+		t.Fatalf("Encrypted = false, want true")
+	}
+
+	_, err = parsePDFInfo([]byte("Producer:        capyfile\n"))
+	if err == nil {
+		t.Fatalf("parsePDFInfo() error = nil, want error")
+	}
+}
+
+func TestPDFProcessOperation_Handle(t *testing.T) {
+	for _, tool := range []string{"pdfinfo", "pdftoppm", "pdftotext"} {
+		if _, lookPathErr := exec.LookPath(tool); lookPathErr != nil {
+			t.Skip(tool + " is not installed")
+		}
+	}
+
+	capyfs.InitOsFilesystem()
+
+	testCases := []struct {
+		name              string
+		filename          string
+		params            *PDFProcessOperationParams
+		expectedOutLen    int
+		expectedErrorCode string
+	}{
+		{
+			name:     "render pages and extract text to sidecar",
+			filename: "testdata/document_2_pages.pdf",
+			params: &PDFProcessOperationParams{
+				RenderPages:      []PDFPageRange{{First: 1, Last: 0}},
+				RenderFormat:     PDFRenderFormatPNG,
+				RenderResolution: 72,
+				TextOutput:       PDFTextOutputSidecar,
+			},
+			expectedOutLen: 4,
+		},
+		{
+			name:     "extract text to metadata",
+			filename: "testdata/document_2_pages.pdf",
+			params: &PDFProcessOperationParams{
+				TextOutput: PDFTextOutputMetadata,
+			},
+			expectedOutLen: 1,
+		},
+		{
+			name:     "too many pages",
+			filename: "testdata/document_2_pages.pdf",
+			params: &PDFProcessOperationParams{
+				MaxPages: 1,
+			},
+			expectedOutLen:    1,
+			expectedErrorCode: ErrorCodePDFHasTooManyPages,
+		},
+		{
+			name:              "not PDF",
+			filename:          "testdata/image_512x512.png",
+			params:            &PDFProcessOperationParams{},
+			expectedOutLen:    1,
+			expectedErrorCode: ErrorCodePDFCanNotBeRead,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := []files.ProcessableFile{
+				files.NewProcessableFile(tc.filename),
+			}
+
+			operation := &PDFProcessOperation{
+				Name:   "pdf_process",
+				Params: tc.params,
+			}
+			out, err := operation.Handle(in, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(out) != tc.expectedOutLen {
+				t.Fatalf("len(out) = %d, want %d", len(out), tc.expectedOutLen)
+			}
+
+			if tc.expectedErrorCode != "" {
+				if out[0].FileProcessingError == nil {
+					t.Fatalf("FileProcessingError = nil, want %s", tc.expectedErrorCode)
+				}
+				if out[0].FileProcessingError.Code() != tc.expectedErrorCode {
+					t.Fatalf(
+						"FileProcessingError.Code() = %s, want %s",
+						out[0].FileProcessingError.Code(),
+						tc.expectedErrorCode,
+					)
+				}
+
+				return
+			}
+
+			for i := range out {
+				if out[i].FileProcessingError != nil {
+					t.Fatalf(
+						"FileProcessingError.Code() = %s, want nil",
+						out[i].FileProcessingError.Code(),
+					)
+				}
+
+				if i > 0 {
+					if out[i].OperationMetadata[MetadataKeyPDFProcessSourceID] != in[0].NanoID {
+						t.Fatalf(
+							"OperationMetadata[%s] = %v, want %s",
+							MetadataKeyPDFProcessSourceID,
+							out[i].OperationMetadata[MetadataKeyPDFProcessSourceID],
+							in[0].NanoID,
+						)
+					}
+
+					_ = out[i].Remove()
+				}
+			}
+
+			if out[0].OperationMetadata[MetadataKeyPDFProcessPageCount] != int64(2) {
+				t.Fatalf(
+					"OperationMetadata[%s] = %v, want 2",
+					MetadataKeyPDFProcessPageCount,
+					out[0].OperationMetadata[MetadataKeyPDFProcessPageCount],
+				)
+			}
+
+			if tc.params.TextOutput == PDFTextOutputMetadata {
+				text, _ := out[0].OperationMetadata[MetadataKeyPDFProcessText].(string)
+				if !strings.Contains(text, "Page 1") || !strings.Contains(text, "Page 2") {
+					t.Fatalf("OperationMetadata[%s] = %q, want the text of both pages", MetadataKeyPDFProcessText, text)
+				}
+			}
+		})
+	}
+}
+
+func TestPDFProcessOperation_HandleWithoutPoppler(t *testing.T) {
+	if _, lookPathErr := exec.LookPath("pdfinfo"); lookPathErr == nil {
+		t.Skip("pdfinfo is installed")
+	}
+
+	operation := &PDFProcessOperation{
+		Name:   "pdf_process",
+		Params: &PDFProcessOperationParams{},
+	}
+	_, err := operation.Handle([]files.ProcessableFile{
+		files.NewProcessableFile("testdata/document_2_pages.pdf"),
+	}, nil, nil)
+	if err == nil {
+		t.Fatalf("Handle() error = nil, want the configuration error")
+	}
+}
+
+func TestNewPDFDerivedFile(t *testing.T) {
+	pf := files.NewProcessableFile("testdata/document_2_pages.pdf")
+	pf.AddOperationMetadata(MetadataKeySQSInputReadMessageId, "1")
+	pf.AddOperationMetadata(MetadataKeyPDFProcessPageCount, int64(2))
+
+	derivedPf := newPDFDerivedFile(&pf, "/tmp/page.png")
+
+	if derivedPf.OperationMetadata[MetadataKeySQSInputReadMessageId] != "1" {
+		t.Fatalf(
+			"OperationMetadata[%s] = %v, want 1",
+			MetadataKeySQSInputReadMessageId,
+			derivedPf.OperationMetadata[MetadataKeySQSInputReadMessageId],
+		)
+	}
+	if _, ok := derivedPf.OperationMetadata[MetadataKeyPDFProcessPageCount]; ok {
+		t.Fatalf("OperationMetadata[%s] is inherited, want it to be skipped", MetadataKeyPDFProcessPageCount)
+	}
+	if derivedPf.OperationMetadata[MetadataKeyPDFProcessSourceID] != pf.NanoID {
+		t.Fatalf(
+			"OperationMetadata[%s] = %v, want %s",
+			MetadataKeyPDFProcessSourceID,
+			derivedPf.OperationMetadata[MetadataKeyPDFProcessSourceID],
+			pf.NanoID,
+		)
+	}
+}
@@ -0,0 +1,146 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errPDFIsEncrypted = errors.New("PDF is encrypted with password")
+
+// pdfInfo The PDF document properties reported by pdfinfo.
+type pdfInfo struct {
+	PageCount int64
+	Encrypted bool
+}
+
+// PDFPageRange The range of the PDF pages. Zero page number means the last page.
+type PDFPageRange struct {
+	First int64
+	Last  int64
+}
+
+// ParsePDFPageRanges Parses the comma separated page ranges. For example: "1", "1-3", "5-", "last", "1,3-5,last".
+func ParsePDFPageRanges(s string) ([]PDFPageRange, error) {
+	var ranges []PDFPageRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+
+		firstPage, firstErr := parsePDFPageNumber(first, false)
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		lastPage, lastErr := parsePDFPageNumber(last, isRange)
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		// The range that starts from the last page can only end with it.
+		if (firstPage == 0 && lastPage != 0) || (lastPage != 0 && firstPage > lastPage) {
+			return nil, errors.New("page range \"" + part + "\" starts after it ends")
+		}
+
+		ranges = append(ranges, PDFPageRange{First: firstPage, Last: lastPage})
+	}
+
+	if len(ranges) == 0 {
+		return nil, errors.New("page ranges are empty")
+	}
+
+	return ranges, nil
+}
+
+func parsePDFPageNumber(s string, allowEmpty bool) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "last" || (allowEmpty && s == "") {
+		return 0, nil
+	}
+
+	page, parseErr := strconv.ParseInt(s, 10, 64)
+	if parseErr != nil || page < 1 {
+		return 0, errors.New("page number \"" + s + "\" is not valid")
+	}
+
+	return page, nil
+}
+
+// pdfPages Returns the pages of the document the ranges select, in the order they are given.
+// The pages that do not exist are skipped.
+func pdfPages(ranges []PDFPageRange, pageCount int64) []int64 {
+	var pages []int64
+	selected := make(map[int64]bool)
+	for _, r := range ranges {
+		first, last := r.First, r.Last
+		if first == 0 {
+			first = pageCount
+		}
+		if last == 0 || last > pageCount {
+			last = pageCount
+		}
+
+		for page := first; page <= last; page++ {
+			if !selected[page] {
+				selected[page] = true
+				pages = append(pages, page)
+			}
+		}
+	}
+
+	return pages
+}
+
+// readPDFInfo Reads the document properties with pdfinfo.
+func readPDFInfo(name string, timeout time.Duration) (pdfInfo, error) {
+	output, runErr := runExternalTool(timeout, "pdfinfo", name)
+	if runErr != nil {
+		// The documents encrypted with the user password can not be opened at all.
+		if strings.Contains(runErr.Error(), "Incorrect password") {
+			return pdfInfo{Encrypted: true}, errPDFIsEncrypted
+		}
+
+		return pdfInfo{}, runErr
+	}
+
+	return parsePDFInfo(output)
+}
+
+func parsePDFInfo(output []byte) (pdfInfo, error) {
+	info := pdfInfo{PageCount: -1}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Pages":
+			pageCount, parseErr := strconv.ParseInt(value, 10, 64)
+			if parseErr != nil {
+				return pdfInfo{}, errors.New("page count \"" + value + "\" is not valid")
+			}
+
+			info.PageCount = pageCount
+		case "Encrypted":
+			info.Encrypted = strings.HasPrefix(value, "yes")
+		}
+	}
+
+	if info.PageCount < 0 {
+		return pdfInfo{}, errors.New("page count is not reported")
+	}
+
+	return info, nil
+}
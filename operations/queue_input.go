@@ -0,0 +1,388 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+)
+
+// QueueMessage The message received from the queue. The message body describes the file to process.
+type QueueMessage interface {
+	ID() string
+	Body() []byte
+	// Ack Acknowledges the message, so it is not delivered again.
+	Ack() error
+	// Nack Gives the message back to the queue, so it can be delivered again.
+	Nack() error
+	// ExtendVisibility Tells the queue the message is still being processed, so it is not
+	// delivered to another consumer meanwhile.
+	ExtendVisibility() error
+}
+
+// QueueInputParams The parameters shared by all the queue input operations.
+type QueueInputParams struct {
+	// MaxMessages is the maximum number of messages to receive per operation run.
+	MaxMessages int64
+	// WaitTime is how long to wait for the messages if there are none. 0 means do not wait.
+	WaitTime time.Duration
+	// VisibilityExtensionInterval is how often to extend the visibility of the messages that
+	// are still being processed. 0 means the visibility is not extended.
+	VisibilityExtensionInterval time.Duration
+	// S3 are the parameters to download the S3 objects the messages refer to.
+	// Nil if the messages are not expected to refer to S3 objects.
+	S3 *S3ClientParams
+	// DownloadTimeout is the time limit to download the file the message refers to by URL.
+	DownloadTimeout time.Duration
+	// DownloadMaxFileSize is the maximum size of the file downloaded by URL in bytes. 0 means no limit.
+	DownloadMaxFileSize int64
+	// DownloadMaxRedirects is the maximum number of redirects to follow. 0 means the redirects are not followed.
+	DownloadMaxRedirects int64
+	// DownloadAllowedSchemes are the URL schemes that can be downloaded from.
+	DownloadAllowedSchemes []string
+	// DownloadAllowedHosts are the hosts that can be downloaded from. The "*." prefix matches the subdomains.
+	// Any host can be downloaded from if empty.
+	DownloadAllowedHosts []string
+	// DownloadAllowPrivateAddresses tells whether the files can be downloaded from the private, loopback
+	// and link-local addresses.
+	DownloadAllowPrivateAddresses bool
+}
+
+// queueFileMessage The message body. Exactly one of Path, Url or S3 must be set.
+// For example:
+//
+//	{"path": "/data/incoming/report.pdf"}
+//	{"url": "https://cdn.example.com/report.pdf", "filename": "report.pdf"}
+//	{"s3": {"bucket": "uploads", "key": "incoming/report.pdf"}}
+type queueFileMessage struct {
+	Path string              `json:"path"`
+	Url  string              `json:"url"`
+	S3   *queueFileMessageS3 `json:"s3"`
+	// Filename is the original filename. If empty, it is derived from the path, URL or S3 key.
+	Filename string `json:"filename"`
+}
+
+type queueFileMessageS3 struct {
+	// Bucket is the bucket of the object. If empty, the configured bucket is used.
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+// queueInputReader Turns the received messages into the processable files. Used by all the queue input operations.
+type queueInputReader struct {
+	OperationName string
+	Params        *QueueInputParams
+	// MetadataKeyMessageId is the metadata key the message ID is written to.
+	MetadataKeyMessageId string
+
+	S3InputReadAPI S3InputReadAPI
+	HTTPClient     *http.Client
+
+	httpInputRead *HTTPInputReadOperation
+
+	// pending are the messages by ID that are waiting for the pipeline to finish.
+	pending   map[string]*trackedQueueMessage
+	pendingMu sync.Mutex
+}
+
+// read Reads the files the messages describe. The messages are tracked until the files are processed,
+// so they can be settled once the pipeline has finished. The closer is closed once all the messages are settled.
+func (r *queueInputReader) read(
+	messages []QueueMessage,
+	closer io.Closer,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile) {
+	conn := &queueConnection{
+		closer:  closer,
+		pending: len(messages),
+	}
+	if len(messages) == 0 {
+		conn.close()
+
+		return out
+	}
+
+	for _, msg := range messages {
+		pf, readErr := r.readMessage(msg)
+		if readErr != nil {
+			if errorCh != nil {
+				errorCh <- r.errorBuilder().Error(
+					fmt.Errorf("queue message \"%s\" can not be read: %w", msg.ID(), readErr),
+				)
+			}
+
+			// Give the message back, so it can be retried or moved to the dead-letter queue.
+			nackErr := msg.Nack()
+			if nackErr != nil && errorCh != nil {
+				errorCh <- r.errorBuilder().Error(
+					fmt.Errorf("queue message \"%s\" can not be nacked: %w", msg.ID(), nackErr),
+				)
+			}
+			conn.release()
+
+			continue
+		}
+
+		r.track(msg, conn)
+
+		if notificationCh != nil {
+			notificationCh <- r.notificationBuilder().Finished("queue message read finished", pf)
+		}
+
+		out = append(out, *pf)
+	}
+
+	return out
+}
+
+func (r *queueInputReader) readMessage(msg QueueMessage) (*files.ProcessableFile, error) {
+	var fileMessage queueFileMessage
+	unmarshalErr := json.Unmarshal(msg.Body(), &fileMessage)
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+
+	var pf files.ProcessableFile
+	var filename string
+	switch {
+	case fileMessage.Path != "":
+		// Same as filesystem_input_read, the file is processed in place.
+		file, openErr := capyfs.Filesystem.Open(fileMessage.Path)
+		if openErr != nil {
+			return nil, openErr
+		}
+		_ = file.Close()
+
+		pf = files.NewProcessableFile(file.Name())
+		filename = path.Base(fileMessage.Path)
+	case fileMessage.Url != "":
+		downloadedFilename, resp, downloadErr := r.downloader().fetch(fileMessage.Url)
+		if downloadErr != nil {
+			return nil, downloadErr
+		}
+
+		pf = files.NewProcessableFile(downloadedFilename)
+		filename = httpResponseFilename(resp)
+	case fileMessage.S3 != nil && fileMessage.S3.Key != "":
+		body, getObjErr := r.getObject(fileMessage.S3)
+		if getObjErr != nil {
+			return nil, getObjErr
+		}
+
+		file, fileWriteErr := capyutils.WriteReaderToAppTmpDirectory(body)
+		_ = body.Close()
+		if fileWriteErr != nil {
+			return nil, fileWriteErr
+		}
+
+		pf = files.NewProcessableFile(file.Name())
+		filename = path.Base(fileMessage.S3.Key)
+	default:
+		return nil, errors.New("message must contain either \"path\", \"url\" or \"s3\" field")
+	}
+
+	if fileMessage.Filename != "" {
+		filename = path.Base(fileMessage.Filename)
+	}
+	if filename != "" && filename != "." && filename != "/" {
+		pf.Metadata.OriginalFilename = filename
+	}
+	pf.AddOperationMetadata(r.MetadataKeyMessageId, msg.ID())
+
+	return &pf, nil
+}
+
+// downloader Returns the operation to download the files the messages refer to by URL. Same as
+// for http_input_read, the URLs are checked, since the messages may come from the untrusted sources.
+func (r *queueInputReader) downloader() *HTTPInputReadOperation {
+	if r.httpInputRead == nil {
+		r.httpInputRead = &HTTPInputReadOperation{
+			Name: r.OperationName,
+			Params: &HTTPInputReadOperationParams{
+				Timeout:               r.Params.DownloadTimeout,
+				MaxFileSize:           r.Params.DownloadMaxFileSize,
+				MaxRedirects:          r.Params.DownloadMaxRedirects,
+				AllowedSchemes:        r.Params.DownloadAllowedSchemes,
+				AllowedHosts:          r.Params.DownloadAllowedHosts,
+				AllowPrivateAddresses: r.Params.DownloadAllowPrivateAddresses,
+			},
+			HTTPClient: r.HTTPClient,
+		}
+		r.httpInputRead.initHTTPClient()
+	}
+
+	return r.httpInputRead
+}
+
+func (r *queueInputReader) getObject(object *queueFileMessageS3) (io.ReadCloser, error) {
+	if r.Params.S3 == nil {
+		return nil, errors.New("S3 is not configured to read the S3 objects")
+	}
+
+	if r.S3InputReadAPI == nil {
+		client, clientErr := r.Params.S3.newClient()
+		if clientErr != nil {
+			return nil, clientErr
+		}
+
+		r.S3InputReadAPI = client
+	}
+
+	bucket := object.Bucket
+	if bucket == "" {
+		bucket = r.Params.S3.Bucket
+	}
+
+	getObjOutput, getObjErr := r.S3InputReadAPI.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object.Key),
+	})
+	if getObjErr != nil {
+		return nil, getObjErr
+	}
+
+	return getObjOutput.Body, nil
+}
+
+func (r *queueInputReader) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: r.OperationName,
+	}
+}
+
+func (r *queueInputReader) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: r.OperationName,
+	}
+}
+
+// queueConnection Closes the connection the messages have been received with once all of them are settled.
+type queueConnection struct {
+	mu      sync.Mutex
+	closer  io.Closer
+	pending int
+}
+
+func (c *queueConnection) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending--
+	if c.pending == 0 {
+		c.close()
+	}
+}
+
+func (c *queueConnection) close() {
+	if c.closer != nil {
+		_ = c.closer.Close()
+	}
+}
+
+type trackedQueueMessage struct {
+	msg  QueueMessage
+	conn *queueConnection
+	// stopExtension stops extending the message visibility.
+	stopExtension chan struct{}
+}
+
+func (m *trackedQueueMessage) settle(ack bool) error {
+	close(m.stopExtension)
+	defer m.conn.release()
+
+	if ack {
+		return m.msg.Ack()
+	}
+
+	return m.msg.Nack()
+}
+
+func (r *queueInputReader) track(msg QueueMessage, conn *queueConnection) {
+	tracked := &trackedQueueMessage{
+		msg:           msg,
+		conn:          conn,
+		stopExtension: make(chan struct{}),
+	}
+
+	if r.Params.VisibilityExtensionInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(r.Params.VisibilityExtensionInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-tracked.stopExtension:
+					return
+				case <-ticker.C:
+					// If it fails, the message may be delivered again. Nothing else we can do here.
+					_ = msg.ExtendVisibility()
+				}
+			}
+		}()
+	}
+
+	r.pendingMu.Lock()
+	defer r.pendingMu.Unlock()
+
+	if r.pending == nil {
+		r.pending = make(map[string]*trackedQueueMessage)
+	}
+	r.pending[msg.ID()] = tracked
+}
+
+// settle Settles the messages once the pipeline has finished. The files are matched with the messages
+// by the message ID metadata, so the files derived from the read file, like the chunks of file_split,
+// settle the message too. The message is acknowledged if all its files are processed without errors.
+// The messages with failed files, and the messages with no files in the output anymore, are given back
+// to the queue. So if the pipeline has failed, all the pending messages are given back with nil output.
+func (r *queueInputReader) settle(out []files.ProcessableFile) []error {
+	r.pendingMu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.pendingMu.Unlock()
+
+	processed := make(map[string]bool)
+	for _, pf := range out {
+		messageId, ok := pf.OperationMetadata[r.MetadataKeyMessageId].(string)
+		if !ok {
+			continue
+		}
+
+		// One failed file is enough to give the message back.
+		if succeeded, seen := processed[messageId]; seen {
+			processed[messageId] = succeeded && !pf.HasFileProcessingError()
+		} else {
+			processed[messageId] = !pf.HasFileProcessingError()
+		}
+	}
+
+	var errs []error
+	for messageId, tracked := range pending {
+		settleErr := tracked.settle(processed[messageId])
+		if settleErr != nil {
+			errs = append(errs, fmt.Errorf("queue message \"%s\" can not be settled: %w", messageId, settleErr))
+		}
+	}
+
+	return errs
+}
+
+// handlePipelineFinished Settles the messages and reports the errors. Used by all the queue input operations.
+func (r *queueInputReader) handlePipelineFinished(out []files.ProcessableFile, errorCh chan<- OperationError) {
+	for _, settleErr := range r.settle(out) {
+		if errorCh != nil {
+			errorCh <- r.errorBuilder().Error(settleErr)
+		}
+	}
+}
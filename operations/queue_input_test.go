@@ -0,0 +1,382 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"github.com/spf13/afero"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeQueueMessage struct {
+	mu sync.Mutex
+
+	id   string
+	body string
+
+	acked      bool
+	nacked     bool
+	extensions int
+}
+
+func (m *fakeQueueMessage) ID() string {
+	return m.id
+}
+
+func (m *fakeQueueMessage) Body() []byte {
+	return []byte(m.body)
+}
+
+func (m *fakeQueueMessage) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.acked = true
+
+	return nil
+}
+
+func (m *fakeQueueMessage) Nack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nacked = true
+
+	return nil
+}
+
+func (m *fakeQueueMessage) ExtendVisibility() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.extensions++
+
+	return nil
+}
+
+type fakeQueueCloser struct {
+	closed int
+}
+
+func (c *fakeQueueCloser) Close() error {
+	c.closed++
+
+	return nil
+}
+
+func TestQueueInputReader_ReadAndSettle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/queue", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/queue/report.pdf", []byte("report"), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("photo"))
+	}))
+	defer server.Close()
+
+	storage := newFakeS3Storage()
+	storage.objects["uploads/incoming/avatar.jpg"] = &fakeS3Object{
+		Body: []byte("avatar"),
+	}
+
+	pathMessage := &fakeQueueMessage{id: "1", body: `{"path": "/tmp/queue/report.pdf"}`}
+	urlMessage := &fakeQueueMessage{id: "2", body: `{"url": "` + server.URL + `/photos/photo.jpg?size=large"}`}
+	s3Message := &fakeQueueMessage{id: "3", body: `{"s3": {"key": "incoming/avatar.jpg"}, "filename": "me.jpg"}`}
+	invalidMessage := &fakeQueueMessage{id: "4", body: `{"unknown": "field"}`}
+	forgottenMessage := &fakeQueueMessage{id: "5", body: `{"path": "/tmp/queue/report.pdf"}`}
+
+	reader := &queueInputReader{
+		OperationName: "queue_input_read",
+		Params: &QueueInputParams{
+			VisibilityExtensionInterval:   10 * time.Millisecond,
+			DownloadAllowedSchemes:        []string{"http"},
+			DownloadAllowPrivateAddresses: true,
+			S3: &S3ClientParams{
+				Bucket: "uploads",
+			},
+		},
+		MetadataKeyMessageId: "queue_input_read.message_id",
+		S3InputReadAPI:       storage,
+	}
+
+	closer := &fakeQueueCloser{}
+	errorCh := make(chan OperationError, 1)
+	out := reader.read(
+		[]QueueMessage{pathMessage, urlMessage, s3Message, invalidMessage, forgottenMessage},
+		closer,
+		errorCh,
+		nil,
+	)
+	close(errorCh)
+
+	if len(out) != 4 {
+		t.Fatalf("len(out) = %d, want 4", len(out))
+	}
+	if len(errorCh) != 1 {
+		t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+	}
+	if !invalidMessage.nacked {
+		t.Fatalf("invalid message is expected to be nacked right away")
+	}
+
+	expectedFiles := []struct {
+		originalFilename string
+		content          string
+	}{
+		{originalFilename: "report.pdf", content: "report"},
+		{originalFilename: "photo.jpg", content: "photo"},
+		{originalFilename: "me.jpg", content: "avatar"},
+	}
+	for i, expectedFile := range expectedFiles {
+		if out[i].OriginalFilename() != expectedFile.originalFilename {
+			t.Fatalf("out[%d].OriginalFilename() = %s, want %s", i, out[i].OriginalFilename(), expectedFile.originalFilename)
+		}
+
+		content, readErr := afero.ReadFile(capyfs.Filesystem, out[i].Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		if string(content) != expectedFile.content {
+			t.Fatalf("out[%d] content = %s, want %s", i, content, expectedFile.content)
+		}
+	}
+
+	if out[0].OperationMetadata["queue_input_read.message_id"] != "1" {
+		t.Fatalf("message id = %v, want 1", out[0].OperationMetadata["queue_input_read.message_id"])
+	}
+
+	// Give the visibility extension some time to kick in.
+	time.Sleep(50 * time.Millisecond)
+
+	pathMessage.mu.Lock()
+	extensions := pathMessage.extensions
+	pathMessage.mu.Unlock()
+	if extensions == 0 {
+		t.Fatalf("message visibility is expected to be extended")
+	}
+
+	if closer.closed != 0 {
+		t.Fatalf("connection is expected to stay open until the messages are settled")
+	}
+
+	// The file of the forgotten message is not in the output anymore, and the S3 file has failed.
+	out[2].SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+	settleErrs := reader.settle(out[:3])
+	if len(settleErrs) != 0 {
+		t.Fatalf("len(settleErrs) = %d, want 0", len(settleErrs))
+	}
+
+	if !pathMessage.acked || !urlMessage.acked {
+		t.Fatalf("messages of the processed files are expected to be acked")
+	}
+	if s3Message.acked || !s3Message.nacked {
+		t.Fatalf("message of the failed file is expected to be nacked")
+	}
+	if forgottenMessage.acked || !forgottenMessage.nacked {
+		t.Fatalf("message of the forgotten file is expected to be nacked")
+	}
+
+	if closer.closed != 1 {
+		t.Fatalf("closer.closed = %d, want 1", closer.closed)
+	}
+
+	if len(reader.settle([]files.ProcessableFile{})) != 0 {
+		t.Fatalf("messages are expected to be settled only once")
+	}
+}
+
+func TestQueueInputReader_ReadWithDownloadLimits(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/photo.jpg", http.StatusFound)
+			return
+		}
+
+		_, _ = w.Write([]byte("photo"))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name   string
+		url    string
+		params QueueInputParams
+	}{
+		{
+			name: "loopback address is not allowed",
+			url:  server.URL + "/photo.jpg",
+			params: QueueInputParams{
+				DownloadAllowedSchemes: []string{"http"},
+			},
+		},
+		{
+			name: "scheme is not allowed",
+			url:  server.URL + "/photo.jpg",
+			params: QueueInputParams{
+				DownloadAllowedSchemes:        []string{"https"},
+				DownloadAllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "host is not allowed",
+			url:  server.URL + "/photo.jpg",
+			params: QueueInputParams{
+				DownloadAllowedSchemes:        []string{"http"},
+				DownloadAllowedHosts:          []string{"*.example.com"},
+				DownloadAllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "file is too large",
+			url:  server.URL + "/photo.jpg",
+			params: QueueInputParams{
+				DownloadMaxFileSize:           2,
+				DownloadAllowedSchemes:        []string{"http"},
+				DownloadAllowPrivateAddresses: true,
+			},
+		},
+		{
+			name: "redirects are not allowed",
+			url:  server.URL + "/redirect",
+			params: QueueInputParams{
+				DownloadAllowedSchemes:        []string{"http"},
+				DownloadAllowPrivateAddresses: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			params := testCase.params
+			reader := &queueInputReader{
+				OperationName:        "queue_input_read",
+				Params:               &params,
+				MetadataKeyMessageId: "queue_input_read.message_id",
+			}
+
+			msg := &fakeQueueMessage{id: "1", body: `{"url": "` + testCase.url + `"}`}
+			errorCh := make(chan OperationError, 1)
+			out := reader.read([]QueueMessage{msg}, nil, errorCh, nil)
+			close(errorCh)
+
+			if len(out) != 0 {
+				t.Fatalf("len(out) = %d, want 0", len(out))
+			}
+			if len(errorCh) != 1 {
+				t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+			}
+			if !msg.nacked {
+				t.Fatalf("message is expected to be nacked right away")
+			}
+		})
+	}
+}
+
+func TestQueueInputReader_SettleWithSplitFiles(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/queue", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/queue/rows.csv", []byte("id\n1\n2\n3\n"), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	processedMessage := &fakeQueueMessage{id: "1", body: `{"path": "/tmp/queue/rows.csv"}`}
+	failedMessage := &fakeQueueMessage{id: "2", body: `{"path": "/tmp/queue/rows.csv"}`}
+
+	reader := &queueInputReader{
+		OperationName:        "queue_input_read",
+		Params:               &QueueInputParams{},
+		MetadataKeyMessageId: "queue_input_read.message_id",
+	}
+
+	closer := &fakeQueueCloser{}
+	in := reader.read([]QueueMessage{processedMessage, failedMessage}, closer, nil, nil)
+	if len(in) != 2 {
+		t.Fatalf("len(in) = %d, want 2", len(in))
+	}
+
+	// The read files are replaced with the chunks, so only the chunks are in the output.
+	operation := &FileSplitOperation{
+		Name: "file_split",
+		Params: &FileSplitOperationParams{
+			LineCount: 2,
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 4 {
+		t.Fatalf("len(out) = %d, want 4", len(out))
+	}
+
+	for i := range out {
+		if out[i].OperationMetadata["queue_input_read.message_id"] == "2" &&
+			out[i].OperationMetadata[MetadataKeyFileSplitIndex] == int64(2) {
+			out[i].SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+		}
+	}
+
+	settleErrs := reader.settle(out)
+	if len(settleErrs) != 0 {
+		t.Fatalf("len(settleErrs) = %d, want 0", len(settleErrs))
+	}
+
+	if !processedMessage.acked || processedMessage.nacked {
+		t.Fatalf("message of the processed chunks is expected to be acked")
+	}
+	if failedMessage.acked || !failedMessage.nacked {
+		t.Fatalf("message with the failed chunk is expected to be nacked")
+	}
+
+	if closer.closed != 1 {
+		t.Fatalf("closer.closed = %d, want 1", closer.closed)
+	}
+}
+
+func TestQueueInputReader_SettleWithoutOutput(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/queue_report.pdf", []byte("report"), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	message := &fakeQueueMessage{id: "1", body: `{"path": "/tmp/queue_report.pdf"}`}
+
+	reader := &queueInputReader{
+		OperationName:        "queue_input_read",
+		Params:               &QueueInputParams{},
+		MetadataKeyMessageId: "queue_input_read.message_id",
+	}
+	reader.read([]QueueMessage{message}, nil, nil, nil)
+
+	// The pipeline has failed.
+	settleErrs := reader.settle(nil)
+	if len(settleErrs) != 0 {
+		t.Fatalf("len(settleErrs) = %d, want 0", len(settleErrs))
+	}
+
+	if message.acked || !message.nacked {
+		t.Fatalf("message is expected to be nacked")
+	}
+}
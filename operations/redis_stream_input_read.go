@@ -0,0 +1,239 @@
+package operations
+
+import (
+	"capyfile/files"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+const MetadataKeyRedisStreamInputReadMessageId = "redis_stream_input_read.message_id"
+
+// RedisStreamInputReadOperation reads the files described by the messages from Redis stream for further processing.
+// The messages are read as a member of the consumer group.
+type RedisStreamInputReadOperation struct {
+	Name   string
+	Params *RedisStreamInputReadOperationParams
+	// queueInputReader reads the files the messages describe.
+	queueInputReader *queueInputReader
+}
+
+type RedisStreamInputReadOperationParams struct {
+	QueueInputParams
+
+	// Addr is the Redis server address. For example: redis:6379
+	Addr     string
+	Username string
+	Password string
+	DB       int64
+
+	Stream string
+	// Group is the consumer group. It is created if it does not exist.
+	Group string
+	// Consumer is the consumer name within the group. Must be unique per worker.
+	Consumer string
+	// Field is the message field that contains the message body.
+	Field string
+	// ClaimMinIdleTime is how long the message must be pending to be claimed from the other consumer.
+	// This is how the messages the other consumers have not acknowledged are delivered again.
+	// 0 means the pending messages of the other consumers are never claimed.
+	ClaimMinIdleTime time.Duration
+}
+
+func (o *RedisStreamInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *RedisStreamInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *RedisStreamInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	// The client must stay open until the messages are settled, so it is closed by the queue input reader.
+	client := redis.NewClient(&redis.Options{
+		Addr:     o.Params.Addr,
+		Username: o.Params.Username,
+		Password: o.Params.Password,
+		DB:       int(o.Params.DB),
+	})
+
+	streamMessages, receiveErr := o.receive(client)
+	if receiveErr != nil {
+		_ = client.Close()
+
+		// Most likely it is a network issue, so the worker keeps polling.
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				fmt.Errorf("Redis stream messages can not be received: %w", receiveErr),
+			)
+		}
+
+		return out, nil
+	}
+
+	var messages []QueueMessage
+	for _, msg := range streamMessages {
+		messages = append(messages, &redisStreamQueueMessage{
+			client: client,
+			params: o.Params,
+			msg:    msg,
+		})
+	}
+
+	return o.reader().read(messages, client, errorCh, notificationCh), nil
+}
+
+// receive Reads the messages the consumer has not acknowledged yet, claims the messages that have been pending
+// for too long, and reads the new ones if there is still room.
+func (o *RedisStreamInputReadOperation) receive(client *redis.Client) ([]redis.XMessage, error) {
+	groupErr := client.XGroupCreateMkStream(context.TODO(), o.Params.Stream, o.Params.Group, "0").Err()
+	if groupErr != nil && !strings.HasPrefix(groupErr.Error(), "BUSYGROUP") {
+		return nil, groupErr
+	}
+
+	// The messages that have been nacked stay pending for this consumer, so they are read again first.
+	messages, pendingErr := o.readGroup(client, "0", o.Params.MaxMessages, -1)
+	if pendingErr != nil {
+		return nil, pendingErr
+	}
+
+	count := o.Params.MaxMessages - int64(len(messages))
+	if count > 0 && o.Params.ClaimMinIdleTime > 0 {
+		claimed, _, claimErr := client.XAutoClaim(context.TODO(), &redis.XAutoClaimArgs{
+			Stream:   o.Params.Stream,
+			Group:    o.Params.Group,
+			Consumer: o.Params.Consumer,
+			MinIdle:  o.Params.ClaimMinIdleTime,
+			Start:    "0-0",
+			Count:    count,
+		}).Result()
+		if claimErr != nil {
+			return nil, claimErr
+		}
+
+		messages = append(messages, claimed...)
+	}
+
+	count = o.Params.MaxMessages - int64(len(messages))
+	if count <= 0 {
+		return messages, nil
+	}
+
+	// Negative block duration means the command does not block.
+	block := o.Params.WaitTime
+	if block == 0 {
+		block = -1
+	}
+
+	newMessages, readErr := o.readGroup(client, ">", count, block)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return append(messages, newMessages...), nil
+}
+
+// readGroup Reads the messages of the consumer starting from the given ID. The ">" ID means the new messages.
+// The messages that have been deleted from the stream meanwhile are acknowledged, since they can not be read.
+func (o *RedisStreamInputReadOperation) readGroup(
+	client *redis.Client,
+	id string,
+	count int64,
+	block time.Duration,
+) ([]redis.XMessage, error) {
+	streams, readErr := client.XReadGroup(context.TODO(), &redis.XReadGroupArgs{
+		Group:    o.Params.Group,
+		Consumer: o.Params.Consumer,
+		Streams:  []string{o.Params.Stream, id},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if readErr != nil && !errors.Is(readErr, redis.Nil) {
+		return nil, readErr
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			if len(msg.Values) == 0 {
+				_ = client.XAck(context.TODO(), o.Params.Stream, o.Params.Group, msg.ID).Err()
+
+				continue
+			}
+
+			messages = append(messages, msg)
+		}
+	}
+
+	return messages, nil
+}
+
+// HandlePipelineFinished Acknowledges the messages of the processed files, and gives the other messages
+// back to the queue.
+func (o *RedisStreamInputReadOperation) HandlePipelineFinished(
+	out []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) {
+	o.reader().handlePipelineFinished(out, errorCh)
+}
+
+func (o *RedisStreamInputReadOperation) reader() *queueInputReader {
+	if o.queueInputReader == nil {
+		o.queueInputReader = &queueInputReader{
+			OperationName:        o.Name,
+			Params:               &o.Params.QueueInputParams,
+			MetadataKeyMessageId: MetadataKeyRedisStreamInputReadMessageId,
+		}
+	}
+
+	return o.queueInputReader
+}
+
+func (o *RedisStreamInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
+
+type redisStreamQueueMessage struct {
+	client *redis.Client
+	params *RedisStreamInputReadOperationParams
+	msg    redis.XMessage
+}
+
+func (m *redisStreamQueueMessage) ID() string {
+	return m.msg.ID
+}
+
+func (m *redisStreamQueueMessage) Body() []byte {
+	return []byte(fmt.Sprint(m.msg.Values[m.params.Field]))
+}
+
+func (m *redisStreamQueueMessage) Ack() error {
+	return m.client.XAck(context.TODO(), m.params.Stream, m.params.Group, m.msg.ID).Err()
+}
+
+func (m *redisStreamQueueMessage) Nack() error {
+	// There is no way to give the message back. It stays pending, so the consumer reads it again
+	// on the next run, or another consumer claims it once it has been idle for ClaimMinIdleTime.
+	return nil
+}
+
+func (m *redisStreamQueueMessage) ExtendVisibility() error {
+	// Claiming the message by the same consumer resets its idle time, so it is not claimed by the others.
+	return m.client.XClaimJustID(context.TODO(), &redis.XClaimArgs{
+		Stream:   m.params.Stream,
+		Group:    m.params.Group,
+		Consumer: m.params.Consumer,
+		MinIdle:  0,
+		Messages: []string{m.msg.ID},
+	}).Err()
+}
@@ -0,0 +1,148 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/afero"
+	"testing"
+	"time"
+)
+
+func TestRedisStreamInputReadOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/redis", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	for _, name := range []string{"document.pdf", "photo.jpg"} {
+		writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/redis/"+name, []byte(name), 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	server := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	for _, name := range []string{"document.pdf", "photo.jpg"} {
+		addErr := client.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: "files",
+			Values: map[string]any{"body": `{"path": "/tmp/redis/` + name + `"}`},
+		}).Err()
+		if addErr != nil {
+			t.Fatal(addErr)
+		}
+	}
+
+	newOperation := func(consumer string) *RedisStreamInputReadOperation {
+		return &RedisStreamInputReadOperation{
+			Name: "redis_stream_input_read",
+			Params: &RedisStreamInputReadOperationParams{
+				QueueInputParams: QueueInputParams{
+					MaxMessages: 10,
+				},
+				Addr:             server.Addr(),
+				Stream:           "files",
+				Group:            "capyworker",
+				Consumer:         consumer,
+				Field:            "body",
+				ClaimMinIdleTime: time.Minute,
+			},
+		}
+	}
+
+	operation := newOperation("worker-1")
+	out, err := operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	if out[0].OriginalFilename() != "document.pdf" || out[1].OriginalFilename() != "photo.jpg" {
+		t.Fatalf("unexpected files %s, %s", out[0].OriginalFilename(), out[1].OriginalFilename())
+	}
+	if out[0].OperationMetadata[MetadataKeyRedisStreamInputReadMessageId] == "" {
+		t.Fatalf("message id is expected to be set")
+	}
+
+	out[1].SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+	settleErrorCh := make(chan OperationError, 10)
+	operation.HandlePipelineFinished(out, settleErrorCh, nil)
+	close(settleErrorCh)
+	if len(settleErrorCh) != 0 {
+		t.Fatalf("len(settleErrorCh) = %d, want 0", len(settleErrorCh))
+	}
+
+	// The failed message stays pending, the processed one is acknowledged.
+	pending, pendingErr := client.XPending(context.Background(), "files", "capyworker").Result()
+	if pendingErr != nil {
+		t.Fatal(pendingErr)
+	}
+	if pending.Count != 1 {
+		t.Fatalf("pending.Count = %d, want 1", pending.Count)
+	}
+
+	// The nacked message stays pending, so the same consumer reads it again right away.
+	operation = newOperation("worker-1")
+	out, err = operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].OriginalFilename() != "photo.jpg" {
+		t.Fatalf("out[0].OriginalFilename() = %s, want photo.jpg", out[0].OriginalFilename())
+	}
+
+	// The pipeline has failed, so the message is nacked again.
+	operation.HandlePipelineFinished(nil, nil, nil)
+
+	// The other consumer does not claim the pending message until it has been idle for long enough.
+	operation = newOperation("worker-2")
+	out, err = operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("len(out) = %d, want 0", len(out))
+	}
+
+	server.SetTime(time.Now().Add(2 * time.Minute))
+
+	operation = newOperation("worker-2")
+	out, err = operation.Handle([]files.ProcessableFile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].OriginalFilename() != "photo.jpg" {
+		t.Fatalf("out[0].OriginalFilename() = %s, want photo.jpg", out[0].OriginalFilename())
+	}
+
+	settleErrorCh = make(chan OperationError, 10)
+	operation.HandlePipelineFinished(out, settleErrorCh, nil)
+	close(settleErrorCh)
+	if len(settleErrorCh) != 0 {
+		t.Fatalf("len(settleErrorCh) = %d, want 0", len(settleErrorCh))
+	}
+
+	pending, pendingErr = client.XPending(context.Background(), "files", "capyworker").Result()
+	if pendingErr != nil {
+		t.Fatal(pendingErr)
+	}
+	if pending.Count != 0 {
+		t.Fatalf("pending.Count = %d, want 0", pending.Count)
+	}
+}
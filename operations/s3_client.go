@@ -0,0 +1,174 @@
+package operations
+
+import (
+	"capyfile/files"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"strings"
+)
+
+// S3ClientParams The parameters to connect to S3-compatible storage. Shared by all the S3 operations.
+type S3ClientParams struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Endpoint        string
+	Region          string
+	Bucket          string
+	// UsePathStyle Whether to use path-style addressing (http://s3.example.com/bucket/key)
+	// instead of virtual hosted-style (http://bucket.s3.example.com/key). Most of the
+	// self-hosted S3-compatible storages (like MinIO) require it.
+	UsePathStyle bool
+}
+
+// compileEndpoint Compiles the endpoint based on the provided pattern.
+//
+// The pattern can contain the following placeholders:
+// - {bucket} - S3 bucket
+// - {region} - S3 region
+//
+// This gives more options to configure the endpoint for different S3 storage providers. For example:
+// - s3.amazonaws.com
+// - https://{region}.digitaloceanspaces.com/{bucket}
+// - https://play.min.io/{bucket}
+// - http://minio.local/{bucket}
+//
+// Another option is to build a custom endpoint resolver for AWS S3 DSK. But this would require a lot
+// of additional work.
+func (p *S3ClientParams) compileEndpoint() (string, error) {
+	replacer := strings.NewReplacer(
+		"{bucket}", p.Bucket,
+		"{region}", p.Region,
+	)
+	return replacer.Replace(p.Endpoint), nil
+}
+
+// newClient Creates S3 client based on the provided parameters.
+func (p *S3ClientParams) newClient() (*s3.Client, error) {
+	endpoint, endpointErr := p.compileEndpoint()
+	if endpointErr != nil {
+		return nil, endpointErr
+	}
+
+	return s3.New(s3.Options{
+		Credentials: credentials.NewStaticCredentialsProvider(
+			p.AccessKeyId,
+			p.SecretAccessKey,
+			p.SessionToken,
+		),
+		Region:           p.Region,
+		EndpointResolver: p.endpointResolver(endpoint),
+		UsePathStyle:     p.UsePathStyle,
+	}), nil
+}
+
+// endpointResolver Resolves the compiled endpoint. For AWS S3 the default resolver is used,
+// so the endpoint is resolved based on the region.
+func (p *S3ClientParams) endpointResolver(endpoint string) s3.EndpointResolver {
+	if endpoint == "" || endpoint == "s3.amazonaws.com" {
+		return s3.NewDefaultEndpointResolver()
+	}
+
+	// The SDK v1 was fine with the endpoint without the scheme, so we keep it this way.
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	return s3.EndpointResolverFromURL(endpoint)
+}
+
+// s3ObjectLocation Retrieves the bucket and the key of the S3 object the processable file is associated with.
+// The object location is taken from the operation metadata, so any operation that reads or writes S3 objects
+// can provide it. If the bucket is not in the metadata, the bucket from the client params is used.
+func (p *S3ClientParams) s3ObjectLocation(
+	pf *files.ProcessableFile,
+	bucketMetadataKey string,
+	keyMetadataKey string,
+) (bucket string, key string, err error) {
+	bucketMetadataKey, keyMetadataKey = s3ObjectMetadataKeys(bucketMetadataKey, keyMetadataKey)
+
+	key, _ = pf.OperationMetadata[keyMetadataKey].(string)
+	if key == "" {
+		return "", "", fmt.Errorf("metadata key \"%s\" is not set", keyMetadataKey)
+	}
+
+	bucket, _ = pf.OperationMetadata[bucketMetadataKey].(string)
+	if bucket == "" {
+		bucket = p.Bucket
+	}
+
+	return bucket, key, nil
+}
+
+// s3ObjectMetadataKeys Returns the metadata keys the object location is stored under. By default,
+// the location written by s3_input_read is used.
+func s3ObjectMetadataKeys(bucketMetadataKey string, keyMetadataKey string) (string, string) {
+	if bucketMetadataKey == "" {
+		bucketMetadataKey = MetadataKeyS3InputReadBucket
+	}
+	if keyMetadataKey == "" {
+		keyMetadataKey = MetadataKeyS3InputReadKey
+	}
+
+	return bucketMetadataKey, keyMetadataKey
+}
+
+// maxDeleteObjectsKeys The maximum number of keys S3 can delete with a single DeleteObjects request.
+const maxDeleteObjectsKeys = 1000
+
+// DeleteObjectsAPI The interface to implement DeleteObjects that we need to delete the objects in batches.
+type DeleteObjectsAPI interface {
+	DeleteObjects(
+		ctx context.Context,
+		params *s3.DeleteObjectsInput,
+		optFns ...func(*s3.Options),
+	) (*s3.DeleteObjectsOutput, error)
+}
+
+// deleteS3Objects Deletes the objects from the bucket with as few DeleteObjects requests as possible.
+// Returns the errors of the objects that have not been deleted by their keys.
+func deleteS3Objects(api DeleteObjectsAPI, bucket string, keys []string) map[string]error {
+	deleteErrs := make(map[string]error)
+
+	for start := 0; start < len(keys); start += maxDeleteObjectsKeys {
+		end := start + maxDeleteObjectsKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		objectIds := make([]types.ObjectIdentifier, 0, end-start)
+		for _, key := range keys[start:end] {
+			objectIds = append(objectIds, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		deleteObjOutput, deleteObjErr := api.DeleteObjects(context.TODO(), &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{
+				Objects: objectIds,
+				// With the quiet mode the response contains only the objects that have not been deleted.
+				Quiet: true,
+			},
+		})
+		if deleteObjErr != nil {
+			for _, key := range keys[start:end] {
+				deleteErrs[key] = deleteObjErr
+			}
+
+			continue
+		}
+
+		for _, deleteErr := range deleteObjOutput.Errors {
+			deleteErrs[aws.ToString(deleteErr.Key)] = fmt.Errorf(
+				"%s: %s",
+				aws.ToString(deleteErr.Code),
+				aws.ToString(deleteErr.Message),
+			)
+		}
+	}
+
+	return deleteErrs
+}
@@ -0,0 +1,320 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeS3Storage In-memory S3 storage that is used instead of MinIO in the tests.
+type fakeS3Storage struct {
+	mu sync.Mutex
+
+	objects map[string]*fakeS3Object
+	uploads map[string]map[int32][]byte
+	aborted []string
+
+	deleteObjectsCalls int
+
+	onPutObject  func(params *s3.PutObjectInput) error
+	onUploadPart func(params *s3.UploadPartInput) error
+}
+
+type fakeS3Object struct {
+	Body         []byte
+	LastModified time.Time
+	Metadata     map[string]string
+}
+
+func newFakeS3Storage() *fakeS3Storage {
+	return &fakeS3Storage{
+		objects: make(map[string]*fakeS3Object),
+		uploads: make(map[string]map[int32][]byte),
+	}
+}
+
+func (s *fakeS3Storage) PutObject(
+	ctx context.Context,
+	params *s3.PutObjectInput,
+	optFns ...func(*s3.Options),
+) (*s3.PutObjectOutput, error) {
+	if s.onPutObject != nil {
+		if err := s.onPutObject(params); err != nil {
+			return nil, err
+		}
+	}
+
+	body, readErr := io.ReadAll(params.Body)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[*params.Bucket+"/"+*params.Key] = &fakeS3Object{
+		Body:         body,
+		LastModified: time.Now(),
+		Metadata:     params.Metadata,
+	}
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (s *fakeS3Storage) CreateMultipartUpload(
+	ctx context.Context,
+	params *s3.CreateMultipartUploadInput,
+	optFns ...func(*s3.Options),
+) (*s3.CreateMultipartUploadOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploadId := fmt.Sprintf("upload-%d", len(s.uploads)+1)
+	s.uploads[uploadId] = make(map[int32][]byte)
+
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:   params.Bucket,
+		Key:      params.Key,
+		UploadId: &uploadId,
+	}, nil
+}
+
+func (s *fakeS3Storage) UploadPart(
+	ctx context.Context,
+	params *s3.UploadPartInput,
+	optFns ...func(*s3.Options),
+) (*s3.UploadPartOutput, error) {
+	if s.onUploadPart != nil {
+		if err := s.onUploadPart(params); err != nil {
+			return nil, err
+		}
+	}
+
+	body, readErr := io.ReadAll(params.Body)
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uploads[*params.UploadId][params.PartNumber] = body
+
+	etag := fmt.Sprintf("etag-%d", params.PartNumber)
+
+	return &s3.UploadPartOutput{ETag: &etag}, nil
+}
+
+func (s *fakeS3Storage) CompleteMultipartUpload(
+	ctx context.Context,
+	params *s3.CompleteMultipartUploadInput,
+	optFns ...func(*s3.Options),
+) (*s3.CompleteMultipartUploadOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := s.uploads[*params.UploadId]
+
+	partNumbers := make([]int, 0, len(parts))
+	for partNumber := range parts {
+		partNumbers = append(partNumbers, int(partNumber))
+	}
+	sort.Ints(partNumbers)
+
+	var body []byte
+	for _, partNumber := range partNumbers {
+		body = append(body, parts[int32(partNumber)]...)
+	}
+
+	s.objects[*params.Bucket+"/"+*params.Key] = &fakeS3Object{
+		Body:         body,
+		LastModified: time.Now(),
+	}
+	delete(s.uploads, *params.UploadId)
+
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (s *fakeS3Storage) AbortMultipartUpload(
+	ctx context.Context,
+	params *s3.AbortMultipartUploadInput,
+	optFns ...func(*s3.Options),
+) (*s3.AbortMultipartUploadOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.uploads, *params.UploadId)
+	s.aborted = append(s.aborted, *params.UploadId)
+
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func (s *fakeS3Storage) ListObjectsV2(
+	ctx context.Context,
+	params *s3.ListObjectsV2Input,
+	optFns ...func(*s3.Options),
+) (*s3.ListObjectsV2Output, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucketPrefix := *params.Bucket + "/" + aws.ToString(params.Prefix)
+
+	var keys []string
+	for k := range s.objects {
+		if strings.HasPrefix(k, bucketPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{}
+	for _, k := range keys {
+		out.Contents = append(out.Contents, types.Object{
+			Key:          aws.String(strings.TrimPrefix(k, *params.Bucket+"/")),
+			Size:         int64(len(s.objects[k].Body)),
+			LastModified: aws.Time(s.objects[k].LastModified),
+		})
+	}
+	out.KeyCount = int32(len(out.Contents))
+
+	return out, nil
+}
+
+func (s *fakeS3Storage) GetObject(
+	ctx context.Context,
+	params *s3.GetObjectInput,
+	optFns ...func(*s3.Options),
+) (*s3.GetObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+
+	return &s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(object.Body)),
+		ContentLength: int64(len(object.Body)),
+		LastModified:  aws.Time(object.LastModified),
+		Metadata:      object.Metadata,
+	}, nil
+}
+
+func (s *fakeS3Storage) DeleteObjects(
+	ctx context.Context,
+	params *s3.DeleteObjectsInput,
+	optFns ...func(*s3.Options),
+) (*s3.DeleteObjectsOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteObjectsCalls++
+
+	out := &s3.DeleteObjectsOutput{}
+	for _, objectId := range params.Delete.Objects {
+		object := *params.Bucket + "/" + *objectId.Key
+		if _, ok := s.objects[object]; !ok {
+			out.Errors = append(out.Errors, types.Error{
+				Key:     objectId.Key,
+				Code:    aws.String("NoSuchKey"),
+				Message: aws.String("The specified key does not exist."),
+			})
+
+			continue
+		}
+
+		delete(s.objects, object)
+	}
+
+	return out, nil
+}
+
+func (s *fakeS3Storage) CopyObject(
+	ctx context.Context,
+	params *s3.CopyObjectInput,
+	optFns ...func(*s3.Options),
+) (*s3.CopyObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copySource, unescapeErr := url.PathUnescape(*params.CopySource)
+	if unescapeErr != nil {
+		return nil, unescapeErr
+	}
+
+	object, ok := s.objects[copySource]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	if int64(len(object.Body)) > s3CopyObjectMaxSize {
+		return nil, errors.New("the specified copy source is larger than the maximum allowable size")
+	}
+
+	s.objects[*params.Bucket+"/"+*params.Key] = &fakeS3Object{
+		Body:         object.Body,
+		LastModified: time.Now(),
+	}
+
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (s *fakeS3Storage) HeadObject(
+	ctx context.Context,
+	params *s3.HeadObjectInput,
+	optFns ...func(*s3.Options),
+) (*s3.HeadObjectOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, &types.NotFound{}
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentLength: int64(len(object.Body)),
+		LastModified:  aws.Time(object.LastModified),
+		Metadata:      object.Metadata,
+	}, nil
+}
+
+func (s *fakeS3Storage) UploadPartCopy(
+	ctx context.Context,
+	params *s3.UploadPartCopyInput,
+	optFns ...func(*s3.Options),
+) (*s3.UploadPartCopyOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copySource, unescapeErr := url.PathUnescape(*params.CopySource)
+	if unescapeErr != nil {
+		return nil, unescapeErr
+	}
+
+	object, ok := s.objects[copySource]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+
+	var start, end int
+	if _, scanErr := fmt.Sscanf(*params.CopySourceRange, "bytes=%d-%d", &start, &end); scanErr != nil {
+		return nil, scanErr
+	}
+
+	s.uploads[*params.UploadId][params.PartNumber] = object.Body[start : end+1]
+
+	etag := fmt.Sprintf("etag-%d", params.PartNumber)
+
+	return &s3.UploadPartCopyOutput{CopyPartResult: &types.CopyPartResult{ETag: &etag}}, nil
+}
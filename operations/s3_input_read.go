@@ -0,0 +1,217 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"path"
+	"strings"
+	"time"
+)
+
+const ErrorCodeS3InputReadOperationConfiguration = "S3_INPUT_READ_OPERATION_CONFIGURATION"
+
+const MetadataKeyS3InputReadBucket = "s3_input_read.bucket"
+const MetadataKeyS3InputReadKey = "s3_input_read.key"
+
+// S3InputReadAPI The interface to implement the S3 API calls that we need to read the files from S3.
+type S3InputReadAPI interface {
+	s3.ListObjectsV2APIClient
+	manager.DownloadAPIClient
+}
+
+// S3InputReadOperation reads input from S3-compatible storage for further processing.
+type S3InputReadOperation struct {
+	Name           string
+	Params         *S3InputReadOperationParams
+	S3InputReadAPI S3InputReadAPI
+}
+
+type S3InputReadOperationParams struct {
+	S3ClientParams
+
+	// Prefix is the prefix of the object keys to list. If empty, all the objects in the bucket are listed.
+	Prefix string
+	// Pattern is the glob pattern the object keys must match. The pattern syntax is the same
+	// as for path.Match, so "*" does not match "/". If empty, all the listed objects are read.
+	// For example: uploads/*/*.jpg
+	Pattern string
+
+	MinLastModified time.Time
+	MaxLastModified time.Time
+	MinFileSize     int64
+	MaxFileSize     int64
+
+	// MaxObjects is the maximum number of objects read at once. The rest of the matching objects
+	// are left for the next run. If zero, all the matching objects are read.
+	MaxObjects int64
+}
+
+func (o *S3InputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *S3InputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+// matches Checks whether the object should be read.
+func (p *S3InputReadOperationParams) matches(object types.Object) bool {
+	key := aws.ToString(object.Key)
+
+	// The "directories" created by some S3 clients are not the files we are interested in.
+	if strings.HasSuffix(key, "/") {
+		return false
+	}
+
+	if p.Pattern != "" {
+		matched, matchErr := path.Match(p.Pattern, key)
+		if matchErr != nil || !matched {
+			return false
+		}
+	}
+
+	lastModified := aws.ToTime(object.LastModified)
+	if !p.MinLastModified.IsZero() && lastModified.Before(p.MinLastModified) {
+		return false
+	}
+	if !p.MaxLastModified.IsZero() && lastModified.After(p.MaxLastModified) {
+		return false
+	}
+
+	if p.MinFileSize > 0 && object.Size < p.MinFileSize {
+		return false
+	}
+	if p.MaxFileSize > 0 && object.Size > p.MaxFileSize {
+		return false
+	}
+
+	return true
+}
+
+func (p *S3InputReadOperationParams) isMaxObjectsReached(readObjects int) bool {
+	return p.MaxObjects > 0 && int64(readObjects) >= p.MaxObjects
+}
+
+func (o *S3InputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.S3InputReadAPI == nil {
+		initErr := o.InitS3InputReadAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("S3 input read API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3InputReadOperationConfiguration,
+				"S3 input read API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	listObjInput := &s3.ListObjectsV2Input{
+		Bucket: aws.String(o.Params.Bucket),
+	}
+	if o.Params.Prefix != "" {
+		listObjInput.Prefix = aws.String(o.Params.Prefix)
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(o.S3InputReadAPI, listObjInput)
+	for paginator.HasMorePages() && !o.Params.isMaxObjectsReached(len(out)) {
+		page, pageErr := paginator.NextPage(context.TODO())
+		if pageErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(pageErr)
+			}
+
+			return out, pageErr
+		}
+
+		for _, object := range page.Contents {
+			if o.Params.isMaxObjectsReached(len(out)) {
+				break
+			}
+
+			if !o.Params.matches(object) {
+				continue
+			}
+
+			key := aws.ToString(object.Key)
+
+			getObjOutput, getObjErr := o.S3InputReadAPI.GetObject(context.TODO(), &s3.GetObjectInput{
+				Bucket: aws.String(o.Params.Bucket),
+				Key:    aws.String(key),
+			})
+			if getObjErr != nil {
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().Error(
+						fmt.Errorf("S3 object \"%s\" can not be read: %w", key, getObjErr),
+					)
+				}
+
+				// Perhaps it makes sense to try to read other objects.
+				continue
+			}
+
+			file, fileWriteErr := capyutils.WriteReaderToAppTmpDirectory(getObjOutput.Body)
+			_ = getObjOutput.Body.Close()
+			if fileWriteErr != nil {
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().Error(fileWriteErr)
+				}
+
+				continue
+			}
+
+			pf := files.NewProcessableFile(file.Name())
+			pf.Metadata.OriginalFilename = path.Base(key)
+			pf.AddOperationMetadata(MetadataKeyS3InputReadBucket, o.Params.Bucket)
+			pf.AddOperationMetadata(MetadataKeyS3InputReadKey, key)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("S3 object read finished", &pf)
+			}
+
+			out = append(out, pf)
+		}
+	}
+
+	return out, nil
+}
+
+// InitS3InputReadAPI Init S3InputReadAPI that we need to read the files from S3.
+func (o *S3InputReadOperation) InitS3InputReadAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.S3InputReadAPI = client
+
+	return nil
+}
+
+func (o *S3InputReadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *S3InputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"testing"
+	"time"
+)
+
+func newS3InputReadFakeStorage() *fakeS3Storage {
+	storage := newFakeS3Storage()
+	storage.objects["files/uploads/1/avatar.jpg"] = &fakeS3Object{
+		Body:         []byte("avatar"),
+		LastModified: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	storage.objects["files/uploads/1/document.pdf"] = &fakeS3Object{
+		Body:         []byte("document"),
+		LastModified: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	storage.objects["files/uploads/2/"] = &fakeS3Object{
+		LastModified: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	storage.objects["files/uploads/2/photo.jpg"] = &fakeS3Object{
+		Body:         bytes.Repeat([]byte("photo"), 1024),
+		LastModified: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	storage.objects["files/processed/avatar.jpg"] = &fakeS3Object{
+		Body:         []byte("avatar"),
+		LastModified: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	return storage
+}
+
+func TestS3InputReadOperation_HandleObjectsReadWithPrefixAndPattern(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var in []files.ProcessableFile
+
+	operation := &S3InputReadOperation{
+		Params: &S3InputReadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			Prefix:  "uploads/",
+			Pattern: "uploads/*/*.jpg",
+		},
+		S3InputReadAPI: newS3InputReadFakeStorage(),
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	expectedKeys := []string{"uploads/1/avatar.jpg", "uploads/2/photo.jpg"}
+	for i, processableFile := range out {
+		if processableFile.OperationMetadata[MetadataKeyS3InputReadBucket] != "files" {
+			t.Fatalf(
+				"metadata key %s = %s, want files",
+				MetadataKeyS3InputReadBucket,
+				processableFile.OperationMetadata[MetadataKeyS3InputReadBucket],
+			)
+		}
+
+		if processableFile.OperationMetadata[MetadataKeyS3InputReadKey] != expectedKeys[i] {
+			t.Fatalf(
+				"metadata key %s = %s, want %s",
+				MetadataKeyS3InputReadKey,
+				processableFile.OperationMetadata[MetadataKeyS3InputReadKey],
+				expectedKeys[i],
+			)
+		}
+	}
+
+	if out[0].OriginalFilename() != "avatar.jpg" {
+		t.Fatalf("OriginalFilename() = %s, want avatar.jpg", out[0].OriginalFilename())
+	}
+
+	content, readErr := capyfs.FilesystemUtils.ReadFile(out[0].Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if string(content) != "avatar" {
+		t.Fatalf("file content = %s, want avatar", content)
+	}
+}
+
+func TestS3InputReadOperation_HandleObjectsReadWithLastModifiedAndSizeFilters(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var in []files.ProcessableFile
+
+	operation := &S3InputReadOperation{
+		Params: &S3InputReadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			MinLastModified: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			MinFileSize:     1024,
+		},
+		S3InputReadAPI: newS3InputReadFakeStorage(),
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].OperationMetadata[MetadataKeyS3InputReadKey] != "uploads/2/photo.jpg" {
+		t.Fatalf(
+			"metadata key %s = %s, want uploads/2/photo.jpg",
+			MetadataKeyS3InputReadKey,
+			out[0].OperationMetadata[MetadataKeyS3InputReadKey],
+		)
+	}
+}
+
+func TestS3InputReadOperation_HandleObjectsReadWithMaxObjects(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	var in []files.ProcessableFile
+
+	operation := &S3InputReadOperation{
+		Params: &S3InputReadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			Prefix:     "uploads/",
+			MaxObjects: 2,
+		},
+		S3InputReadAPI: newS3InputReadFakeStorage(),
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	expectedKeys := []string{"uploads/1/avatar.jpg", "uploads/1/document.pdf"}
+	for i, processableFile := range out {
+		if processableFile.OperationMetadata[MetadataKeyS3InputReadKey] != expectedKeys[i] {
+			t.Fatalf(
+				"metadata key %s = %s, want %s",
+				MetadataKeyS3InputReadKey,
+				processableFile.OperationMetadata[MetadataKeyS3InputReadKey],
+				expectedKeys[i],
+			)
+		}
+	}
+}
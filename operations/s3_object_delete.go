@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/files"
+	"errors"
+)
+
+const ErrorCodeS3ObjectDeleteOperationConfiguration = "S3_OBJECT_DELETE_OPERATION_CONFIGURATION"
+
+// S3ObjectDeleteOperation deletes the S3 objects the processable files are associated with.
+type S3ObjectDeleteOperation struct {
+	Name             string
+	Params           *S3ObjectDeleteOperationParams
+	DeleteObjectsAPI DeleteObjectsAPI
+}
+
+type S3ObjectDeleteOperationParams struct {
+	S3ClientParams
+
+	// BucketMetadataKey is the operation metadata key the object bucket is stored under.
+	// If empty, the bucket written by s3_input_read is used. If the file has no such
+	// metadata, the bucket from the client params is used.
+	BucketMetadataKey string
+	// KeyMetadataKey is the operation metadata key the object key is stored under.
+	// If empty, the key written by s3_input_read is used.
+	KeyMetadataKey string
+}
+
+func (o *S3ObjectDeleteOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *S3ObjectDeleteOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *S3ObjectDeleteOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.DeleteObjectsAPI == nil {
+		initErr := o.InitDeleteObjectsAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("delete objects API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3ObjectDeleteOperationConfiguration,
+				"delete objects API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	// The objects are grouped by bucket, so they can be deleted with DeleteObjects requests.
+	var buckets []string
+	keysByBucket := make(map[string][]string)
+	pfsByObject := make(map[string][]*files.ProcessableFile)
+
+	for i := range in {
+		pf := &in[i]
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Started("S3 object delete started", pf)
+		}
+
+		bucket, key, locationErr := o.Params.s3ObjectLocation(pf, o.Params.BucketMetadataKey, o.Params.KeyMetadataKey)
+		if locationErr != nil {
+			pf.SetFileProcessingError(
+				NewS3ObjectKeyIsMissingError(locationErr),
+			)
+
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(pf, locationErr)
+			}
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(
+					"S3 object key is missing", pf, locationErr)
+			}
+
+			out = append(out, *pf)
+
+			continue
+		}
+
+		if _, ok := keysByBucket[bucket]; !ok {
+			buckets = append(buckets, bucket)
+		}
+
+		object := bucket + "/" + key
+		// The same object can be associated with multiple files, but it must be deleted only once.
+		if _, ok := pfsByObject[object]; !ok {
+			keysByBucket[bucket] = append(keysByBucket[bucket], key)
+		}
+		pfsByObject[object] = append(pfsByObject[object], pf)
+	}
+
+	for _, bucket := range buckets {
+		deleteErrs := deleteS3Objects(o.DeleteObjectsAPI, bucket, keysByBucket[bucket])
+
+		for _, key := range keysByBucket[bucket] {
+			for _, pf := range pfsByObject[bucket+"/"+key] {
+				if deleteErr, ok := deleteErrs[key]; ok {
+					pf.SetFileProcessingError(
+						NewS3ObjectDeleteFailureError(deleteErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, deleteErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"S3 object delete failed with the error", pf, deleteErr)
+					}
+
+					out = append(out, *pf)
+
+					continue
+				}
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("S3 object delete finished", pf)
+				}
+
+				out = append(out, *pf)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// InitDeleteObjectsAPI Init DeleteObjectsAPI that we need to delete the objects from S3.
+func (o *S3ObjectDeleteOperation) InitDeleteObjectsAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.DeleteObjectsAPI = client
+
+	return nil
+}
+
+func (o *S3ObjectDeleteOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *S3ObjectDeleteOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeS3ObjectKeyIsMissing = "FILE_S3_OBJECT_KEY_IS_MISSING"
+
+func NewS3ObjectKeyIsMissingError(origErr error) *S3ObjectKeyIsMissingError {
+	return &S3ObjectKeyIsMissingError{
+		Data: &S3ObjectKeyIsMissingErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type S3ObjectKeyIsMissingError struct {
+	files.FileProcessingError
+	Data *S3ObjectKeyIsMissingErrorData
+}
+
+type S3ObjectKeyIsMissingErrorData struct {
+	OrigErr error
+}
+
+func (e *S3ObjectKeyIsMissingError) Code() string {
+	return ErrorCodeS3ObjectKeyIsMissing
+}
+
+func (e *S3ObjectKeyIsMissingError) Error() string {
+	return "S3 object key is missing"
+}
+
+const ErrorCodeS3ObjectDeleteFailure = "FILE_S3_OBJECT_DELETE_FAILURE"
+
+func NewS3ObjectDeleteFailureError(origErr error) *S3ObjectDeleteFailureError {
+	return &S3ObjectDeleteFailureError{
+		Data: &S3ObjectDeleteFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type S3ObjectDeleteFailureError struct {
+	files.FileProcessingError
+	Data *S3ObjectDeleteFailureErrorData
+}
+
+type S3ObjectDeleteFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *S3ObjectDeleteFailureError) Code() string {
+	return ErrorCodeS3ObjectDeleteFailure
+}
+
+func (e *S3ObjectDeleteFailureError) Error() string {
+	return "failed to delete S3 object"
+}
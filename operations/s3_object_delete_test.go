@@ -0,0 +1,131 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestS3ObjectDeleteOperation_HandleObjectsDelete(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	storage := newFakeS3Storage()
+
+	var in []files.ProcessableFile
+	for i := 0; i < maxDeleteObjectsKeys+1; i++ {
+		key := fmt.Sprintf("inbox/%d.bin", i)
+		storage.objects["files/"+key] = &fakeS3Object{}
+
+		processableFile := files.NewProcessableFile("testdata/file_1kb.bin")
+		processableFile.AddOperationMetadata(MetadataKeyS3InputReadBucket, "files")
+		processableFile.AddOperationMetadata(MetadataKeyS3InputReadKey, key)
+		in = append(in, processableFile)
+	}
+
+	operation := &S3ObjectDeleteOperation{
+		Params: &S3ObjectDeleteOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+		},
+		DeleteObjectsAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != maxDeleteObjectsKeys+1 {
+		t.Fatalf("len(out) = %d, want %d", len(out), maxDeleteObjectsKeys+1)
+	}
+
+	for _, processableFile := range out {
+		if processableFile.FileProcessingError != nil {
+			t.Fatalf(
+				"FileProcessingError.Code() = %s, want nil",
+				processableFile.FileProcessingError.Code(),
+			)
+		}
+	}
+
+	if storage.deleteObjectsCalls != 2 {
+		t.Fatalf("deleteObjectsCalls = %d, want 2", storage.deleteObjectsCalls)
+	}
+
+	if len(storage.objects) != 0 {
+		t.Fatalf("len(objects) = %d, want 0", len(storage.objects))
+	}
+}
+
+func TestS3ObjectDeleteOperation_HandleFailedObjectsDelete(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	storage := newFakeS3Storage()
+	storage.objects["files/inbox/1.bin"] = &fakeS3Object{}
+
+	existingFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	existingFile.AddOperationMetadata(MetadataKeyS3UploadKey, "inbox/1.bin")
+	missingFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	missingFile.AddOperationMetadata(MetadataKeyS3UploadKey, "inbox/2.bin")
+	fileWithoutKey := files.NewProcessableFile("testdata/file_1kb.bin")
+
+	in := []files.ProcessableFile{
+		existingFile,
+		missingFile,
+		fileWithoutKey,
+	}
+
+	operation := &S3ObjectDeleteOperation{
+		Params: &S3ObjectDeleteOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			KeyMetadataKey: MetadataKeyS3UploadKey,
+		},
+		DeleteObjectsAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 3 {
+		t.Fatalf("len(out) = %d, want 3", len(out))
+	}
+
+	for _, processableFile := range out {
+		switch processableFile.NanoID {
+		case existingFile.NanoID:
+			if processableFile.FileProcessingError != nil {
+				t.Fatalf(
+					"FileProcessingError.Code() = %s, want nil",
+					processableFile.FileProcessingError.Code(),
+				)
+			}
+		case missingFile.NanoID:
+			var s3ObjectDeleteFailureError *S3ObjectDeleteFailureError
+			if !errors.As(processableFile.FileProcessingError, &s3ObjectDeleteFailureError) {
+				t.Fatalf(
+					"FileProcessingError = %v, want *S3ObjectDeleteFailureError",
+					processableFile.FileProcessingError,
+				)
+			}
+		case fileWithoutKey.NanoID:
+			var s3ObjectKeyIsMissingError *S3ObjectKeyIsMissingError
+			if !errors.As(processableFile.FileProcessingError, &s3ObjectKeyIsMissingError) {
+				t.Fatalf(
+					"FileProcessingError = %v, want *S3ObjectKeyIsMissingError",
+					processableFile.FileProcessingError,
+				)
+			}
+		}
+	}
+
+	if len(storage.objects) != 0 {
+		t.Fatalf("len(objects) = %d, want 0", len(storage.objects))
+	}
+}
@@ -0,0 +1,415 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/files"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const ErrorCodeS3ObjectMoveOperationConfiguration = "S3_OBJECT_MOVE_OPERATION_CONFIGURATION"
+
+// S3ObjectMoveAPI The interface to implement the S3 API calls that we need to move the objects.
+// S3 has no move API, so the object is copied on the server side and then the source object is deleted.
+type S3ObjectMoveAPI interface {
+	DeleteObjectsAPI
+	HeadObject(
+		ctx context.Context,
+		params *s3.HeadObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.HeadObjectOutput, error)
+	CopyObject(
+		ctx context.Context,
+		params *s3.CopyObjectInput,
+		optFns ...func(*s3.Options),
+	) (*s3.CopyObjectOutput, error)
+	// The objects bigger than 5GiB can not be copied with CopyObject, so they are copied part by part.
+	CreateMultipartUpload(
+		ctx context.Context,
+		params *s3.CreateMultipartUploadInput,
+		optFns ...func(*s3.Options),
+	) (*s3.CreateMultipartUploadOutput, error)
+	UploadPartCopy(
+		ctx context.Context,
+		params *s3.UploadPartCopyInput,
+		optFns ...func(*s3.Options),
+	) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(
+		ctx context.Context,
+		params *s3.CompleteMultipartUploadInput,
+		optFns ...func(*s3.Options),
+	) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(
+		ctx context.Context,
+		params *s3.AbortMultipartUploadInput,
+		optFns ...func(*s3.Options),
+	) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// s3CopyObjectMaxSize The biggest object that can be copied with a single CopyObject request.
+var s3CopyObjectMaxSize int64 = 5 * 1024 * 1024 * 1024
+
+// s3CopyPartMinSize The size of the part the bigger objects are copied by. The part is bigger
+// if the object does not fit into the maximum number of parts.
+var s3CopyPartMinSize int64 = 512 * 1024 * 1024
+
+const s3MaxPartsCount = 10000
+
+// S3ObjectMoveOperation moves the S3 objects the processable files are associated with.
+type S3ObjectMoveOperation struct {
+	Name            string
+	Params          *S3ObjectMoveOperationParams
+	S3ObjectMoveAPI S3ObjectMoveAPI
+}
+
+type S3ObjectMoveOperationParams struct {
+	S3ClientParams
+
+	// BucketMetadataKey is the operation metadata key the object bucket is stored under.
+	// If empty, the bucket written by s3_input_read is used. If the file has no such
+	// metadata, the bucket from the client params is used.
+	BucketMetadataKey string
+	// KeyMetadataKey is the operation metadata key the object key is stored under.
+	// If empty, the key written by s3_input_read is used.
+	KeyMetadataKey string
+
+	// DestinationBucket is the bucket to move the objects to. If empty, the objects
+	// are moved within the same bucket.
+	DestinationBucket string
+	// DestinationPrefix is the prefix the destination key is built with. For example: processed/
+	DestinationPrefix string
+	// TrimPrefix is the prefix to remove from the source key before adding the destination prefix.
+	// For example, with "inbox/" and "processed/", inbox/1/a.jpg is moved to processed/1/a.jpg.
+	TrimPrefix string
+}
+
+func (o *S3ObjectMoveOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *S3ObjectMoveOperation) AllowConcurrency() bool {
+	return true
+}
+
+// destination Returns the bucket and the key the object must be moved to.
+func (p *S3ObjectMoveOperationParams) destination(bucket string, key string) (string, string) {
+	destBucket := bucket
+	if p.DestinationBucket != "" {
+		destBucket = p.DestinationBucket
+	}
+
+	return destBucket, p.DestinationPrefix + strings.TrimPrefix(key, p.TrimPrefix)
+}
+
+// s3ObjectMove The object to move along with the files that are associated with it.
+type s3ObjectMove struct {
+	bucket     string
+	key        string
+	destBucket string
+	destKey    string
+	pfs        []*files.ProcessableFile
+	copied     bool
+}
+
+func (o *S3ObjectMoveOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.S3ObjectMoveAPI == nil {
+		initErr := o.InitS3ObjectMoveAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("S3 object move API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3ObjectMoveOperationConfiguration,
+				"S3 object move API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	outHolder := newOutputHolder()
+
+	// The same object can be associated with multiple files, but it must be moved only once.
+	var moves []*s3ObjectMove
+	movesByObject := make(map[string]*s3ObjectMove)
+
+	for i := range in {
+		pf := &in[i]
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Started("S3 object move started", pf)
+		}
+
+		bucket, key, locationErr := o.Params.s3ObjectLocation(pf, o.Params.BucketMetadataKey, o.Params.KeyMetadataKey)
+		if locationErr != nil {
+			pf.SetFileProcessingError(
+				NewS3ObjectKeyIsMissingError(locationErr),
+			)
+
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(pf, locationErr)
+			}
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(
+					"S3 object key is missing", pf, locationErr)
+			}
+
+			outHolder.AppendToOut(pf)
+
+			continue
+		}
+
+		destBucket, destKey := o.Params.destination(bucket, key)
+		// Copying the object to itself and deleting the source would remove the object.
+		if destBucket == bucket && destKey == key {
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("S3 object is already in the destination", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+
+			continue
+		}
+
+		move, ok := movesByObject[bucket+"/"+key]
+		if !ok {
+			move = &s3ObjectMove{
+				bucket:     bucket,
+				key:        key,
+				destBucket: destBucket,
+				destKey:    destKey,
+			}
+
+			moves = append(moves, move)
+			movesByObject[bucket+"/"+key] = move
+		}
+		move.pfs = append(move.pfs, pf)
+	}
+
+	var wg sync.WaitGroup
+	var copiedLock sync.Mutex
+	copiedKeysByBucket := make(map[string][]string)
+
+	for _, move := range moves {
+		wg.Add(1)
+
+		go func(move *s3ObjectMove) {
+			defer wg.Done()
+
+			copyObjErr := o.copyObject(move)
+			if copyObjErr != nil {
+				for _, pf := range move.pfs {
+					pf.SetFileProcessingError(
+						NewS3ObjectCopyFailureError(copyObjErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, copyObjErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"S3 object copy failed with the error", pf, copyObjErr)
+					}
+
+					outHolder.AppendToOut(pf)
+				}
+
+				return
+			}
+
+			move.copied = true
+
+			copiedLock.Lock()
+			copiedKeysByBucket[move.bucket] = append(copiedKeysByBucket[move.bucket], move.key)
+			copiedLock.Unlock()
+		}(move)
+	}
+
+	wg.Wait()
+
+	deleteErrsByBucket := make(map[string]map[string]error, len(copiedKeysByBucket))
+	for bucket, keys := range copiedKeysByBucket {
+		deleteErrsByBucket[bucket] = deleteS3Objects(o.S3ObjectMoveAPI, bucket, keys)
+	}
+
+	bucketMetadataKey, keyMetadataKey := s3ObjectMetadataKeys(o.Params.BucketMetadataKey, o.Params.KeyMetadataKey)
+
+	for _, move := range moves {
+		// The object has not been copied, so the files are already in the output.
+		if !move.copied {
+			continue
+		}
+
+		for _, pf := range move.pfs {
+			if deleteErr, ok := deleteErrsByBucket[move.bucket][move.key]; ok {
+				pf.SetFileProcessingError(
+					NewS3ObjectDeleteFailureError(deleteErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, deleteErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"source S3 object delete failed with the error", pf, deleteErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				continue
+			}
+
+			pf.AddOperationMetadata(bucketMetadataKey, move.destBucket)
+			pf.AddOperationMetadata(keyMetadataKey, move.destKey)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("S3 object move finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}
+	}
+
+	return outHolder.Out, nil
+}
+
+// copyObject Copies the object to the destination. The objects bigger than CopyObject can copy
+// are copied part by part with the multipart upload.
+func (o *S3ObjectMoveOperation) copyObject(move *s3ObjectMove) error {
+	copySource := (&url.URL{Path: move.bucket + "/" + move.key}).EscapedPath()
+
+	headObjOutput, headObjErr := o.S3ObjectMoveAPI.HeadObject(context.TODO(), &s3.HeadObjectInput{
+		Bucket: aws.String(move.bucket),
+		Key:    aws.String(move.key),
+	})
+	if headObjErr != nil {
+		return headObjErr
+	}
+
+	if headObjOutput.ContentLength <= s3CopyObjectMaxSize {
+		_, copyObjErr := o.S3ObjectMoveAPI.CopyObject(context.TODO(), &s3.CopyObjectInput{
+			Bucket:     aws.String(move.destBucket),
+			Key:        aws.String(move.destKey),
+			CopySource: aws.String(copySource),
+		})
+
+		return copyObjErr
+	}
+
+	// Unlike CopyObject, the multipart upload does not copy the object metadata.
+	createUploadOutput, createUploadErr := o.S3ObjectMoveAPI.CreateMultipartUpload(
+		context.TODO(),
+		&s3.CreateMultipartUploadInput{
+			Bucket:             aws.String(move.destBucket),
+			Key:                aws.String(move.destKey),
+			CacheControl:       headObjOutput.CacheControl,
+			ContentDisposition: headObjOutput.ContentDisposition,
+			ContentEncoding:    headObjOutput.ContentEncoding,
+			ContentLanguage:    headObjOutput.ContentLanguage,
+			ContentType:        headObjOutput.ContentType,
+			Metadata:           headObjOutput.Metadata,
+		},
+	)
+	if createUploadErr != nil {
+		return createUploadErr
+	}
+
+	partSize := s3CopyPartMinSize
+	if minPartSize := (headObjOutput.ContentLength + s3MaxPartsCount - 1) / s3MaxPartsCount; minPartSize > partSize {
+		partSize = minPartSize
+	}
+
+	var completedParts []types.CompletedPart
+	for offset := int64(0); offset < headObjOutput.ContentLength; offset += partSize {
+		end := offset + partSize
+		if end > headObjOutput.ContentLength {
+			end = headObjOutput.ContentLength
+		}
+
+		partNumber := int32(len(completedParts) + 1)
+
+		uploadPartOutput, uploadPartErr := o.S3ObjectMoveAPI.UploadPartCopy(context.TODO(), &s3.UploadPartCopyInput{
+			Bucket:          aws.String(move.destBucket),
+			Key:             aws.String(move.destKey),
+			UploadId:        createUploadOutput.UploadId,
+			PartNumber:      partNumber,
+			CopySource:      aws.String(copySource),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, end-1)),
+		})
+		if uploadPartErr != nil {
+			o.abortMultipartUpload(move, createUploadOutput.UploadId)
+
+			return uploadPartErr
+		}
+
+		completedPart := types.CompletedPart{PartNumber: partNumber}
+		if uploadPartOutput.CopyPartResult != nil {
+			completedPart.ETag = uploadPartOutput.CopyPartResult.ETag
+		}
+		completedParts = append(completedParts, completedPart)
+	}
+
+	_, completeUploadErr := o.S3ObjectMoveAPI.CompleteMultipartUpload(
+		context.TODO(),
+		&s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(move.destBucket),
+			Key:             aws.String(move.destKey),
+			UploadId:        createUploadOutput.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+		},
+	)
+	if completeUploadErr != nil {
+		o.abortMultipartUpload(move, createUploadOutput.UploadId)
+
+		return completeUploadErr
+	}
+
+	return nil
+}
+
+// abortMultipartUpload Aborts the failed multipart upload, so the copied parts are not stored.
+// The abort error is ignored since the copy error is what matters.
+func (o *S3ObjectMoveOperation) abortMultipartUpload(move *s3ObjectMove, uploadId *string) {
+	_, _ = o.S3ObjectMoveAPI.AbortMultipartUpload(context.TODO(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(move.destBucket),
+		Key:      aws.String(move.destKey),
+		UploadId: uploadId,
+	})
+}
+
+// InitS3ObjectMoveAPI Init S3ObjectMoveAPI that we need to move the objects.
+func (o *S3ObjectMoveOperation) InitS3ObjectMoveAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.S3ObjectMoveAPI = client
+
+	return nil
+}
+
+func (o *S3ObjectMoveOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *S3ObjectMoveOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
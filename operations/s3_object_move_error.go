@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeS3ObjectCopyFailure = "FILE_S3_OBJECT_COPY_FAILURE"
+
+func NewS3ObjectCopyFailureError(origErr error) *S3ObjectCopyFailureError {
+	return &S3ObjectCopyFailureError{
+		Data: &S3ObjectCopyFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type S3ObjectCopyFailureError struct {
+	files.FileProcessingError
+	Data *S3ObjectCopyFailureErrorData
+}
+
+type S3ObjectCopyFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *S3ObjectCopyFailureError) Code() string {
+	return ErrorCodeS3ObjectCopyFailure
+}
+
+func (e *S3ObjectCopyFailureError) Error() string {
+	return "failed to copy S3 object"
+}
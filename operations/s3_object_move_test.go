@@ -0,0 +1,195 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"testing"
+)
+
+func TestS3ObjectMoveOperation_HandleObjectsMove(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	storage := newFakeS3Storage()
+	storage.objects["inbox/uploads/1/avatar.jpg"] = &fakeS3Object{Body: []byte("avatar")}
+	storage.objects["inbox/uploads/2/photo 1.jpg"] = &fakeS3Object{Body: []byte("photo")}
+
+	avatarFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	avatarFile.AddOperationMetadata(MetadataKeyS3InputReadBucket, "inbox")
+	avatarFile.AddOperationMetadata(MetadataKeyS3InputReadKey, "uploads/1/avatar.jpg")
+	photoFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	photoFile.AddOperationMetadata(MetadataKeyS3InputReadBucket, "inbox")
+	photoFile.AddOperationMetadata(MetadataKeyS3InputReadKey, "uploads/2/photo 1.jpg")
+
+	in := []files.ProcessableFile{
+		avatarFile,
+		photoFile,
+	}
+
+	operation := &S3ObjectMoveOperation{
+		Params: &S3ObjectMoveOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "inbox",
+			},
+			DestinationBucket: "archive",
+			DestinationPrefix: "processed/",
+			TrimPrefix:        "uploads/",
+		},
+		S3ObjectMoveAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	expectedKeys := map[string]string{
+		avatarFile.NanoID: "processed/1/avatar.jpg",
+		photoFile.NanoID:  "processed/2/photo 1.jpg",
+	}
+	for _, processableFile := range out {
+		if processableFile.FileProcessingError != nil {
+			t.Fatalf(
+				"FileProcessingError.Code() = %s, want nil",
+				processableFile.FileProcessingError.Code(),
+			)
+		}
+
+		if processableFile.OperationMetadata[MetadataKeyS3InputReadBucket] != "archive" {
+			t.Fatalf(
+				"metadata key %s = %s, want archive",
+				MetadataKeyS3InputReadBucket,
+				processableFile.OperationMetadata[MetadataKeyS3InputReadBucket],
+			)
+		}
+
+		expectedKey := expectedKeys[processableFile.NanoID]
+		if processableFile.OperationMetadata[MetadataKeyS3InputReadKey] != expectedKey {
+			t.Fatalf(
+				"metadata key %s = %s, want %s",
+				MetadataKeyS3InputReadKey,
+				processableFile.OperationMetadata[MetadataKeyS3InputReadKey],
+				expectedKey,
+			)
+		}
+
+		if _, ok := storage.objects["archive/"+expectedKey]; !ok {
+			t.Fatalf("object archive/%s does not exist", expectedKey)
+		}
+	}
+
+	if len(storage.objects) != 2 {
+		t.Fatalf("len(objects) = %d, want 2", len(storage.objects))
+	}
+}
+
+func TestS3ObjectMoveOperation_HandleFailedObjectsCopy(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	storage := newFakeS3Storage()
+
+	processableFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	processableFile.AddOperationMetadata(MetadataKeyS3InputReadKey, "inbox/1.bin")
+
+	in := []files.ProcessableFile{
+		processableFile,
+	}
+
+	operation := &S3ObjectMoveOperation{
+		Params: &S3ObjectMoveOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			DestinationPrefix: "failed/",
+			TrimPrefix:        "inbox/",
+		},
+		S3ObjectMoveAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	var s3ObjectCopyFailureError *S3ObjectCopyFailureError
+	if !errors.As(out[0].FileProcessingError, &s3ObjectCopyFailureError) {
+		t.Fatalf("FileProcessingError = %v, want *S3ObjectCopyFailureError", out[0].FileProcessingError)
+	}
+
+	if storage.deleteObjectsCalls != 0 {
+		t.Fatalf("deleteObjectsCalls = %d, want 0", storage.deleteObjectsCalls)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyS3InputReadKey] != "inbox/1.bin" {
+		t.Fatalf(
+			"metadata key %s = %s, want inbox/1.bin",
+			MetadataKeyS3InputReadKey,
+			out[0].OperationMetadata[MetadataKeyS3InputReadKey],
+		)
+	}
+}
+
+func TestS3ObjectMoveOperation_HandleBigObjectMove(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	copyObjectMaxSize, copyPartMinSize := s3CopyObjectMaxSize, s3CopyPartMinSize
+	s3CopyObjectMaxSize, s3CopyPartMinSize = 8, 4
+	defer func() {
+		s3CopyObjectMaxSize, s3CopyPartMinSize = copyObjectMaxSize, copyPartMinSize
+	}()
+
+	storage := newFakeS3Storage()
+	storage.objects["inbox/uploads/video.mp4"] = &fakeS3Object{Body: []byte("big video content")}
+
+	processableFile := files.NewProcessableFile("testdata/file_1kb.bin")
+	processableFile.AddOperationMetadata(MetadataKeyS3InputReadBucket, "inbox")
+	processableFile.AddOperationMetadata(MetadataKeyS3InputReadKey, "uploads/video.mp4")
+
+	operation := &S3ObjectMoveOperation{
+		Params: &S3ObjectMoveOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "inbox",
+			},
+			DestinationPrefix: "processed/",
+			TrimPrefix:        "uploads/",
+		},
+		S3ObjectMoveAPI: storage,
+	}
+	out, err := operation.Handle([]files.ProcessableFile{processableFile}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf("FileProcessingError.Code() = %s, want nil", out[0].FileProcessingError.Code())
+	}
+
+	object, ok := storage.objects["inbox/processed/video.mp4"]
+	if !ok {
+		t.Fatal("object inbox/processed/video.mp4 does not exist")
+	}
+	if string(object.Body) != "big video content" {
+		t.Fatalf("object body = %q, want %q", object.Body, "big video content")
+	}
+
+	if _, ok := storage.objects["inbox/uploads/video.mp4"]; ok {
+		t.Fatal("object inbox/uploads/video.mp4 has not been deleted")
+	}
+
+	if len(storage.uploads) != 0 {
+		t.Fatalf("len(uploads) = %d, want 0", len(storage.uploads))
+	}
+}
@@ -0,0 +1,312 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ErrorCodeS3PresignedUploadInputReadOperationConfiguration = "S3_PRESIGNED_UPLOAD_INPUT_READ_OPERATION_CONFIGURATION"
+
+// metadataKeyS3PresignedUploadOriginalFilename The S3 object metadata key the original filename is stored under.
+const metadataKeyS3PresignedUploadOriginalFilename = "original-filename"
+
+// PresignedUpload The presigned request the client can upload the file directly to the storage with.
+type PresignedUpload struct {
+	Filename string
+	Key      string
+	// Token is the token the client must send along with the key when the upload is completed.
+	// It proves that the key has been issued to the client.
+	Token string
+
+	Method string
+	Url    string
+	// Headers are the headers the client must send along with the request because they are signed.
+	Headers   http.Header
+	ExpiresAt time.Time
+}
+
+// PresignedUploadIssuer The operation that can issue presigned requests to upload the files directly
+// to the storage. The uploaded files are read by the same operation when the upload is completed.
+type PresignedUploadIssuer interface {
+	IssuePresignedUpload(filename string) (*PresignedUpload, error)
+}
+
+// PresignPutObjectAPI The interface to implement PresignPutObject that we need to issue the presigned uploads.
+type PresignPutObjectAPI interface {
+	PresignPutObject(
+		ctx context.Context,
+		params *s3.PutObjectInput,
+		optFns ...func(*s3.PresignOptions),
+	) (*v4.PresignedHTTPRequest, error)
+}
+
+// S3PresignedUploadInputReadOperation issues presigned requests to upload the files directly to
+// S3-compatible storage, and reads the uploaded files for further processing when the client
+// reports that the upload is completed.
+type S3PresignedUploadInputReadOperation struct {
+	Name   string
+	Params *S3PresignedUploadInputReadOperationParams
+	Req    *http.Request
+
+	PresignPutObjectAPI PresignPutObjectAPI
+	GetObjectAPI        manager.DownloadAPIClient
+}
+
+type S3PresignedUploadInputReadOperationParams struct {
+	S3ClientParams
+
+	// KeyPrefix is the prefix of the object keys the presigned requests are issued for.
+	// Only the objects with this prefix can be read, so the clients can not read arbitrary
+	// objects from the bucket. For example: incoming/
+	KeyPrefix string
+	// TokenSecret is the secret the tokens of the issued keys are signed with.
+	TokenSecret string
+	// PresignedUrlExpiration is how long the presigned request is valid for.
+	PresignedUrlExpiration time.Duration
+	// TokenExpiration is how long the token of the issued key is valid for, so how long the client has
+	// to report that the upload is completed. Must not be less than PresignedUrlExpiration.
+	TokenExpiration time.Duration
+}
+
+func (o *S3PresignedUploadInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *S3PresignedUploadInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *S3PresignedUploadInputReadOperation) IssuePresignedUpload(filename string) (*PresignedUpload, error) {
+	if o.PresignPutObjectAPI == nil {
+		initErr := o.InitPresignPutObjectAPI()
+		if initErr != nil {
+			return nil, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3PresignedUploadInputReadOperationConfiguration,
+				"presign put object API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	filename = path.Base(filename)
+	key := o.Params.KeyPrefix + gonanoid.Must() + strings.ToLower(path.Ext(filename))
+
+	presignedAt := time.Now()
+	presignedReq, presignErr := o.PresignPutObjectAPI.PresignPutObject(
+		context.TODO(),
+		&s3.PutObjectInput{
+			Bucket: aws.String(o.Params.Bucket),
+			Key:    aws.String(key),
+			// The header values must be ASCII, so the filename is escaped.
+			Metadata: map[string]string{
+				metadataKeyS3PresignedUploadOriginalFilename: url.PathEscape(filename),
+			},
+		},
+		s3.WithPresignExpires(o.Params.PresignedUrlExpiration),
+	)
+	if presignErr != nil {
+		return nil, presignErr
+	}
+
+	headers := presignedReq.SignedHeader.Clone()
+	// The host header is set by the HTTP client.
+	headers.Del("Host")
+
+	return &PresignedUpload{
+		Filename:  filename,
+		Key:       key,
+		Token:     o.Params.keyToken(key, presignedAt.Add(o.Params.TokenExpiration)),
+		Method:    presignedReq.Method,
+		Url:       presignedReq.URL,
+		Headers:   headers,
+		ExpiresAt: presignedAt.Add(o.Params.PresignedUrlExpiration),
+	}, nil
+}
+
+// keyToken Signs the issued key along with the token expiration time, so the client can prove the key
+// has been issued to it. The token is the expiration Unix time and the signature separated by the dot.
+func (p *S3PresignedUploadInputReadOperationParams) keyToken(key string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(p.TokenSecret))
+	mac.Write([]byte(p.Bucket + "/" + key + "\n" + expires))
+
+	return expires + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isIssuedKey Checks whether the key is the one the presigned request has been issued for, and its token
+// has not expired yet.
+func (p *S3PresignedUploadInputReadOperationParams) isIssuedKey(key, token string) bool {
+	if p.KeyPrefix == "" || p.TokenSecret == "" {
+		return false
+	}
+
+	if !strings.HasPrefix(key, p.KeyPrefix) || strings.Contains(key[len(p.KeyPrefix):], "/") {
+		return false
+	}
+
+	expires, _, found := strings.Cut(token, ".")
+	if !found {
+		return false
+	}
+	expiresUnix, parseErr := strconv.ParseInt(expires, 10, 64)
+	if parseErr != nil {
+		return false
+	}
+	expiresAt := time.Unix(expiresUnix, 0)
+	if !time.Now().Before(expiresAt) {
+		return false
+	}
+
+	return hmac.Equal([]byte(token), []byte(p.keyToken(key, expiresAt)))
+}
+
+func (o *S3PresignedUploadInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.GetObjectAPI == nil {
+		initErr := o.InitGetObjectAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("get object API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3PresignedUploadInputReadOperationConfiguration,
+				"get object API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	parseErr := o.Req.ParseForm()
+	if parseErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(parseErr)
+		}
+
+		return out, parseErr
+	}
+
+	// The tokens are sent in the same order as the keys.
+	tokens := o.Req.Form["token"]
+	for i, key := range o.Req.Form["key"] {
+		var token string
+		if i < len(tokens) {
+			token = tokens[i]
+		}
+
+		if !o.Params.isIssuedKey(key, token) {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("S3 object \"%s\" is not allowed to be read", key),
+				)
+			}
+
+			continue
+		}
+
+		getObjOutput, getObjErr := o.GetObjectAPI.GetObject(context.TODO(), &s3.GetObjectInput{
+			Bucket: aws.String(o.Params.Bucket),
+			Key:    aws.String(key),
+		})
+		if getObjErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("S3 object \"%s\" can not be read: %w", key, getObjErr),
+				)
+			}
+
+			// Perhaps it makes sense to try to read other objects.
+			continue
+		}
+
+		file, fileWriteErr := capyutils.WriteReaderToAppTmpDirectory(getObjOutput.Body)
+		_ = getObjOutput.Body.Close()
+		if fileWriteErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(fileWriteErr)
+			}
+
+			continue
+		}
+
+		pf := files.NewProcessableFile(file.Name())
+		pf.Metadata.OriginalFilename = path.Base(key)
+		if originalFilename, ok := getObjOutput.Metadata[metadataKeyS3PresignedUploadOriginalFilename]; ok {
+			if unescapedFilename, unescapeErr := url.PathUnescape(originalFilename); unescapeErr == nil {
+				pf.Metadata.OriginalFilename = unescapedFilename
+			}
+		}
+		// The same metadata as s3_input_read writes, so s3_object_delete and s3_object_move
+		// can be used to clean up the uploaded objects.
+		pf.AddOperationMetadata(MetadataKeyS3InputReadBucket, o.Params.Bucket)
+		pf.AddOperationMetadata(MetadataKeyS3InputReadKey, key)
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("presigned upload file read finished", &pf)
+		}
+
+		out = append(out, pf)
+	}
+
+	return out, nil
+}
+
+// InitPresignPutObjectAPI Init PresignPutObjectAPI that we need to issue the presigned uploads.
+func (o *S3PresignedUploadInputReadOperation) InitPresignPutObjectAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.PresignPutObjectAPI = s3.NewPresignClient(client)
+
+	return nil
+}
+
+// InitGetObjectAPI Init GetObjectAPI that we need to read the uploaded files.
+func (o *S3PresignedUploadInputReadOperation) InitGetObjectAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.GetObjectAPI = client
+
+	return nil
+}
+
+func (o *S3PresignedUploadInputReadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *S3PresignedUploadInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,171 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestS3PresignedUploadInputReadOperation_IssuePresignedUpload(t *testing.T) {
+	operation := &S3PresignedUploadInputReadOperation{
+		Params: &S3PresignedUploadInputReadOperationParams{
+			S3ClientParams: S3ClientParams{
+				AccessKeyId:     "minioadmin",
+				SecretAccessKey: "minioadmin",
+				Endpoint:        "http://minio.local",
+				Region:          "us-east-1",
+				Bucket:          "files",
+				UsePathStyle:    true,
+			},
+			KeyPrefix:              "incoming/",
+			TokenSecret:            "secret",
+			PresignedUrlExpiration: 15 * time.Minute,
+			TokenExpiration:        time.Hour,
+		},
+	}
+
+	upload, err := operation.IssuePresignedUpload("Фото.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if upload.Method != http.MethodPut {
+		t.Fatalf("Method = %s, want %s", upload.Method, http.MethodPut)
+	}
+
+	if !strings.HasPrefix(upload.Key, "incoming/") || !strings.HasSuffix(upload.Key, ".jpg") {
+		t.Fatalf("Key = %s, want incoming/*.jpg", upload.Key)
+	}
+
+	expectedUrlPrefix := "http://minio.local/files/" + upload.Key + "?"
+	if !strings.HasPrefix(upload.Url, expectedUrlPrefix) {
+		t.Fatalf("Url = %s, want prefix %s", upload.Url, expectedUrlPrefix)
+	}
+
+	if !strings.Contains(upload.Url, "X-Amz-Signature=") {
+		t.Fatalf("Url = %s, want to contain X-Amz-Signature", upload.Url)
+	}
+
+	originalFilename := upload.Headers.Get("X-Amz-Meta-Original-Filename")
+	if originalFilename != url.PathEscape("Фото.JPG") {
+		t.Fatalf(
+			"Headers[X-Amz-Meta-Original-Filename] = %s, want %s",
+			originalFilename,
+			url.PathEscape("Фото.JPG"),
+		)
+	}
+
+	if upload.Headers.Get("Host") != "" {
+		t.Fatalf("Headers[Host] = %s, want empty", upload.Headers.Get("Host"))
+	}
+
+	if !operation.Params.isIssuedKey(upload.Key, upload.Token) {
+		t.Fatalf("Token = %s, want the token of the key %s", upload.Token, upload.Key)
+	}
+
+	// The expiration time is signed too, so it can not be extended by the client.
+	_, signature, _ := strings.Cut(upload.Token, ".")
+	extendedToken := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10) + "." + signature
+	if operation.Params.isIssuedKey(upload.Key, extendedToken) {
+		t.Fatalf("expected the token with the extended expiration time to be refused")
+	}
+}
+
+func TestS3PresignedUploadInputReadOperation_HandleUploadedFilesRead(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	storage := newFakeS3Storage()
+	storage.objects["files/incoming/V1StGXR8_Z5jdHi6B-myT.jpg"] = &fakeS3Object{
+		Body: []byte("photo"),
+		Metadata: map[string]string{
+			"original-filename": url.PathEscape("Фото.JPG"),
+		},
+	}
+	storage.objects["files/private/secret.txt"] = &fakeS3Object{
+		Body: []byte("secret"),
+	}
+
+	storage.objects["files/incoming/other-client-upload.jpg"] = &fakeS3Object{
+		Body: []byte("other photo"),
+	}
+	storage.objects["files/incoming/expired-upload.jpg"] = &fakeS3Object{
+		Body: []byte("expired photo"),
+	}
+
+	params := &S3PresignedUploadInputReadOperationParams{
+		S3ClientParams: S3ClientParams{
+			Endpoint: "http://minio.local",
+			Bucket:   "files",
+		},
+		KeyPrefix:   "incoming/",
+		TokenSecret: "secret",
+	}
+
+	form := url.Values{}
+	for _, key := range []string{
+		"incoming/V1StGXR8_Z5jdHi6B-myT.jpg",
+		"private/secret.txt",
+		"incoming/../private/secret.txt",
+		"incoming/other-client-upload.jpg",
+		"incoming/expired-upload.jpg",
+	} {
+		form.Add("key", key)
+		switch key {
+		case "incoming/other-client-upload.jpg":
+			// The key has not been issued to the client, so it can not know the token.
+			form.Add("token", params.keyToken("incoming/V1StGXR8_Z5jdHi6B-myT.jpg", time.Now().Add(time.Hour)))
+		case "incoming/expired-upload.jpg":
+			form.Add("token", params.keyToken(key, time.Now().Add(-time.Minute)))
+		default:
+			form.Add("token", params.keyToken(key, time.Now().Add(time.Hour)))
+		}
+	}
+	// The key without the token.
+	form.Add("key", "incoming/other-client-upload.jpg")
+
+	req := httptest.NewRequest(http.MethodPost, "/uploader/direct", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var in []files.ProcessableFile
+
+	operation := &S3PresignedUploadInputReadOperation{
+		Params:       params,
+		Req:          req,
+		GetObjectAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].OriginalFilename() != "Фото.JPG" {
+		t.Fatalf("OriginalFilename() = %s, want Фото.JPG", out[0].OriginalFilename())
+	}
+
+	if out[0].OperationMetadata[MetadataKeyS3InputReadKey] != "incoming/V1StGXR8_Z5jdHi6B-myT.jpg" {
+		t.Fatalf(
+			"metadata key %s = %s, want incoming/V1StGXR8_Z5jdHi6B-myT.jpg",
+			MetadataKeyS3InputReadKey,
+			out[0].OperationMetadata[MetadataKeyS3InputReadKey],
+		)
+	}
+
+	content, readErr := capyfs.FilesystemUtils.ReadFile(out[0].Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if string(content) != "photo" {
+		t.Fatalf("file content = %s, want photo", content)
+	}
+}
@@ -7,35 +7,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/spf13/afero"
+	"io"
 	"net/url"
-	"strings"
 	"sync"
+	"time"
 )
 
 const ErrorCodeS3UploadOperationConfiguration = "S3_UPLOAD_OPERATION_CONFIGURATION"
 
 const MetadataKeyS3UploadFileUrl = "s3_upload.file_url"
+const MetadataKeyS3UploadKey = "s3_upload.key"
+const MetadataKeyS3UploadPresignedUrl = "s3_upload.presigned_url"
+const MetadataKeyS3UploadPresignedUrlExpiresAt = "s3_upload.presigned_url_expires_at"
 
-// PutObjectAPI The interface to implement PutObjectWithContext that we need to upload the files to S3.
-type PutObjectAPI interface {
-	PutObjectWithContext(
+// MaxS3PresignedUrlExpiration The maximum expiration time of the presigned URL signed with Signature Version 4.
+const MaxS3PresignedUrlExpiration = 7 * 24 * time.Hour
+
+// UploadAPI The interface to implement the S3 API calls that we need to upload the files to S3.
+// This includes both single request uploads and multipart uploads.
+type UploadAPI interface {
+	manager.UploadAPIClient
+}
+
+// PresignGetObjectAPI The interface to implement PresignGetObject that we need to generate
+// the presigned URLs of the uploaded files.
+type PresignGetObjectAPI interface {
+	PresignGetObject(
 		ctx context.Context,
-		params *s3.PutObjectInput,
-		opts ...request.Option,
-	) (*s3.PutObjectOutput, error)
+		params *s3.GetObjectInput,
+		optFns ...func(*s3.PresignOptions),
+	) (*v4.PresignedHTTPRequest, error)
 }
 
 type S3UploadOperation struct {
-	Name         string
-	Params       *S3UploadOperationParams
-	PutObjectAPI PutObjectAPI
+	Name                string
+	Params              *S3UploadOperationParams
+	UploadAPI           UploadAPI
+	PresignGetObjectAPI PresignGetObjectAPI
 }
 
 func (o *S3UploadOperation) OperationName() string {
@@ -47,34 +62,102 @@ func (o *S3UploadOperation) AllowConcurrency() bool {
 }
 
 type S3UploadOperationParams struct {
-	AccessKeyId     string
-	SecretAccessKey string
-	SessionToken    string
-	Endpoint        string
-	Region          string
-	Bucket          string
+	S3ClientParams
+
+	// PartSize is the size of the part in bytes. The files bigger than this are uploaded
+	// with multipart upload. If zero, the default part size (5MiB) is used.
+	PartSize int64
+	// Concurrency is the number of parts of the same file to upload in parallel.
+	// If zero, the default concurrency (5) is used.
+	Concurrency int
+	// ChecksumAlgorithm is the algorithm the object checksum is calculated with, so S3 can
+	// verify the integrity of the uploaded object. Possible values: CRC32, CRC32C, SHA1, SHA256.
+	ChecksumAlgorithm string
+
+	// Key is the object key template. If empty, the generated filename is used as the key.
+	// See objectTemplateData for the available template vars.
+	// For example: avatars/{{.UserID}}/{{.NanoID}}{{.Ext}}
+	Key string
+	// KeyTemplateVars are the additional vars available in the key and metadata templates.
+	KeyTemplateVars map[string]string
+	// ContentDisposition is the disposition type ("attachment" or "inline") of the
+	// Content-Disposition header that is sent along with the original filename.
+	// If empty, the header is not set.
+	ContentDisposition string
+	CacheControl       string
+	// ACL is the canned ACL to apply to the object. For example: public-read
+	ACL          string
+	StorageClass string
+	// Metadata is the user-defined object metadata. The values can be templated the same way as the key.
+	Metadata map[string]string
+	// Tags is the object tag set. The values can be templated the same way as the key.
+	Tags map[string]string
+
+	// PresignedUrlExpiration is how long the presigned GET URL of the uploaded file is valid for.
+	// If zero, the presigned URL is not generated. Useful for the private buckets.
+	PresignedUrlExpiration time.Duration
 }
 
-// compileEndpoint Compiles the endpoint based on the provided pattern.
-//
-// The pattern can contain the following placeholders:
-// - {bucket} - S3 bucket
-// - {region} - S3 region
-//
-// This gives more options to configure the endpoint for different S3 storage providers. For example:
-// - s3.amazonaws.com
-// - https://{region}.digitaloceanspaces.com/{bucket}
-// - https://play.min.io/{bucket}
-// - http://minio.local/{bucket}
-//
-// Another option is to build a custom endpoint resolver for AWS S3 DSK. But this would require a lot
-// of additional work.
-func (p *S3UploadOperationParams) compileEndpoint() (string, error) {
-	replacer := strings.NewReplacer(
-		"{bucket}", p.Bucket,
-		"{region}", p.Region,
-	)
-	return replacer.Replace(p.Endpoint), nil
+// putObjectInput Builds the put object input for the given processable file.
+func (p *S3UploadOperationParams) putObjectInput(
+	pf *files.ProcessableFile,
+	body io.Reader,
+) (*s3.PutObjectInput, error) {
+	data := objectTemplateData(pf, p.KeyTemplateVars)
+
+	key, keyErr := renderObjectKey(pf, p.Key, data)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(p.Bucket),
+		Key:    aws.String(key),
+		Body:   body,
+	}
+
+	if p.ChecksumAlgorithm != "" {
+		input.ChecksumAlgorithm = types.ChecksumAlgorithm(p.ChecksumAlgorithm)
+	}
+
+	mime, mimeErr := pf.Mime()
+	if mimeErr == nil && mime != nil {
+		input.ContentType = aws.String(mime.String())
+	}
+
+	if p.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(objectContentDisposition(p.ContentDisposition, data))
+	}
+	if p.CacheControl != "" {
+		input.CacheControl = aws.String(p.CacheControl)
+	}
+	if p.ACL != "" {
+		input.ACL = types.ObjectCannedACL(p.ACL)
+	}
+	if p.StorageClass != "" {
+		input.StorageClass = types.StorageClass(p.StorageClass)
+	}
+
+	metadata, metadataErr := renderObjectTemplateMap(p.Metadata, data)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+	input.Metadata = metadata
+
+	tags, tagsErr := renderObjectTemplateMap(p.Tags, data)
+	if tagsErr != nil {
+		return nil, tagsErr
+	}
+	if len(tags) > 0 {
+		tagging := url.Values{}
+		for k, v := range tags {
+			tagging.Set(k, v)
+		}
+
+		input.Tagging = aws.String(tagging.Encode())
+	}
+
+	return input, nil
 }
 
 // compileFileUrl Compiles a file URL based on the available parameters and provided key.
@@ -104,23 +187,53 @@ func (o *S3UploadOperation) Handle(
 	errorCh chan<- OperationError,
 	notificationCh chan<- OperationNotification,
 ) (out []files.ProcessableFile, err error) {
-	if o.PutObjectAPI == nil {
-		initErr := o.InitPutObjectAPI()
+	if o.UploadAPI == nil {
+		initErr := o.InitUploadAPI()
 		if initErr != nil {
 			if errorCh != nil {
 				errorCh <- o.errorBuilder().Error(
-					errors.New("put object API can not be initialized"),
+					errors.New("upload API can not be initialized"),
 				)
 			}
 
 			return out, capyerr.NewOperationConfigurationError(
 				ErrorCodeS3UploadOperationConfiguration,
-				"put object API can not be initialized",
+				"upload API can not be initialized",
 				initErr,
 			)
 		}
 	}
 
+	if o.PresignGetObjectAPI == nil && o.Params.PresignedUrlExpiration > 0 {
+		initErr := o.InitPresignGetObjectAPI()
+		if initErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					errors.New("presign get object API can not be initialized"),
+				)
+			}
+
+			return out, capyerr.NewOperationConfigurationError(
+				ErrorCodeS3UploadOperationConfiguration,
+				"presign get object API can not be initialized",
+				initErr,
+			)
+		}
+	}
+
+	// The uploader decides whether to upload the file with a single request or with
+	// multipart upload. If the multipart upload fails, the uploader aborts it, so the
+	// uploaded parts don't remain in the storage.
+	uploader := manager.NewUploader(o.UploadAPI, func(u *manager.Uploader) {
+		if o.Params.PartSize > 0 {
+			u.PartSize = o.Params.PartSize
+		}
+		if o.Params.Concurrency > 0 {
+			u.Concurrency = o.Params.Concurrency
+		}
+		u.LeavePartsOnError = false
+	})
+
 	var wg sync.WaitGroup
 
 	outHolder := newOutputHolder()
@@ -168,35 +281,65 @@ func (o *S3UploadOperation) Handle(
 				}
 			}(file)
 
-			_, putObjErr := o.PutObjectAPI.PutObjectWithContext(context.TODO(), &s3.PutObjectInput{
-				Bucket: aws.String(o.Params.Bucket),
-				Key:    aws.String(pf.GeneratedFilename()),
-				Body:   file,
-			})
-			if putObjErr != nil {
+			putObjInput, putObjInputErr := o.Params.putObjectInput(pf, file)
+			if putObjInputErr != nil {
 				pf.SetFileProcessingError(
-					NewS3FileUploadFailureError(putObjErr),
+					NewS3ObjectTemplateCanNotBeRenderedError(putObjInputErr),
 				)
 
 				if errorCh != nil {
-					errorCh <- o.errorBuilder().ProcessableFileError(pf, putObjErr)
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, putObjInputErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"S3 object key or metadata template can not be rendered", pf, putObjInputErr)
 				}
 
-				var aerr awserr.Error
-				if errors.As(putObjErr, &aerr) {
-					switch aerr.Code() {
-					case s3.ErrCodeNoSuchBucket:
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			_, uploadErr := uploader.Upload(context.TODO(), putObjInput)
+			if uploadErr != nil {
+				pf.SetFileProcessingError(
+					NewS3FileUploadFailureError(uploadErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, uploadErr)
+				}
+
+				var multiUploadFailure manager.MultiUploadFailure
+				if errors.As(uploadErr, &multiUploadFailure) {
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(
+							pf,
+							fmt.Errorf("multipart upload \"%s\" has failed and has been aborted", multiUploadFailure.UploadID()),
+						)
+					}
+				}
+
+				var apiErr smithy.APIError
+				if errors.As(uploadErr, &apiErr) {
+					switch apiErr.ErrorCode() {
+					case "NoSuchBucket":
 						if notificationCh != nil {
 							notificationCh <- o.notificationBuilder().Failed(
-								"can not upload the file because S3 storage bucket does not exist", pf, putObjErr)
+								"can not upload the file because S3 storage bucket does not exist", pf, uploadErr)
+						}
+					case "BadDigest", "InvalidDigest", "XAmzContentSHA256Mismatch":
+						if notificationCh != nil {
+							notificationCh <- o.notificationBuilder().Failed(
+								"can not upload the file because S3 storage checksum verification has failed", pf, uploadErr)
 						}
 					default:
 						if errorCh != nil {
-							errorCh <- o.errorBuilder().ProcessableFileError(pf, putObjErr)
+							errorCh <- o.errorBuilder().ProcessableFileError(pf, uploadErr)
 						}
 						if notificationCh != nil {
 							notificationCh <- o.notificationBuilder().Failed(
-								"can not upload the file because the request to S3 storage has failed", pf, putObjErr)
+								"can not upload the file because the request to S3 storage has failed", pf, uploadErr)
 						}
 					}
 				}
@@ -210,7 +353,7 @@ func (o *S3UploadOperation) Handle(
 				return
 			}
 
-			fileUrl, fileUrlError := o.Params.compileFileUrl(pf.GeneratedFilename())
+			fileUrl, fileUrlError := o.Params.compileFileUrl(*putObjInput.Key)
 			if fileUrlError != nil {
 				pf.SetFileProcessingError(
 					NewS3FileUrlCanNotBeRetrievedError(fileUrlError),
@@ -230,6 +373,42 @@ func (o *S3UploadOperation) Handle(
 			}
 
 			pf.AddOperationMetadata(MetadataKeyS3UploadFileUrl, fileUrl)
+			pf.AddOperationMetadata(MetadataKeyS3UploadKey, *putObjInput.Key)
+
+			if o.Params.PresignedUrlExpiration > 0 {
+				presignedAt := time.Now()
+				presignedReq, presignErr := o.PresignGetObjectAPI.PresignGetObject(
+					context.TODO(),
+					&s3.GetObjectInput{
+						Bucket: putObjInput.Bucket,
+						Key:    putObjInput.Key,
+					},
+					s3.WithPresignExpires(o.Params.PresignedUrlExpiration),
+				)
+				if presignErr != nil {
+					pf.SetFileProcessingError(
+						NewS3FileUrlCanNotBeRetrievedError(presignErr),
+					)
+
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, presignErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"can not generate S3 file presigned URL", pf, presignErr)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				pf.AddOperationMetadata(MetadataKeyS3UploadPresignedUrl, presignedReq.URL)
+				pf.AddOperationMetadata(
+					MetadataKeyS3UploadPresignedUrlExpiresAt,
+					presignedAt.Add(o.Params.PresignedUrlExpiration),
+				)
+			}
 
 			if notificationCh != nil {
 				notificationCh <- o.notificationBuilder().Finished("S3 file upload has finished", pf)
@@ -244,27 +423,26 @@ func (o *S3UploadOperation) Handle(
 	return outHolder.Out, nil
 }
 
-// InitPutObjectAPI Init PutObjectAPI that we need to upload the files to S3.
-func (o *S3UploadOperation) InitPutObjectAPI() error {
-	endpoint, endpointErr := o.Params.compileEndpoint()
-	if endpointErr != nil {
-		return endpointErr
+// InitUploadAPI Init UploadAPI that we need to upload the files to S3.
+func (o *S3UploadOperation) InitUploadAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
+	}
+
+	o.UploadAPI = client
+
+	return nil
+}
+
+// InitPresignGetObjectAPI Init PresignGetObjectAPI that we need to generate the presigned URLs.
+func (o *S3UploadOperation) InitPresignGetObjectAPI() error {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		return clientErr
 	}
 
-	sess := session.Must(
-		session.NewSession(
-			&aws.Config{
-				Credentials: credentials.NewStaticCredentials(
-					o.Params.AccessKeyId,
-					o.Params.SecretAccessKey,
-					o.Params.SessionToken,
-				),
-				Endpoint: &endpoint,
-				Region:   &o.Params.Region,
-			},
-		),
-	)
-	o.PutObjectAPI = s3.New(sess)
+	o.PresignGetObjectAPI = s3.NewPresignClient(client)
 
 	return nil
 }
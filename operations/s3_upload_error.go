@@ -57,3 +57,30 @@ func (e *S3FileUrlCanNotBeRetrievedError) Code() string {
 func (e *S3FileUrlCanNotBeRetrievedError) Error() string {
 	return "file cannot be uploaded to S3"
 }
+
+const ErrorCodeS3ObjectTemplateCanNotBeRendered = "FILE_S3_OBJECT_TEMPLATE_CAN_NOT_BE_RENDERED"
+
+func NewS3ObjectTemplateCanNotBeRenderedError(origErr error) *S3ObjectTemplateCanNotBeRenderedError {
+	return &S3ObjectTemplateCanNotBeRenderedError{
+		Data: &S3ObjectTemplateCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type S3ObjectTemplateCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *S3ObjectTemplateCanNotBeRenderedErrorData
+}
+
+type S3ObjectTemplateCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *S3ObjectTemplateCanNotBeRenderedError) Code() string {
+	return ErrorCodeS3ObjectTemplateCanNotBeRendered
+}
+
+func (e *S3ObjectTemplateCanNotBeRenderedError) Error() string {
+	return "S3 object key or metadata template can not be rendered"
+}
@@ -4,27 +4,17 @@ import (
 	"bytes"
 	"capyfile/capyfs"
 	"capyfile/files"
-	"context"
 	"errors"
-	"github.com/aws/aws-sdk-go/aws/request"
-	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
-type mockPutObjectAPI func(
-	ctx context.Context,
-	params *s3.PutObjectInput,
-	opts ...request.Option,
-) (*s3.PutObjectOutput, error)
-
-func (m mockPutObjectAPI) PutObjectWithContext(
-	ctx context.Context,
-	params *s3.PutObjectInput,
-	opts ...request.Option,
-) (*s3.PutObjectOutput, error) {
-	return m(ctx, params, opts...)
-}
-
 func TestS3UploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
 	capyfs.InitCopyOnWriteFilesystem()
 
@@ -40,15 +30,14 @@ func TestS3UploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
 
 	operation := &S3UploadOperation{
 		Params: &S3UploadOperationParams{
-			Endpoint: "https://{bucket}.example.com",
-			Bucket:   "files",
+			S3ClientParams: S3ClientParams{
+				Endpoint: "https://{bucket}.example.com",
+				Bucket:   "files",
+			},
 		},
-		PutObjectAPI: mockPutObjectAPI(
-			func(
-				ctx context.Context,
-				params *s3.PutObjectInput,
-				opts ...request.Option,
-			) (*s3.PutObjectOutput, error) {
+		UploadAPI: &fakeS3Storage{
+			objects: make(map[string]*fakeS3Object),
+			onPutObject: func(params *s3.PutObjectInput) error {
 				t.Helper()
 
 				if *params.Bucket != "files" {
@@ -75,9 +64,9 @@ func TestS3UploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
 					t.Fatalf("expected body content to be equal to file content")
 				}
 
-				return &s3.PutObjectOutput{}, nil
+				return nil
 			},
-		),
+		},
 	}
 	out, err := operation.Handle(in, nil, nil)
 	if err != nil {
@@ -128,15 +117,14 @@ func TestS3UploadOperation_HandleFailedFilesUpload(t *testing.T) {
 
 	operation := &S3UploadOperation{
 		Params: &S3UploadOperationParams{
-			Endpoint: "example.com",
-			Bucket:   "files",
+			S3ClientParams: S3ClientParams{
+				Endpoint: "example.com",
+				Bucket:   "files",
+			},
 		},
-		PutObjectAPI: mockPutObjectAPI(
-			func(
-				ctx context.Context,
-				params *s3.PutObjectInput,
-				opts ...request.Option,
-			) (*s3.PutObjectOutput, error) {
+		UploadAPI: &fakeS3Storage{
+			objects: make(map[string]*fakeS3Object),
+			onPutObject: func(params *s3.PutObjectInput) error {
 				t.Helper()
 
 				if *params.Bucket != "files" {
@@ -163,9 +151,9 @@ func TestS3UploadOperation_HandleFailedFilesUpload(t *testing.T) {
 					t.Fatalf("expected body content to be equal to file content")
 				}
 
-				return &s3.PutObjectOutput{}, errors.New("whatever error")
+				return errors.New("whatever error")
 			},
-		),
+		},
 	}
 
 	out, handleErr := operation.Handle(in, nil, nil)
@@ -205,3 +193,379 @@ func TestS3UploadOperation_HandleFailedFilesUpload(t *testing.T) {
 		)
 	}
 }
+
+func TestS3UploadOperation_HandleTemplatedObjectKeyAndMetadata(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processableFile := files.NewProcessableFile(file.Name())
+	processableFile.AddOperationMetadata("checksum", "abc")
+	in := []files.ProcessableFile{
+		processableFile,
+	}
+
+	expectedKey := "avatars/42/" + processableFile.GeneratedFilename()
+
+	operation := &S3UploadOperation{
+		Params: &S3UploadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "https://{bucket}.example.com",
+				Bucket:   "files",
+			},
+
+			Key: "/avatars/{{.UserID}}/{{.NanoID}}{{.Ext}}",
+			KeyTemplateVars: map[string]string{
+				"UserID": "42",
+			},
+			ContentDisposition: "attachment",
+			CacheControl:       "max-age=3600",
+			ACL:                "private",
+			StorageClass:       "STANDARD_IA",
+			Metadata: map[string]string{
+				"original-filename": "{{.OriginalFilename}}",
+				"checksum":          "{{.Metadata.checksum}}",
+			},
+			Tags: map[string]string{
+				"user": "{{.UserID}}",
+			},
+		},
+		UploadAPI: &fakeS3Storage{
+			objects: make(map[string]*fakeS3Object),
+			onPutObject: func(params *s3.PutObjectInput) error {
+				t.Helper()
+
+				if *params.Key != expectedKey {
+					t.Fatalf("expected key to be %s, got %s", expectedKey, *params.Key)
+				}
+
+				if *params.ContentType != "application/octet-stream" {
+					t.Fatalf("expected content type to be application/octet-stream, got %s", *params.ContentType)
+				}
+
+				if *params.ContentDisposition != "attachment; filename=file_5kb.bin" {
+					t.Fatalf(
+						"expected content disposition to be attachment; filename=file_5kb.bin, got %s",
+						*params.ContentDisposition,
+					)
+				}
+
+				if *params.CacheControl != "max-age=3600" {
+					t.Fatalf("expected cache control to be max-age=3600, got %s", *params.CacheControl)
+				}
+
+				if params.ACL != types.ObjectCannedACLPrivate {
+					t.Fatalf("expected ACL to be private, got %s", params.ACL)
+				}
+
+				if params.StorageClass != types.StorageClassStandardIa {
+					t.Fatalf("expected storage class to be STANDARD_IA, got %s", params.StorageClass)
+				}
+
+				if params.Metadata["original-filename"] != "file_5kb.bin" {
+					t.Fatalf(
+						"expected original-filename metadata to be file_5kb.bin, got %s",
+						params.Metadata["original-filename"],
+					)
+				}
+
+				if params.Metadata["checksum"] != "abc" {
+					t.Fatalf("expected checksum metadata to be abc, got %s", params.Metadata["checksum"])
+				}
+
+				if *params.Tagging != "user=42" {
+					t.Fatalf("expected tagging to be user=42, got %s", *params.Tagging)
+				}
+
+				return nil
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if out[0].OperationMetadata[MetadataKeyS3UploadKey] != expectedKey {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyS3UploadKey,
+			out[0].OperationMetadata[MetadataKeyS3UploadKey],
+			expectedKey,
+		)
+	}
+
+	expectedUrl := "https://files.example.com/" + expectedKey
+	if out[0].OperationMetadata[MetadataKeyS3UploadFileUrl] != expectedUrl {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeyS3UploadFileUrl,
+			out[0].OperationMetadata[MetadataKeyS3UploadFileUrl],
+			expectedUrl,
+		)
+	}
+}
+
+func TestS3UploadOperation_HandleMissingKeyTemplateVar(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &S3UploadOperation{
+		Params: &S3UploadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "https://{bucket}.example.com",
+				Bucket:   "files",
+			},
+			Key: "avatars/{{.UserID}}/{{.NanoID}}{{.Ext}}",
+		},
+		UploadAPI: &fakeS3Storage{
+			objects: make(map[string]*fakeS3Object),
+			onPutObject: func(params *s3.PutObjectInput) error {
+				t.Fatalf("the object must not be uploaded")
+
+				return nil
+			},
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	var s3ObjectTemplateCanNotBeRenderedError *S3ObjectTemplateCanNotBeRenderedError
+	if !errors.As(out[0].FileProcessingError, &s3ObjectTemplateCanNotBeRenderedError) {
+		t.Fatalf("FileProcessingError = %v, want *S3ObjectTemplateCanNotBeRenderedError", out[0].FileProcessingError)
+	}
+}
+
+func TestS3UploadOperation_HandleMultipartUpload(t *testing.T) {
+	// The multipart upload reads the parts concurrently, so the file is kept on the disk.
+	capyfs.InitOsFilesystem()
+
+	filename := filepath.Join(t.TempDir(), "file_10mb.bin")
+
+	// 2 full parts and 1 partial part.
+	fileContent := bytes.Repeat([]byte("capyfile"), int(manager.MinUploadPartSize)/4+1)
+
+	writeErr := capyfs.FilesystemUtils.WriteFile(filename, fileContent, os.ModePerm)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	processableFile := files.NewProcessableFile(filename)
+	in := []files.ProcessableFile{
+		processableFile,
+	}
+
+	storage := newFakeS3Storage()
+	storage.onPutObject = func(params *s3.PutObjectInput) error {
+		t.Fatalf("the object must be uploaded with multipart upload")
+
+		return nil
+	}
+	storage.onUploadPart = func(params *s3.UploadPartInput) error {
+		if params.ChecksumAlgorithm != types.ChecksumAlgorithmSha256 {
+			t.Fatalf("expected checksum algorithm to be SHA256, got %s", params.ChecksumAlgorithm)
+		}
+
+		return nil
+	}
+
+	operation := &S3UploadOperation{
+		Params: &S3UploadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			PartSize:          manager.MinUploadPartSize,
+			Concurrency:       2,
+			ChecksumAlgorithm: "SHA256",
+		},
+		UploadAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	if len(storage.uploads) != 0 {
+		t.Fatalf("len(uploads) = %d, want 0", len(storage.uploads))
+	}
+
+	object, ok := storage.objects["files/"+processableFile.GeneratedFilename()]
+	if !ok {
+		t.Fatalf("object %s has not been uploaded", processableFile.GeneratedFilename())
+	}
+
+	if !bytes.Equal(object.Body, fileContent) {
+		t.Fatalf("expected object content to be equal to file content")
+	}
+}
+
+func TestS3UploadOperation_HandleFailedMultipartUpload(t *testing.T) {
+	// The multipart upload reads the parts concurrently, so the file is kept on the disk.
+	capyfs.InitOsFilesystem()
+
+	filename := filepath.Join(t.TempDir(), "file_10mb.bin")
+
+	fileContent := bytes.Repeat([]byte("capyfile"), int(manager.MinUploadPartSize)/4+1)
+
+	writeErr := capyfs.FilesystemUtils.WriteFile(filename, fileContent, os.ModePerm)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(filename),
+	}
+
+	storage := newFakeS3Storage()
+	storage.onUploadPart = func(params *s3.UploadPartInput) error {
+		if params.PartNumber == 2 {
+			return errors.New("connection reset by peer")
+		}
+
+		return nil
+	}
+
+	operation := &S3UploadOperation{
+		Params: &S3UploadOperationParams{
+			S3ClientParams: S3ClientParams{
+				Endpoint: "http://minio.local/{bucket}",
+				Bucket:   "files",
+			},
+			Concurrency: 2,
+		},
+		UploadAPI: storage,
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	var s3FileUploadFailureError *S3FileUploadFailureError
+	if !errors.As(out[0].FileProcessingError, &s3FileUploadFailureError) {
+		t.Fatalf("FileProcessingError = %v, want *S3FileUploadFailureError", out[0].FileProcessingError)
+	}
+
+	if len(storage.aborted) != 1 {
+		t.Fatalf("len(aborted) = %d, want 1", len(storage.aborted))
+	}
+
+	if len(storage.uploads) != 0 {
+		t.Fatalf("len(uploads) = %d, want 0", len(storage.uploads))
+	}
+
+	if len(storage.objects) != 0 {
+		t.Fatalf("len(objects) = %d, want 0", len(storage.objects))
+	}
+}
+
+func TestS3UploadOperation_HandlePresignedUrlGeneration(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &S3UploadOperation{
+		Params: &S3UploadOperationParams{
+			S3ClientParams: S3ClientParams{
+				AccessKeyId:     "minioadmin",
+				SecretAccessKey: "minioadmin",
+				Endpoint:        "http://minio.local",
+				Region:          "us-east-1",
+				Bucket:          "files",
+				UsePathStyle:    true,
+			},
+			PresignedUrlExpiration: 15 * time.Minute,
+		},
+		UploadAPI: newFakeS3Storage(),
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	presignedUrl, ok := out[0].OperationMetadata[MetadataKeyS3UploadPresignedUrl].(string)
+	if !ok {
+		t.Fatalf("metadata key %s = nil, want !nil", MetadataKeyS3UploadPresignedUrl)
+	}
+
+	expectedUrlPrefix := "http://minio.local/files/" + out[0].GeneratedFilename() + "?"
+	if !strings.HasPrefix(presignedUrl, expectedUrlPrefix) {
+		t.Fatalf("presigned URL = %s, want prefix %s", presignedUrl, expectedUrlPrefix)
+	}
+
+	for _, queryParam := range []string{"X-Amz-Expires=900", "X-Amz-Signature="} {
+		if !strings.Contains(presignedUrl, queryParam) {
+			t.Fatalf("presigned URL = %s, want to contain %s", presignedUrl, queryParam)
+		}
+	}
+
+	expiresAt, ok := out[0].OperationMetadata[MetadataKeyS3UploadPresignedUrlExpiresAt].(time.Time)
+	if !ok {
+		t.Fatalf("metadata key %s = nil, want !nil", MetadataKeyS3UploadPresignedUrlExpiresAt)
+	}
+
+	if expiresAt.Before(time.Now().Add(14*time.Minute)) || expiresAt.After(time.Now().Add(15*time.Minute)) {
+		t.Fatalf("metadata key %s = %s, want ~15m from now", MetadataKeyS3UploadPresignedUrlExpiresAt, expiresAt)
+	}
+}
@@ -0,0 +1,140 @@
+package operations
+
+import (
+	"errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"net"
+	"strings"
+	"time"
+)
+
+const sftpDialTimeout = 30 * time.Second
+
+// SFTPClientParams The parameters to connect to SFTP server. Shared by all the SFTP operations.
+type SFTPClientParams struct {
+	// Host is the server address. If the port is not specified, 22 is used.
+	Host     string
+	Username string
+	Password string
+	// PrivateKey is the PEM encoded private key to authenticate with. Can be used along with the password.
+	PrivateKey           string
+	PrivateKeyPassphrase string
+	// HostKey is the server public key the server is verified with. Either in authorized_keys
+	// format (ssh-ed25519 AAAA...) or in known_hosts format (example.com ssh-ed25519 AAAA...).
+	HostKey string
+	// InsecureIgnoreHostKey Whether to skip the server verification. Never use it in production.
+	InsecureIgnoreHostKey bool
+}
+
+// sftpConnection Is the SFTP client that also owns the underlying SSH connection.
+type sftpConnection struct {
+	*sftp.Client
+	sshClient *ssh.Client
+}
+
+func (c *sftpConnection) Close() error {
+	sftpCloseErr := c.Client.Close()
+	sshCloseErr := c.sshClient.Close()
+	if sftpCloseErr != nil {
+		return sftpCloseErr
+	}
+
+	return sshCloseErr
+}
+
+func (p *SFTPClientParams) address() string {
+	if _, _, splitErr := net.SplitHostPort(p.Host); splitErr == nil {
+		return p.Host
+	}
+
+	return net.JoinHostPort(p.Host, "22")
+}
+
+func (p *SFTPClientParams) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if p.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	if p.HostKey == "" {
+		return nil, errors.New("host key is required to verify the server")
+	}
+
+	hostKey, _, _, _, parseErr := ssh.ParseAuthorizedKey([]byte(p.HostKey))
+	if parseErr != nil {
+		var knownHostsParseErr error
+		_, _, hostKey, _, _, knownHostsParseErr = ssh.ParseKnownHosts([]byte(p.HostKey))
+		if knownHostsParseErr != nil {
+			return nil, parseErr
+		}
+	}
+
+	return ssh.FixedHostKey(hostKey), nil
+}
+
+func (p *SFTPClientParams) authMethods() ([]ssh.AuthMethod, error) {
+	var authMethods []ssh.AuthMethod
+
+	if p.PrivateKey != "" {
+		var signer ssh.Signer
+		var signerErr error
+		if p.PrivateKeyPassphrase != "" {
+			signer, signerErr = ssh.ParsePrivateKeyWithPassphrase(
+				[]byte(strings.TrimSpace(p.PrivateKey)+"\n"),
+				[]byte(p.PrivateKeyPassphrase),
+			)
+		} else {
+			signer, signerErr = ssh.ParsePrivateKey([]byte(strings.TrimSpace(p.PrivateKey) + "\n"))
+		}
+		if signerErr != nil {
+			return nil, signerErr
+		}
+
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+
+	if p.Password != "" {
+		authMethods = append(authMethods, ssh.Password(p.Password))
+	}
+
+	if len(authMethods) == 0 {
+		return nil, errors.New("either password or private key is required to authenticate")
+	}
+
+	return authMethods, nil
+}
+
+// newClient Connects to SFTP server based on the provided parameters.
+func (p *SFTPClientParams) newClient() (*sftpConnection, error) {
+	hostKeyCallback, hostKeyErr := p.hostKeyCallback()
+	if hostKeyErr != nil {
+		return nil, hostKeyErr
+	}
+
+	authMethods, authErr := p.authMethods()
+	if authErr != nil {
+		return nil, authErr
+	}
+
+	sshClient, dialErr := ssh.Dial("tcp", p.address(), &ssh.ClientConfig{
+		User:            p.Username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sftpDialTimeout,
+	})
+	if dialErr != nil {
+		return nil, dialErr
+	}
+
+	sftpClient, sftpErr := sftp.NewClient(sshClient)
+	if sftpErr != nil {
+		_ = sshClient.Close()
+
+		return nil, sftpErr
+	}
+
+	return &sftpConnection{
+		Client:    sftpClient,
+		sshClient: sshClient,
+	}, nil
+}
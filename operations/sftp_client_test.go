@@ -0,0 +1,280 @@
+package operations
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"path"
+	"testing"
+)
+
+const testSFTPUsername = "capy"
+const testSFTPPassword = "secret"
+
+// testSFTPServer is the in-process SFTP server that keeps the files in memory.
+type testSFTPServer struct {
+	Addr string
+	// HostKey is the server public key in authorized_keys format.
+	HostKey string
+	// ClientPrivateKey is the PEM encoded private key the server accepts.
+	ClientPrivateKey string
+}
+
+func newTestSFTPServer(t *testing.T) *testSFTPServer {
+	t.Helper()
+
+	_, hostPrivateKey, keyErr := ed25519.GenerateKey(rand.Reader)
+	if keyErr != nil {
+		t.Fatal(keyErr)
+	}
+	hostSigner, signerErr := ssh.NewSignerFromKey(hostPrivateKey)
+	if signerErr != nil {
+		t.Fatal(signerErr)
+	}
+
+	clientPublicKey, clientPrivateKey, keyErr := ed25519.GenerateKey(rand.Reader)
+	if keyErr != nil {
+		t.Fatal(keyErr)
+	}
+	clientSSHPublicKey, pubKeyErr := ssh.NewPublicKey(clientPublicKey)
+	if pubKeyErr != nil {
+		t.Fatal(pubKeyErr)
+	}
+	clientPrivateKeyBytes, marshalErr := x509.MarshalPKCS8PrivateKey(clientPrivateKey)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == testSFTPUsername && string(password) == testSFTPPassword {
+				return nil, nil
+			}
+
+			return nil, errors.New("invalid credentials")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == testSFTPUsername && bytes.Equal(key.Marshal(), clientSSHPublicKey.Marshal()) {
+				return nil, nil
+			}
+
+			return nil, errors.New("invalid credentials")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	// All the connections share the same in-memory filesystem.
+	handlers := sftp.InMemHandler()
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+
+			go serveTestSFTPConn(conn, config, handlers)
+		}
+	}()
+
+	return &testSFTPServer{
+		Addr:             listener.Addr().String(),
+		HostKey:          string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+		ClientPrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: clientPrivateKeyBytes})),
+	}
+}
+
+func serveTestSFTPConn(conn net.Conn, config *ssh.ServerConfig, handlers sftp.Handlers) {
+	_, chans, reqs, handshakeErr := ssh.NewServerConn(conn, config)
+	if handshakeErr != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+
+		channel, requests, acceptErr := newChannel.Accept()
+		if acceptErr != nil {
+			continue
+		}
+
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				_ = req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}(requests)
+
+		go func(channel ssh.Channel) {
+			server := sftp.NewRequestServer(channel, handlers)
+			_ = server.Serve()
+			_ = server.Close()
+		}(channel)
+	}
+}
+
+// clientParams Returns the parameters to connect to the server with the password.
+func (s *testSFTPServer) clientParams() SFTPClientParams {
+	return SFTPClientParams{
+		Host:     s.Addr,
+		Username: testSFTPUsername,
+		Password: testSFTPPassword,
+		HostKey:  s.HostKey,
+	}
+}
+
+func (s *testSFTPServer) writeFile(t *testing.T, remotePath string, content []byte) {
+	t.Helper()
+
+	params := s.clientParams()
+	client, clientErr := params.newClient()
+	if clientErr != nil {
+		t.Fatal(clientErr)
+	}
+	defer client.Close()
+
+	mkdirErr := client.MkdirAll(path.Dir(remotePath))
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	file, createErr := client.Create(remotePath)
+	if createErr != nil {
+		t.Fatal(createErr)
+	}
+	defer file.Close()
+
+	_, writeErr := file.Write(content)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+}
+
+func (s *testSFTPServer) readFile(t *testing.T, remotePath string) ([]byte, error) {
+	t.Helper()
+
+	params := s.clientParams()
+	client, clientErr := params.newClient()
+	if clientErr != nil {
+		t.Fatal(clientErr)
+	}
+	defer client.Close()
+
+	file, openErr := client.Open(remotePath)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+func TestSFTPClientParams_Authentication(t *testing.T) {
+	server := newTestSFTPServer(t)
+
+	testCases := []struct {
+		name        string
+		params      SFTPClientParams
+		expectError bool
+	}{
+		{
+			name:   "password",
+			params: server.clientParams(),
+		},
+		{
+			name: "private key",
+			params: SFTPClientParams{
+				Host:       server.Addr,
+				Username:   testSFTPUsername,
+				PrivateKey: server.ClientPrivateKey,
+				HostKey:    server.HostKey,
+			},
+		},
+		{
+			name: "known_hosts host key",
+			params: SFTPClientParams{
+				Host:     server.Addr,
+				Username: testSFTPUsername,
+				Password: testSFTPPassword,
+				HostKey:  "[127.0.0.1]:22 " + server.HostKey,
+			},
+		},
+		{
+			name: "invalid password",
+			params: SFTPClientParams{
+				Host:     server.Addr,
+				Username: testSFTPUsername,
+				Password: "invalid",
+				HostKey:  server.HostKey,
+			},
+			expectError: true,
+		},
+		{
+			name: "unknown host key",
+			params: SFTPClientParams{
+				Host:     server.Addr,
+				Username: testSFTPUsername,
+				Password: testSFTPPassword,
+				HostKey:  "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGH8Bf0V0pQ3zYGv2Xw3ybfMUEmFTNFpkQ8BHbq4D3VC",
+			},
+			expectError: true,
+		},
+		{
+			name: "missing host key",
+			params: SFTPClientParams{
+				Host:     server.Addr,
+				Username: testSFTPUsername,
+				Password: testSFTPPassword,
+			},
+			expectError: true,
+		},
+		{
+			name: "insecure ignore host key",
+			params: SFTPClientParams{
+				Host:                  server.Addr,
+				Username:              testSFTPUsername,
+				Password:              testSFTPPassword,
+				InsecureIgnoreHostKey: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client, clientErr := testCase.params.newClient()
+			if testCase.expectError {
+				if clientErr == nil {
+					_ = client.Close()
+					t.Fatalf("expected error, got nil")
+				}
+
+				return
+			}
+
+			if clientErr != nil {
+				t.Fatalf("expected error to be nil, got %v", clientErr)
+			}
+
+			_ = client.Close()
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+)
+
+const ErrorCodeSFTPInputReadOperationConfiguration = "SFTP_INPUT_READ_OPERATION_CONFIGURATION"
+
+const MetadataKeySFTPInputReadRemotePath = "sftp_input_read.remote_path"
+
+const (
+	SFTPPostReadActionNone   = ""
+	SFTPPostReadActionDelete = "delete"
+	SFTPPostReadActionMove   = "move"
+)
+
+// SFTPInputReadOperation reads input from SFTP server for further processing.
+type SFTPInputReadOperation struct {
+	Name   string
+	Params *SFTPInputReadOperationParams
+
+	// readFiles are the files that have been read, so the post-read action can be applied
+	// to them once the pipeline has finished.
+	readFiles   []files.ProcessableFile
+	readFilesMu sync.Mutex
+}
+
+type SFTPInputReadOperationParams struct {
+	SFTPClientParams
+
+	// Target is the glob pattern of the remote files to read. The pattern syntax is the same
+	// as for path.Match. For example: /outgoing/*.csv
+	Target string
+
+	// PostReadAction is what to do with the remote file once the pipeline has processed it. Possible values:
+	// "" (keep the file), "delete", "move".
+	PostReadAction string
+	// PostReadMoveTo is the remote path template the file is moved to if PostReadAction is "move".
+	// See objectTemplateData for the available template vars.
+	// For example: /archive/{{.OriginalFilename}}
+	PostReadMoveTo string
+	// RemotePathTemplateVars are the additional vars available in the PostReadMoveTo template.
+	RemotePathTemplateVars map[string]string
+	// CreateDirectories Whether to create the missing remote directories the files are moved to.
+	CreateDirectories bool
+}
+
+func (o *SFTPInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *SFTPInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *SFTPInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New("SFTP client can not be initialized"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeSFTPInputReadOperationConfiguration,
+			"SFTP client can not be initialized",
+			clientErr,
+		)
+	}
+	defer func(client *sftpConnection) {
+		closeErr := client.Close()
+		if closeErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(closeErr)
+			}
+		}
+	}(client)
+
+	matches, globErr := client.Glob(o.Params.Target)
+	if globErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(globErr)
+		}
+
+		return out, globErr
+	}
+
+	for _, remotePath := range matches {
+		fileInfo, statErr := client.Stat(remotePath)
+		if statErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("SFTP file \"%s\" can not be read: %w", remotePath, statErr),
+				)
+			}
+
+			continue
+		}
+
+		if !fileInfo.Mode().IsRegular() {
+			continue
+		}
+
+		remoteFile, openErr := client.Open(remotePath)
+		if openErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("SFTP file \"%s\" can not be read: %w", remotePath, openErr),
+				)
+			}
+
+			// Perhaps it makes sense to try to read other files.
+			continue
+		}
+
+		file, fileWriteErr := capyutils.WriteReaderToAppTmpDirectory(remoteFile)
+		_ = remoteFile.Close()
+		if fileWriteErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(
+					fmt.Errorf("SFTP file \"%s\" can not be read: %w", remotePath, fileWriteErr),
+				)
+			}
+
+			continue
+		}
+
+		pf := files.NewProcessableFile(file.Name())
+		pf.Metadata.OriginalFilename = path.Base(remotePath)
+		pf.AddOperationMetadata(MetadataKeySFTPInputReadRemotePath, remotePath)
+
+		if o.Params.PostReadAction != SFTPPostReadActionNone {
+			o.readFilesMu.Lock()
+			o.readFiles = append(o.readFiles, pf)
+			o.readFilesMu.Unlock()
+		}
+
+		if notificationCh != nil {
+			notificationCh <- o.notificationBuilder().Finished("SFTP file read finished", &pf)
+		}
+
+		out = append(out, pf)
+	}
+
+	return out, nil
+}
+
+// HandlePipelineFinished Applies the post-read action to the remote files that have been processed.
+// The file is processed if all the files in the output that have been read from it, like the chunks
+// of file_split, have no errors. The other files are kept, so they are read again next time.
+func (o *SFTPInputReadOperation) HandlePipelineFinished(
+	out []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) {
+	o.readFilesMu.Lock()
+	readFiles := o.readFiles
+	o.readFiles = nil
+	o.readFilesMu.Unlock()
+
+	processed := make(map[string]bool)
+	for _, pf := range out {
+		remotePath, ok := pf.OperationMetadata[MetadataKeySFTPInputReadRemotePath].(string)
+		if !ok {
+			continue
+		}
+
+		// One failed file is enough to keep the remote file.
+		if succeeded, seen := processed[remotePath]; seen {
+			processed[remotePath] = succeeded && !pf.HasFileProcessingError()
+		} else {
+			processed[remotePath] = !pf.HasFileProcessingError()
+		}
+	}
+
+	var processedFiles []files.ProcessableFile
+	for _, pf := range readFiles {
+		if processed[pf.OperationMetadata[MetadataKeySFTPInputReadRemotePath].(string)] {
+			processedFiles = append(processedFiles, pf)
+		}
+	}
+	if len(processedFiles) == 0 {
+		return
+	}
+
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				fmt.Errorf("SFTP client can not be initialized to apply the post-read action: %w", clientErr),
+			)
+		}
+
+		return
+	}
+	defer func(client *sftpConnection) {
+		closeErr := client.Close()
+		if closeErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(closeErr)
+			}
+		}
+	}(client)
+
+	for i := range processedFiles {
+		pf := &processedFiles[i]
+		remotePath := pf.OperationMetadata[MetadataKeySFTPInputReadRemotePath].(string)
+
+		postReadErr := o.applyPostReadAction(client, pf, remotePath)
+		if postReadErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().ProcessableFileError(
+					pf,
+					fmt.Errorf("SFTP file \"%s\" post-read action has failed: %w", remotePath, postReadErr),
+				)
+			}
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Failed(
+					"SFTP file post-read action has failed", pf, postReadErr)
+			}
+		}
+	}
+}
+
+// applyPostReadAction Deletes or moves the remote file that has been read.
+func (o *SFTPInputReadOperation) applyPostReadAction(
+	client *sftpConnection,
+	pf *files.ProcessableFile,
+	remotePath string,
+) error {
+	switch o.Params.PostReadAction {
+	case SFTPPostReadActionDelete:
+		return client.Remove(remotePath)
+	case SFTPPostReadActionMove:
+		moveTo, moveToErr := renderObjectTemplate(
+			o.Params.PostReadMoveTo,
+			objectTemplateData(pf, o.Params.RemotePathTemplateVars),
+		)
+		if moveToErr != nil {
+			return moveToErr
+		}
+
+		if o.Params.CreateDirectories {
+			mkdirErr := client.MkdirAll(path.Dir(moveTo))
+			if mkdirErr != nil {
+				return mkdirErr
+			}
+		}
+
+		// POSIX rename overwrites the existing file, the plain one fails if the file exists.
+		renameErr := client.PosixRename(remotePath, moveTo)
+		if renameErr != nil {
+			renameErr = client.Rename(remotePath, moveTo)
+		}
+
+		return renameErr
+	}
+
+	return nil
+}
+
+func (o *SFTPInputReadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *SFTPInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,206 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"sort"
+	"testing"
+)
+
+func TestSFTPInputReadOperation_HandleFilesRead(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+	server.writeFile(t, "/outgoing/report.csv", []byte("a,b\n1,2\n"))
+	server.writeFile(t, "/outgoing/summary.csv", []byte("c,d\n3,4\n"))
+	server.writeFile(t, "/outgoing/readme.txt", []byte("readme"))
+
+	operation := &SFTPInputReadOperation{
+		Params: &SFTPInputReadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			Target:           "/outgoing/*.csv",
+		},
+	}
+	out, err := operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	var originalFilenames []string
+	for _, pf := range out {
+		originalFilenames = append(originalFilenames, pf.OriginalFilename())
+	}
+	sort.Strings(originalFilenames)
+
+	if originalFilenames[0] != "report.csv" || originalFilenames[1] != "summary.csv" {
+		t.Fatalf("original filenames = %v, want [report.csv summary.csv]", originalFilenames)
+	}
+
+	for _, pf := range out {
+		content, readErr := capyfs.FilesystemUtils.ReadFile(pf.Name())
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+
+		if pf.OriginalFilename() == "report.csv" && string(content) != "a,b\n1,2\n" {
+			t.Fatalf("report.csv content = %q, want %q", content, "a,b\n1,2\n")
+		}
+
+		if pf.OperationMetadata[MetadataKeySFTPInputReadRemotePath] != "/outgoing/"+pf.OriginalFilename() {
+			t.Fatalf(
+				"metadata key %s = %s, want %s",
+				MetadataKeySFTPInputReadRemotePath,
+				pf.OperationMetadata[MetadataKeySFTPInputReadRemotePath],
+				"/outgoing/"+pf.OriginalFilename(),
+			)
+		}
+	}
+
+	// The files are kept, so they are read again.
+	out, err = operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+}
+
+func TestSFTPInputReadOperation_HandlePostReadDelete(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+	server.writeFile(t, "/outgoing/report.csv", []byte("a,b\n1,2\n"))
+
+	operation := &SFTPInputReadOperation{
+		Params: &SFTPInputReadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			Target:           "/outgoing/*.csv",
+			PostReadAction:   SFTPPostReadActionDelete,
+		},
+	}
+	out, err := operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	// The remote file is kept until the pipeline has processed it.
+	if _, readErr := server.readFile(t, "/outgoing/report.csv"); readErr != nil {
+		t.Fatalf("expected remote file to be kept, got %v", readErr)
+	}
+
+	operation.HandlePipelineFinished(out, nil, nil)
+
+	if _, readErr := server.readFile(t, "/outgoing/report.csv"); readErr == nil {
+		t.Fatalf("expected remote file to be deleted")
+	}
+}
+
+func TestSFTPInputReadOperation_HandlePostReadDeleteOfFailedFile(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+	server.writeFile(t, "/outgoing/report.csv", []byte("a,b\n1,2\n"))
+	server.writeFile(t, "/outgoing/summary.csv", []byte("c,d\n3,4\n"))
+
+	operation := &SFTPInputReadOperation{
+		Params: &SFTPInputReadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			Target:           "/outgoing/*.csv",
+			PostReadAction:   SFTPPostReadActionDelete,
+		},
+	}
+	out, err := operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	for i := range out {
+		if out[i].OriginalFilename() == "report.csv" {
+			out[i].SetFileProcessingError(NewFileJoinFailureError(nil))
+		}
+	}
+
+	operation.HandlePipelineFinished(out, nil, nil)
+
+	// The failed file is kept, so it is read again next time.
+	if _, readErr := server.readFile(t, "/outgoing/report.csv"); readErr != nil {
+		t.Fatalf("expected failed remote file to be kept, got %v", readErr)
+	}
+
+	if _, readErr := server.readFile(t, "/outgoing/summary.csv"); readErr == nil {
+		t.Fatalf("expected processed remote file to be deleted")
+	}
+
+	// If the pipeline has failed, all the remote files are kept.
+	out, err = operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	operation.HandlePipelineFinished(nil, nil, nil)
+
+	if _, readErr := server.readFile(t, "/outgoing/report.csv"); readErr != nil {
+		t.Fatalf("expected remote file to be kept, got %v", readErr)
+	}
+}
+
+func TestSFTPInputReadOperation_HandlePostReadMove(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+	server.writeFile(t, "/outgoing/report.csv", []byte("a,b\n1,2\n"))
+
+	operation := &SFTPInputReadOperation{
+		Params: &SFTPInputReadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			Target:           "/outgoing/*.csv",
+			PostReadAction:   SFTPPostReadActionMove,
+			PostReadMoveTo:   "/archive/{{.Date}}/{{.OriginalFilename}}",
+			RemotePathTemplateVars: map[string]string{
+				"Date": "2024-03-01",
+			},
+			CreateDirectories: true,
+		},
+	}
+	out, err := operation.Handle(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	operation.HandlePipelineFinished(out, nil, nil)
+
+	expectedRemotePath := "/archive/2024-03-01/report.csv"
+	if _, readErr := server.readFile(t, "/outgoing/report.csv"); readErr == nil {
+		t.Fatalf("expected remote file to be moved")
+	}
+
+	content, readErr := server.readFile(t, expectedRemotePath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if string(content) != "a,b\n1,2\n" {
+		t.Fatalf("moved file content = %q, want %q", content, "a,b\n1,2\n")
+	}
+}
@@ -0,0 +1,219 @@
+package operations
+
+import (
+	"capyfile/capyerr"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"github.com/spf13/afero"
+	"io"
+	"path"
+	"sync"
+)
+
+const ErrorCodeSFTPUploadOperationConfiguration = "SFTP_UPLOAD_OPERATION_CONFIGURATION"
+
+const MetadataKeySFTPUploadRemotePath = "sftp_upload.remote_path"
+
+type SFTPUploadOperation struct {
+	Name   string
+	Params *SFTPUploadOperationParams
+}
+
+func (o *SFTPUploadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *SFTPUploadOperation) AllowConcurrency() bool {
+	return true
+}
+
+type SFTPUploadOperationParams struct {
+	SFTPClientParams
+
+	// RemotePath is the remote file path template. If empty, the logical filename is used,
+	// so the file is uploaded to the user's home directory.
+	// See objectTemplateData for the available template vars.
+	// For example: /incoming/{{.PartnerID}}/{{.OriginalFilename}}
+	RemotePath string
+	// RemotePathTemplateVars are the additional vars available in the remote path template.
+	RemotePathTemplateVars map[string]string
+	// CreateDirectories Whether to create the missing remote directories.
+	CreateDirectories bool
+}
+
+func (p *SFTPUploadOperationParams) remotePath(pf *files.ProcessableFile) (string, error) {
+	if p.RemotePath == "" {
+		return pf.LogicalFilename(), nil
+	}
+
+	return renderObjectTemplate(p.RemotePath, objectTemplateData(pf, p.RemotePathTemplateVars))
+}
+
+func (o *SFTPUploadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	client, clientErr := o.Params.newClient()
+	if clientErr != nil {
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				errors.New("SFTP client can not be initialized"),
+			)
+		}
+
+		return out, capyerr.NewOperationConfigurationError(
+			ErrorCodeSFTPUploadOperationConfiguration,
+			"SFTP client can not be initialized",
+			clientErr,
+		)
+	}
+	defer func(client *sftpConnection) {
+		closeErr := client.Close()
+		if closeErr != nil {
+			if errorCh != nil {
+				errorCh <- o.errorBuilder().Error(closeErr)
+			}
+		}
+	}(client)
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		var pf = &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("SFTP file upload has started", pf)
+			}
+
+			remotePath, remotePathErr := o.Params.remotePath(pf)
+			if remotePathErr != nil {
+				pf.SetFileProcessingError(
+					NewSFTPRemotePathTemplateCanNotBeRenderedError(remotePathErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, remotePathErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"SFTP remote path template can not be rendered", pf, remotePathErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			defer func(file afero.File) {
+				closeErr := file.Close()
+				if closeErr != nil {
+					if errorCh != nil {
+						errorCh <- o.errorBuilder().ProcessableFileError(pf, closeErr)
+					}
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Failed(
+							"file can not be closed", pf, closeErr)
+					}
+				}
+			}(file)
+
+			uploadErr := o.upload(client, file, remotePath)
+			if uploadErr != nil {
+				pf.SetFileProcessingError(
+					NewSFTPFileUploadFailureError(uploadErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, uploadErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"can not upload the file to SFTP server", pf, uploadErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeySFTPUploadRemotePath, remotePath)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("SFTP file upload has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+// upload Uploads the file to the remote path. The partially uploaded file is removed if the upload fails.
+func (o *SFTPUploadOperation) upload(client *sftpConnection, file io.Reader, remotePath string) error {
+	if o.Params.CreateDirectories {
+		mkdirErr := client.MkdirAll(path.Dir(remotePath))
+		if mkdirErr != nil {
+			return mkdirErr
+		}
+	}
+
+	remoteFile, createErr := client.Create(remotePath)
+	if createErr != nil {
+		return createErr
+	}
+
+	_, copyErr := io.Copy(remoteFile, file)
+	closeErr := remoteFile.Close()
+	if copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = client.Remove(remotePath)
+
+		return copyErr
+	}
+
+	return nil
+}
+
+func (o *SFTPUploadOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *SFTPUploadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
@@ -0,0 +1,59 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeSFTPFileUploadFailure = "FILE_SFTP_FILE_UPLOAD_FAILURE"
+
+func NewSFTPFileUploadFailureError(origErr error) *SFTPFileUploadFailureError {
+	return &SFTPFileUploadFailureError{
+		Data: &SFTPFileUploadFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type SFTPFileUploadFailureError struct {
+	files.FileProcessingError
+	Data *SFTPFileUploadFailureErrorData
+}
+
+type SFTPFileUploadFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *SFTPFileUploadFailureError) Code() string {
+	return ErrorCodeSFTPFileUploadFailure
+}
+
+func (e *SFTPFileUploadFailureError) Error() string {
+	return "failed to upload file to SFTP server"
+}
+
+const ErrorCodeSFTPRemotePathTemplateCanNotBeRendered = "FILE_SFTP_REMOTE_PATH_TEMPLATE_CAN_NOT_BE_RENDERED"
+
+func NewSFTPRemotePathTemplateCanNotBeRenderedError(origErr error) *SFTPRemotePathTemplateCanNotBeRenderedError {
+	return &SFTPRemotePathTemplateCanNotBeRenderedError{
+		Data: &SFTPRemotePathTemplateCanNotBeRenderedErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type SFTPRemotePathTemplateCanNotBeRenderedError struct {
+	files.FileProcessingError
+	Data *SFTPRemotePathTemplateCanNotBeRenderedErrorData
+}
+
+type SFTPRemotePathTemplateCanNotBeRenderedErrorData struct {
+	OrigErr error
+}
+
+func (e *SFTPRemotePathTemplateCanNotBeRenderedError) Code() string {
+	return ErrorCodeSFTPRemotePathTemplateCanNotBeRendered
+}
+
+func (e *SFTPRemotePathTemplateCanNotBeRenderedError) Error() string {
+	return "SFTP remote path template can not be rendered"
+}
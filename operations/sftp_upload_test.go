@@ -0,0 +1,116 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"io"
+	"testing"
+)
+
+func TestSFTPUploadOperation_HandleSuccessfulFilesUpload(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileContent, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &SFTPUploadOperation{
+		Params: &SFTPUploadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			RemotePath:       "/incoming/{{.PartnerID}}/{{.OriginalFilename}}",
+			RemotePathTemplateVars: map[string]string{
+				"PartnerID": "acme",
+			},
+			CreateDirectories: true,
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError != nil {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want nil",
+			out[0].FileProcessingError.Code(),
+		)
+	}
+
+	expectedRemotePath := "/incoming/acme/file_5kb.bin"
+	if out[0].OperationMetadata[MetadataKeySFTPUploadRemotePath] != expectedRemotePath {
+		t.Fatalf(
+			"metadata key %s = %s, want %s",
+			MetadataKeySFTPUploadRemotePath,
+			out[0].OperationMetadata[MetadataKeySFTPUploadRemotePath],
+			expectedRemotePath,
+		)
+	}
+
+	remoteContent, readErr := server.readFile(t, expectedRemotePath)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+
+	if bytes.Compare(remoteContent, fileContent) != 0 {
+		t.Fatalf("expected remote file content to be equal to file content")
+	}
+}
+
+func TestSFTPUploadOperation_HandleMissingRemoteDirectory(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	server := newTestSFTPServer(t)
+
+	file, err := capyfs.Filesystem.Open("testdata/file_5kb.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile(file.Name()),
+	}
+
+	operation := &SFTPUploadOperation{
+		Params: &SFTPUploadOperationParams{
+			SFTPClientParams: server.clientParams(),
+			RemotePath:       "/missing/{{.GeneratedFilename}}",
+		},
+	}
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+
+	if out[0].FileProcessingError == nil {
+		t.Fatalf("FileProcessingError = nil, want !nil")
+	}
+
+	if out[0].FileProcessingError.Code() != ErrorCodeSFTPFileUploadFailure {
+		t.Fatalf(
+			"FileProcessingError.Code() = %s, want %s",
+			out[0].FileProcessingError.Code(),
+			ErrorCodeSFTPFileUploadFailure,
+		)
+	}
+}
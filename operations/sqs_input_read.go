@@ -0,0 +1,193 @@
+package operations
+
+import (
+	"capyfile/files"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"strings"
+	"time"
+)
+
+const MetadataKeySQSInputReadMessageId = "sqs_input_read.message_id"
+
+// SQSInputReadAPI The interface to implement the SQS API calls that we need to consume the messages.
+type SQSInputReadAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// SQSInputReadOperation reads the files described by the messages from SQS queue for further processing.
+type SQSInputReadOperation struct {
+	Name            string
+	Params          *SQSInputReadOperationParams
+	SQSInputReadAPI SQSInputReadAPI
+	// queueInputReader reads the files the messages describe.
+	queueInputReader *queueInputReader
+}
+
+type SQSInputReadOperationParams struct {
+	QueueInputParams
+
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint is the custom SQS endpoint. For example: http://localstack:4566
+	// If empty, the endpoint is resolved based on the region.
+	Endpoint string
+	Region   string
+	QueueUrl string
+	// VisibilityTimeout is how long the received messages are hidden from the other consumers.
+	// Also, the visibility is extended by this value. 0 means the queue default is used.
+	VisibilityTimeout time.Duration
+}
+
+func (o *SQSInputReadOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *SQSInputReadOperation) AllowConcurrency() bool {
+	return false
+}
+
+func (o *SQSInputReadOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	if o.SQSInputReadAPI == nil {
+		o.InitSQSInputReadAPI()
+	}
+
+	receiveOutput, receiveErr := o.SQSInputReadAPI.ReceiveMessage(context.TODO(), &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(o.Params.QueueUrl),
+		MaxNumberOfMessages: int32(o.Params.MaxMessages),
+		WaitTimeSeconds:     int32(o.Params.WaitTime.Seconds()),
+		VisibilityTimeout:   int32(o.Params.VisibilityTimeout.Seconds()),
+	})
+	if receiveErr != nil {
+		// Most likely it is a network or throttling issue, so the worker keeps polling.
+		if errorCh != nil {
+			errorCh <- o.errorBuilder().Error(
+				fmt.Errorf("SQS messages can not be received: %w", receiveErr),
+			)
+		}
+
+		return out, nil
+	}
+
+	var messages []QueueMessage
+	for _, msg := range receiveOutput.Messages {
+		messages = append(messages, &sqsQueueMessage{
+			api:               o.SQSInputReadAPI,
+			queueUrl:          o.Params.QueueUrl,
+			visibilityTimeout: o.Params.VisibilityTimeout,
+			msg:               msg,
+		})
+	}
+
+	return o.reader().read(messages, nil, errorCh, notificationCh), nil
+}
+
+// InitSQSInputReadAPI Init SQSInputReadAPI that we need to consume the messages.
+func (o *SQSInputReadOperation) InitSQSInputReadAPI() {
+	options := sqs.Options{
+		Credentials: credentials.NewStaticCredentialsProvider(
+			o.Params.AccessKeyId,
+			o.Params.SecretAccessKey,
+			o.Params.SessionToken,
+		),
+		Region: o.Params.Region,
+	}
+
+	if o.Params.Endpoint != "" {
+		endpoint := o.Params.Endpoint
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "https://" + endpoint
+		}
+
+		options.EndpointResolver = sqs.EndpointResolverFromURL(endpoint)
+	}
+
+	o.SQSInputReadAPI = sqs.New(options)
+}
+
+// HandlePipelineFinished Acknowledges the messages of the processed files, and gives the other messages
+// back to the queue.
+func (o *SQSInputReadOperation) HandlePipelineFinished(
+	out []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) {
+	o.reader().handlePipelineFinished(out, errorCh)
+}
+
+func (o *SQSInputReadOperation) reader() *queueInputReader {
+	if o.queueInputReader == nil {
+		o.queueInputReader = &queueInputReader{
+			OperationName:        o.Name,
+			Params:               &o.Params.QueueInputParams,
+			MetadataKeyMessageId: MetadataKeySQSInputReadMessageId,
+		}
+	}
+
+	return o.queueInputReader
+}
+
+func (o *SQSInputReadOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
+
+type sqsQueueMessage struct {
+	api               SQSInputReadAPI
+	queueUrl          string
+	visibilityTimeout time.Duration
+	msg               sqstypes.Message
+}
+
+func (m *sqsQueueMessage) ID() string {
+	return aws.ToString(m.msg.MessageId)
+}
+
+func (m *sqsQueueMessage) Body() []byte {
+	return []byte(aws.ToString(m.msg.Body))
+}
+
+func (m *sqsQueueMessage) Ack() error {
+	_, deleteErr := m.api.DeleteMessage(context.TODO(), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(m.queueUrl),
+		ReceiptHandle: m.msg.ReceiptHandle,
+	})
+
+	return deleteErr
+}
+
+func (m *sqsQueueMessage) Nack() error {
+	// Make the message visible right away, so it can be received again.
+	return m.changeVisibility(0)
+}
+
+func (m *sqsQueueMessage) ExtendVisibility() error {
+	// Changing the visibility to 0 would make the message visible, so do not extend the queue default.
+	if m.visibilityTimeout <= 0 {
+		return nil
+	}
+
+	return m.changeVisibility(m.visibilityTimeout)
+}
+
+func (m *sqsQueueMessage) changeVisibility(timeout time.Duration) error {
+	_, changeErr := m.api.ChangeMessageVisibility(context.TODO(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(m.queueUrl),
+		ReceiptHandle:     m.msg.ReceiptHandle,
+		VisibilityTimeout: int32(timeout.Seconds()),
+	})
+
+	return changeErr
+}
@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/spf13/afero"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSQSQueue struct {
+	mu sync.Mutex
+
+	messages          []sqstypes.Message
+	receiveErr        error
+	receiveInput      *sqs.ReceiveMessageInput
+	deleted           []string
+	visibilityChanges map[string][]int32
+}
+
+func (q *fakeSQSQueue) ReceiveMessage(
+	ctx context.Context,
+	params *sqs.ReceiveMessageInput,
+	optFns ...func(*sqs.Options),
+) (*sqs.ReceiveMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.receiveInput = params
+	if q.receiveErr != nil {
+		return nil, q.receiveErr
+	}
+
+	return &sqs.ReceiveMessageOutput{
+		Messages: q.messages,
+	}, nil
+}
+
+func (q *fakeSQSQueue) DeleteMessage(
+	ctx context.Context,
+	params *sqs.DeleteMessageInput,
+	optFns ...func(*sqs.Options),
+) (*sqs.DeleteMessageOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.deleted = append(q.deleted, aws.ToString(params.ReceiptHandle))
+
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (q *fakeSQSQueue) ChangeMessageVisibility(
+	ctx context.Context,
+	params *sqs.ChangeMessageVisibilityInput,
+	optFns ...func(*sqs.Options),
+) (*sqs.ChangeMessageVisibilityOutput, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	receiptHandle := aws.ToString(params.ReceiptHandle)
+	q.visibilityChanges[receiptHandle] = append(q.visibilityChanges[receiptHandle], params.VisibilityTimeout)
+
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func TestSQSInputReadOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/sqs", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	for _, name := range []string{"document.pdf", "photo.jpg"} {
+		writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/sqs/"+name, []byte(name), 0644)
+		if writeErr != nil {
+			t.Fatal(writeErr)
+		}
+	}
+
+	queue := &fakeSQSQueue{
+		messages: []sqstypes.Message{
+			{
+				MessageId:     aws.String("1"),
+				ReceiptHandle: aws.String("receipt-1"),
+				Body:          aws.String(`{"path": "/tmp/sqs/document.pdf"}`),
+			},
+			{
+				MessageId:     aws.String("2"),
+				ReceiptHandle: aws.String("receipt-2"),
+				Body:          aws.String(`{"path": "/tmp/sqs/photo.jpg"}`),
+			},
+			{
+				MessageId:     aws.String("3"),
+				ReceiptHandle: aws.String("receipt-3"),
+				Body:          aws.String(`not a json`),
+			},
+		},
+		visibilityChanges: make(map[string][]int32),
+	}
+
+	operation := &SQSInputReadOperation{
+		Name: "sqs_input_read",
+		Params: &SQSInputReadOperationParams{
+			QueueInputParams: QueueInputParams{
+				MaxMessages:                 10,
+				WaitTime:                    20 * time.Second,
+				VisibilityExtensionInterval: 10 * time.Millisecond,
+			},
+			QueueUrl:          "https://sqs.us-east-1.amazonaws.com/000000000000/files",
+			VisibilityTimeout: 60 * time.Second,
+		},
+		SQSInputReadAPI: queue,
+	}
+
+	errorCh := make(chan OperationError, 1)
+	out, err := operation.Handle([]files.ProcessableFile{}, errorCh, nil)
+	close(errorCh)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+	if len(errorCh) != 1 {
+		t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+	}
+
+	if queue.receiveInput.MaxNumberOfMessages != 10 || queue.receiveInput.WaitTimeSeconds != 20 ||
+		queue.receiveInput.VisibilityTimeout != 60 {
+		t.Fatalf("unexpected receive input %+v", queue.receiveInput)
+	}
+
+	if out[0].OperationMetadata[MetadataKeySQSInputReadMessageId] != "1" {
+		t.Fatalf("message id = %v, want 1", out[0].OperationMetadata[MetadataKeySQSInputReadMessageId])
+	}
+
+	// Give the visibility extension some time to kick in.
+	time.Sleep(50 * time.Millisecond)
+
+	out[1].SetFileProcessingError(NewFileCanNotBeOpenedError(errors.New("failed")))
+	settleErrorCh := make(chan OperationError, 10)
+	operation.HandlePipelineFinished(out, settleErrorCh, nil)
+	close(settleErrorCh)
+	if len(settleErrorCh) != 0 {
+		t.Fatalf("len(settleErrorCh) = %d, want 0", len(settleErrorCh))
+	}
+
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	if len(queue.deleted) != 1 || queue.deleted[0] != "receipt-1" {
+		t.Fatalf("queue.deleted = %v, want [receipt-1]", queue.deleted)
+	}
+
+	if len(queue.visibilityChanges["receipt-1"]) == 0 || queue.visibilityChanges["receipt-1"][0] != 60 {
+		t.Fatalf("visibility of receipt-1 is expected to be extended, got %v", queue.visibilityChanges["receipt-1"])
+	}
+
+	// The failed messages are made visible right away.
+	for _, receiptHandle := range []string{"receipt-2", "receipt-3"} {
+		changes := queue.visibilityChanges[receiptHandle]
+		if len(changes) == 0 || changes[len(changes)-1] != 0 {
+			t.Fatalf("visibility of %s is expected to be reset, got %v", receiptHandle, changes)
+		}
+	}
+}
+
+func TestSQSInputReadOperation_HandleReceiveFailure(t *testing.T) {
+	operation := &SQSInputReadOperation{
+		Name: "sqs_input_read",
+		Params: &SQSInputReadOperationParams{
+			QueueUrl: "https://sqs.us-east-1.amazonaws.com/000000000000/files",
+		},
+		SQSInputReadAPI: &fakeSQSQueue{
+			receiveErr: errors.New("connection reset by peer"),
+		},
+	}
+
+	errorCh := make(chan OperationError, 1)
+	out, err := operation.Handle([]files.ProcessableFile{}, errorCh, nil)
+	close(errorCh)
+
+	// The worker must keep polling, so the failure is only reported.
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("len(out) = %d, want 0", len(out))
+	}
+	if len(errorCh) != 1 {
+		t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+	}
+}
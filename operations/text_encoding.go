@@ -0,0 +1,215 @@
+package operations
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gogs/chardet"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	LineEndingsLF   = "lf"
+	LineEndingsCRLF = "crlf"
+)
+
+// textEncodingSampleSize The amount of data the encoding is detected by.
+const textEncodingSampleSize = 64 * 1024
+
+var errNotText = errors.New("content is not text")
+
+var errEncodingCanNotBeDetected = errors.New("text encoding can not be detected")
+
+// TextEncoding Returns the encoding by its IANA name or alias, for example "windows-1251", "latin1" or "utf-16le".
+func TextEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(name) {
+	case "utf-8", "utf8":
+		return unicode.UTF8, nil
+	case "utf-16":
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), nil
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	}
+
+	enc, encErr := ianaindex.IANA.Encoding(name)
+	if encErr != nil {
+		return nil, encErr
+	}
+	// The encoding is known, but not supported.
+	if enc == nil {
+		return nil, errors.New("text encoding " + name + " is not supported")
+	}
+
+	return enc, nil
+}
+
+// detectTextEncoding Detects the encoding of the text by its beginning. The encodings with BOM and
+// valid UTF-8 are detected for sure, the rest of the encodings are guessed statistically.
+func detectTextEncoding(sample []byte, isComplete bool) (string, error) {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8", nil
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "utf-16le", nil
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "utf-16be", nil
+	}
+
+	// The sample may end in the middle of the multibyte character.
+	validUTF8 := sample
+	if !isComplete {
+		for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+			if utf8.RuneStart(sample[len(sample)-i]) {
+				if !utf8.FullRune(sample[len(sample)-i:]) {
+					validUTF8 = sample[:len(sample)-i]
+				}
+
+				break
+			}
+		}
+	}
+	if utf8.Valid(validUTF8) {
+		return "utf-8", nil
+	}
+
+	// The NUL bytes do not appear in the text of the single-byte and multibyte encodings,
+	// and UTF-16 text without BOM is not supported.
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return "", errNotText
+	}
+
+	result, detectErr := chardet.NewTextDetector().DetectBest(sample)
+	if detectErr != nil {
+		return "", errEncodingCanNotBeDetected
+	}
+
+	_, encErr := TextEncoding(result.Charset)
+	if encErr != nil {
+		return "", errEncodingCanNotBeDetected
+	}
+
+	return strings.ToLower(result.Charset), nil
+}
+
+// lineEndingsTransformer Converts CRLF, CR and LF line endings of UTF-8 text to the given ones.
+type lineEndingsTransformer struct {
+	transform.NopResetter
+
+	lineEnding []byte
+}
+
+func newLineEndingsTransformer(lineEndings string) *lineEndingsTransformer {
+	lineEnding := []byte{'\n'}
+	if lineEndings == LineEndingsCRLF {
+		lineEnding = []byte{'\r', '\n'}
+	}
+
+	return &lineEndingsTransformer{lineEnding: lineEnding}
+}
+
+func (t *lineEndingsTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c := src[nSrc]
+		if c != '\r' && c != '\n' {
+			if nDst >= len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+
+			dst[nDst] = c
+			nDst++
+			nSrc++
+
+			continue
+		}
+
+		consumed := 1
+		if c == '\r' {
+			// CR may be followed by LF in the next chunk.
+			if nSrc+1 >= len(src) && !atEOF {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			if nSrc+1 < len(src) && src[nSrc+1] == '\n' {
+				consumed = 2
+			}
+		}
+
+		if nDst+len(t.lineEnding) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+
+		nDst += copy(dst[nDst:], t.lineEnding)
+		nSrc += consumed
+	}
+
+	return nDst, nSrc, nil
+}
+
+// textCheckingTransformer Passes UTF-8 text through as is, and fails if the text contains control
+// characters that do not appear in text files.
+type textCheckingTransformer struct {
+	transform.NopResetter
+}
+
+func (t textCheckingTransformer) Transform(dst, src []byte, _ bool) (nDst, nSrc int, err error) {
+	n := copy(dst, src)
+	for _, c := range src[:n] {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\v' && c != '\f' && c != '\r' && c != 0x1B {
+			return 0, 0, errNotText
+		}
+	}
+
+	if n < len(src) {
+		return n, n, transform.ErrShortDst
+	}
+
+	return n, n, nil
+}
+
+// utf8ValidatingTransformer Passes UTF-8 text through as is, and fails with the given error if the text
+// is not valid UTF-8. Unlike the UTF-8 decoder, it does not replace the invalid bytes with U+FFFD.
+type utf8ValidatingTransformer struct {
+	transform.NopResetter
+
+	err error
+}
+
+func (t utf8ValidatingTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if src[nSrc] < utf8.RuneSelf {
+			if nDst >= len(dst) {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+
+			dst[nDst] = src[nSrc]
+			nDst++
+			nSrc++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 {
+			// The multibyte character may continue in the next chunk.
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+
+			return nDst, nSrc, t.err
+		}
+
+		if nDst+size > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+
+	return nDst, nSrc, nil
+}
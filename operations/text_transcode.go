@@ -0,0 +1,244 @@
+package operations
+
+import (
+	"bufio"
+	"capyfile/capyfs"
+	"capyfile/capyutils"
+	"capyfile/files"
+	"errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"strings"
+	"sync"
+)
+
+const MetadataKeyTextTranscodeSourceEncoding = "text_transcode.source_encoding"
+const MetadataKeyTextTranscodeTargetEncoding = "text_transcode.target_encoding"
+
+type TextTranscodeOperation struct {
+	Name   string
+	Params *TextTranscodeOperationParams
+}
+
+type TextTranscodeOperationParams struct {
+	// SourceEncoding is the encoding of the files. If empty, the encoding is detected.
+	SourceEncoding string
+	// TargetEncoding is the encoding the files are converted to.
+	TargetEncoding string
+	// LineEndings are the line endings the files are converted to. Possible values: "lf", "crlf".
+	// If empty, the line endings are kept as is.
+	LineEndings string
+	// WriteBOM Whether to write BOM to the converted files. The source BOM is always stripped.
+	WriteBOM bool
+}
+
+// targetEncoding Returns the encoding the files are converted to, honoring the BOM preference.
+func (p *TextTranscodeOperationParams) targetEncoding() (encoding.Encoding, error) {
+	switch strings.ToLower(p.TargetEncoding) {
+	case "", "utf-8", "utf8":
+		if p.WriteBOM {
+			return unicode.UTF8BOM, nil
+		}
+
+		return unicode.UTF8, nil
+	case "utf-16", "utf-16le":
+		if p.WriteBOM {
+			return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+		}
+
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), nil
+	case "utf-16be":
+		if p.WriteBOM {
+			return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+		}
+
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), nil
+	}
+
+	return TextEncoding(p.TargetEncoding)
+}
+
+func (p *TextTranscodeOperationParams) targetEncodingName() string {
+	if p.TargetEncoding == "" {
+		return "utf-8"
+	}
+
+	return strings.ToLower(p.TargetEncoding)
+}
+
+// transcode Writes the text converted to the target encoding. Returns the source encoding name.
+func (p *TextTranscodeOperationParams) transcode(r io.Reader, w io.Writer) (string, error) {
+	bufferedReader := bufio.NewReaderSize(r, textEncodingSampleSize)
+
+	sourceEncodingName := p.SourceEncoding
+	if sourceEncodingName == "" {
+		sample, peekErr := bufferedReader.Peek(textEncodingSampleSize)
+		if peekErr != nil && peekErr != io.EOF {
+			return "", peekErr
+		}
+
+		detectedEncodingName, detectErr := detectTextEncoding(sample, peekErr == io.EOF)
+		if detectErr != nil {
+			return "", detectErr
+		}
+
+		sourceEncodingName = detectedEncodingName
+	}
+
+	sourceEncoding, sourceEncodingErr := TextEncoding(sourceEncodingName)
+	if sourceEncodingErr != nil {
+		return sourceEncodingName, sourceEncodingErr
+	}
+	targetEncoding, targetEncodingErr := p.targetEncoding()
+	if targetEncodingErr != nil {
+		return sourceEncodingName, targetEncodingErr
+	}
+
+	sourceDecoder := transform.Transformer(sourceEncoding.NewDecoder())
+	if sourceEncoding == unicode.UTF8 {
+		// The detection only checks the beginning of the file, so the invalid UTF-8 further in the file means
+		// the encoding has been detected wrong.
+		invalidUTF8Err := errEncodingCanNotBeDetected
+		if p.SourceEncoding != "" {
+			invalidUTF8Err = errNotText
+		}
+
+		sourceDecoder = utf8ValidatingTransformer{err: invalidUTF8Err}
+	}
+
+	// BOM, if any, overrides the source encoding and is stripped.
+	transformers := []transform.Transformer{
+		unicode.BOMOverride(sourceDecoder),
+		textCheckingTransformer{},
+	}
+	if p.LineEndings != "" {
+		transformers = append(transformers, newLineEndingsTransformer(p.LineEndings))
+	}
+	transformers = append(transformers, targetEncoding.NewEncoder())
+
+	_, copyErr := io.Copy(w, transform.NewReader(bufferedReader, transform.Chain(transformers...)))
+
+	return sourceEncodingName, copyErr
+}
+
+func (o *TextTranscodeOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *TextTranscodeOperation) AllowConcurrency() bool {
+	return true
+}
+
+func (o *TextTranscodeOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("text transcoding has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			var sourceEncoding string
+			transcodedFile, transcodeErr := capyutils.WriteToAppTmpDirectory(func(w io.Writer) error {
+				var err error
+				sourceEncoding, err = o.Params.transcode(file, w)
+
+				return err
+			})
+			_ = file.Close()
+
+			// The file is not the text, so this is the same kind of error as the validation one.
+			if errors.Is(transcodeErr, errNotText) || errors.Is(transcodeErr, errEncodingCanNotBeDetected) {
+				var fileProcessingError files.FileProcessingError = NewFileIsNotTextError()
+				if errors.Is(transcodeErr, errEncodingCanNotBeDetected) {
+					fileProcessingError = NewTextEncodingCanNotBeDetectedError()
+				}
+
+				pf.SetFileProcessingError(fileProcessingError)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished(fileProcessingError.Error(), pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+			if transcodeErr != nil {
+				pf.SetFileProcessingError(
+					NewTextTranscodeFailureError(transcodeErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, transcodeErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed("text can not be transcoded", pf, transcodeErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.ReplaceFile(transcodedFile.Name())
+			pf.AddOperationMetadata(MetadataKeyTextTranscodeSourceEncoding, sourceEncoding)
+			pf.AddOperationMetadata(MetadataKeyTextTranscodeTargetEncoding, o.Params.targetEncodingName())
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("text transcoding has finished", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *TextTranscodeOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *TextTranscodeOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
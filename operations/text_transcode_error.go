@@ -0,0 +1,68 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeTextTranscodeFailure = "TEXT_TRANSCODE_FAILURE"
+
+func NewTextTranscodeFailureError(origErr error) *TextTranscodeFailureError {
+	return &TextTranscodeFailureError{
+		Data: &TextTranscodeFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type TextTranscodeFailureError struct {
+	files.FileProcessingError
+	Data *TextTranscodeFailureErrorData
+}
+
+type TextTranscodeFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *TextTranscodeFailureError) Code() string {
+	return ErrorCodeTextTranscodeFailure
+}
+
+func (e *TextTranscodeFailureError) Error() string {
+	return "text can not be transcoded"
+}
+
+const ErrorCodeFileIsNotText = "FILE_IS_NOT_TEXT"
+
+func NewFileIsNotTextError() *FileIsNotTextError {
+	return &FileIsNotTextError{}
+}
+
+type FileIsNotTextError struct {
+	files.FileProcessingError
+}
+
+func (e *FileIsNotTextError) Code() string {
+	return ErrorCodeFileIsNotText
+}
+
+func (e *FileIsNotTextError) Error() string {
+	return "file is not text"
+}
+
+const ErrorCodeTextEncodingCanNotBeDetected = "TEXT_ENCODING_CAN_NOT_BE_DETECTED"
+
+func NewTextEncodingCanNotBeDetectedError() *TextEncodingCanNotBeDetectedError {
+	return &TextEncodingCanNotBeDetectedError{}
+}
+
+type TextEncodingCanNotBeDetectedError struct {
+	files.FileProcessingError
+}
+
+func (e *TextEncodingCanNotBeDetectedError) Code() string {
+	return ErrorCodeTextEncodingCanNotBeDetected
+}
+
+func (e *TextEncodingCanNotBeDetectedError) Error() string {
+	return "text encoding can not be detected"
+}
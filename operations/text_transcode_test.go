@@ -0,0 +1,166 @@
+package operations
+
+import (
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"github.com/spf13/afero"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"strings"
+	"testing"
+)
+
+func TestTextTranscodeOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	cyrillic := "id;город;комментарий\r\n1;Москва;Доставка в пункт выдачи заказов\r\n2;Санкт-Петербург;Курьерская доставка до двери\r\n"
+	windows1251, encodeErr := charmap.Windows1251.NewEncoder().String(cyrillic)
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+
+	utf16, encodeErr := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String("id,name\r\n1,Zoë\r\n")
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+
+	latin1, encodeErr := charmap.ISO8859_1.NewEncoder().String("id,name\n1,café\n")
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/text_transcode", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+
+	testCases := []struct {
+		name                   string
+		content                string
+		params                 *TextTranscodeOperationParams
+		expectedContent        string
+		expectedSourceEncoding string
+		expectedErrorCode      string
+	}{
+		{
+			name:    "detected windows-1251",
+			content: windows1251,
+			params: &TextTranscodeOperationParams{
+				LineEndings: LineEndingsLF,
+			},
+			expectedContent:        "id;город;комментарий\n1;Москва;Доставка в пункт выдачи заказов\n2;Санкт-Петербург;Курьерская доставка до двери\n",
+			expectedSourceEncoding: "windows-1251",
+		},
+		{
+			name:                   "utf-16 with bom",
+			content:                utf16,
+			params:                 &TextTranscodeOperationParams{},
+			expectedContent:        "id,name\r\n1,Zoë\r\n",
+			expectedSourceEncoding: "utf-16le",
+		},
+		{
+			name:    "given latin-1",
+			content: latin1,
+			params: &TextTranscodeOperationParams{
+				SourceEncoding: "latin1",
+				LineEndings:    LineEndingsCRLF,
+			},
+			expectedContent:        "id,name\r\n1,café\r\n",
+			expectedSourceEncoding: "latin1",
+		},
+		{
+			name:                   "utf-8 with bom",
+			content:                "\xEF\xBB\xBFid\r1\r",
+			params:                 &TextTranscodeOperationParams{LineEndings: LineEndingsLF},
+			expectedContent:        "id\n1\n",
+			expectedSourceEncoding: "utf-8",
+		},
+		{
+			name:    "utf-8 to windows-1251",
+			content: "город",
+			params: &TextTranscodeOperationParams{
+				TargetEncoding: "windows-1251",
+			},
+			expectedContent:        "\xE3\xEE\xF0\xEE\xE4",
+			expectedSourceEncoding: "utf-8",
+		},
+		{
+			name:              "binary file",
+			content:           "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+			params:            &TextTranscodeOperationParams{},
+			expectedErrorCode: ErrorCodeFileIsNotText,
+		},
+		{
+			name:    "text with control characters",
+			content: "id,name\n1,\x01\x02\n",
+			params: &TextTranscodeOperationParams{
+				SourceEncoding: "utf-8",
+			},
+			expectedErrorCode: ErrorCodeFileIsNotText,
+		},
+		{
+			name:              "invalid utf-8 after the detection sample",
+			content:           strings.Repeat("id,name\n", textEncodingSampleSize/8+1) + "1,caf\xe9\n",
+			params:            &TextTranscodeOperationParams{},
+			expectedErrorCode: ErrorCodeTextEncodingCanNotBeDetected,
+		},
+		{
+			name:    "given utf-8 with invalid bytes",
+			content: "id,name\n1,caf\xe9\n",
+			params: &TextTranscodeOperationParams{
+				SourceEncoding: "utf-8",
+			},
+			expectedErrorCode: ErrorCodeFileIsNotText,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/text_transcode/file.csv", []byte(testCase.content), 0644)
+			if writeErr != nil {
+				t.Fatal(writeErr)
+			}
+
+			operation := &TextTranscodeOperation{
+				Name:   "text_transcode",
+				Params: testCase.params,
+			}
+
+			out, err := operation.Handle(
+				[]files.ProcessableFile{files.NewProcessableFile("/tmp/text_transcode/file.csv")}, nil, nil)
+			if err != nil {
+				t.Fatalf("expected error to be nil, got %v", err)
+			}
+
+			if len(out) != 1 {
+				t.Fatalf("len(out) = %d, want 1", len(out))
+			}
+
+			if testCase.expectedErrorCode != "" {
+				if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != testCase.expectedErrorCode {
+					t.Fatalf("expected %s error, got %v", testCase.expectedErrorCode, out[0].FileProcessingError)
+				}
+
+				return
+			}
+
+			if out[0].HasFileProcessingError() {
+				t.Fatalf("expected file processing error to be nil, got %v", out[0].FileProcessingError)
+			}
+
+			content, readErr := afero.ReadFile(capyfs.Filesystem, out[0].Name())
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if !bytes.Equal(content, []byte(testCase.expectedContent)) {
+				t.Fatalf("content = %q, want %q", content, testCase.expectedContent)
+			}
+
+			sourceEncoding := out[0].OperationMetadata[MetadataKeyTextTranscodeSourceEncoding]
+			if sourceEncoding != testCase.expectedSourceEncoding {
+				t.Fatalf("source encoding = %v, want %s", sourceEncoding, testCase.expectedSourceEncoding)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package operations
+
+import (
+	"capyfile/capyfs"
+	"capyfile/files"
+	"errors"
+	"sync"
+	"time"
+)
+
+const MetadataKeyVirusScanStatus = "virus_scan.status"
+const MetadataKeyVirusScanSignature = "virus_scan.signature"
+
+const (
+	VirusScanStatusClean      = "clean"
+	VirusScanStatusInfected   = "infected"
+	VirusScanStatusNotScanned = "not_scanned"
+)
+
+const (
+	// VirusScanFailPolicyClosed The files fail if they can not be scanned.
+	VirusScanFailPolicyClosed = "closed"
+	// VirusScanFailPolicyOpen The files pass if they can not be scanned.
+	VirusScanFailPolicyOpen = "open"
+)
+
+type VirusScanOperation struct {
+	Name   string
+	Params *VirusScanOperationParams
+}
+
+func (o *VirusScanOperation) OperationName() string {
+	return o.Name
+}
+
+func (o *VirusScanOperation) AllowConcurrency() bool {
+	return true
+}
+
+type VirusScanOperationParams struct {
+	// Network is how clamd is reached. Possible values: "tcp", "unix".
+	Network string
+	// Address is either host:port or the unix socket path.
+	Address string
+	Timeout time.Duration
+	// ChunkSize is the size of the chunks the file is streamed in.
+	ChunkSize int64
+	// FailPolicy is what happens to the file if clamd is unavailable. Possible values: "closed", "open".
+	FailPolicy string
+}
+
+func (o *VirusScanOperation) Handle(
+	in []files.ProcessableFile,
+	errorCh chan<- OperationError,
+	notificationCh chan<- OperationNotification,
+) (out []files.ProcessableFile, err error) {
+	client := &clamdClient{
+		network:   o.Params.Network,
+		address:   o.Params.Address,
+		timeout:   o.Params.Timeout,
+		chunkSize: int(o.Params.ChunkSize),
+	}
+
+	var wg sync.WaitGroup
+
+	outHolder := newOutputHolder()
+
+	for i := range in {
+		wg.Add(1)
+
+		pf := &in[i]
+
+		go func(pf *files.ProcessableFile) {
+			defer wg.Done()
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Started("virus scan has started", pf)
+			}
+
+			file, fileOpenErr := capyfs.Filesystem.Open(pf.Name())
+			if fileOpenErr != nil {
+				pf.SetFileProcessingError(
+					NewFileCanNotBeOpenedError(fileOpenErr),
+				)
+
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, fileOpenErr)
+				}
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be opened", pf, fileOpenErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			signature, scanErr := client.scan(file)
+			_ = file.Close()
+
+			if scanErr != nil {
+				if errorCh != nil {
+					errorCh <- o.errorBuilder().ProcessableFileError(pf, scanErr)
+				}
+
+				var unavailableErr *clamdUnavailableError
+				if errors.As(scanErr, &unavailableErr) && o.Params.FailPolicy == VirusScanFailPolicyOpen {
+					pf.AddOperationMetadata(MetadataKeyVirusScanStatus, VirusScanStatusNotScanned)
+
+					if notificationCh != nil {
+						notificationCh <- o.notificationBuilder().Finished(
+							"virus scan has been skipped because the scanner is unavailable", pf)
+					}
+
+					outHolder.AppendToOut(pf)
+
+					return
+				}
+
+				pf.SetFileProcessingError(
+					NewVirusScanFailureError(scanErr),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Failed(
+						"file can not be scanned for viruses", pf, scanErr)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			if signature != "" {
+				pf.AddOperationMetadata(MetadataKeyVirusScanStatus, VirusScanStatusInfected)
+				pf.AddOperationMetadata(MetadataKeyVirusScanSignature, signature)
+
+				pf.SetFileProcessingError(
+					NewFileIsInfectedError(signature),
+				)
+
+				if notificationCh != nil {
+					notificationCh <- o.notificationBuilder().Finished("file is infected", pf)
+				}
+
+				outHolder.AppendToOut(pf)
+
+				return
+			}
+
+			pf.AddOperationMetadata(MetadataKeyVirusScanStatus, VirusScanStatusClean)
+
+			if notificationCh != nil {
+				notificationCh <- o.notificationBuilder().Finished("file is clean", pf)
+			}
+
+			outHolder.AppendToOut(pf)
+		}(pf)
+	}
+
+	wg.Wait()
+
+	return outHolder.Out, nil
+}
+
+func (o *VirusScanOperation) notificationBuilder() *OperationNotificationBuilder {
+	return &OperationNotificationBuilder{
+		OperationName: o.Name,
+	}
+}
+
+func (o *VirusScanOperation) errorBuilder() *OperationErrorBuilder {
+	return &OperationErrorBuilder{
+		OperationName: o.Name,
+	}
+}
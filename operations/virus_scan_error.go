@@ -0,0 +1,61 @@
+package operations
+
+import (
+	"capyfile/files"
+)
+
+const ErrorCodeFileIsInfected = "FILE_IS_INFECTED"
+
+func NewFileIsInfectedError(signature string) *FileIsInfectedError {
+	return &FileIsInfectedError{
+		Data: &FileIsInfectedErrorData{
+			Signature: signature,
+		},
+	}
+}
+
+type FileIsInfectedError struct {
+	files.FileProcessingError
+
+	Data *FileIsInfectedErrorData
+}
+
+type FileIsInfectedErrorData struct {
+	// Signature is the name of the signature the file has matched. For example: Eicar-Signature
+	Signature string
+}
+
+func (e *FileIsInfectedError) Code() string {
+	return ErrorCodeFileIsInfected
+}
+
+func (e *FileIsInfectedError) Error() string {
+	return "file is infected with " + e.Data.Signature
+}
+
+const ErrorCodeVirusScanFailure = "FILE_VIRUS_SCAN_FAILURE"
+
+func NewVirusScanFailureError(origErr error) *VirusScanFailureError {
+	return &VirusScanFailureError{
+		Data: &VirusScanFailureErrorData{
+			OrigErr: origErr,
+		},
+	}
+}
+
+type VirusScanFailureError struct {
+	files.FileProcessingError
+	Data *VirusScanFailureErrorData
+}
+
+type VirusScanFailureErrorData struct {
+	OrigErr error
+}
+
+func (e *VirusScanFailureError) Code() string {
+	return ErrorCodeVirusScanFailure
+}
+
+func (e *VirusScanFailureError) Error() string {
+	return "file can not be scanned for viruses"
+}
@@ -0,0 +1,259 @@
+package operations
+
+import (
+	"bufio"
+	"bytes"
+	"capyfile/capyfs"
+	"capyfile/files"
+	"encoding/binary"
+	"github.com/spf13/afero"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const eicarTestString = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// runFakeClamd Runs the fake clamd that reports the streams containing EICAR test string as infected.
+// Same as clamd, it fails the streams that are longer than streamMaxLength, unless it is 0.
+func runFakeClamd(t *testing.T, network, address string, streamMaxLength int64) net.Listener {
+	listener, listenErr := net.Listen(network, address)
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				cmd, cmdErr := r.ReadString(0)
+				if cmdErr != nil || cmd != "zINSTREAM\x00" {
+					_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+
+				var content bytes.Buffer
+				for {
+					var size uint32
+					sizeErr := binary.Read(r, binary.BigEndian, &size)
+					if sizeErr != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+
+					_, copyErr := io.CopyN(&content, r, int64(size))
+					if copyErr != nil {
+						return
+					}
+
+					if streamMaxLength > 0 && int64(content.Len()) > streamMaxLength {
+						_, _ = conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+						return
+					}
+				}
+
+				if bytes.Contains(content.Bytes(), []byte(eicarTestString)) {
+					_, _ = conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+				} else {
+					_, _ = conn.Write([]byte("stream: OK\x00"))
+				}
+			}(conn)
+		}
+	}()
+
+	return listener
+}
+
+func TestVirusScanOperation_Handle(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	mkdirErr := capyfs.Filesystem.MkdirAll("/tmp/virus_scan", 0755)
+	if mkdirErr != nil {
+		t.Fatal(mkdirErr)
+	}
+	writeErr := afero.WriteFile(capyfs.Filesystem, "/tmp/virus_scan/eicar.com", []byte(eicarTestString), 0644)
+	if writeErr != nil {
+		t.Fatal(writeErr)
+	}
+
+	listener := runFakeClamd(t, "tcp", "127.0.0.1:0", 0)
+
+	in := []files.ProcessableFile{
+		files.NewProcessableFile("testdata/file_5kb.bin"),
+		files.NewProcessableFile("/tmp/virus_scan/eicar.com"),
+	}
+
+	operation := &VirusScanOperation{
+		Name: "virus_scan",
+		Params: &VirusScanOperationParams{
+			Network:    "tcp",
+			Address:    listener.Addr().String(),
+			Timeout:    5 * time.Second,
+			ChunkSize:  1024,
+			FailPolicy: VirusScanFailPolicyClosed,
+		},
+	}
+
+	out, err := operation.Handle(in, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 2 {
+		t.Fatalf("len(out) = %d, want 2", len(out))
+	}
+
+	for _, pf := range out {
+		switch pf.OriginalFilename() {
+		case "testdata/file_5kb.bin":
+			if pf.HasFileProcessingError() {
+				t.Fatalf("expected file processing error to be nil, got %s", pf.FileProcessingError.Code())
+			}
+			if pf.OperationMetadata[MetadataKeyVirusScanStatus] != VirusScanStatusClean {
+				t.Fatalf("status = %v, want clean", pf.OperationMetadata[MetadataKeyVirusScanStatus])
+			}
+		case "/tmp/virus_scan/eicar.com":
+			if !pf.HasFileProcessingError() || pf.FileProcessingError.Code() != ErrorCodeFileIsInfected {
+				t.Fatalf("expected %s error, got %v", ErrorCodeFileIsInfected, pf.FileProcessingError)
+			}
+			infectedErr := pf.FileProcessingError.(*FileIsInfectedError)
+			if infectedErr.Data.Signature != "Eicar-Test-Signature" {
+				t.Fatalf("signature = %s, want Eicar-Test-Signature", infectedErr.Data.Signature)
+			}
+			if pf.OperationMetadata[MetadataKeyVirusScanSignature] != "Eicar-Test-Signature" {
+				t.Fatalf("signature metadata = %v, want Eicar-Test-Signature", pf.OperationMetadata[MetadataKeyVirusScanSignature])
+			}
+		default:
+			t.Fatalf("unexpected file %s", pf.OriginalFilename())
+		}
+	}
+}
+
+func TestVirusScanOperation_HandleUnixSocket(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	socketPath := filepath.Join(t.TempDir(), "clamd.sock")
+	runFakeClamd(t, "unix", socketPath, 0)
+
+	operation := &VirusScanOperation{
+		Name: "virus_scan",
+		Params: &VirusScanOperationParams{
+			Network:    "unix",
+			Address:    socketPath,
+			Timeout:    5 * time.Second,
+			ChunkSize:  4096,
+			FailPolicy: VirusScanFailPolicyClosed,
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if out[0].HasFileProcessingError() {
+		t.Fatalf("expected file processing error to be nil, got %s", out[0].FileProcessingError.Code())
+	}
+}
+
+func TestVirusScanOperation_HandleUnavailableScanner(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	// Reserve the address and release it right away, so nothing listens on it.
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	for _, failPolicy := range []string{VirusScanFailPolicyClosed, VirusScanFailPolicyOpen} {
+		operation := &VirusScanOperation{
+			Name: "virus_scan",
+			Params: &VirusScanOperationParams{
+				Network:    "tcp",
+				Address:    address,
+				Timeout:    time.Second,
+				ChunkSize:  1024,
+				FailPolicy: failPolicy,
+			},
+		}
+
+		errorCh := make(chan OperationError, 1)
+		out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, errorCh, nil)
+		close(errorCh)
+		if err != nil {
+			t.Fatalf("expected error to be nil, got %v", err)
+		}
+
+		if len(out) != 1 {
+			t.Fatalf("len(out) = %d, want 1", len(out))
+		}
+		if len(errorCh) != 1 {
+			t.Fatalf("len(errorCh) = %d, want 1", len(errorCh))
+		}
+
+		if failPolicy == VirusScanFailPolicyClosed {
+			if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != ErrorCodeVirusScanFailure {
+				t.Fatalf("expected %s error, got %v", ErrorCodeVirusScanFailure, out[0].FileProcessingError)
+			}
+		} else {
+			if out[0].HasFileProcessingError() {
+				t.Fatalf("expected file processing error to be nil, got %s", out[0].FileProcessingError.Code())
+			}
+			if out[0].OperationMetadata[MetadataKeyVirusScanStatus] != VirusScanStatusNotScanned {
+				t.Fatalf("status = %v, want not_scanned", out[0].OperationMetadata[MetadataKeyVirusScanStatus])
+			}
+		}
+	}
+}
+
+func TestVirusScanOperation_HandleStreamSizeLimitExceeded(t *testing.T) {
+	capyfs.InitCopyOnWriteFilesystem()
+
+	listener := runFakeClamd(t, "tcp", "127.0.0.1:0", 1024)
+
+	// The file is not scanned because of the file itself, so it must fail even with the open fail policy.
+	operation := &VirusScanOperation{
+		Name: "virus_scan",
+		Params: &VirusScanOperationParams{
+			Network:    "tcp",
+			Address:    listener.Addr().String(),
+			Timeout:    5 * time.Second,
+			ChunkSize:  1024,
+			FailPolicy: VirusScanFailPolicyOpen,
+		},
+	}
+
+	out, err := operation.Handle([]files.ProcessableFile{files.NewProcessableFile("testdata/file_5kb.bin")}, nil, nil)
+	if err != nil {
+		t.Fatalf("expected error to be nil, got %v", err)
+	}
+
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	if !out[0].HasFileProcessingError() || out[0].FileProcessingError.Code() != ErrorCodeVirusScanFailure {
+		t.Fatalf("expected %s error, got %v", ErrorCodeVirusScanFailure, out[0].FileProcessingError)
+	}
+	if _, ok := out[0].OperationMetadata[MetadataKeyVirusScanStatus]; ok {
+		t.Fatalf("status = %v, want it to be unset", out[0].OperationMetadata[MetadataKeyVirusScanStatus])
+	}
+}
@@ -0,0 +1,312 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewAzureBlobUploadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.AzureBlobUploadOperation, error) {
+	var accountName = ""
+	if accountNameParameter, ok := params["accountName"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			accountNameParameter.SourceType,
+			accountNameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		accountName = val
+	} else {
+		return nil, errors.New("failed to retrieve \"accountName\" parameter")
+	}
+
+	var accountKey = ""
+	if accountKeyParameter, ok := params["accountKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			accountKeyParameter.SourceType,
+			accountKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		accountKey = val
+	} else {
+		return nil, errors.New("failed to retrieve \"accountKey\" parameter")
+	}
+
+	var endpoint = ""
+	if endpointParameter, ok := params["endpoint"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			endpointParameter.SourceType,
+			endpointParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		endpoint = val
+	}
+
+	var container = ""
+	if containerParameter, ok := params["container"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			containerParameter.SourceType,
+			containerParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		container = val
+	} else {
+		return nil, errors.New("failed to retrieve \"container\" parameter")
+	}
+
+	var blockSize int64 = 0
+	if blockSizeParameter, ok := params["blockSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			blockSizeParameter.SourceType,
+			blockSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		blockSize = val
+	}
+	if blockSize < 0 {
+		return nil, errors.New("\"blockSize\" parameter must be a positive number")
+	}
+
+	var concurrency int64 = 0
+	if concurrencyParameter, ok := params["concurrency"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			concurrencyParameter.SourceType,
+			concurrencyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		concurrency = val
+	}
+	if concurrency < 0 {
+		return nil, errors.New("\"concurrency\" parameter must be a positive number")
+	}
+
+	var key = ""
+	if keyParameter, ok := params["key"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyParameter.SourceType,
+			keyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		key = val
+	}
+
+	var keyTemplateVars map[string]string
+	if keyTemplateVarsParameter, ok := params["keyTemplateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyTemplateVarsParameter.SourceType,
+			keyTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyTemplateVars = val
+	}
+
+	var contentDisposition = ""
+	if contentDispositionParameter, ok := params["contentDisposition"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			contentDispositionParameter.SourceType,
+			contentDispositionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		contentDisposition = val
+	}
+	if contentDisposition != "" && contentDisposition != "attachment" && contentDisposition != "inline" {
+		return nil, errors.New("\"contentDisposition\" parameter must be either \"attachment\" or \"inline\"")
+	}
+
+	var cacheControl = ""
+	if cacheControlParameter, ok := params["cacheControl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			cacheControlParameter.SourceType,
+			cacheControlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		cacheControl = val
+	}
+
+	var accessTier = ""
+	if accessTierParameter, ok := params["accessTier"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			accessTierParameter.SourceType,
+			accessTierParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		accessTier = val
+	}
+
+	var metadata map[string]string
+	if metadataParameter, ok := params["metadata"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			metadataParameter.SourceType,
+			metadataParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		metadata = val
+	}
+
+	var tags map[string]string
+	if tagsParameter, ok := params["tags"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			tagsParameter.SourceType,
+			tagsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		tags = val
+	}
+
+	var presignedUrlExpiration time.Duration
+	if presignedUrlExpirationParameter, ok := params["presignedUrlExpiration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			presignedUrlExpirationParameter.SourceType,
+			presignedUrlExpirationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		expiration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		presignedUrlExpiration = expiration
+	}
+	if presignedUrlExpiration < 0 {
+		return nil, errors.New("\"presignedUrlExpiration\" parameter must be a positive duration")
+	}
+
+	return &operations.AzureBlobUploadOperation{
+		Name: name,
+		Params: &operations.AzureBlobUploadOperationParams{
+			AccountName: accountName,
+			AccountKey:  accountKey,
+			Endpoint:    endpoint,
+			Container:   container,
+
+			BlockSize:   blockSize,
+			Concurrency: int(concurrency),
+
+			Key:                key,
+			KeyTemplateVars:    keyTemplateVars,
+			ContentDisposition: contentDisposition,
+			CacheControl:       cacheControl,
+			AccessTier:         accessTier,
+			Metadata:           metadata,
+			Tags:               tags,
+
+			PresignedUrlExpiration: presignedUrlExpiration,
+		},
+	}, nil
+}
@@ -85,6 +85,42 @@ func NewCommandExecOperation(
 		allowParallelExecution = val
 	}
 
+	var batchMode bool = false
+	if batchModeParameter, ok := params["batchMode"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			batchModeParameter.SourceType,
+			batchModeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		batchMode = val
+	}
+
+	var batchFailedFilePattern string
+	if batchFailedFilePatternParameter, ok := params["batchFailedFilePattern"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			batchFailedFilePatternParameter.SourceType,
+			batchFailedFilePatternParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		batchFailedFilePattern = val
+	}
+
 	return &operations.CommandExecOperation{
 		Name: name,
 		Params: &operations.CommandExecOperationParams{
@@ -92,6 +128,8 @@ func NewCommandExecOperation(
 			CommandArgs:            commandArgs,
 			OutputFileDestination:  outputFileDestination,
 			AllowParallelExecution: allowParallelExecution,
+			BatchMode:              batchMode,
+			BatchFailedFilePattern: batchFailedFilePattern,
 		},
 	}, nil
 }
@@ -0,0 +1,170 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"unicode/utf8"
+)
+
+func NewContentValidateOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.ContentValidateOperation, error) {
+	var format = ""
+	if formatParameter, ok := params["format"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			formatParameter.SourceType,
+			formatParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		format = val
+	}
+
+	var jsonSchema = ""
+	if jsonSchemaParameter, ok := params["jsonSchema"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			jsonSchemaParameter.SourceType,
+			jsonSchemaParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		jsonSchema = val
+	}
+
+	var csvDelimiter = ","
+	if csvDelimiterParameter, ok := params["csvDelimiter"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			csvDelimiterParameter.SourceType,
+			csvDelimiterParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		csvDelimiter = val
+	}
+
+	var csvHeader []string
+	if csvHeaderParameter, ok := params["csvHeader"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			csvHeaderParameter.SourceType,
+			csvHeaderParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		csvHeader = val
+	}
+
+	var csvColumnCount int64 = 0
+	if csvColumnCountParameter, ok := params["csvColumnCount"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			csvColumnCountParameter.SourceType,
+			csvColumnCountParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		csvColumnCount = val
+	}
+
+	var csvEncoding = operations.ContentEncodingUTF8
+	if csvEncodingParameter, ok := params["csvEncoding"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			csvEncodingParameter.SourceType,
+			csvEncodingParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		csvEncoding = val
+	}
+	operationParams := &operations.ContentValidateOperationParams{
+		Format: format,
+	}
+
+	switch format {
+	case operations.ContentFormatJSON:
+		if jsonSchema != "" {
+			// The schema can be loaded from the file with "file" parameter source.
+			schema, schemaErr := jsonschema.CompileString("schema.json", jsonSchema)
+			if schemaErr != nil {
+				return nil, errors.New("\"jsonSchema\" parameter must contain valid JSON schema: " + schemaErr.Error())
+			}
+
+			operationParams.JSONSchema = schema
+		}
+	case operations.ContentFormatCSV:
+		delimiter, delimiterSize := utf8.DecodeRuneInString(csvDelimiter)
+		if delimiter == utf8.RuneError || delimiterSize != len(csvDelimiter) ||
+			delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return nil, errors.New("\"csvDelimiter\" parameter must be a single character")
+		}
+		if csvColumnCount < 0 {
+			return nil, errors.New("\"csvColumnCount\" parameter can not be negative")
+		}
+		if csvColumnCount > 0 && len(csvHeader) > 0 && int64(len(csvHeader)) != csvColumnCount {
+			return nil, errors.New("\"csvHeader\" parameter must have \"csvColumnCount\" columns")
+		}
+		if csvEncoding != operations.ContentEncodingUTF8 &&
+			csvEncoding != operations.ContentEncodingASCII &&
+			csvEncoding != operations.ContentEncodingAny {
+			return nil, errors.New("\"csvEncoding\" parameter must be either \"utf-8\", \"ascii\" or \"any\"")
+		}
+
+		operationParams.CSVDelimiter = delimiter
+		operationParams.CSVHeader = csvHeader
+		operationParams.CSVColumnCount = csvColumnCount
+		operationParams.CSVEncoding = csvEncoding
+	case operations.ContentFormatXML:
+		// XML is checked for well-formedness only, so there is nothing to configure.
+	default:
+		return nil, errors.New("\"format\" parameter must be either \"json\", \"csv\" or \"xml\"")
+	}
+
+	return &operations.ContentValidateOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
@@ -0,0 +1,596 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func NewEventPublishOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.EventPublishOperation, error) {
+	var sink = ""
+	if sinkParameter, ok := params["sink"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sinkParameter.SourceType,
+			sinkParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sink = val
+	} else {
+		return nil, errors.New("failed to retrieve \"sink\" parameter")
+	}
+
+	var template = ""
+	if templateParameter, ok := params["template"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			templateParameter.SourceType,
+			templateParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		template = val
+	}
+
+	var templateVars map[string]string
+	if templateVarsParameter, ok := params["templateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			templateVarsParameter.SourceType,
+			templateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		templateVars = val
+	}
+
+	var maxRetries int64 = 3
+	if maxRetriesParameter, ok := params["maxRetries"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxRetriesParameter.SourceType,
+			maxRetriesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxRetries = val
+	}
+	if maxRetries < 0 {
+		return nil, errors.New("\"maxRetries\" parameter must be a positive integer")
+	}
+
+	var retryDelay = time.Second
+	if retryDelayParameter, ok := params["retryDelay"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			retryDelayParameter.SourceType,
+			retryDelayParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedRetryDelay, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		retryDelay = parsedRetryDelay
+	}
+	if retryDelay < 0 {
+		return nil, errors.New("\"retryDelay\" parameter must be a positive duration")
+	}
+
+	var webhookUrl = ""
+	if webhookUrlParameter, ok := params["webhookUrl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			webhookUrlParameter.SourceType,
+			webhookUrlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		webhookUrl = val
+	}
+
+	var webhookHeaders map[string]string
+	if webhookHeadersParameter, ok := params["webhookHeaders"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			webhookHeadersParameter.SourceType,
+			webhookHeadersParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		webhookHeaders = val
+	}
+
+	var webhookTimeout = 30 * time.Second
+	if webhookTimeoutParameter, ok := params["webhookTimeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			webhookTimeoutParameter.SourceType,
+			webhookTimeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedWebhookTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		webhookTimeout = parsedWebhookTimeout
+	}
+	if webhookTimeout < 0 {
+		return nil, errors.New("\"webhookTimeout\" parameter must be a positive duration")
+	}
+
+	var redisAddr = ""
+	if redisAddrParameter, ok := params["redisAddr"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisAddrParameter.SourceType,
+			redisAddrParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisAddr = val
+	}
+
+	var redisUsername = ""
+	if redisUsernameParameter, ok := params["redisUsername"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisUsernameParameter.SourceType,
+			redisUsernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisUsername = val
+	}
+
+	var redisPassword = ""
+	if redisPasswordParameter, ok := params["redisPassword"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisPasswordParameter.SourceType,
+			redisPasswordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisPassword = val
+	}
+
+	var redisDb int64 = 0
+	if redisDbParameter, ok := params["redisDb"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisDbParameter.SourceType,
+			redisDbParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisDb = val
+	}
+
+	var redisStream = ""
+	if redisStreamParameter, ok := params["redisStream"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisStreamParameter.SourceType,
+			redisStreamParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisStream = val
+	}
+
+	var redisField = "event"
+	if redisFieldParameter, ok := params["redisField"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			redisFieldParameter.SourceType,
+			redisFieldParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		redisField = val
+	}
+
+	var natsUrl = ""
+	if natsUrlParameter, ok := params["natsUrl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsUrlParameter.SourceType,
+			natsUrlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsUrl = val
+	}
+
+	var natsUsername = ""
+	if natsUsernameParameter, ok := params["natsUsername"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsUsernameParameter.SourceType,
+			natsUsernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsUsername = val
+	}
+
+	var natsPassword = ""
+	if natsPasswordParameter, ok := params["natsPassword"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsPasswordParameter.SourceType,
+			natsPasswordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsPassword = val
+	}
+
+	var natsToken = ""
+	if natsTokenParameter, ok := params["natsToken"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsTokenParameter.SourceType,
+			natsTokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsToken = val
+	}
+
+	var natsSubject = ""
+	if natsSubjectParameter, ok := params["natsSubject"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsSubjectParameter.SourceType,
+			natsSubjectParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsSubject = val
+	}
+
+	var natsJetStream bool = false
+	if natsJetStreamParameter, ok := params["natsJetStream"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			natsJetStreamParameter.SourceType,
+			natsJetStreamParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		natsJetStream = val
+	}
+
+	var sqsAccessKeyId = ""
+	if sqsAccessKeyIdParameter, ok := params["sqsAccessKeyId"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsAccessKeyIdParameter.SourceType,
+			sqsAccessKeyIdParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsAccessKeyId = val
+	}
+
+	var sqsSecretAccessKey = ""
+	if sqsSecretAccessKeyParameter, ok := params["sqsSecretAccessKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsSecretAccessKeyParameter.SourceType,
+			sqsSecretAccessKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsSecretAccessKey = val
+	}
+
+	var sqsSessionToken = ""
+	if sqsSessionTokenParameter, ok := params["sqsSessionToken"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsSessionTokenParameter.SourceType,
+			sqsSessionTokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsSessionToken = val
+	}
+
+	var sqsEndpoint = ""
+	if sqsEndpointParameter, ok := params["sqsEndpoint"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsEndpointParameter.SourceType,
+			sqsEndpointParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsEndpoint = val
+	}
+
+	var sqsRegion = ""
+	if sqsRegionParameter, ok := params["sqsRegion"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsRegionParameter.SourceType,
+			sqsRegionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsRegion = val
+	}
+
+	var sqsQueueUrl = ""
+	if sqsQueueUrlParameter, ok := params["sqsQueueUrl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sqsQueueUrlParameter.SourceType,
+			sqsQueueUrlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sqsQueueUrl = val
+	}
+
+	var filePath = ""
+	if filePathParameter, ok := params["filePath"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			filePathParameter.SourceType,
+			filePathParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		filePath = val
+	}
+
+	sinkParams := operations.EventSinkParams{
+		Sink: sink,
+
+		WebhookUrl:     webhookUrl,
+		WebhookHeaders: webhookHeaders,
+		WebhookTimeout: webhookTimeout,
+
+		RedisAddr:     redisAddr,
+		RedisUsername: redisUsername,
+		RedisPassword: redisPassword,
+		RedisDB:       redisDb,
+		RedisStream:   redisStream,
+		RedisField:    redisField,
+
+		NATSUrl:       natsUrl,
+		NATSUsername:  natsUsername,
+		NATSPassword:  natsPassword,
+		NATSToken:     natsToken,
+		NATSSubject:   natsSubject,
+		NATSJetStream: natsJetStream,
+
+		SQSAccessKeyId:     sqsAccessKeyId,
+		SQSSecretAccessKey: sqsSecretAccessKey,
+		SQSSessionToken:    sqsSessionToken,
+		SQSEndpoint:        sqsEndpoint,
+		SQSRegion:          sqsRegion,
+		SQSQueueUrl:        sqsQueueUrl,
+
+		FilePath: filePath,
+	}
+	sinkParamsErr := validateEventSinkParams(sinkParams)
+	if sinkParamsErr != nil {
+		return nil, sinkParamsErr
+	}
+
+	return &operations.EventPublishOperation{
+		Name: name,
+		Params: &operations.EventPublishOperationParams{
+			EventSinkParams: sinkParams,
+			Template:        template,
+			TemplateVars:    templateVars,
+			MaxRetries:      maxRetries,
+			RetryDelay:      retryDelay,
+		},
+	}, nil
+}
+
+// validateEventSinkParams Checks that the parameters the chosen sink needs are provided.
+func validateEventSinkParams(p operations.EventSinkParams) error {
+	var required [][2]string
+	switch p.Sink {
+	case operations.EventSinkWebhook:
+		required = [][2]string{{"webhookUrl", p.WebhookUrl}}
+	case operations.EventSinkRedisStream:
+		required = [][2]string{{"redisAddr", p.RedisAddr}, {"redisStream", p.RedisStream}}
+	case operations.EventSinkNATS:
+		required = [][2]string{{"natsUrl", p.NATSUrl}, {"natsSubject", p.NATSSubject}}
+	case operations.EventSinkSQS:
+		required = [][2]string{{"sqsRegion", p.SQSRegion}, {"sqsQueueUrl", p.SQSQueueUrl}}
+	case operations.EventSinkFile:
+		required = [][2]string{{"filePath", p.FilePath}}
+	default:
+		return errors.New(
+			"\"sink\" parameter must be one of \"webhook\", \"redis_stream\", \"nats\", \"sqs\", \"file\"")
+	}
+
+	for _, param := range required {
+		if param[1] == "" {
+			return fmt.Errorf("\"%s\" parameter is required for \"%s\" sink", param[0], p.Sink)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"filippo.io/age"
+	"strings"
+)
+
+func NewFileDecryptOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileDecryptOperation, error) {
+	var algorithm = operations.EncryptionAlgorithmAge
+	if algorithmParameter, ok := params["algorithm"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			algorithmParameter.SourceType,
+			algorithmParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		algorithm = val
+	}
+	if algorithm != operations.EncryptionAlgorithmAge && algorithm != operations.EncryptionAlgorithmAES256GCM {
+		return nil, errors.New("\"algorithm\" parameter must be either \"age\" or \"aes-256-gcm\"")
+	}
+
+	var identities = ""
+	if identitiesParameter, ok := params["identities"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			identitiesParameter.SourceType,
+			identitiesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		identities = val
+	}
+
+	var passphrase = ""
+	if passphraseParameter, ok := params["passphrase"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			passphraseParameter.SourceType,
+			passphraseParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		passphrase = val
+	}
+
+	var aesKey = ""
+	if aesKeyParameter, ok := params["aesKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			aesKeyParameter.SourceType,
+			aesKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		aesKey = val
+	}
+
+	var aesKeyId = ""
+	if aesKeyIdParameter, ok := params["aesKeyId"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			aesKeyIdParameter.SourceType,
+			aesKeyIdParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		aesKeyId = val
+	}
+
+	operationParams := &operations.FileDecryptOperationParams{
+		Algorithm: algorithm,
+	}
+
+	if algorithm == operations.EncryptionAlgorithmAES256GCM {
+		// Unlike on encryption, the key ID is optional: the key ID stored in the file is checked only if it is set.
+		if len(aesKeyId) > maxAESKeyIdLength {
+			return nil, errors.New("\"aesKeyId\" parameter must be at most 255 bytes long")
+		}
+
+		key, keyErr := parseAESKey(aesKey)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+
+		operationParams.AESKey = key
+		operationParams.AESKeyId = aesKeyId
+	} else {
+		if (identities == "") == (passphrase == "") {
+			return nil, errors.New("either \"identities\" or \"passphrase\" parameter must be provided")
+		}
+
+		if identities != "" {
+			// The identities are in age identity file format, so the identity file can be
+			// loaded as is with "file" or "secret" parameter source.
+			parsedIdentities, parseErr := age.ParseIdentities(strings.NewReader(identities))
+			if parseErr != nil {
+				return nil, errors.New("\"identities\" parameter must contain age X25519 identities: " + parseErr.Error())
+			}
+
+			operationParams.AgeIdentities = parsedIdentities
+		}
+
+		if passphrase != "" {
+			identity, identityErr := age.NewScryptIdentity(passphrase)
+			if identityErr != nil {
+				return nil, identityErr
+			}
+
+			operationParams.AgeIdentities = []age.Identity{identity}
+		}
+	}
+
+	return &operations.FileDecryptOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"encoding/base64"
+	"errors"
+	"filippo.io/age"
+	"strings"
+)
+
+func NewFileEncryptOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileEncryptOperation, error) {
+	var algorithm = operations.EncryptionAlgorithmAge
+	if algorithmParameter, ok := params["algorithm"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			algorithmParameter.SourceType,
+			algorithmParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		algorithm = val
+	}
+	if algorithm != operations.EncryptionAlgorithmAge && algorithm != operations.EncryptionAlgorithmAES256GCM {
+		return nil, errors.New("\"algorithm\" parameter must be either \"age\" or \"aes-256-gcm\"")
+	}
+
+	var recipients []string
+	if recipientsParameter, ok := params["recipients"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			recipientsParameter.SourceType,
+			recipientsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		recipients = val
+	}
+
+	var passphrase = ""
+	if passphraseParameter, ok := params["passphrase"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			passphraseParameter.SourceType,
+			passphraseParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		passphrase = val
+	}
+
+	var aesKey = ""
+	if aesKeyParameter, ok := params["aesKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			aesKeyParameter.SourceType,
+			aesKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		aesKey = val
+	}
+
+	var aesKeyId = ""
+	if aesKeyIdParameter, ok := params["aesKeyId"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			aesKeyIdParameter.SourceType,
+			aesKeyIdParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		aesKeyId = val
+	}
+
+	operationParams := &operations.FileEncryptOperationParams{
+		Algorithm: algorithm,
+	}
+
+	if algorithm == operations.EncryptionAlgorithmAES256GCM {
+		// The key ID is required, so the key can be rotated.
+		if aesKeyId == "" || len(aesKeyId) > maxAESKeyIdLength {
+			return nil, errors.New("\"aesKeyId\" parameter must be from 1 to 255 bytes long")
+		}
+
+		key, keyErr := parseAESKey(aesKey)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+
+		operationParams.AESKey = key
+		operationParams.AESKeyId = aesKeyId
+	} else {
+		if (len(recipients) == 0) == (passphrase == "") {
+			return nil, errors.New("either \"recipients\" or \"passphrase\" parameter must be provided")
+		}
+
+		for _, recipient := range recipients {
+			parsedRecipient, parseErr := age.ParseX25519Recipient(strings.TrimSpace(recipient))
+			if parseErr != nil {
+				return nil, errors.New("\"recipients\" parameter must contain age X25519 recipients: " + parseErr.Error())
+			}
+
+			operationParams.AgeRecipients = append(operationParams.AgeRecipients, parsedRecipient)
+		}
+
+		if passphrase != "" {
+			recipient, recipientErr := age.NewScryptRecipient(passphrase)
+			if recipientErr != nil {
+				return nil, recipientErr
+			}
+
+			operationParams.AgeRecipients = []age.Recipient{recipient}
+		}
+	}
+
+	return &operations.FileEncryptOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
+
+// maxAESKeyIdLength The key ID length is stored in a single byte of the envelope header.
+const maxAESKeyIdLength = 255
+
+// parseAESKey Decodes base64 encoded AES-256 key.
+func parseAESKey(aesKey string) ([]byte, error) {
+	// The keys loaded from the files usually end with a newline.
+	key, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(aesKey))
+	if decodeErr != nil || len(key) != 32 {
+		return nil, errors.New("\"aesKey\" parameter must be base64 encoded 32 bytes long key")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,12 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+)
+
+func NewFileJoinOperation(name string) (*operations.FileJoinOperation, error) {
+	return &operations.FileJoinOperation{
+		Name:   name,
+		Params: &operations.FileJoinOperationParams{},
+	}, nil
+}
@@ -0,0 +1,59 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewFileRenameOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileRenameOperation, error) {
+	var template string
+	if templateParameter, ok := params["template"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			templateParameter.SourceType,
+			templateParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		template = val
+	} else {
+		return nil, errors.New("failed to retrieve \"template\" parameter")
+	}
+
+	var templateVars map[string]string
+	if templateVarsParameter, ok := params["templateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			templateVarsParameter.SourceType,
+			templateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		templateVars = val
+	}
+
+	return &operations.FileRenameOperation{
+		Name: name,
+		Params: &operations.FileRenameOperationParams{
+			Template:     template,
+			TemplateVars: templateVars,
+		},
+	}, nil
+}
@@ -0,0 +1,132 @@
+package opfactories
+
+import (
+	"aead.dev/minisign"
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"strings"
+)
+
+func NewFileSignOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileSignOperation, error) {
+	var format = operations.SignatureFormatMinisign
+	if formatParameter, ok := params["format"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			formatParameter.SourceType,
+			formatParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		format = val
+	}
+
+	var privateKey = ""
+	if privateKeyParameter, ok := params["privateKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			privateKeyParameter.SourceType,
+			privateKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		privateKey = val
+	}
+
+	var privateKeyPassword = ""
+	if privateKeyPasswordParameter, ok := params["privateKeyPassword"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			privateKeyPasswordParameter.SourceType,
+			privateKeyPasswordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		privateKeyPassword = val
+	}
+	if format != operations.SignatureFormatMinisign && format != operations.SignatureFormatOpenPGP {
+		return nil, errors.New("\"format\" parameter must be either \"minisign\" or \"openpgp\"")
+	}
+
+	if privateKey == "" {
+		return nil, errors.New("\"privateKey\" parameter is required")
+	}
+
+	operationParams := &operations.FileSignOperationParams{
+		Format: format,
+	}
+
+	if format == operations.SignatureFormatOpenPGP {
+		signer, signerErr := parseOpenPGPSigner(privateKey, privateKeyPassword)
+		if signerErr != nil {
+			return nil, signerErr
+		}
+
+		operationParams.OpenPGPSigner = signer
+	} else {
+		// The minisign secret keys are always encrypted, so the password is required.
+		key, keyErr := minisign.DecryptKey(privateKeyPassword, []byte(privateKey))
+		if keyErr != nil {
+			return nil, errors.New("\"privateKey\" parameter must contain minisign secret key: " + keyErr.Error())
+		}
+
+		operationParams.MinisignPrivateKey = key
+	}
+
+	return &operations.FileSignOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
+
+// parseOpenPGPSigner Reads the first entity with the private key from the armored key.
+func parseOpenPGPSigner(privateKey, password string) (*openpgp.Entity, error) {
+	entities, readErr := openpgp.ReadArmoredKeyRing(strings.NewReader(privateKey))
+	if readErr != nil {
+		return nil, errors.New("\"privateKey\" parameter must contain armored OpenPGP private key: " + readErr.Error())
+	}
+
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			continue
+		}
+
+		if entity.PrivateKey.Encrypted {
+			if password == "" {
+				return nil, errors.New("\"privateKeyPassword\" parameter is required for the encrypted private key")
+			}
+
+			decryptErr := entity.DecryptPrivateKeys([]byte(password))
+			if decryptErr != nil {
+				return nil, errors.New("\"privateKey\" parameter can not be decrypted: " + decryptErr.Error())
+			}
+		}
+
+		return entity, nil
+	}
+
+	return nil, errors.New("\"privateKey\" parameter must contain OpenPGP private key")
+}
@@ -0,0 +1,89 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewFileSplitOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileSplitOperation, error) {
+	var chunkSize int64 = 0
+	if chunkSizeParameter, ok := params["chunkSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			chunkSizeParameter.SourceType,
+			chunkSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		chunkSize = val
+	}
+
+	var preserveLines bool = false
+	if preserveLinesParameter, ok := params["preserveLines"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			preserveLinesParameter.SourceType,
+			preserveLinesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		preserveLines = val
+	}
+
+	var lineCount int64 = 0
+	if lineCountParameter, ok := params["lineCount"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			lineCountParameter.SourceType,
+			lineCountParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		lineCount = val
+	}
+
+	if chunkSize < 0 {
+		return nil, errors.New("\"chunkSize\" parameter must be positive")
+	}
+	if lineCount < 0 {
+		return nil, errors.New("\"lineCount\" parameter must be positive")
+	}
+	if (chunkSize == 0) == (lineCount == 0) {
+		return nil, errors.New("either \"chunkSize\" or \"lineCount\" parameter must be provided")
+	}
+	if preserveLines && lineCount > 0 {
+		return nil, errors.New("\"preserveLines\" parameter can be used only along with \"chunkSize\" parameter")
+	}
+
+	return &operations.FileSplitOperation{
+		Name: name,
+		Params: &operations.FileSplitOperationParams{
+			ChunkSize:     chunkSize,
+			PreserveLines: preserveLines,
+			LineCount:     lineCount,
+		},
+	}, nil
+}
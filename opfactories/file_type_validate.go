@@ -4,6 +4,7 @@ import (
 	"capyfile/operations"
 	"capyfile/parameters"
 	"errors"
+	"strings"
 )
 
 func NewFileTypeValidateOperation(
@@ -27,14 +28,112 @@ func NewFileTypeValidateOperation(
 		}
 
 		allowedMimeTypes = val
-	} else {
-		return nil, errors.New("failed to retrieve \"allowedMimeTypes\" parameter")
+	}
+
+	var deniedMimeTypes []string
+	if deniedMimeTypesParameter, ok := params["deniedMimeTypes"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			deniedMimeTypesParameter.SourceType,
+			deniedMimeTypesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		deniedMimeTypes = val
+	}
+
+	var allowedExtensions []string
+	if allowedExtensionsParameter, ok := params["allowedExtensions"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowedExtensionsParameter.SourceType,
+			allowedExtensionsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowedExtensions = val
+	}
+
+	var deniedExtensions []string
+	if deniedExtensionsParameter, ok := params["deniedExtensions"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			deniedExtensionsParameter.SourceType,
+			deniedExtensionsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		deniedExtensions = val
+	}
+
+	var validateExtension bool = false
+	if validateExtensionParameter, ok := params["validateExtension"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			validateExtensionParameter.SourceType,
+			validateExtensionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		validateExtension = val
+	}
+	if len(allowedMimeTypes) == 0 && len(deniedMimeTypes) == 0 &&
+		len(allowedExtensions) == 0 && len(deniedExtensions) == 0 && !validateExtension {
+		return nil, errors.New(
+			"at least one of \"allowedMimeTypes\", \"deniedMimeTypes\", \"allowedExtensions\", " +
+				"\"deniedExtensions\" or \"validateExtension\" parameters must be set")
 	}
 
 	return &operations.FileTypeValidateOperation{
 		Name: name,
 		Params: &operations.FileTypeValidateOperationParams{
-			AllowedMimeTypes: allowedMimeTypes,
+			AllowedMimeTypes:  allowedMimeTypes,
+			DeniedMimeTypes:   deniedMimeTypes,
+			AllowedExtensions: normalizeExtensions(allowedExtensions),
+			DeniedExtensions:  normalizeExtensions(deniedExtensions),
+			ValidateExtension: validateExtension,
 		},
 	}, nil
 }
+
+// normalizeExtensions Converts the extensions to lowercase with the leading dot, so both "PDF" and ".pdf" can be used.
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+
+		normalized = append(normalized, ext)
+	}
+
+	return normalized
+}
@@ -0,0 +1,112 @@
+package opfactories
+
+import (
+	"aead.dev/minisign"
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"strings"
+)
+
+func NewFileVerifySignatureOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FileVerifySignatureOperation, error) {
+	var format = operations.SignatureFormatMinisign
+	if formatParameter, ok := params["format"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			formatParameter.SourceType,
+			formatParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		format = val
+	}
+
+	var publicKeys []string
+	if publicKeysParameter, ok := params["publicKeys"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			publicKeysParameter.SourceType,
+			publicKeysParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		publicKeys = val
+	}
+
+	var signatureExtension = ""
+	if signatureExtensionParameter, ok := params["signatureExtension"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			signatureExtensionParameter.SourceType,
+			signatureExtensionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		signatureExtension = val
+	}
+	if format != operations.SignatureFormatMinisign && format != operations.SignatureFormatOpenPGP {
+		return nil, errors.New("\"format\" parameter must be either \"minisign\" or \"openpgp\"")
+	}
+
+	if len(publicKeys) == 0 {
+		return nil, errors.New("\"publicKeys\" parameter is required")
+	}
+
+	if signatureExtension == "" {
+		signatureExtension = operations.DefaultSignatureExtension(format)
+	}
+
+	operationParams := &operations.FileVerifySignatureOperationParams{
+		Format:             format,
+		SignatureExtension: signatureExtension,
+	}
+
+	for _, publicKey := range publicKeys {
+		if format == operations.SignatureFormatOpenPGP {
+			entities, readErr := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+			if readErr != nil {
+				return nil, errors.New("\"publicKeys\" parameter must contain armored OpenPGP public keys: " + readErr.Error())
+			}
+
+			operationParams.OpenPGPKeyRing = append(operationParams.OpenPGPKeyRing, entities...)
+
+			continue
+		}
+
+		var key minisign.PublicKey
+		unmarshalErr := key.UnmarshalText([]byte(strings.TrimSpace(publicKey)))
+		if unmarshalErr != nil {
+			return nil, errors.New("\"publicKeys\" parameter must contain minisign public keys: " + unmarshalErr.Error())
+		}
+
+		operationParams.MinisignPublicKeys = append(operationParams.MinisignPublicKeys, key)
+	}
+
+	return &operations.FileVerifySignatureOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
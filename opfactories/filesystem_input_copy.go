@@ -0,0 +1,80 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewFilesystemInputCopyOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.FilesystemInputCopyOperation, error) {
+	var destination string
+	if destinationParameter, ok := params["destination"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			destinationParameter.SourceType,
+			destinationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		destination = val
+	} else {
+		return nil, errors.New("failed to retrieve \"destination\" parameter")
+	}
+
+	var destinationTemplateVars map[string]string
+	if destinationTemplateVarsParameter, ok := params["destinationTemplateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			destinationTemplateVarsParameter.SourceType,
+			destinationTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		destinationTemplateVars = val
+	}
+
+	var useOriginalFilename bool = false
+	if useOriginalFilenameParameter, ok := params["useOriginalFilename"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			useOriginalFilenameParameter.SourceType,
+			useOriginalFilenameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		useOriginalFilename = val
+	}
+
+	return &operations.FilesystemInputCopyOperation{
+		Name: name,
+		Params: &operations.FilesystemInputCopyOperationParams{
+			FilesystemDestinationParams: operations.FilesystemDestinationParams{
+				Destination:             destination,
+				DestinationTemplateVars: destinationTemplateVars,
+				UseOriginalFilename:     useOriginalFilename,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,270 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func NewGCSUploadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.GCSUploadOperation, error) {
+	var credentialsJson = ""
+	if credentialsJsonParameter, ok := params["credentialsJson"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			credentialsJsonParameter.SourceType,
+			credentialsJsonParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		credentialsJson = val
+	}
+
+	var endpoint = ""
+	if endpointParameter, ok := params["endpoint"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			endpointParameter.SourceType,
+			endpointParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		endpoint = val
+	}
+
+	var bucket = ""
+	if bucketParameter, ok := params["bucket"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bucketParameter.SourceType,
+			bucketParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		bucket = val
+	} else {
+		return nil, errors.New("failed to retrieve \"bucket\" parameter")
+	}
+
+	var chunkSize int64 = 0
+	if chunkSizeParameter, ok := params["chunkSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			chunkSizeParameter.SourceType,
+			chunkSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		chunkSize = val
+	}
+	if chunkSize < 0 {
+		return nil, errors.New("\"chunkSize\" parameter must be a positive number")
+	}
+
+	var key = ""
+	if keyParameter, ok := params["key"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyParameter.SourceType,
+			keyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		key = val
+	}
+
+	var keyTemplateVars map[string]string
+	if keyTemplateVarsParameter, ok := params["keyTemplateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyTemplateVarsParameter.SourceType,
+			keyTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyTemplateVars = val
+	}
+
+	var contentDisposition = ""
+	if contentDispositionParameter, ok := params["contentDisposition"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			contentDispositionParameter.SourceType,
+			contentDispositionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		contentDisposition = val
+	}
+	if contentDisposition != "" && contentDisposition != "attachment" && contentDisposition != "inline" {
+		return nil, errors.New("\"contentDisposition\" parameter must be either \"attachment\" or \"inline\"")
+	}
+
+	var cacheControl = ""
+	if cacheControlParameter, ok := params["cacheControl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			cacheControlParameter.SourceType,
+			cacheControlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		cacheControl = val
+	}
+
+	var predefinedAcl = ""
+	if predefinedAclParameter, ok := params["predefinedAcl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			predefinedAclParameter.SourceType,
+			predefinedAclParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		predefinedAcl = val
+	}
+
+	var storageClass = ""
+	if storageClassParameter, ok := params["storageClass"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			storageClassParameter.SourceType,
+			storageClassParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		storageClass = val
+	}
+
+	var metadata map[string]string
+	if metadataParameter, ok := params["metadata"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			metadataParameter.SourceType,
+			metadataParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		metadata = val
+	}
+
+	var presignedUrlExpiration time.Duration
+	if presignedUrlExpirationParameter, ok := params["presignedUrlExpiration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			presignedUrlExpirationParameter.SourceType,
+			presignedUrlExpirationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		expiration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		presignedUrlExpiration = expiration
+	}
+	if presignedUrlExpiration < 0 || presignedUrlExpiration > operations.MaxGCSPresignedUrlExpiration {
+		return nil, fmt.Errorf(
+			"\"presignedUrlExpiration\" parameter must be between 0 and %s",
+			operations.MaxGCSPresignedUrlExpiration,
+		)
+	}
+
+	return &operations.GCSUploadOperation{
+		Name: name,
+		Params: &operations.GCSUploadOperationParams{
+			CredentialsJson: credentialsJson,
+			Endpoint:        endpoint,
+			Bucket:          bucket,
+			ChunkSize:       chunkSize,
+
+			Key:                key,
+			KeyTemplateVars:    keyTemplateVars,
+			ContentDisposition: contentDisposition,
+			CacheControl:       cacheControl,
+			PredefinedACL:      predefinedAcl,
+			StorageClass:       storageClass,
+			Metadata:           metadata,
+
+			PresignedUrlExpiration: presignedUrlExpiration,
+		},
+	}, nil
+}
@@ -2,7 +2,11 @@ package opfactories
 
 import (
 	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 )
 
 func NewHttpMultipartFormInputReadOperation(
@@ -24,3 +28,213 @@ func NewHttpOctetStreamInputReadOperation(
 		Req:  req,
 	}, nil
 }
+
+func NewHTTPInputReadOperation(
+	name string,
+	req *http.Request,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.HTTPInputReadOperation, error) {
+	var urls []string
+	if urlsParameter, ok := params["urls"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			urlsParameter.SourceType,
+			urlsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		urls = val
+	}
+
+	var requestJsonField = ""
+	if requestJsonFieldParameter, ok := params["requestJsonField"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			requestJsonFieldParameter.SourceType,
+			requestJsonFieldParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		requestJsonField = val
+	}
+	if len(urls) == 0 && requestJsonField == "" {
+		return nil, errors.New("either \"urls\" or \"requestJsonField\" parameter is required")
+	}
+
+	var headers map[string]string
+	if headersParameter, ok := params["headers"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			headersParameter.SourceType,
+			headersParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		headers = val
+	}
+
+	var timeout = 60 * time.Second
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	var maxFileSize int64 = 0
+	if maxFileSizeParameter, ok := params["maxFileSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxFileSizeParameter.SourceType,
+			maxFileSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxFileSize = val
+	}
+	if maxFileSize < 0 {
+		return nil, errors.New("\"maxFileSize\" parameter must be a positive number")
+	}
+
+	var maxRedirects int64 = 10
+	if maxRedirectsParameter, ok := params["maxRedirects"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxRedirectsParameter.SourceType,
+			maxRedirectsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxRedirects = val
+	}
+	if maxRedirects < 0 {
+		return nil, errors.New("\"maxRedirects\" parameter must be a positive number")
+	}
+
+	var allowedSchemes = []string{"http", "https"}
+	if allowedSchemesParameter, ok := params["allowedSchemes"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowedSchemesParameter.SourceType,
+			allowedSchemesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowedSchemes = val
+	}
+	for _, scheme := range allowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			return nil, fmt.Errorf("\"allowedSchemes\" parameter contains unsupported scheme \"%s\"", scheme)
+		}
+	}
+
+	var allowedHosts []string
+	if allowedHostsParameter, ok := params["allowedHosts"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowedHostsParameter.SourceType,
+			allowedHostsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowedHosts = val
+	}
+	if len(headers) > 0 && len(allowedHosts) == 0 {
+		return nil, errors.New("\"allowedHosts\" parameter is required to send the \"headers\"")
+	}
+
+	var allowPrivateAddresses bool = false
+	if allowPrivateAddressesParameter, ok := params["allowPrivateAddresses"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowPrivateAddressesParameter.SourceType,
+			allowPrivateAddressesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowPrivateAddresses = val
+	}
+
+	return &operations.HTTPInputReadOperation{
+		Name: name,
+		Params: &operations.HTTPInputReadOperationParams{
+			Urls:                  urls,
+			RequestJsonField:      requestJsonField,
+			Headers:               headers,
+			Timeout:               timeout,
+			MaxFileSize:           maxFileSize,
+			MaxRedirects:          maxRedirects,
+			AllowedSchemes:        allowedSchemes,
+			AllowedHosts:          allowedHosts,
+			AllowPrivateAddresses: allowPrivateAddresses,
+		},
+		Req: req,
+	}, nil
+}
@@ -0,0 +1,410 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+var statusErrorCodePattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+func NewHTTPUploadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.HTTPUploadOperation, error) {
+	var url = ""
+	if urlParameter, ok := params["url"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			urlParameter.SourceType,
+			urlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		url = val
+	} else {
+		return nil, errors.New("failed to retrieve \"url\" parameter")
+	}
+
+	var method = http.MethodPost
+	if methodParameter, ok := params["method"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			methodParameter.SourceType,
+			methodParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		method = val
+	}
+	if method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch {
+		return nil, errors.New("\"method\" parameter must be one of \"POST\", \"PUT\", \"PATCH\"")
+	}
+
+	var templateVars map[string]string
+	if templateVarsParameter, ok := params["templateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			templateVarsParameter.SourceType,
+			templateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		templateVars = val
+	}
+
+	var headers map[string]string
+	if headersParameter, ok := params["headers"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			headersParameter.SourceType,
+			headersParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		headers = val
+	}
+
+	var timeout = 60 * time.Second
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	var bodyType = operations.HTTPUploadBodyTypeMultipart
+	if bodyTypeParameter, ok := params["bodyType"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bodyTypeParameter.SourceType,
+			bodyTypeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		bodyType = val
+	}
+	if bodyType != operations.HTTPUploadBodyTypeMultipart && bodyType != operations.HTTPUploadBodyTypeRaw {
+		return nil, errors.New("\"bodyType\" parameter must be either \"multipart\" or \"raw\"")
+	}
+
+	var multipartFieldName = "file"
+	if multipartFieldNameParameter, ok := params["multipartFieldName"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			multipartFieldNameParameter.SourceType,
+			multipartFieldNameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		multipartFieldName = val
+	}
+
+	var multipartFields map[string]string
+	if multipartFieldsParameter, ok := params["multipartFields"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			multipartFieldsParameter.SourceType,
+			multipartFieldsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		multipartFields = val
+	}
+
+	var authType = ""
+	if authTypeParameter, ok := params["authType"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			authTypeParameter.SourceType,
+			authTypeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		authType = val
+	}
+	if authType != operations.HTTPUploadAuthTypeNone &&
+		authType != operations.HTTPUploadAuthTypeBearer &&
+		authType != operations.HTTPUploadAuthTypeBasic &&
+		authType != operations.HTTPUploadAuthTypeHMAC {
+		return nil, errors.New("\"authType\" parameter must be one of \"bearer\", \"basic\", \"hmac\"")
+	}
+
+	var bearerToken = ""
+	if bearerTokenParameter, ok := params["bearerToken"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bearerTokenParameter.SourceType,
+			bearerTokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		bearerToken = val
+	}
+
+	var basicUsername = ""
+	if basicUsernameParameter, ok := params["basicUsername"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			basicUsernameParameter.SourceType,
+			basicUsernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		basicUsername = val
+	}
+
+	var basicPassword = ""
+	if basicPasswordParameter, ok := params["basicPassword"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			basicPasswordParameter.SourceType,
+			basicPasswordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		basicPassword = val
+	}
+
+	var hmacSecret = ""
+	if hmacSecretParameter, ok := params["hmacSecret"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			hmacSecretParameter.SourceType,
+			hmacSecretParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		hmacSecret = val
+	}
+
+	var hmacHeader = "X-Signature"
+	if hmacHeaderParameter, ok := params["hmacHeader"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			hmacHeaderParameter.SourceType,
+			hmacHeaderParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		hmacHeader = val
+	}
+
+	var hmacAlgorithm = operations.HTTPUploadHMACAlgorithmSHA256
+	if hmacAlgorithmParameter, ok := params["hmacAlgorithm"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			hmacAlgorithmParameter.SourceType,
+			hmacAlgorithmParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		hmacAlgorithm = val
+	}
+	if hmacAlgorithm != operations.HTTPUploadHMACAlgorithmSHA256 && hmacAlgorithm != operations.HTTPUploadHMACAlgorithmSHA512 {
+		return nil, errors.New("\"hmacAlgorithm\" parameter must be either \"sha256\" or \"sha512\"")
+	}
+
+	var statusErrorCodes map[string]string
+	if statusErrorCodesParameter, ok := params["statusErrorCodes"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			statusErrorCodesParameter.SourceType,
+			statusErrorCodesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		statusErrorCodes = val
+	}
+	for status := range statusErrorCodes {
+		if !statusErrorCodePattern.MatchString(status) {
+			return nil, fmt.Errorf("\"statusErrorCodes\" parameter key \"%s\" must be either status code or status class like \"4xx\"", status)
+		}
+	}
+
+	var fileUrlField = ""
+	if fileUrlFieldParameter, ok := params["fileUrlField"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			fileUrlFieldParameter.SourceType,
+			fileUrlFieldParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		fileUrlField = val
+	}
+
+	var responseFields map[string]string
+	if responseFieldsParameter, ok := params["responseFields"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			responseFieldsParameter.SourceType,
+			responseFieldsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		responseFields = val
+	}
+
+	switch {
+	case authType == operations.HTTPUploadAuthTypeBearer && bearerToken == "":
+		return nil, errors.New("\"bearerToken\" parameter is required for bearer auth")
+	case authType == operations.HTTPUploadAuthTypeBasic && basicUsername == "":
+		return nil, errors.New("\"basicUsername\" parameter is required for basic auth")
+	case authType == operations.HTTPUploadAuthTypeHMAC && hmacSecret == "":
+		return nil, errors.New("\"hmacSecret\" parameter is required for hmac auth")
+	}
+
+	return &operations.HTTPUploadOperation{
+		Name: name,
+		Params: &operations.HTTPUploadOperationParams{
+			Url:          url,
+			Method:       method,
+			TemplateVars: templateVars,
+			Headers:      headers,
+			Timeout:      timeout,
+
+			BodyType:           bodyType,
+			MultipartFieldName: multipartFieldName,
+			MultipartFields:    multipartFields,
+
+			AuthType:      authType,
+			BearerToken:   bearerToken,
+			BasicUsername: basicUsername,
+			BasicPassword: basicPassword,
+			HMACSecret:    hmacSecret,
+			HMACHeader:    hmacHeader,
+			HMACAlgorithm: hmacAlgorithm,
+
+			StatusErrorCodes: statusErrorCodes,
+
+			FileUrlField:   fileUrlField,
+			ResponseFields: responseFields,
+		},
+	}, nil
+}
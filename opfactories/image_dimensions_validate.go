@@ -0,0 +1,266 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func NewImageDimensionsValidateOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.ImageDimensionsValidateOperation, error) {
+	var minWidth int64 = 0
+	if minWidthParameter, ok := params["minWidth"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minWidthParameter.SourceType,
+			minWidthParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minWidth = val
+	}
+
+	var maxWidth int64 = 0
+	if maxWidthParameter, ok := params["maxWidth"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxWidthParameter.SourceType,
+			maxWidthParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxWidth = val
+	}
+
+	var minHeight int64 = 0
+	if minHeightParameter, ok := params["minHeight"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minHeightParameter.SourceType,
+			minHeightParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minHeight = val
+	}
+
+	var maxHeight int64 = 0
+	if maxHeightParameter, ok := params["maxHeight"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxHeightParameter.SourceType,
+			maxHeightParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxHeight = val
+	}
+
+	var minMegapixels = ""
+	if minMegapixelsParameter, ok := params["minMegapixels"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minMegapixelsParameter.SourceType,
+			minMegapixelsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minMegapixels = val
+	}
+
+	var maxMegapixels = ""
+	if maxMegapixelsParameter, ok := params["maxMegapixels"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxMegapixelsParameter.SourceType,
+			maxMegapixelsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxMegapixels = val
+	}
+
+	var minAspectRatio = ""
+	if minAspectRatioParameter, ok := params["minAspectRatio"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minAspectRatioParameter.SourceType,
+			minAspectRatioParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minAspectRatio = val
+	}
+
+	var maxAspectRatio = ""
+	if maxAspectRatioParameter, ok := params["maxAspectRatio"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxAspectRatioParameter.SourceType,
+			maxAspectRatioParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxAspectRatio = val
+	}
+	var animated *bool
+	if animatedParameter, ok := params["animated"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			animatedParameter.SourceType,
+			animatedParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		animated = &val
+	}
+
+	operationParams := &operations.ImageDimensionsValidateOperationParams{
+		MinWidth:  minWidth,
+		MaxWidth:  maxWidth,
+		MinHeight: minHeight,
+		MaxHeight: maxHeight,
+		Animated:  animated,
+	}
+
+	parsedMinMegapixels, parseMinMegapixelsErr := parsePositiveFloat("minMegapixels", minMegapixels)
+	if parseMinMegapixelsErr != nil {
+		return nil, parseMinMegapixelsErr
+	}
+	operationParams.MinMegapixels = parsedMinMegapixels
+
+	parsedMaxMegapixels, parseMaxMegapixelsErr := parsePositiveFloat("maxMegapixels", maxMegapixels)
+	if parseMaxMegapixelsErr != nil {
+		return nil, parseMaxMegapixelsErr
+	}
+	operationParams.MaxMegapixels = parsedMaxMegapixels
+
+	parsedMinAspectRatio, parseMinAspectRatioErr := parseAspectRatio("minAspectRatio", minAspectRatio)
+	if parseMinAspectRatioErr != nil {
+		return nil, parseMinAspectRatioErr
+	}
+	operationParams.MinAspectRatio = parsedMinAspectRatio
+
+	parsedMaxAspectRatio, parseMaxAspectRatioErr := parseAspectRatio("maxAspectRatio", maxAspectRatio)
+	if parseMaxAspectRatioErr != nil {
+		return nil, parseMaxAspectRatioErr
+	}
+	operationParams.MaxAspectRatio = parsedMaxAspectRatio
+
+	if operationParams.MinWidth == 0 && operationParams.MaxWidth == 0 &&
+		operationParams.MinHeight == 0 && operationParams.MaxHeight == 0 &&
+		operationParams.MinMegapixels == 0 && operationParams.MaxMegapixels == 0 &&
+		operationParams.MinAspectRatio == 0 && operationParams.MaxAspectRatio == 0 &&
+		operationParams.Animated == nil {
+		return nil, errors.New("at least one of the image constraints must be set")
+	}
+
+	if operationParams.MaxWidth > 0 && operationParams.MinWidth > operationParams.MaxWidth {
+		return nil, errors.New("\"minWidth\" parameter can not be greater than \"maxWidth\"")
+	}
+	if operationParams.MaxHeight > 0 && operationParams.MinHeight > operationParams.MaxHeight {
+		return nil, errors.New("\"minHeight\" parameter can not be greater than \"maxHeight\"")
+	}
+	if operationParams.MaxMegapixels > 0 && operationParams.MinMegapixels > operationParams.MaxMegapixels {
+		return nil, errors.New("\"minMegapixels\" parameter can not be greater than \"maxMegapixels\"")
+	}
+	if operationParams.MaxAspectRatio > 0 && operationParams.MinAspectRatio > operationParams.MaxAspectRatio {
+		return nil, errors.New("\"minAspectRatio\" parameter can not be greater than \"maxAspectRatio\"")
+	}
+
+	return &operations.ImageDimensionsValidateOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
+
+// parsePositiveFloat Parses the decimal parameter value. The empty value is parsed as zero.
+func parsePositiveFloat(name, value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, parseErr := strconv.ParseFloat(value, 64)
+	if parseErr != nil || parsed <= 0 {
+		return 0, fmt.Errorf("\"%s\" parameter must be a positive number", name)
+	}
+
+	return parsed, nil
+}
+
+// parseAspectRatio Parses the aspect ratio written either as "width:height" (for example, "16:9")
+// or as the decimal width to height ratio (for example, "1.78"). The empty value is parsed as zero.
+func parseAspectRatio(name, value string) (float64, error) {
+	width, height, isRatio := strings.Cut(value, ":")
+	if !isRatio {
+		return parsePositiveFloat(name, value)
+	}
+
+	parsedWidth, widthErr := strconv.ParseFloat(width, 64)
+	parsedHeight, heightErr := strconv.ParseFloat(height, 64)
+	if widthErr != nil || heightErr != nil || parsedWidth <= 0 || parsedHeight <= 0 {
+		return 0, fmt.Errorf("\"%s\" parameter must be either \"width:height\" or a positive number", name)
+	}
+
+	return parsedWidth / parsedHeight, nil
+}
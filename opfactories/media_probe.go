@@ -0,0 +1,47 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewMediaProbeOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.MediaProbeOperation, error) {
+	var timeout = time.Minute
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	return &operations.MediaProbeOperation{
+		Name: name,
+		Params: &operations.MediaProbeOperationParams{
+			Timeout: timeout,
+		},
+	}, nil
+}
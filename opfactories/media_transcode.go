@@ -0,0 +1,101 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewMediaTranscodeOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.MediaTranscodeOperation, error) {
+	var preset = ""
+	if presetParameter, ok := params["preset"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			presetParameter.SourceType,
+			presetParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		preset = val
+	} else {
+		return nil, errors.New("failed to retrieve \"preset\" parameter")
+	}
+
+	var posterFrameTime = time.Second
+	if posterFrameTimeParameter, ok := params["posterFrameTime"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			posterFrameTimeParameter.SourceType,
+			posterFrameTimeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedPosterFrameTime, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		posterFrameTime = parsedPosterFrameTime
+	}
+	if posterFrameTime < 0 {
+		return nil, errors.New("\"posterFrameTime\" parameter must be a positive duration")
+	}
+
+	var timeout = time.Hour
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	if preset != operations.MediaTranscodePresetWebMP4 &&
+		preset != operations.MediaTranscodePresetAudioMP3 &&
+		preset != operations.MediaTranscodePresetPosterFrame {
+		return nil, errors.New("\"preset\" parameter must be one of \"web_mp4\", \"audio_mp3\" or \"poster_frame\"")
+	}
+
+	return &operations.MediaTranscodeOperation{
+		Name: name,
+		Params: &operations.MediaTranscodeOperationParams{
+			Preset:          preset,
+			PosterFrameTime: posterFrameTime,
+			Timeout:         timeout,
+		},
+	}, nil
+}
@@ -0,0 +1,135 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewMediaValidateOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.MediaValidateOperation, error) {
+	var maxDuration time.Duration
+	if maxDurationParameter, ok := params["maxDuration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxDurationParameter.SourceType,
+			maxDurationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedMaxDuration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		maxDuration = parsedMaxDuration
+	}
+	if maxDuration < 0 {
+		return nil, errors.New("\"maxDuration\" parameter must be a positive duration")
+	}
+
+	var allowUnknownDuration bool = false
+	if allowUnknownDurationParameter, ok := params["allowUnknownDuration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowUnknownDurationParameter.SourceType,
+			allowUnknownDurationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowUnknownDuration = val
+	}
+
+	var allowedVideoCodecs []string
+	if allowedVideoCodecsParameter, ok := params["allowedVideoCodecs"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowedVideoCodecsParameter.SourceType,
+			allowedVideoCodecsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowedVideoCodecs = val
+	}
+
+	var allowedAudioCodecs []string
+	if allowedAudioCodecsParameter, ok := params["allowedAudioCodecs"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowedAudioCodecsParameter.SourceType,
+			allowedAudioCodecsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowedAudioCodecs = val
+	}
+
+	var timeout = time.Minute
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	if maxDuration == 0 && len(allowedVideoCodecs) == 0 && len(allowedAudioCodecs) == 0 {
+		return nil, errors.New("at least one of \"maxDuration\", \"allowedVideoCodecs\" or \"allowedAudioCodecs\" parameters must be set")
+	}
+
+	return &operations.MediaValidateOperation{
+		Name: name,
+		Params: &operations.MediaValidateOperationParams{
+			MaxDuration:          maxDuration,
+			AllowUnknownDuration: allowUnknownDuration,
+			AllowedVideoCodecs:   allowedVideoCodecs,
+			AllowedAudioCodecs:   allowedAudioCodecs,
+			Timeout:              timeout,
+		},
+	}, nil
+}
@@ -0,0 +1,196 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewNATSInputReadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.NATSInputReadOperation, error) {
+	queueInputParams, queueInputParamsErr := newQueueInputParams(params, parameterLoaderProvider)
+	if queueInputParamsErr != nil {
+		return nil, queueInputParamsErr
+	}
+
+	var url = ""
+	if urlParameter, ok := params["url"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			urlParameter.SourceType,
+			urlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		url = val
+	} else {
+		return nil, errors.New("failed to retrieve \"url\" parameter")
+	}
+
+	var username = ""
+	if usernameParameter, ok := params["username"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			usernameParameter.SourceType,
+			usernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		username = val
+	}
+
+	var password = ""
+	if passwordParameter, ok := params["password"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			passwordParameter.SourceType,
+			passwordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		password = val
+	}
+
+	var token = ""
+	if tokenParameter, ok := params["token"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			tokenParameter.SourceType,
+			tokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		token = val
+	}
+
+	var stream = ""
+	if streamParameter, ok := params["stream"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			streamParameter.SourceType,
+			streamParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		stream = val
+	}
+
+	var subject = ""
+	if subjectParameter, ok := params["subject"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			subjectParameter.SourceType,
+			subjectParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		subject = val
+	} else {
+		return nil, errors.New("failed to retrieve \"subject\" parameter")
+	}
+
+	var durable = ""
+	if durableParameter, ok := params["durable"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			durableParameter.SourceType,
+			durableParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		durable = val
+	} else {
+		return nil, errors.New("failed to retrieve \"durable\" parameter")
+	}
+
+	var ackWait time.Duration = 0
+	if ackWaitParameter, ok := params["ackWait"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			ackWaitParameter.SourceType,
+			ackWaitParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedAckWait, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		ackWait = parsedAckWait
+	}
+	if ackWait < 0 {
+		return nil, errors.New("\"ackWait\" parameter must be a positive duration")
+	}
+	if queueInputParams.VisibilityExtensionInterval > 0 && ackWait <= queueInputParams.VisibilityExtensionInterval {
+		return nil, errors.New(
+			"\"ackWait\" parameter must be greater than \"visibilityExtensionInterval\" parameter")
+	}
+
+	return &operations.NATSInputReadOperation{
+		Name: name,
+		Params: &operations.NATSInputReadOperationParams{
+			QueueInputParams: queueInputParams,
+			Url:              url,
+			Username:         username,
+			Password:         password,
+			Token:            token,
+			Stream:           stream,
+			Subject:          subject,
+			Durable:          durable,
+			AckWait:          ackWait,
+		},
+	}, nil
+}
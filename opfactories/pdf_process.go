@@ -0,0 +1,209 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewPDFProcessOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.PDFProcessOperation, error) {
+	var minPages int64 = 0
+	if minPagesParameter, ok := params["minPages"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minPagesParameter.SourceType,
+			minPagesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minPages = val
+	}
+
+	var maxPages int64 = 0
+	if maxPagesParameter, ok := params["maxPages"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxPagesParameter.SourceType,
+			maxPagesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxPages = val
+	}
+
+	var allowEncrypted bool = false
+	if allowEncryptedParameter, ok := params["allowEncrypted"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			allowEncryptedParameter.SourceType,
+			allowEncryptedParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		allowEncrypted = val
+	}
+
+	var renderPages = ""
+	if renderPagesParameter, ok := params["renderPages"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			renderPagesParameter.SourceType,
+			renderPagesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		renderPages = val
+	}
+
+	var renderFormat = operations.PDFRenderFormatPNG
+	if renderFormatParameter, ok := params["renderFormat"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			renderFormatParameter.SourceType,
+			renderFormatParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		renderFormat = val
+	}
+
+	var renderResolution int64 = 150
+	if renderResolutionParameter, ok := params["renderResolution"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			renderResolutionParameter.SourceType,
+			renderResolutionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		renderResolution = val
+	}
+
+	var textOutput = ""
+	if textOutputParameter, ok := params["textOutput"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			textOutputParameter.SourceType,
+			textOutputParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		textOutput = val
+	}
+
+	if minPages < 0 || maxPages < 0 {
+		return nil, errors.New("\"minPages\" and \"maxPages\" parameters must be positive")
+	}
+	if maxPages > 0 && minPages > maxPages {
+		return nil, errors.New("\"minPages\" parameter must be less than or equal to \"maxPages\" parameter")
+	}
+
+	var renderPageRanges []operations.PDFPageRange
+	if renderPages != "" {
+		var parseErr error
+		renderPageRanges, parseErr = operations.ParsePDFPageRanges(renderPages)
+		if parseErr != nil {
+			return nil, errors.New("\"renderPages\" parameter is not valid: " + parseErr.Error())
+		}
+	}
+
+	if renderFormat != operations.PDFRenderFormatPNG && renderFormat != operations.PDFRenderFormatJPEG {
+		return nil, errors.New("\"renderFormat\" parameter must be either \"png\" or \"jpeg\"")
+	}
+	if renderResolution <= 0 {
+		return nil, errors.New("\"renderResolution\" parameter must be positive")
+	}
+
+	if textOutput != "" &&
+		textOutput != operations.PDFTextOutputMetadata &&
+		textOutput != operations.PDFTextOutputSidecar {
+		return nil, errors.New("\"textOutput\" parameter must be either \"metadata\" or \"sidecar\"")
+	}
+
+	var timeout = 5 * time.Minute
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	return &operations.PDFProcessOperation{
+		Name: name,
+		Params: &operations.PDFProcessOperationParams{
+			MinPages:         minPages,
+			MaxPages:         maxPages,
+			AllowEncrypted:   allowEncrypted,
+			RenderPages:      renderPageRanges,
+			RenderFormat:     renderFormat,
+			RenderResolution: renderResolution,
+			TextOutput:       textOutput,
+			Timeout:          timeout,
+		},
+	}, nil
+}
@@ -0,0 +1,254 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// queueInputS3ParameterPrefix is the prefix of the parameters to connect to S3-compatible storage
+// to read the S3 objects the messages refer to. For example: s3AccessKeyId, s3Bucket
+const queueInputS3ParameterPrefix = "s3"
+
+// newQueueInputParams Loads the parameters that are shared by all the queue input operations.
+func newQueueInputParams(
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (operations.QueueInputParams, error) {
+	var maxMessages int64 = 10
+	if maxMessagesParameter, ok := params["maxMessages"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxMessagesParameter.SourceType,
+			maxMessagesParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		maxMessages = val
+	}
+	if maxMessages <= 0 {
+		return operations.QueueInputParams{}, errors.New("\"maxMessages\" parameter must be a positive number")
+	}
+
+	var waitTime time.Duration = 0
+	if waitTimeParameter, ok := params["waitTime"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			waitTimeParameter.SourceType,
+			waitTimeParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		parsedWaitTime, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return operations.QueueInputParams{}, parseErr
+		}
+
+		waitTime = parsedWaitTime
+	}
+	if waitTime < 0 {
+		return operations.QueueInputParams{}, errors.New("\"waitTime\" parameter must be a positive duration")
+	}
+
+	var visibilityExtensionInterval time.Duration = 0
+	if visibilityExtensionIntervalParameter, ok := params["visibilityExtensionInterval"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			visibilityExtensionIntervalParameter.SourceType,
+			visibilityExtensionIntervalParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		parsedVisibilityExtensionInterval, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return operations.QueueInputParams{}, parseErr
+		}
+
+		visibilityExtensionInterval = parsedVisibilityExtensionInterval
+	}
+	if visibilityExtensionInterval < 0 {
+		return operations.QueueInputParams{}, errors.New("\"visibilityExtensionInterval\" parameter must be a positive duration")
+	}
+
+	var downloadTimeout = 60 * time.Second
+	if downloadTimeoutParameter, ok := params["downloadTimeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadTimeoutParameter.SourceType,
+			downloadTimeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		parsedDownloadTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return operations.QueueInputParams{}, parseErr
+		}
+
+		downloadTimeout = parsedDownloadTimeout
+	}
+	if downloadTimeout < 0 {
+		return operations.QueueInputParams{}, errors.New("\"downloadTimeout\" parameter must be a positive duration")
+	}
+
+	var downloadMaxFileSize int64 = 0
+	if downloadMaxFileSizeParameter, ok := params["downloadMaxFileSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadMaxFileSizeParameter.SourceType,
+			downloadMaxFileSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		downloadMaxFileSize = val
+	}
+	if downloadMaxFileSize < 0 {
+		return operations.QueueInputParams{}, errors.New("\"downloadMaxFileSize\" parameter must be a positive number")
+	}
+
+	var downloadMaxRedirects int64 = 10
+	if downloadMaxRedirectsParameter, ok := params["downloadMaxRedirects"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadMaxRedirectsParameter.SourceType,
+			downloadMaxRedirectsParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		downloadMaxRedirects = val
+	}
+	if downloadMaxRedirects < 0 {
+		return operations.QueueInputParams{}, errors.New("\"downloadMaxRedirects\" parameter must be a positive number")
+	}
+
+	var downloadAllowedSchemes = []string{"http", "https"}
+	if downloadAllowedSchemesParameter, ok := params["downloadAllowedSchemes"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadAllowedSchemesParameter.SourceType,
+			downloadAllowedSchemesParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		downloadAllowedSchemes = val
+	}
+	for _, scheme := range downloadAllowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			return operations.QueueInputParams{}, fmt.Errorf(
+				"\"downloadAllowedSchemes\" parameter contains unsupported scheme \"%s\"", scheme)
+		}
+	}
+
+	var downloadAllowedHosts []string
+	if downloadAllowedHostsParameter, ok := params["downloadAllowedHosts"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadAllowedHostsParameter.SourceType,
+			downloadAllowedHostsParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringArrayValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		downloadAllowedHosts = val
+	}
+
+	var downloadAllowPrivateAddresses bool = false
+	if downloadAllowPrivateAddressesParameter, ok := params["downloadAllowPrivateAddresses"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			downloadAllowPrivateAddressesParameter.SourceType,
+			downloadAllowPrivateAddressesParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.QueueInputParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return operations.QueueInputParams{}, valErr
+		}
+
+		downloadAllowPrivateAddresses = val
+	}
+
+	queueInputParams := operations.QueueInputParams{
+		MaxMessages:                   maxMessages,
+		WaitTime:                      waitTime,
+		VisibilityExtensionInterval:   visibilityExtensionInterval,
+		DownloadTimeout:               downloadTimeout,
+		DownloadMaxFileSize:           downloadMaxFileSize,
+		DownloadMaxRedirects:          downloadMaxRedirects,
+		DownloadAllowedSchemes:        downloadAllowedSchemes,
+		DownloadAllowedHosts:          downloadAllowedHosts,
+		DownloadAllowPrivateAddresses: downloadAllowPrivateAddresses,
+	}
+
+	// The S3 parameters are only needed if the messages refer to S3 objects.
+	s3Params := make(map[string]parameters.Parameter)
+	for name, parameter := range params {
+		s3ParamName := strings.TrimPrefix(name, queueInputS3ParameterPrefix)
+		if s3ParamName == name || s3ParamName == "" {
+			continue
+		}
+
+		s3Params[strings.ToLower(s3ParamName[:1])+s3ParamName[1:]] = parameter
+	}
+	if len(s3Params) > 0 {
+		s3ClientParams, s3ClientParamsErr := newS3ClientParams(s3Params, parameterLoaderProvider)
+		if s3ClientParamsErr != nil {
+			return operations.QueueInputParams{}, s3ClientParamsErr
+		}
+
+		queueInputParams.S3 = &s3ClientParams
+	}
+
+	return queueInputParams, nil
+}
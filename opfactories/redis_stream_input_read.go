@@ -0,0 +1,226 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"os"
+	"time"
+)
+
+func NewRedisStreamInputReadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.RedisStreamInputReadOperation, error) {
+	queueInputParams, queueInputParamsErr := newQueueInputParams(params, parameterLoaderProvider)
+	if queueInputParamsErr != nil {
+		return nil, queueInputParamsErr
+	}
+
+	var addr = ""
+	if addrParameter, ok := params["addr"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			addrParameter.SourceType,
+			addrParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		addr = val
+	} else {
+		return nil, errors.New("failed to retrieve \"addr\" parameter")
+	}
+
+	var username = ""
+	if usernameParameter, ok := params["username"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			usernameParameter.SourceType,
+			usernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		username = val
+	}
+
+	var password = ""
+	if passwordParameter, ok := params["password"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			passwordParameter.SourceType,
+			passwordParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		password = val
+	}
+
+	var db int64 = 0
+	if dbParameter, ok := params["db"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			dbParameter.SourceType,
+			dbParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		db = val
+	}
+
+	var stream = ""
+	if streamParameter, ok := params["stream"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			streamParameter.SourceType,
+			streamParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		stream = val
+	} else {
+		return nil, errors.New("failed to retrieve \"stream\" parameter")
+	}
+
+	var group = ""
+	if groupParameter, ok := params["group"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			groupParameter.SourceType,
+			groupParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		group = val
+	} else {
+		return nil, errors.New("failed to retrieve \"group\" parameter")
+	}
+
+	var consumer = ""
+	if consumerParameter, ok := params["consumer"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			consumerParameter.SourceType,
+			consumerParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		consumer = val
+	}
+	if consumer == "" {
+		hostname, hostnameErr := os.Hostname()
+		if hostnameErr != nil {
+			return nil, hostnameErr
+		}
+
+		consumer = hostname
+	}
+
+	var field = "body"
+	if fieldParameter, ok := params["field"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			fieldParameter.SourceType,
+			fieldParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		field = val
+	}
+
+	var claimMinIdleTime = 10 * time.Minute
+	if claimMinIdleTimeParameter, ok := params["claimMinIdleTime"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			claimMinIdleTimeParameter.SourceType,
+			claimMinIdleTimeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedClaimMinIdleTime, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		claimMinIdleTime = parsedClaimMinIdleTime
+	}
+	if claimMinIdleTime < 0 {
+		return nil, errors.New("\"claimMinIdleTime\" parameter must be a positive duration")
+	}
+	if claimMinIdleTime > 0 &&
+		queueInputParams.VisibilityExtensionInterval > 0 &&
+		claimMinIdleTime <= queueInputParams.VisibilityExtensionInterval {
+		return nil, errors.New(
+			"\"claimMinIdleTime\" parameter must be greater than \"visibilityExtensionInterval\" parameter")
+	}
+
+	return &operations.RedisStreamInputReadOperation{
+		Name: name,
+		Params: &operations.RedisStreamInputReadOperationParams{
+			QueueInputParams: queueInputParams,
+			Addr:             addr,
+			Username:         username,
+			Password:         password,
+			DB:               db,
+			Stream:           stream,
+			Group:            group,
+			Consumer:         consumer,
+			Field:            field,
+			ClaimMinIdleTime: claimMinIdleTime,
+		},
+	}, nil
+}
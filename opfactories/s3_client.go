@@ -0,0 +1,159 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+// newS3ClientParams Loads the parameters that are required to connect to S3-compatible storage.
+func newS3ClientParams(
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (operations.S3ClientParams, error) {
+	var accessKeyId = ""
+	if accessKeyIdParameter, ok := params["accessKeyId"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			accessKeyIdParameter.SourceType,
+			accessKeyIdParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		accessKeyId = val
+	} else {
+		return operations.S3ClientParams{}, errors.New("failed to retrieve \"accessKeyId\" parameter")
+	}
+
+	var secretAccessKey = ""
+	if secretAccessKeyParameter, ok := params["secretAccessKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			secretAccessKeyParameter.SourceType,
+			secretAccessKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		secretAccessKey = val
+	} else {
+		return operations.S3ClientParams{}, errors.New("failed to retrieve \"secretAccessKey\" parameter")
+	}
+
+	var sessionToken = ""
+	if sessionTokenParameter, ok := params["sessionToken"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sessionTokenParameter.SourceType,
+			sessionTokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		sessionToken = val
+	}
+
+	var endpoint = ""
+	if endpointParameter, ok := params["endpoint"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			endpointParameter.SourceType,
+			endpointParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		endpoint = val
+	} else {
+		return operations.S3ClientParams{}, errors.New("failed to retrieve \"endpoint\" parameter")
+	}
+
+	var region = ""
+	if regionParameter, ok := params["region"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			regionParameter.SourceType,
+			regionParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		region = val
+	} else {
+		return operations.S3ClientParams{}, errors.New("failed to retrieve \"region\" parameter")
+	}
+
+	var bucket = ""
+	if bucketParameter, ok := params["bucket"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bucketParameter.SourceType,
+			bucketParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		bucket = val
+	} else {
+		return operations.S3ClientParams{}, errors.New("failed to retrieve \"bucket\" parameter")
+	}
+
+	var usePathStyle bool = false
+	if usePathStyleParameter, ok := params["usePathStyle"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			usePathStyleParameter.SourceType,
+			usePathStyleParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.S3ClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return operations.S3ClientParams{}, valErr
+		}
+
+		usePathStyle = val
+	}
+
+	return operations.S3ClientParams{
+		AccessKeyId:     accessKeyId,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		Endpoint:        endpoint,
+		Region:          region,
+		Bucket:          bucket,
+		UsePathStyle:    usePathStyle,
+	}, nil
+}
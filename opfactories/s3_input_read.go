@@ -0,0 +1,167 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"path"
+	"time"
+)
+
+func NewS3InputReadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.S3InputReadOperation, error) {
+	s3ClientParams, s3ClientParamsErr := newS3ClientParams(params, parameterLoaderProvider)
+	if s3ClientParamsErr != nil {
+		return nil, s3ClientParamsErr
+	}
+
+	var prefix = ""
+	if prefixParameter, ok := params["prefix"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			prefixParameter.SourceType,
+			prefixParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		prefix = val
+	}
+
+	var pattern = ""
+	if patternParameter, ok := params["pattern"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			patternParameter.SourceType,
+			patternParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		pattern = val
+	}
+	if _, matchErr := path.Match(pattern, ""); matchErr != nil {
+		return nil, errors.New("\"pattern\" parameter must be a valid glob pattern")
+	}
+
+	var minFileSize int64 = 0
+	if minFileSizeParameter, ok := params["minFileSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			minFileSizeParameter.SourceType,
+			minFileSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		minFileSize = val
+	}
+
+	var maxFileSize int64 = 0
+	if maxFileSizeParameter, ok := params["maxFileSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxFileSizeParameter.SourceType,
+			maxFileSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxFileSize = val
+	}
+
+	var maxObjects int64 = 0
+	if maxObjectsParameter, ok := params["maxObjects"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			maxObjectsParameter.SourceType,
+			maxObjectsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		maxObjects = val
+	}
+	if maxObjects < 0 {
+		return nil, errors.New("\"maxObjects\" parameter must be greater than or equal to 0")
+	}
+
+	timeParamValExtractor := func(paramName string) (time.Time, error) {
+		var paramVal time.Time
+		if param, ok := params[paramName]; ok {
+			parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+				param.SourceType,
+				param.Source,
+			)
+			if loaderErr != nil {
+				return paramVal, loaderErr
+			}
+
+			val, valErr := parameterLoader.LoadStringValue()
+			if valErr != nil {
+				return paramVal, valErr
+			}
+
+			timeVal, timeParseErr := time.Parse(time.RFC3339, val)
+			if timeParseErr != nil {
+				return paramVal, timeParseErr
+			}
+
+			paramVal = timeVal
+		}
+
+		return paramVal, nil
+	}
+
+	minLastModified, minLastModifiedErr := timeParamValExtractor("minLastModified")
+	if minLastModifiedErr != nil {
+		return nil, minLastModifiedErr
+	}
+
+	maxLastModified, maxLastModifiedErr := timeParamValExtractor("maxLastModified")
+	if maxLastModifiedErr != nil {
+		return nil, maxLastModifiedErr
+	}
+
+	return &operations.S3InputReadOperation{
+		Name: name,
+		Params: &operations.S3InputReadOperationParams{
+			S3ClientParams:  s3ClientParams,
+			Prefix:          prefix,
+			Pattern:         pattern,
+			MinLastModified: minLastModified,
+			MaxLastModified: maxLastModified,
+			MinFileSize:     minFileSize,
+			MaxFileSize:     maxFileSize,
+			MaxObjects:      maxObjects,
+		},
+	}, nil
+}
@@ -0,0 +1,61 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+)
+
+func NewS3ObjectDeleteOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.S3ObjectDeleteOperation, error) {
+	s3ClientParams, s3ClientParamsErr := newS3ClientParams(params, parameterLoaderProvider)
+	if s3ClientParamsErr != nil {
+		return nil, s3ClientParamsErr
+	}
+
+	var bucketMetadataKey = ""
+	if bucketMetadataKeyParameter, ok := params["bucketMetadataKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bucketMetadataKeyParameter.SourceType,
+			bucketMetadataKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		bucketMetadataKey = val
+	}
+
+	var keyMetadataKey = ""
+	if keyMetadataKeyParameter, ok := params["keyMetadataKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyMetadataKeyParameter.SourceType,
+			keyMetadataKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyMetadataKey = val
+	}
+	return &operations.S3ObjectDeleteOperation{
+		Name: name,
+		Params: &operations.S3ObjectDeleteOperationParams{
+			S3ClientParams:    s3ClientParams,
+			BucketMetadataKey: bucketMetadataKey,
+			KeyMetadataKey:    keyMetadataKey,
+		},
+	}, nil
+}
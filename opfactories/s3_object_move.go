@@ -0,0 +1,123 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewS3ObjectMoveOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.S3ObjectMoveOperation, error) {
+	s3ClientParams, s3ClientParamsErr := newS3ClientParams(params, parameterLoaderProvider)
+	if s3ClientParamsErr != nil {
+		return nil, s3ClientParamsErr
+	}
+
+	var bucketMetadataKey = ""
+	if bucketMetadataKeyParameter, ok := params["bucketMetadataKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			bucketMetadataKeyParameter.SourceType,
+			bucketMetadataKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		bucketMetadataKey = val
+	}
+
+	var keyMetadataKey = ""
+	if keyMetadataKeyParameter, ok := params["keyMetadataKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyMetadataKeyParameter.SourceType,
+			keyMetadataKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyMetadataKey = val
+	}
+	var destinationBucket = ""
+	if destinationBucketParameter, ok := params["destinationBucket"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			destinationBucketParameter.SourceType,
+			destinationBucketParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		destinationBucket = val
+	}
+
+	var destinationPrefix = ""
+	if destinationPrefixParameter, ok := params["destinationPrefix"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			destinationPrefixParameter.SourceType,
+			destinationPrefixParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		destinationPrefix = val
+	}
+
+	var trimPrefix = ""
+	if trimPrefixParameter, ok := params["trimPrefix"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			trimPrefixParameter.SourceType,
+			trimPrefixParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		trimPrefix = val
+	}
+	if destinationBucket == "" && destinationPrefix == "" && trimPrefix == "" {
+		return nil, errors.New(
+			"either \"destinationBucket\", \"destinationPrefix\" or \"trimPrefix\" parameter must be set")
+	}
+
+	return &operations.S3ObjectMoveOperation{
+		Name: name,
+		Params: &operations.S3ObjectMoveOperationParams{
+			S3ClientParams:    s3ClientParams,
+			BucketMetadataKey: bucketMetadataKey,
+			KeyMetadataKey:    keyMetadataKey,
+			DestinationBucket: destinationBucket,
+			DestinationPrefix: destinationPrefix,
+			TrimPrefix:        trimPrefix,
+		},
+	}, nil
+}
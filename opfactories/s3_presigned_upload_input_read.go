@@ -0,0 +1,136 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+func NewS3PresignedUploadInputReadOperation(
+	name string,
+	req *http.Request,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.S3PresignedUploadInputReadOperation, error) {
+	s3ClientParams, s3ClientParamsErr := newS3ClientParams(params, parameterLoaderProvider)
+	if s3ClientParamsErr != nil {
+		return nil, s3ClientParamsErr
+	}
+
+	var keyPrefix = ""
+	if keyPrefixParameter, ok := params["keyPrefix"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			keyPrefixParameter.SourceType,
+			keyPrefixParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyPrefix = val
+	}
+	// The clients could read any top-level object of the bucket otherwise.
+	if keyPrefix == "" {
+		return nil, errors.New("\"keyPrefix\" parameter is required")
+	}
+
+	var tokenSecret = ""
+	if tokenSecretParameter, ok := params["tokenSecret"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			tokenSecretParameter.SourceType,
+			tokenSecretParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		tokenSecret = val
+	}
+	if tokenSecret == "" {
+		return nil, errors.New("\"tokenSecret\" parameter is required")
+	}
+
+	var presignedUrlExpiration = 15 * time.Minute
+	if presignedUrlExpirationParameter, ok := params["presignedUrlExpiration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			presignedUrlExpirationParameter.SourceType,
+			presignedUrlExpirationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		expiration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		presignedUrlExpiration = expiration
+	}
+	if presignedUrlExpiration <= 0 || presignedUrlExpiration > operations.MaxS3PresignedUrlExpiration {
+		return nil, fmt.Errorf(
+			"\"presignedUrlExpiration\" parameter must be greater than 0 and not greater than %s",
+			operations.MaxS3PresignedUrlExpiration,
+		)
+	}
+
+	// The client reports the upload is completed after the upload, so the token outlives the presigned URL.
+	var tokenExpiration = 24 * time.Hour
+	if tokenExpiration < presignedUrlExpiration {
+		tokenExpiration = presignedUrlExpiration
+	}
+	if tokenExpirationParameter, ok := params["tokenExpiration"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			tokenExpirationParameter.SourceType,
+			tokenExpirationParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		expiration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		tokenExpiration = expiration
+	}
+	if tokenExpiration < presignedUrlExpiration {
+		return nil, errors.New("\"tokenExpiration\" parameter must not be less than \"presignedUrlExpiration\"")
+	}
+
+	return &operations.S3PresignedUploadInputReadOperation{
+		Name: name,
+		Params: &operations.S3PresignedUploadInputReadOperationParams{
+			S3ClientParams:         s3ClientParams,
+			KeyPrefix:              keyPrefix,
+			TokenSecret:            tokenSecret,
+			PresignedUrlExpiration: presignedUrlExpiration,
+			TokenExpiration:        tokenExpiration,
+		},
+		Req: req,
+	}, nil
+}
@@ -4,6 +4,10 @@ import (
 	"capyfile/operations"
 	"capyfile/parameters"
 	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"time"
 )
 
 func NewS3UploadOperation(
@@ -11,11 +15,88 @@ func NewS3UploadOperation(
 	params map[string]parameters.Parameter,
 	parameterLoaderProvider parameters.ParameterLoaderProvider,
 ) (*operations.S3UploadOperation, error) {
-	var accessKeyId = ""
-	if accessKeyIdParameter, ok := params["accessKeyId"]; ok {
+	s3ClientParams, s3ClientParamsErr := newS3ClientParams(params, parameterLoaderProvider)
+	if s3ClientParamsErr != nil {
+		return nil, s3ClientParamsErr
+	}
+
+	var partSize int64 = 0
+	if partSizeParameter, ok := params["partSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			partSizeParameter.SourceType,
+			partSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		partSize = val
+	}
+	if partSize != 0 && partSize < manager.MinUploadPartSize {
+		return nil, fmt.Errorf("\"partSize\" parameter must be at least %d bytes", manager.MinUploadPartSize)
+	}
+
+	var concurrency int64 = 0
+	if concurrencyParameter, ok := params["concurrency"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			concurrencyParameter.SourceType,
+			concurrencyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		concurrency = val
+	}
+	if concurrency < 0 {
+		return nil, errors.New("\"concurrency\" parameter must be a positive number")
+	}
+
+	var checksumAlgorithm = ""
+	if checksumAlgorithmParameter, ok := params["checksumAlgorithm"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			checksumAlgorithmParameter.SourceType,
+			checksumAlgorithmParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		checksumAlgorithm = val
+	}
+	if checksumAlgorithm != "" {
+		var isKnownAlgorithm bool
+		for _, algorithm := range types.ChecksumAlgorithm("").Values() {
+			if checksumAlgorithm == string(algorithm) {
+				isKnownAlgorithm = true
+				break
+			}
+		}
+		if !isKnownAlgorithm {
+			return nil, errors.New("\"checksumAlgorithm\" parameter must be one of \"CRC32\", \"CRC32C\", \"SHA1\", \"SHA256\"")
+		}
+	}
+
+	var key = ""
+	if keyParameter, ok := params["key"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			accessKeyIdParameter.SourceType,
-			accessKeyIdParameter.Source,
+			keyParameter.SourceType,
+			keyParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -26,16 +107,32 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		accessKeyId = val
-	} else {
-		return nil, errors.New("failed to retrieve \"accessKeyId\" parameter")
+		key = val
 	}
 
-	var secretAccessKey = ""
-	if secretAccessKeyParameter, ok := params["secretAccessKey"]; ok {
+	var keyTemplateVars map[string]string
+	if keyTemplateVarsParameter, ok := params["keyTemplateVars"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			secretAccessKeyParameter.SourceType,
-			secretAccessKeyParameter.Source,
+			keyTemplateVarsParameter.SourceType,
+			keyTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		keyTemplateVars = val
+	}
+
+	var contentDisposition = ""
+	if contentDispositionParameter, ok := params["contentDisposition"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			contentDispositionParameter.SourceType,
+			contentDispositionParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -46,16 +143,17 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		secretAccessKey = val
-	} else {
-		return nil, errors.New("failed to retrieve \"secretAccessKey\" parameter")
+		contentDisposition = val
+	}
+	if contentDisposition != "" && contentDisposition != "attachment" && contentDisposition != "inline" {
+		return nil, errors.New("\"contentDisposition\" parameter must be either \"attachment\" or \"inline\"")
 	}
 
-	var sessionToken = ""
-	if sessionTokenParameter, ok := params["sessionToken"]; ok {
+	var cacheControl = ""
+	if cacheControlParameter, ok := params["cacheControl"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			sessionTokenParameter.SourceType,
-			sessionTokenParameter.Source,
+			cacheControlParameter.SourceType,
+			cacheControlParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -66,14 +164,14 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		sessionToken = val
+		cacheControl = val
 	}
 
-	var endpoint = ""
-	if endpointParameter, ok := params["endpoint"]; ok {
+	var acl = ""
+	if aclParameter, ok := params["acl"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			endpointParameter.SourceType,
-			endpointParameter.Source,
+			aclParameter.SourceType,
+			aclParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -84,16 +182,14 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		endpoint = val
-	} else {
-		return nil, errors.New("failed to retrieve \"endpoint\" parameter")
+		acl = val
 	}
 
-	var region = ""
-	if regionParameter, ok := params["region"]; ok {
+	var storageClass = ""
+	if storageClassParameter, ok := params["storageClass"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			regionParameter.SourceType,
-			regionParameter.Source,
+			storageClassParameter.SourceType,
+			storageClassParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -104,16 +200,50 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		region = val
-	} else {
-		return nil, errors.New("failed to retrieve \"region\" parameter")
+		storageClass = val
+	}
+
+	var metadata map[string]string
+	if metadataParameter, ok := params["metadata"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			metadataParameter.SourceType,
+			metadataParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		metadata = val
+	}
+
+	var tags map[string]string
+	if tagsParameter, ok := params["tags"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			tagsParameter.SourceType,
+			tagsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		tags = val
 	}
 
-	var bucket = ""
-	if bucketParameter, ok := params["bucket"]; ok {
+	var presignedUrlExpiration time.Duration
+	if presignedUrlExpirationParameter, ok := params["presignedUrlExpiration"]; ok {
 		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
-			bucketParameter.SourceType,
-			bucketParameter.Source,
+			presignedUrlExpirationParameter.SourceType,
+			presignedUrlExpirationParameter.Source,
 		)
 		if loaderErr != nil {
 			return nil, loaderErr
@@ -124,20 +254,39 @@ func NewS3UploadOperation(
 			return nil, valErr
 		}
 
-		bucket = val
-	} else {
-		return nil, errors.New("failed to retrieve \"bucket\" parameter")
+		expiration, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		presignedUrlExpiration = expiration
+	}
+	if presignedUrlExpiration < 0 || presignedUrlExpiration > operations.MaxS3PresignedUrlExpiration {
+		return nil, fmt.Errorf(
+			"\"presignedUrlExpiration\" parameter must be between 0 and %s",
+			operations.MaxS3PresignedUrlExpiration,
+		)
 	}
 
 	return &operations.S3UploadOperation{
 		Name: name,
 		Params: &operations.S3UploadOperationParams{
-			AccessKeyId:     accessKeyId,
-			SecretAccessKey: secretAccessKey,
-			SessionToken:    sessionToken,
-			Endpoint:        endpoint,
-			Region:          region,
-			Bucket:          bucket,
+			S3ClientParams: s3ClientParams,
+
+			PartSize:          partSize,
+			Concurrency:       int(concurrency),
+			ChecksumAlgorithm: checksumAlgorithm,
+
+			Key:                key,
+			KeyTemplateVars:    keyTemplateVars,
+			ContentDisposition: contentDisposition,
+			CacheControl:       cacheControl,
+			ACL:                acl,
+			StorageClass:       storageClass,
+			Metadata:           metadata,
+			Tags:               tags,
+
+			PresignedUrlExpiration: presignedUrlExpiration,
 		},
 	}, nil
 }
@@ -0,0 +1,161 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+// newSFTPClientParams Loads the parameters that are required to connect to SFTP server.
+func newSFTPClientParams(
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (operations.SFTPClientParams, error) {
+	var host = ""
+	if hostParameter, ok := params["host"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			hostParameter.SourceType,
+			hostParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		host = val
+	} else {
+		return operations.SFTPClientParams{}, errors.New("failed to retrieve \"host\" parameter")
+	}
+
+	var username = ""
+	if usernameParameter, ok := params["username"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			usernameParameter.SourceType,
+			usernameParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		username = val
+	} else {
+		return operations.SFTPClientParams{}, errors.New("failed to retrieve \"username\" parameter")
+	}
+
+	var password = ""
+	if passwordParameter, ok := params["password"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			passwordParameter.SourceType,
+			passwordParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		password = val
+	}
+
+	var privateKey = ""
+	if privateKeyParameter, ok := params["privateKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			privateKeyParameter.SourceType,
+			privateKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		privateKey = val
+	}
+
+	var privateKeyPassphrase = ""
+	if privateKeyPassphraseParameter, ok := params["privateKeyPassphrase"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			privateKeyPassphraseParameter.SourceType,
+			privateKeyPassphraseParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		privateKeyPassphrase = val
+	}
+
+	var hostKey = ""
+	if hostKeyParameter, ok := params["hostKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			hostKeyParameter.SourceType,
+			hostKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		hostKey = val
+	}
+
+	var insecureIgnoreHostKey bool = false
+	if insecureIgnoreHostKeyParameter, ok := params["insecureIgnoreHostKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			insecureIgnoreHostKeyParameter.SourceType,
+			insecureIgnoreHostKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return operations.SFTPClientParams{}, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return operations.SFTPClientParams{}, valErr
+		}
+
+		insecureIgnoreHostKey = val
+	}
+
+	if password == "" && privateKey == "" {
+		return operations.SFTPClientParams{}, errors.New("either \"password\" or \"privateKey\" parameter is required")
+	}
+
+	if hostKey == "" && !insecureIgnoreHostKey {
+		return operations.SFTPClientParams{}, errors.New("\"hostKey\" parameter is required to verify the server")
+	}
+
+	return operations.SFTPClientParams{
+		Host:                  host,
+		Username:              username,
+		Password:              password,
+		PrivateKey:            privateKey,
+		PrivateKeyPassphrase:  privateKeyPassphrase,
+		HostKey:               hostKey,
+		InsecureIgnoreHostKey: insecureIgnoreHostKey,
+	}, nil
+}
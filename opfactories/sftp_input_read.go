@@ -0,0 +1,132 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewSFTPInputReadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.SFTPInputReadOperation, error) {
+	sftpClientParams, sftpClientParamsErr := newSFTPClientParams(params, parameterLoaderProvider)
+	if sftpClientParamsErr != nil {
+		return nil, sftpClientParamsErr
+	}
+
+	var target = ""
+	if targetParameter, ok := params["target"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			targetParameter.SourceType,
+			targetParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		target = val
+	} else {
+		return nil, errors.New("failed to retrieve \"target\" parameter")
+	}
+
+	var postReadAction = ""
+	if postReadActionParameter, ok := params["postReadAction"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			postReadActionParameter.SourceType,
+			postReadActionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		postReadAction = val
+	}
+	if postReadAction != operations.SFTPPostReadActionNone &&
+		postReadAction != operations.SFTPPostReadActionDelete &&
+		postReadAction != operations.SFTPPostReadActionMove {
+		return nil, errors.New("\"postReadAction\" parameter must be either \"delete\" or \"move\"")
+	}
+
+	var postReadMoveTo = ""
+	if postReadMoveToParameter, ok := params["postReadMoveTo"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			postReadMoveToParameter.SourceType,
+			postReadMoveToParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		postReadMoveTo = val
+	}
+
+	var remotePathTemplateVars map[string]string
+	if remotePathTemplateVarsParameter, ok := params["remotePathTemplateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			remotePathTemplateVarsParameter.SourceType,
+			remotePathTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		remotePathTemplateVars = val
+	}
+
+	var createDirectories bool = false
+	if createDirectoriesParameter, ok := params["createDirectories"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			createDirectoriesParameter.SourceType,
+			createDirectoriesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		createDirectories = val
+	}
+
+	if postReadAction == operations.SFTPPostReadActionMove && postReadMoveTo == "" {
+		return nil, errors.New("\"postReadMoveTo\" parameter is required to move the files")
+	}
+
+	return &operations.SFTPInputReadOperation{
+		Name: name,
+		Params: &operations.SFTPInputReadOperationParams{
+			SFTPClientParams: sftpClientParams,
+
+			Target:                 target,
+			PostReadAction:         postReadAction,
+			PostReadMoveTo:         postReadMoveTo,
+			RemotePathTemplateVars: remotePathTemplateVars,
+			CreateDirectories:      createDirectories,
+		},
+	}, nil
+}
@@ -0,0 +1,82 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+)
+
+func NewSFTPUploadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.SFTPUploadOperation, error) {
+	sftpClientParams, sftpClientParamsErr := newSFTPClientParams(params, parameterLoaderProvider)
+	if sftpClientParamsErr != nil {
+		return nil, sftpClientParamsErr
+	}
+
+	var remotePath = ""
+	if remotePathParameter, ok := params["remotePath"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			remotePathParameter.SourceType,
+			remotePathParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		remotePath = val
+	}
+
+	var remotePathTemplateVars map[string]string
+	if remotePathTemplateVarsParameter, ok := params["remotePathTemplateVars"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			remotePathTemplateVarsParameter.SourceType,
+			remotePathTemplateVarsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringMapValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		remotePathTemplateVars = val
+	}
+
+	var createDirectories bool = false
+	if createDirectoriesParameter, ok := params["createDirectories"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			createDirectoriesParameter.SourceType,
+			createDirectoriesParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		createDirectories = val
+	}
+
+	return &operations.SFTPUploadOperation{
+		Name: name,
+		Params: &operations.SFTPUploadOperationParams{
+			SFTPClientParams: sftpClientParams,
+
+			RemotePath:             remotePath,
+			RemotePathTemplateVars: remotePathTemplateVars,
+			CreateDirectories:      createDirectories,
+		},
+	}, nil
+}
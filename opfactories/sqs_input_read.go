@@ -0,0 +1,183 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewSQSInputReadOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.SQSInputReadOperation, error) {
+	queueInputParams, queueInputParamsErr := newQueueInputParams(params, parameterLoaderProvider)
+	if queueInputParamsErr != nil {
+		return nil, queueInputParamsErr
+	}
+	// SQS does not allow to receive more than 10 messages at once.
+	if queueInputParams.MaxMessages > 10 {
+		return nil, errors.New("\"maxMessages\" parameter must not be greater than 10")
+	}
+	// SQS does not allow to wait longer than 20 seconds.
+	if queueInputParams.WaitTime > 20*time.Second {
+		return nil, errors.New("\"waitTime\" parameter must not be greater than 20s")
+	}
+
+	var accessKeyId = ""
+	if accessKeyIdParameter, ok := params["accessKeyId"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			accessKeyIdParameter.SourceType,
+			accessKeyIdParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		accessKeyId = val
+	}
+
+	var secretAccessKey = ""
+	if secretAccessKeyParameter, ok := params["secretAccessKey"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			secretAccessKeyParameter.SourceType,
+			secretAccessKeyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		secretAccessKey = val
+	}
+
+	var sessionToken = ""
+	if sessionTokenParameter, ok := params["sessionToken"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sessionTokenParameter.SourceType,
+			sessionTokenParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sessionToken = val
+	}
+
+	var endpoint = ""
+	if endpointParameter, ok := params["endpoint"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			endpointParameter.SourceType,
+			endpointParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		endpoint = val
+	}
+
+	var region = ""
+	if regionParameter, ok := params["region"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			regionParameter.SourceType,
+			regionParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		region = val
+	} else {
+		return nil, errors.New("failed to retrieve \"region\" parameter")
+	}
+
+	var queueUrl = ""
+	if queueUrlParameter, ok := params["queueUrl"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			queueUrlParameter.SourceType,
+			queueUrlParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		queueUrl = val
+	} else {
+		return nil, errors.New("failed to retrieve \"queueUrl\" parameter")
+	}
+
+	var visibilityTimeout time.Duration = 0
+	if visibilityTimeoutParameter, ok := params["visibilityTimeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			visibilityTimeoutParameter.SourceType,
+			visibilityTimeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedVisibilityTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		visibilityTimeout = parsedVisibilityTimeout
+	}
+	if visibilityTimeout < 0 {
+		return nil, errors.New("\"visibilityTimeout\" parameter must be a positive duration")
+	}
+	if queueInputParams.VisibilityExtensionInterval > 0 && visibilityTimeout <= queueInputParams.VisibilityExtensionInterval {
+		return nil, errors.New(
+			"\"visibilityTimeout\" parameter must be greater than \"visibilityExtensionInterval\" parameter")
+	}
+
+	return &operations.SQSInputReadOperation{
+		Name: name,
+		Params: &operations.SQSInputReadOperationParams{
+			QueueInputParams:  queueInputParams,
+			AccessKeyId:       accessKeyId,
+			SecretAccessKey:   secretAccessKey,
+			SessionToken:      sessionToken,
+			Endpoint:          endpoint,
+			Region:            region,
+			QueueUrl:          queueUrl,
+			VisibilityTimeout: visibilityTimeout,
+		},
+	}, nil
+}
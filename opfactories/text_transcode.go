@@ -0,0 +1,116 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+)
+
+func NewTextTranscodeOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.TextTranscodeOperation, error) {
+	var sourceEncoding = ""
+	if sourceEncodingParameter, ok := params["sourceEncoding"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			sourceEncodingParameter.SourceType,
+			sourceEncodingParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		sourceEncoding = val
+	}
+
+	var targetEncoding = "utf-8"
+	if targetEncodingParameter, ok := params["targetEncoding"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			targetEncodingParameter.SourceType,
+			targetEncodingParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		targetEncoding = val
+	}
+
+	var lineEndings = ""
+	if lineEndingsParameter, ok := params["lineEndings"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			lineEndingsParameter.SourceType,
+			lineEndingsParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		lineEndings = val
+	}
+
+	var writeBOM bool = false
+	if writeBOMParameter, ok := params["writeBOM"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			writeBOMParameter.SourceType,
+			writeBOMParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadBoolValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		writeBOM = val
+	}
+	// The encoding is detected if it is not set explicitly.
+	if sourceEncoding == "auto" {
+		sourceEncoding = ""
+	}
+	if sourceEncoding != "" {
+		_, sourceEncodingErr := operations.TextEncoding(sourceEncoding)
+		if sourceEncodingErr != nil {
+			return nil, errors.New("\"sourceEncoding\" parameter is invalid: " + sourceEncodingErr.Error())
+		}
+	}
+
+	_, targetEncodingErr := operations.TextEncoding(targetEncoding)
+	if targetEncodingErr != nil {
+		return nil, errors.New("\"targetEncoding\" parameter is invalid: " + targetEncodingErr.Error())
+	}
+
+	if lineEndings != "" && lineEndings != operations.LineEndingsLF && lineEndings != operations.LineEndingsCRLF {
+		return nil, errors.New("\"lineEndings\" parameter must be either \"lf\" or \"crlf\"")
+	}
+
+	operationParams := &operations.TextTranscodeOperationParams{
+		SourceEncoding: sourceEncoding,
+		TargetEncoding: targetEncoding,
+		LineEndings:    lineEndings,
+		WriteBOM:       writeBOM,
+	}
+
+	return &operations.TextTranscodeOperation{
+		Name:   name,
+		Params: operationParams,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package opfactories
+
+import (
+	"capyfile/operations"
+	"capyfile/parameters"
+	"errors"
+	"time"
+)
+
+func NewVirusScanOperation(
+	name string,
+	params map[string]parameters.Parameter,
+	parameterLoaderProvider parameters.ParameterLoaderProvider,
+) (*operations.VirusScanOperation, error) {
+	var network = "tcp"
+	if networkParameter, ok := params["network"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			networkParameter.SourceType,
+			networkParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		network = val
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, errors.New("\"network\" parameter must be either \"tcp\" or \"unix\"")
+	}
+
+	var address = ""
+	if addressParameter, ok := params["address"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			addressParameter.SourceType,
+			addressParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		address = val
+	} else {
+		return nil, errors.New("failed to retrieve \"address\" parameter")
+	}
+
+	var timeout = 60 * time.Second
+	if timeoutParameter, ok := params["timeout"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			timeoutParameter.SourceType,
+			timeoutParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		parsedTimeout, parseErr := time.ParseDuration(val)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+
+		timeout = parsedTimeout
+	}
+	if timeout < 0 {
+		return nil, errors.New("\"timeout\" parameter must be a positive duration")
+	}
+
+	var chunkSize int64 = 64 * 1024
+	if chunkSizeParameter, ok := params["chunkSize"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			chunkSizeParameter.SourceType,
+			chunkSizeParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadIntValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		chunkSize = val
+	}
+	if chunkSize <= 0 {
+		return nil, errors.New("\"chunkSize\" parameter must be a positive integer")
+	}
+
+	var failPolicy = operations.VirusScanFailPolicyClosed
+	if failPolicyParameter, ok := params["failPolicy"]; ok {
+		parameterLoader, loaderErr := parameterLoaderProvider.ParameterLoader(
+			failPolicyParameter.SourceType,
+			failPolicyParameter.Source,
+		)
+		if loaderErr != nil {
+			return nil, loaderErr
+		}
+
+		val, valErr := parameterLoader.LoadStringValue()
+		if valErr != nil {
+			return nil, valErr
+		}
+
+		failPolicy = val
+	}
+	if failPolicy != operations.VirusScanFailPolicyClosed && failPolicy != operations.VirusScanFailPolicyOpen {
+		return nil, errors.New("\"failPolicy\" parameter must be either \"closed\" or \"open\"")
+	}
+
+	return &operations.VirusScanOperation{
+		Name: name,
+		Params: &operations.VirusScanOperationParams{
+			Network:    network,
+			Address:    address,
+			Timeout:    timeout,
+			ChunkSize:  chunkSize,
+			FailPolicy: failPolicy,
+		},
+	}, nil
+}
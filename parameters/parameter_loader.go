@@ -26,4 +26,5 @@ type ParameterLoader interface {
 	LoadIntValue() (int64, error)
 	LoadStringValue() (string, error)
 	LoadStringArrayValue() ([]string, error)
+	LoadStringMapValue() (map[string]string, error)
 }